@@ -0,0 +1,254 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is used when Options.CacheTTL is not set
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultCacheSize is the size of the LRU cache created by NewLRUCache
+	// when the size is not set
+	DefaultCacheSize = 1024
+)
+
+// resources whose responses embed other resources, a change to the
+// key invalidates the cached responses of the values as well
+var relatedResources = map[string][]string{
+	"aliasaddresses": {"users"},
+	"domains":        {"organizations"},
+	"organizations":  {"domains"},
+}
+
+// CacheEntry holds a cached response
+type CacheEntry struct {
+	Body         []byte
+	ETag         string
+	LastModified string
+	Expires      time.Time
+}
+
+// CacheStore stores cached responses, implementations must be safe for
+// concurrent use
+type CacheStore interface {
+	// Get returns the entry for a key
+	Get(key string) (entry *CacheEntry, ok bool)
+	// Set stores the entry for a key
+	Set(key string, entry *CacheEntry)
+	// Purge removes the entries whose keys match
+	Purge(match func(key string) bool)
+}
+
+// LRUCache is an in-memory CacheStore that evicts the least recently
+// used entries
+type LRUCache struct {
+	size  int
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewLRUCache returns an LRUCache holding up to size entries
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &LRUCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for a key
+func (l *LRUCache) Get(key string) (entry *CacheEntry, ok bool) {
+	var e *list.Element
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok = l.items[key]; ok {
+		l.ll.MoveToFront(e)
+		entry = e.Value.(*lruItem).entry
+	}
+
+	return
+}
+
+// Set stores the entry for a key
+func (l *LRUCache) Set(key string, entry *CacheEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.items[key]; ok {
+		l.ll.MoveToFront(e)
+		e.Value.(*lruItem).entry = entry
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&lruItem{key: key, entry: entry})
+
+	for l.ll.Len() > l.size {
+		e := l.ll.Back()
+		l.ll.Remove(e)
+		delete(l.items, e.Value.(*lruItem).key)
+	}
+}
+
+// Purge removes the entries whose keys match
+func (l *LRUCache) Purge(match func(key string) bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, e := range l.items {
+		if match(key) {
+			l.ll.Remove(e)
+			delete(l.items, key)
+		}
+	}
+}
+
+// Len returns the number of entries
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ll.Len()
+}
+
+// cacheKey is the request URL and a fingerprint of the token so that
+// clients sharing a store never see each other's responses
+func (c *Client) cacheKey(req *http.Request) string {
+	return req.URL.String() + "#" + c.cacheID
+}
+
+// doCached serves a GET request from the cache when the entry is
+// fresh, revalidates stale entries using the ETag or Last-Modified
+// validators and stores successful responses
+func (c *Client) doCached(req *http.Request, v interface{}) (err error) {
+	var ok bool
+	var data []byte
+	var entry *CacheEntry
+	var resp *http.Response
+
+	key := c.cacheKey(req)
+
+	if entry, ok = c.cache.Get(key); ok {
+		if time.Now().Before(entry.Expires) {
+			err = decodeCached(entry.Body, v)
+			return
+		}
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		c.cache.Set(key, &CacheEntry{
+			Body:         entry.Body,
+			ETag:         entry.ETag,
+			LastModified: entry.LastModified,
+			Expires:      time.Now().Add(c.cacheTTL),
+		})
+		err = decodeCached(entry.Body, v)
+		return
+	}
+
+	if err = checkResponse(resp); err != nil {
+		return
+	}
+
+	if data, err = ioutil.ReadAll(resp.Body); err != nil {
+		return
+	}
+
+	if !strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		c.cache.Set(key, &CacheEntry{
+			Body:         data,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Expires:      time.Now().Add(c.cacheTTL),
+		})
+	}
+
+	err = decodeCached(data, v)
+
+	return
+}
+
+// invalidate removes the cached responses of the resource a
+// successful Create, Update or Delete request changed
+func (c *Client) invalidate(p string) {
+	if c.cache == nil {
+		return
+	}
+
+	resource := strings.SplitN(p, "/", 2)[0]
+	prefixes := []string{apiPath(resource)}
+	for _, r := range relatedResources[resource] {
+		prefixes = append(prefixes, apiPath(r))
+	}
+
+	c.cache.Purge(func(key string) bool {
+		u := key
+		if i := strings.Index(u, "/api/"); i != -1 {
+			u = u[i:]
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(u, prefix) {
+				switch rest := u[len(prefix):]; {
+				case rest == "", rest[0] == '/', rest[0] == '?', rest[0] == '#':
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+func decodeCached(data []byte, v interface{}) (err error) {
+	if v == nil {
+		return
+	}
+
+	err = json.Unmarshal(data, v)
+
+	return
+}
+
+func cacheID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,233 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type cacheTestServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+	name string
+	etag string
+}
+
+func newCacheTestServer() *cacheTestServer {
+	s := &cacheTestServer{
+		hits: make(map[string]int),
+		name: "example.com",
+		etag: `"v1"`,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.hits[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method == http.MethodPut:
+			s.name = "example.net"
+			s.etag = `"v2"`
+			fmt.Fprintf(w, `{"id": 1, "name": "%s"}`, s.name)
+		case r.URL.Path == "/api/v1/domains/2":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 404, "message": "Not Found"}`)
+		case r.URL.Path == "/api/v1/status":
+			w.Header().Set("Cache-Control", "no-store")
+			fmt.Fprint(w, `{"inbound": 1}`)
+		case r.Header.Get("If-None-Match") == s.etag:
+			w.WriteHeader(http.StatusNotModified)
+		case r.URL.Path == "/api/v1/organizations/1":
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			fmt.Fprint(w, `{"id": 1, "name": "Example"}`)
+		default:
+			w.Header().Set("ETag", s.etag)
+			fmt.Fprintf(w, `{"id": 1, "name": "%s"}`, s.name)
+		}
+	}))
+	return s
+}
+
+func (s *cacheTestServer) count(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[key]
+}
+
+func TestLRUCache(t *testing.T) {
+	l := NewLRUCache(2)
+	l.Set("a", &CacheEntry{ETag: "a"})
+	l.Set("b", &CacheEntry{ETag: "b"})
+	if _, ok := l.Get("a"); !ok {
+		t.Fatalf("Expected entry a to be cached")
+	}
+	l.Set("c", &CacheEntry{ETag: "c"})
+	if _, ok := l.Get("b"); ok {
+		t.Errorf("Expected entry b to be evicted")
+	}
+	if l.Len() != 2 {
+		t.Errorf("Expected %d got %d", 2, l.Len())
+	}
+	l.Set("c", &CacheEntry{ETag: "d"})
+	if e, _ := l.Get("c"); e.ETag != "d" {
+		t.Errorf("Expected %s got %s", "d", e.ETag)
+	}
+	l.Purge(func(key string) bool {
+		return key == "a"
+	})
+	if _, ok := l.Get("a"); ok {
+		t.Errorf("Expected entry a to be purged")
+	}
+	if l.Len() != 1 {
+		t.Errorf("Expected %d got %d", 1, l.Len())
+	}
+	if NewLRUCache(0).size != DefaultCacheSize {
+		t.Errorf("Expected %d got %d", DefaultCacheSize, NewLRUCache(0).size)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	server := newCacheTestServer()
+	defer server.Close()
+
+	client, err := getTestClient(server.URL, &Options{Cache: NewLRUCache(10)})
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	if client.cacheTTL != DefaultCacheTTL {
+		t.Errorf("Expected %s got %s", DefaultCacheTTL, client.cacheTTL)
+	}
+
+	for i := 0; i < 3; i++ {
+		d, err := client.GetDomain(1)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if d.Name != "example.com" {
+			t.Errorf("Expected %s got %s", "example.com", d.Name)
+		}
+	}
+	if n := server.count("GET /api/v1/domains/1"); n != 1 {
+		t.Errorf("Expected %d got %d", 1, n)
+	}
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	server := newCacheTestServer()
+	defer server.Close()
+
+	cache := NewLRUCache(10)
+	client, err := getTestClient(server.URL, &Options{Cache: cache, CacheTTL: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+
+	for i := 0; i < 3; i++ {
+		d, err := client.GetDomain(1)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if d.Name != "example.com" {
+			t.Errorf("Expected %s got %s", "example.com", d.Name)
+		}
+	}
+	if n := server.count("GET /api/v1/domains/1"); n != 3 {
+		t.Errorf("Expected %d got %d", 3, n)
+	}
+
+	if _, err = client.GetOrganization(1); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	entry, ok := cache.Get(client.cacheKey(httptest.NewRequest(http.MethodGet, server.URL+"/api/v1/organizations/1", nil)))
+	if !ok {
+		t.Fatalf("Expected the organization to be cached")
+	}
+	if entry.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" {
+		t.Errorf("Expected Last-Modified to be stored got %s", entry.LastModified)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	server := newCacheTestServer()
+	defer server.Close()
+
+	cache := NewLRUCache(10)
+	client, err := getTestClient(server.URL, &Options{Cache: cache, CacheTTL: time.Hour})
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+
+	if _, err = client.GetDomain(1); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if _, err = client.GetOrganization(1); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if _, err = client.GetUser(1); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if cache.Len() != 3 {
+		t.Fatalf("Expected %d got %d", 3, cache.Len())
+	}
+
+	if err = client.UpdateDomain(&Domain{ID: 1, Name: "example.net"}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Expected %d got %d", 1, cache.Len())
+	}
+
+	d, err := client.GetDomain(1)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if d.Name != "example.net" {
+		t.Errorf("Expected %s got %s", "example.net", d.Name)
+	}
+	if n := server.count("GET /api/v1/domains/1"); n != 2 {
+		t.Errorf("Expected %d got %d", 2, n)
+	}
+}
+
+func TestCacheNotStored(t *testing.T) {
+	server := newCacheTestServer()
+	defer server.Close()
+
+	cache := NewLRUCache(10)
+	client, err := getTestClient(server.URL, &Options{Cache: cache})
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+
+	if _, err = client.GetSystemStatus(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if _, err = client.GetDomain(2); err == nil {
+		t.Fatalf("An error should be returned")
+	} else if !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a 404 error got %s", err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected %d got %d", 0, cache.Len())
+	}
+}
+
+func TestCacheKeyToken(t *testing.T) {
+	a, _ := getTestClient("http://localhost", &Options{Cache: NewLRUCache(10)})
+	b, _ := New("http://localhost", "other-token", &Options{Cache: NewLRUCache(10)})
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/api/v1/domains/1", nil)
+	if a.cacheKey(req) == b.cacheKey(req) {
+		t.Errorf("Expected cache keys to differ between tokens")
+	}
+}
@@ -11,12 +11,14 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // Client represents the Baruwa API client
@@ -25,6 +27,11 @@ type Client struct {
 	UserAgent string
 	client    *http.Client
 	token     string
+	cache     CacheStore
+	cacheTTL  time.Duration
+	cacheID   string
+	dryRun    *dryRun
+	events    *EventBus
 }
 
 // Options represents optional settings and flags that can be passed to New
@@ -33,6 +40,21 @@ type Options struct {
 	HTTPClient *http.Client
 	// User agent for HTTP client
 	UserAgent string
+	// Cache stores GET responses when set, see NewLRUCache
+	Cache CacheStore
+	// CacheTTL is how long cached responses are used before they are
+	// revalidated, defaults to DefaultCacheTTL
+	CacheTTL time.Duration
+	// DryRun sends GET requests as normal but records POST, PUT and
+	// DELETE requests instead of sending them, see DryRunRequests
+	DryRun bool
+	// DryRunLog receives a line for each request recorded in dry-run
+	// mode with the passwords in the form replaced, nothing is logged
+	// when it is nil
+	DryRunLog io.Writer
+	// Events receives an Event after each successful Create, Update or
+	// Delete request, no events are published in dry-run mode
+	Events *EventBus
 }
 
 // TokenResponse is for API response for the /oauth2/token endpoint
@@ -56,10 +78,17 @@ func (r *ErrorResponse) Error() string {
 	return fmt.Sprintf("%v %v: %d %s", r.Response.Request.Method, r.Response.Request.URL, r.Code, r.Message)
 }
 
+// IsNotFound returns true if err is an ErrorResponse for a 404
+func IsNotFound(err error) bool {
+	var errResp *ErrorResponse
+
+	return errors.As(err, &errResp) && errResp.Code == http.StatusNotFound
+}
+
 func (c *Client) newRequest(method, path string, opts *ListOptions, body io.Reader) (req *http.Request, err error) {
-	var k, p string
+	var p string
 	var q url.Values
-	var u, nu, rel *url.URL
+	var u, nu, rel, bu *url.URL
 
 	if rel, err = url.ParseRequestURI(path); err != nil {
 		return
@@ -69,17 +98,13 @@ func (c *Client) newRequest(method, path string, opts *ListOptions, body io.Read
 
 	if method == http.MethodGet && opts != nil && opts.Page != "" {
 		if nu, err = url.Parse(opts.Page); err == nil {
-			if strings.HasPrefix(nu.String(), u.String()) {
-				q = nu.Query()
-				if len(q) >= 1 {
-					for k = range q {
-						if k != "page" {
-							q.Del(k)
-						}
-					}
-					if p = q.Get("page"); p != "" {
-						u.RawQuery = q.Encode()
-					}
+			bu = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+			if strings.HasPrefix(nu.String(), bu.String()) {
+				if p = nu.Query().Get("page"); p != "" {
+					// keep any filters already set on the path
+					q = u.Query()
+					q.Set("page", p)
+					u.RawQuery = q.Encode()
 				}
 			}
 		}
@@ -106,6 +131,11 @@ func (c *Client) get(path string, opts *ListOptions, data interface{}) (err erro
 		return
 	}
 
+	if c.cache != nil {
+		err = c.doCached(req, data)
+		return
+	}
+
 	err = c.doWithOAuth(req, data)
 
 	return
@@ -120,7 +150,17 @@ func (c *Client) post(p string, v url.Values, data interface{}) (err error) {
 		return
 	}
 
-	err = c.doWithOAuth(req, data)
+	if c.dryRun != nil {
+		err = c.intercept(req, v, data)
+		return
+	}
+
+	before := c.before(req, data)
+
+	if err = c.doWithOAuth(req, data); err == nil {
+		c.invalidate(p)
+		c.publish(req.Method, p, v, before, data)
+	}
 
 	return
 }
@@ -134,7 +174,17 @@ func (c *Client) put(p string, v url.Values, data interface{}) (err error) {
 		return
 	}
 
-	err = c.doWithOAuth(req, data)
+	if c.dryRun != nil {
+		err = c.intercept(req, v, data)
+		return
+	}
+
+	before := c.before(req, data)
+
+	if err = c.doWithOAuth(req, data); err == nil {
+		c.invalidate(p)
+		c.publish(req.Method, p, v, before, data)
+	}
 
 	return
 }
@@ -152,7 +202,17 @@ func (c *Client) delete(p string, v url.Values) (err error) {
 		}
 	}
 
-	err = c.doWithOAuth(req, nil)
+	if c.dryRun != nil {
+		err = c.intercept(req, v, nil)
+		return
+	}
+
+	before := c.before(req, nil)
+
+	if err = c.doWithOAuth(req, nil); err == nil {
+		c.invalidate(p)
+		c.publish(req.Method, p, v, before, nil)
+	}
 
 	return
 }
@@ -195,8 +255,6 @@ func (c *Client) doWithOAuth(req *http.Request, data interface{}) (err error) {
 }
 
 func (c *Client) do(req *http.Request, v interface{}) (err error) {
-	var data []byte
-	var errResp *ErrorResponse
 	var resp *http.Response
 
 	if resp, err = c.client.Do(req); err != nil {
@@ -204,6 +262,23 @@ func (c *Client) do(req *http.Request, v interface{}) (err error) {
 	}
 	defer resp.Body.Close()
 
+	if err = checkResponse(resp); err != nil {
+		return
+	}
+
+	if v == nil {
+		return
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+
+	return
+}
+
+func checkResponse(resp *http.Response) (err error) {
+	var data []byte
+	var errResp *ErrorResponse
+
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		errResp = &ErrorResponse{Response: resp}
 		errResp.Code = resp.StatusCode
@@ -216,16 +291,8 @@ func (c *Client) do(req *http.Request, v interface{}) (err error) {
 		} else {
 			err = errResp
 		}
-
-		return
 	}
 
-	if v == nil {
-		return
-	}
-
-	err = json.NewDecoder(resp.Body).Decode(v)
-
 	return
 }
 
@@ -271,5 +338,22 @@ func New(endpoint, token string, options *Options) (c *Client, err error) {
 		token:     token,
 	}
 
+	if options != nil && options.Cache != nil {
+		c.cache = options.Cache
+		c.cacheTTL = options.CacheTTL
+		if c.cacheTTL <= 0 {
+			c.cacheTTL = DefaultCacheTTL
+		}
+		c.cacheID = cacheID(token)
+	}
+
+	if options != nil {
+		c.events = options.Events
+	}
+
+	if options != nil && options.DryRun {
+		c.dryRun = &dryRun{log: options.DryRunLog}
+	}
+
 	return
 }
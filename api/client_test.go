@@ -11,7 +11,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/cassette"
+)
+
+// Set BARUWA_RECORD_URL and BARUWA_RECORD_TOKEN to re-record the
+// fixtures in testdata/fixtures against a real Baruwa server
+const (
+	recordURLEnv   = "BARUWA_RECORD_URL"
+	recordTokenEnv = "BARUWA_RECORD_TOKEN"
 )
 
 func getTestServer(code int, body string) *httptest.Server {
@@ -33,6 +44,36 @@ func getTestServerAndClient(code int, body string) (*httptest.Server, *Client, e
 	return server, client, err
 }
 
+// getTestFixtureClient returns a client that replays the cassette
+// testdata/fixtures/<test name>.json
+func getTestFixtureClient(t *testing.T) (c *Client, err error) {
+	var rec *cassette.Recorder
+
+	endpoint := "http://baruwa.example.com"
+	token := "test-token"
+	mode := cassette.ModeReplay
+	if u := os.Getenv(recordURLEnv); u != "" {
+		endpoint = u
+		token = os.Getenv(recordTokenEnv)
+		mode = cassette.ModeRecord
+	}
+
+	path := filepath.Join("testdata", "fixtures", t.Name()+".json")
+	if rec, err = cassette.New(path, mode, nil); err != nil {
+		return
+	}
+
+	t.Cleanup(func() {
+		if err := rec.Stop(); err != nil {
+			t.Errorf("An error should not be returned: %s", err)
+		}
+	})
+
+	c, err = New(endpoint, token, &Options{HTTPClient: rec.HTTPClient()})
+
+	return
+}
+
 func TestNewErrors(t *testing.T) {
 	c, e := New("", "", nil)
 	if e == nil {
@@ -199,3 +240,21 @@ func TestNewRequestErrors(t *testing.T) {
 		t.Fatalf("An error should be returned")
 	}
 }
+
+func TestIsNotFound(t *testing.T) {
+	server, client, err := getTestServerAndClient(http.StatusNotFound, `{"error": "Not Found", "code": 404}`)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	_, err = client.GetDomain(1)
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error got '%v'", err)
+	}
+	if !IsNotFound(fmt.Errorf("reading domain: %w", err)) {
+		t.Errorf("Expected a wrapped not found error to match")
+	}
+	if IsNotFound(nil) || IsNotFound(fmt.Errorf("Not Found")) || IsNotFound(&ErrorResponse{Code: http.StatusForbidden}) {
+		t.Errorf("Expected other errors not to match")
+	}
+}
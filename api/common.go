@@ -45,6 +45,25 @@ type ListOptions struct {
 	Page string
 }
 
+// EachPage calls fetch with the options of each page, starting with
+// the first, until fetch returns done or an error or there are no more
+// pages. fetch should return done when a page holds no items.
+func EachPage(fetch func(opts *ListOptions) (links Links, done bool, err error)) (err error) {
+	var done bool
+	var links Links
+	var opts *ListOptions
+
+	for {
+		if links, done, err = fetch(opts); err != nil || done || links.Pages.Next == "" {
+			return
+		}
+
+		opts = &ListOptions{
+			Page: links.Pages.Next,
+		}
+	}
+}
+
 // MyTime custom date formater
 type MyTime struct {
 	time.Time
@@ -9,6 +9,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -71,3 +72,44 @@ func TestLocalFloat64(t *testing.T) {
 		t.Errorf("Expected %s got %s", "0.1", s)
 	}
 }
+
+func TestEachPage(t *testing.T) {
+	var pages []string
+
+	next := map[string]string{"": "http://baruwa.example.com/api/v1/users?page=2", "2": "http://baruwa.example.com/api/v1/users?page=3"}
+	err := EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		page := ""
+		if opts != nil {
+			page = opts.Page[len(opts.Page)-1:]
+		}
+		pages = append(pages, page)
+		links.Pages.Next = next[page]
+		return
+	})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(pages) != 3 || pages[2] != "3" {
+		t.Errorf("Expected %d got %v", 3, pages)
+	}
+
+	pages = nil
+	EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		pages = append(pages, "")
+		links.Pages.Next = next[""]
+		done = true
+		return
+	})
+	if len(pages) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(pages))
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		links.Pages.Next = next[""]
+		err = fmt.Errorf("failed")
+		return
+	})
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
@@ -38,6 +38,28 @@ const (
 	clientIDError        = "clientID is required"
 	clientSecretError    = "secret is required"
 	pwFormError          = "The form param is required"
+	itemIDError          = "The itemID param should be > 0"
+	nodeIDError          = "The nodeID param should be > 0"
+	directionParamError  = "The direction param should be inbound or outbound"
+	reportOptsError      = "The opts param is required"
+	reportRangeError     = "The opts.Start param should be before opts.End"
+	reportIntervalError  = "The opts.Interval param should be hour or day"
+	reportMetricError    = "The metric param is not a valid report metric"
+	adminIDError         = "The adminID param should be > 0"
+	usernameParamError   = "The username param is required"
+	emailParamError      = "The email param is required"
+	userNotFoundError    = "The user account was not found"
+	aliasOwnerError      = "The alias address does not belong to the user"
+	addressParamError    = "The address param should be an email address"
+	templateError        = "The user map template is invalid at position %d: %s"
+	// QueueInbound - inbound mail queue direction
+	QueueInbound = "inbound"
+	// QueueOutbound - outbound mail queue direction
+	QueueOutbound = "outbound"
+	// ReportHourly - group report statistics by hour
+	ReportHourly = "hour"
+	// ReportDaily - group report statistics by day
+	ReportDaily = "day"
 	// UserListURL - users list paging url fmt string
 	UserListURL = "%s/api/%s/users?page=%d"
 	// OrgListURL - organization list paging url fmt string
@@ -58,4 +80,12 @@ const (
 	DASListURL = "%s/api/%s/authservers/%d?page=%d"
 	// DAliasListURL - domain aliases list paging url fmt string
 	DAliasListURL = "%s/api/%s/domainaliases/%d?page=%d"
+	// MailQueueListURL - mail queue list paging url fmt string
+	MailQueueListURL = "%s/api/%s/mailqueue/%s?page=%d"
+	// NodeListURL - scanner nodes list paging url fmt string
+	NodeListURL = "%s/api/%s/nodes?page=%d"
+	// OrgAdminListURL - organization admins list paging url fmt string
+	OrgAdminListURL = "%s/api/%s/organizations/admins/%d?page=%d"
+	// AliasListURL - user alias addresses list paging url fmt string
+	AliasListURL = "%s/api/%s/aliasaddresses/list/%d?page=%d"
 )
@@ -14,28 +14,10 @@ import (
 )
 
 func TestGetDomainAliasesOK(t *testing.T) {
-	data := `
-	{
-		"items": [{
-			"status": true,
-			"domain": {
-				"name": "example.com",
-				"id": 2
-			},
-			"accept_inbound": true,
-			"id": 2,
-			"name": "example.net"
-		}],
-		"meta": {
-			"total": 1
-		}
-	}
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetDomainAliases(2, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -52,12 +34,10 @@ func TestGetDomainAliasesOK(t *testing.T) {
 }
 
 func TestGetDomainAliasError(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusInternalServerError, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	alias, err := client.GetDomainAlias(0, 1)
 	if err == nil {
 		t.Fatalf("An error should be returned")
@@ -82,7 +62,7 @@ func TestGetDomainAliasError(t *testing.T) {
 		t.Fatalf("An error should be returned")
 	}
 	path := fmt.Sprintf("domainaliases/%d/%d", domainID, aliasID)
-	expected := fmt.Sprintf("GET %s%s: %d 500 Internal Server Error", server.URL, apiPath(path), http.StatusInternalServerError)
+	expected := fmt.Sprintf("GET %s%s: %d 500 Internal Server Error", client.BaseURL, apiPath(path), http.StatusInternalServerError)
 	if err.Error() != expected {
 		t.Errorf("Expected '%s' got '%s'", expected, err)
 	}
@@ -91,23 +71,10 @@ func TestGetDomainAliasError(t *testing.T) {
 func TestGetDomainAliasOK(t *testing.T) {
 	domainID := 2
 	aliasID := 4
-	data := fmt.Sprintf(`
-	{
-		"status": true,
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"accept_inbound": true,
-		"id": %d,
-		"name": "example.net"
-	}
-	`, domainID, aliasID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	alias, err := client.GetDomainAlias(domainID, aliasID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -146,30 +113,17 @@ func TestCreateDomainAliasError(t *testing.T) {
 func TestCreateDomainAliasOK(t *testing.T) {
 	domainID := 2
 	aliasID := 4
-	data := fmt.Sprintf(`
-	{
-		"status": true,
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"accept_inbound": true,
-		"id": %d,
-		"name": "example.net"
-	}
-	`, domainID, aliasID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &DomainAliasForm{
 		Name:          "example.net",
 		Enabled:       true,
 		AcceptInbound: true,
-		Domain:        2,
+		Domain:        domainID,
 	}
-	a, err := client.CreateDomainAlias(2, f)
+	a, err := client.CreateDomainAlias(domainID, f)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
 	}
@@ -217,23 +171,10 @@ func TestUpdateDomainAliasError(t *testing.T) {
 func TestUpdateDomainAliasOK(t *testing.T) {
 	domainID := 2
 	aliasID := 4
-	data := fmt.Sprintf(`
-	{
-		"status": true,
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"accept_inbound": false,
-		"id": %d,
-		"name": "example.net"
-	}
-	`, domainID, aliasID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	a := &DomainAliasForm{
 		ID:            aliasID,
 		Name:          "example.net",
@@ -286,12 +227,10 @@ func TestDeleteDomainAliasError(t *testing.T) {
 func TestDeleteDomainAliasOK(t *testing.T) {
 	domainID := 2
 	aliasID := 4
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	a := &DomainAliasForm{
 		ID:            aliasID,
 		Name:          "example.net",
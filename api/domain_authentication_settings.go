@@ -14,6 +14,15 @@ import (
 	"github.com/google/go-querystring/query"
 )
 
+// Authentication server protocols
+const (
+	AuthProtocolPOP3   = 1
+	AuthProtocolIMAP   = 2
+	AuthProtocolSMTP   = 3
+	AuthProtocolRADIUS = 4
+	AuthProtocolLDAP   = 5
+)
+
 // AuthServer holds an authentication server
 type AuthServer struct {
 	ID              int    `json:"id,omitempty" url:"id,omitempty"`
@@ -87,6 +96,12 @@ func (c *Client) CreateAuthServer(domainID int, server *AuthServer) (err error)
 		return
 	}
 
+	if server.UserMapTemplate != "" {
+		if _, err = ParseUserMapTemplate(server.UserMapTemplate); err != nil {
+			return
+		}
+	}
+
 	v, _ = query.Values(server)
 
 	err = c.post(fmt.Sprintf("authservers/%d", domainID), v, server)
@@ -115,6 +130,12 @@ func (c *Client) UpdateAuthServer(domainID int, server *AuthServer) (err error)
 		return
 	}
 
+	if server.UserMapTemplate != "" {
+		if _, err = ParseUserMapTemplate(server.UserMapTemplate); err != nil {
+			return
+		}
+	}
+
 	v, _ = query.Values(server)
 
 	err = c.put(fmt.Sprintf("authservers/%d/%d", domainID, server.ID), v, nil)
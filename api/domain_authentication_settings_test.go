@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -30,26 +29,10 @@ func TestGetAuthServersError(t *testing.T) {
 }
 
 func TestGetAuthServersOK(t *testing.T) {
-	data := `
-	{
-		"items": [{
-			"protocol": 2,
-			"enabled": true,
-			"user_map_template": "example_%(user)s",
-			"split_address": true,
-			"address": "192.168.1.150",
-			"id": 2
-		}],
-		"meta": {
-			"total": 1
-		}
-	}
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	s, err := client.GetAuthServers(1, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -91,21 +74,10 @@ func TestGetAuthServerError(t *testing.T) {
 func TestGetAuthServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"protocol": 2,
-		"enabled": true,
-		"user_map_template": "example_%%(user)s",
-		"split_address": true,
-		"address": "192.168.1.151",
-		"id": %d
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as, err := client.GetAuthServer(domainID, serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -145,21 +117,10 @@ func TestCreateAuthServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	umpt := "example_%(user)s"
-	data := fmt.Sprintf(`
-	{
-		"protocol": 2,
-		"enabled": true,
-		"user_map_template": "%s",
-		"split_address": true,
-		"address": "192.168.1.151",
-		"id": %d
-	}
-	`, umpt, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as := &AuthServer{
 		Address:         "192.168.1.151",
 		Protocol:        2,
@@ -222,21 +183,10 @@ func TestUpdateAuthServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	umpt := "example_%(user)s"
-	data := fmt.Sprintf(`
-	{
-		"protocol": 2,
-		"enabled": false,
-		"user_map_template": "%s",
-		"split_address": true,
-		"address": "192.168.1.151",
-		"id": %d
-	}
-	`, umpt, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as := &AuthServer{
 		ID:              serverID,
 		Address:         "192.168.1.151",
@@ -296,12 +246,10 @@ func TestDeleteAuthServerError(t *testing.T) {
 func TestDeleteAuthServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as := &AuthServer{
 		ID:           serverID,
 		Address:      "192.168.1.151",
@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -30,30 +29,10 @@ func TestGetDomainDeliveryServersError(t *testing.T) {
 }
 
 func TestGetDomainDeliveryServersOK(t *testing.T) {
-	data := `
-	{
-		"items": [{
-			"domain": {
-				"name": "example.com",
-				"id": 2
-			},
-			"protocol": 1,
-			"enabled": true,
-			"verification_only": false,
-			"id": 2,
-			"address": "192.168.1.150",
-			"port": 25
-		}],
-		"meta": {
-			"total": 1
-		}
-	}
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	s, err := client.GetDomainDeliveryServers(1, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -95,26 +74,10 @@ func TestGetDomainDeliveryServerError(t *testing.T) {
 func TestGetDomainDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": 2
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as, err := client.GetDomainDeliveryServer(domainID, serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -153,26 +116,10 @@ func TestCreateDomainDeliveryServerError(t *testing.T) {
 func TestCreateDomainDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, domainID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &DomainDeliveryServerForm{
 		Address:          "192.168.1.151",
 		Protocol:         1,
@@ -235,26 +182,10 @@ func TestUpdateDomainDeliveryServerError(t *testing.T) {
 func TestUpdateDomainDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": false,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, domainID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &DomainDeliveryServerForm{
 		ID:               serverID,
 		Address:          "192.168.1.151",
@@ -311,12 +242,10 @@ func TestDeleteDomainDeliveryServerError(t *testing.T) {
 func TestDeleteDomainDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &DomainDeliveryServerForm{
 		ID:               serverID,
 		Address:          "192.168.1.151",
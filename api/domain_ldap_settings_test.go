@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -50,29 +49,10 @@ func TestGetLDAPSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"binddn": "uid=readonly-admin,ou=Users,dc=example,dc=com",
-		"emailsearchfilter": "",
-		"emailsearch_scope": "subtree",
-		"searchfilter": "",
-		"search_scope": "subtree",
-		"authserver": {
-			"id": %d
-		},
-		"basedn": "ou=Users,dc=example,dc=com",
-		"usetls": true,
-		"usesearch": false,
-		"emailattribute": "mail",
-		"id": %d,
-		"nameattribute": "uid"
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls, err := client.GetLDAPSettings(domainID, serverID, settingsID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -119,29 +99,10 @@ func TestCreateLDAPSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"binddn": "uid=readonly-admin,ou=Users,dc=example,dc=com",
-		"emailsearchfilter": "",
-		"emailsearch_scope": "subtree",
-		"searchfilter": "",
-		"search_scope": "subtree",
-		"authserver": {
-			"id": %d
-		},
-		"basedn": "ou=Users,dc=example,dc=com",
-		"usetls": true,
-		"usesearch": false,
-		"emailattribute": "mail",
-		"id": %d,
-		"nameattribute": "uid"
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &LDAPSettings{
 		BindDN:           "uid=readonly-admin,ou=Users,dc=example,dc=com",
 		EmailSearchScope: "subtree",
@@ -215,29 +176,10 @@ func TestUpdateLDAPSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"binddn": "uid=readonly-admin,ou=Users,dc=example,dc=com",
-		"emailsearchfilter": "",
-		"emailsearch_scope": "subtree",
-		"searchfilter": "",
-		"search_scope": "subtree",
-		"authserver": {
-			"id": %d
-		},
-		"basedn": "ou=Users,dc=example,dc=com",
-		"usetls": false,
-		"usesearch": false,
-		"emailattribute": "mail",
-		"id": %d,
-		"nameattribute": "uid"
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &LDAPSettings{
 		ID:               settingsID,
 		BindDN:           "uid=readonly-admin,ou=Users,dc=example,dc=com",
@@ -309,29 +251,10 @@ func TestDeleteLDAPSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"binddn": "uid=readonly-admin,ou=Users,dc=example,dc=com",
-		"emailsearchfilter": "",
-		"emailsearch_scope": "subtree",
-		"searchfilter": "",
-		"search_scope": "subtree",
-		"authserver": {
-			"id": %d
-		},
-		"basedn": "ou=Users,dc=example,dc=com",
-		"usetls": false,
-		"usesearch": false,
-		"emailattribute": "mail",
-		"id": %d,
-		"nameattribute": "uid"
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &LDAPSettings{
 		ID:               settingsID,
 		BindDN:           "uid=readonly-admin,ou=Users,dc=example,dc=com",
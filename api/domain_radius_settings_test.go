@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -50,20 +49,10 @@ func TestGetRadiusSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"authserver": {
-			"id": %d
-		},
-		"id": %d,
-		"timeout": 30
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	rs, err := client.GetRadiusSettings(domainID, serverID, settingsID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -110,20 +99,10 @@ func TestCreateRadiusSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"authserver": {
-			"id": %d
-		},
-		"id": %d,
-		"timeout": 30
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &RadiusSettings{
 		Secret:     "secret",
 		Timeout:    30,
@@ -186,20 +165,10 @@ func TestUpdateRadiusSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := fmt.Sprintf(`
-	{
-		"authserver": {
-			"id": %d
-		},
-		"id": %d,
-		"timeout": 30
-	}
-	`, serverID, settingsID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &RadiusSettings{
 		ID:         settingsID,
 		Secret:     "secret",
@@ -257,12 +226,10 @@ func TestDeleteRadiusSettingsOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
 	settingsID := 2
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ls := &RadiusSettings{
 		ID:         settingsID,
 		Secret:     "secret",
@@ -31,41 +31,10 @@ func TestGetDomainSmartHostsError(t *testing.T) {
 
 func TestGetDomainSmartHostsOK(t *testing.T) {
 	domainID := 2
-	data := fmt.Sprintf(`{
-		"items": [{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.1.150",
-			"username": "andrew",
-			"description": "outbound-archiver",
-			"port": 25
-		},
-		{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.2.150",
-			"username": "andrew",
-			"description": "outbound-archiver2",
-			"port": 25
-		}],
-		"meta": {
-			"total": 2
-		},
-		"links": {
-			"pages": {
-				"last": "http://baruwa.example.com/api/v1/domains/smarthosts/%d?page=2",
-				"next": "http://baruwa.example.com/api/v1/domains/smarthosts/%d?page=2"
-			}
-		}
-	}
-`, domainID, domainID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetDomainSmartHosts(domainID, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -113,22 +82,10 @@ func TestGetDomainSmartHostError(t *testing.T) {
 
 func TestGetDomainSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds, err := client.GetDomainSmartHost(1, serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -163,22 +120,10 @@ func TestCreateDomainSmartHostError(t *testing.T) {
 
 func TestCreateDomainSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &DomainSmartHost{
 		Username:    "andrew",
 		Password:    "p4ssw0rd",
@@ -235,22 +180,10 @@ func TestUpdateDomainSmartHostError(t *testing.T) {
 
 func TestUpdateDomainSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &DomainSmartHost{
 		ID:          serverID,
 		Username:    "andrew",
@@ -307,22 +240,10 @@ func TestDeleteDomainSmartHostError(t *testing.T) {
 
 func TestDeleteDomainSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &DomainSmartHost{
 		ID:          serverID,
 		Username:    "andrew",
@@ -8,18 +8,15 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
 
 func TestGetDomainsError(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusInternalServerError, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	_, err = client.GetDomains(nil)
 	if err == nil {
 		t.Fatalf("An error should be returned")
@@ -27,94 +24,10 @@ func TestGetDomainsError(t *testing.T) {
 }
 
 func TestGetDomainsOK(t *testing.T) {
-	data := `{
-		"items": [{
-			"signatures": [{
-				"type": 1,
-				"id": 1
-			}],
-			"highspam_actions": 2,
-			"delivery_mode": 1,
-			"virus_checks": true,
-			"ldap_callout": false,
-			"dkimkeys": [],
-			"timezone": "Africa/Johannesburg",
-			"spam_actions": 2,
-			"id": 2,
-			"deliveryservers": [{
-				"address": "192.168.1.150",
-				"id": 2,
-				"port": 25
-			}],
-			"site_url": "https://mail.example.com",
-			"authservers": [{
-				"protocol": 2,
-				"id": 2,
-				"address": "mail.example.com"
-			}],
-			"report_every": 3,
-			"aliases": [{
-				"name": "mojo.example.com",
-				"id": 2
-			}],
-			"status": true,
-			"accept_inbound": true,
-			"discard_mail": false,
-			"virus_checks_at_smtp": true,
-			"low_score": 10.0,
-			"name": "example.com",
-			"language": "en",
-			"spam_checks": false,
-			"smtp_callout": false,
-			"message_size": "0",
-			"high_score": 20.0,
-			"virus_actions": 2
-		}, {
-			"signatures": [],
-			"highspam_actions": 2,
-			"delivery_mode": 1,
-			"virus_checks": true,
-			"ldap_callout": false,
-			"dkimkeys": [],
-			"timezone": "Africa/Johannesburg",
-			"spam_actions": 2,
-			"id": 4,
-			"deliveryservers": [{
-				"address": "192.168.1.150",
-				"id": 4,
-				"port": 25
-			}],
-			"site_url": "https://mail.example.net",
-			"authservers": [],
-			"report_every": 3,
-			"aliases": [],
-			"status": true,
-			"discard_mail": false,
-			"virus_checks_at_smtp": false,
-			"low_score": 0.0,
-			"name": "example.net",
-			"language": "en",
-			"spam_checks": true,
-			"smtp_callout": true,
-			"message_size": "0",
-			"high_score": 0.0,
-			"virus_actions": 2
-		}],
-		"meta": {
-			"total": 2
-		},
-		"links": {
-			"pages": {
-				"last": "http://baruwa.example.com/api/v1/domains?page=2",
-				"next": "http://baruwa.example.com/api/v1/domains?page=2"
-			}
-		}
-	}`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetDomains(nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -155,54 +68,10 @@ func TestGetDomainError(t *testing.T) {
 
 func TestGetDomainOK(t *testing.T) {
 	domainID := 4
-	data := fmt.Sprintf(`
-	{
-		"signatures": [{
-			"type": 1,
-			"id": 1
-		}],
-		"highspam_actions": 2,
-		"delivery_mode": 1,
-		"virus_checks": true,
-		"ldap_callout": false,
-		"dkimkeys": [],
-		"timezone": "Africa/Johannesburg",
-		"spam_actions": 2,
-		"id": %d,
-		"deliveryservers": [{
-			"address": "192.168.1.150",
-			"id": 2,
-			"port": 25
-		}],
-		"site_url": "https://mail.example.com",
-		"authservers": [{
-			"protocol": 2,
-			"id": 2,
-			"address": "mail.example.com"
-		}],
-		"report_every": 3,
-		"aliases": [{
-			"name": "mojo.example.com",
-			"id": 2
-		}],
-		"status": true,
-		"discard_mail": false,
-		"virus_checks_at_smtp": true,
-		"low_score": 10.0,
-		"name": "example.com",
-		"language": "en",
-		"spam_checks": false,
-		"smtp_callout": false,
-		"message_size": "0",
-		"high_score": 20.0,
-		"virus_actions": 2
-	}
-	`, domainID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	d, err := client.GetDomain(domainID)
 	if err != nil {
 		t.Fatalf("An error should not be returned")
@@ -233,54 +102,10 @@ func TestGetDomainByNameError(t *testing.T) {
 
 func TestGetDomainByNameOK(t *testing.T) {
 	domainName := "example.net"
-	data := fmt.Sprintf(`
-	{
-		"signatures": [{
-			"type": 1,
-			"id": 1
-		}],
-		"highspam_actions": 2,
-		"delivery_mode": 1,
-		"virus_checks": true,
-		"ldap_callout": false,
-		"dkimkeys": [],
-		"timezone": "Africa/Johannesburg",
-		"spam_actions": 2,
-		"id": 1,
-		"deliveryservers": [{
-			"address": "192.168.1.150",
-			"id": 2,
-			"port": 25
-		}],
-		"site_url": "https://mail.example.com",
-		"authservers": [{
-			"protocol": 2,
-			"id": 2,
-			"address": "mail.example.com"
-		}],
-		"report_every": 3,
-		"aliases": [{
-			"name": "mojo.example.com",
-			"id": 2
-		}],
-		"status": true,
-		"discard_mail": false,
-		"virus_checks_at_smtp": true,
-		"low_score": 10.0,
-		"name": "%s",
-		"language": "en",
-		"spam_checks": false,
-		"smtp_callout": false,
-		"message_size": "0",
-		"high_score": 20.0,
-		"virus_actions": 2
-	}
-	`, domainName)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	d, err := client.GetDomainByName(domainName)
 	if err != nil {
 		t.Fatalf("An error should not be returned")
@@ -308,40 +133,10 @@ func TestCreateDomainError(t *testing.T) {
 
 func TestCreateDomainOK(t *testing.T) {
 	domainID := 2
-	data := fmt.Sprintf(`
-	{
-		"signatures": [],
-		"highspam_actions": 3,
-		"delivery_mode": 1,
-		"virus_checks": true,
-		"ldap_callout": true,
-		"dkimkeys": [],
-		"timezone": "Africa/Johannesburg",
-		"spam_actions": 3,
-		"id": %d,
-		"deliveryservers": [],
-		"site_url": "http://baruwa.example.net",
-		"authservers": [],
-		"report_every": 3,
-		"aliases": [],
-		"status": true,
-		"discard_mail": false,
-		"virus_checks_at_smtp": true,
-		"low_score": 0.0,
-		"name": "example.net",
-		"language": "en",
-		"spam_checks": true,
-		"smtp_callout": true,
-		"message_size": "0",
-		"high_score": 0.0,
-		"virus_actions": 3
-	}
-	`, domainID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	d := &Domain{
 		Name:              "example.net",
 		Language:          "en",
@@ -418,40 +213,10 @@ func TestUpdateDomainError(t *testing.T) {
 
 func TestUpdateDomainOK(t *testing.T) {
 	domainID := 2
-	data := fmt.Sprintf(`
-	{
-		"signatures": [],
-		"highspam_actions": 3,
-		"delivery_mode": 1,
-		"virus_checks": true,
-		"ldap_callout": true,
-		"dkimkeys": [],
-		"timezone": "Africa/Johannesburg",
-		"spam_actions": 3,
-		"id": %d,
-		"deliveryservers": [],
-		"site_url": "http://baruwa.example.net",
-		"authservers": [],
-		"report_every": 3,
-		"aliases": [],
-		"status": true,
-		"discard_mail": false,
-		"virus_checks_at_smtp": true,
-		"low_score": 0.0,
-		"name": "example.net",
-		"language": "en",
-		"spam_checks": true,
-		"smtp_callout": true,
-		"message_size": "0",
-		"high_score": 0.0,
-		"virus_actions": 3
-	}
-	`, domainID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	d := &Domain{
 		ID:                domainID,
 		Name:              "example.net",
@@ -497,12 +262,10 @@ func TestDeleteDomainError(t *testing.T) {
 }
 
 func TestDeleteDomainOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	err = client.DeleteDomain(1)
 	if err != nil {
 		t.Fatalf("An error should not be returned")
@@ -0,0 +1,173 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DryRunIDStart is added to the fake IDs assigned to the resources
+	// created in dry-run mode, IDs above it were never sent by the server
+	DryRunIDStart = 1000000000
+)
+
+// form fields replaced in the dry-run log
+var secretFields = []string{"bindpw", "password", "password1", "password2", "secret"}
+
+// DryRunRequest is a request intercepted in dry-run mode
+type DryRunRequest struct {
+	Method string
+	URL    string
+	// Body is the encoded form sent with the request
+	Body string
+	// ID is the fake ID returned for a created resource
+	ID int
+}
+
+type dryRun struct {
+	mu       sync.Mutex
+	log      io.Writer
+	lastID   int
+	requests []DryRunRequest
+}
+
+// DryRun returns true if the client does not send changes to the server
+func (c *Client) DryRun() bool {
+	return c.dryRun != nil
+}
+
+// DryRunRequests returns the requests intercepted in dry-run mode, in
+// the order they were made
+func (c *Client) DryRunRequests() (l []DryRunRequest) {
+	if c.dryRun == nil {
+		return
+	}
+
+	c.dryRun.mu.Lock()
+	defer c.dryRun.mu.Unlock()
+
+	l = make([]DryRunRequest, len(c.dryRun.requests))
+	copy(l, c.dryRun.requests)
+
+	return
+}
+
+// intercept records a POST, PUT or DELETE request instead of sending it
+// and fills data with the submitted values, as the server would
+func (c *Client) intercept(req *http.Request, v url.Values, data interface{}) (err error) {
+	d := c.dryRun
+	r := DryRunRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Body:   v.Encode(),
+	}
+
+	d.mu.Lock()
+	if req.Method == http.MethodPost && data != nil {
+		d.lastID++
+		r.ID = DryRunIDStart + d.lastID
+	}
+	d.requests = append(d.requests, r)
+	if d.log != nil {
+		_, err = fmt.Fprintf(d.log, "dry-run: %s %s %s\n", r.Method, r.URL, redact(v).Encode())
+	}
+	d.mu.Unlock()
+
+	if data != nil {
+		echo(v, data, r.ID)
+	}
+
+	return
+}
+
+func redact(v url.Values) (r url.Values) {
+	r = url.Values{}
+	for k, l := range v {
+		r[k] = l
+		for _, s := range secretFields {
+			if k == s {
+				r[k] = []string{"********"}
+			}
+		}
+	}
+
+	return
+}
+
+// echo sets the fields of data from the form values using the url
+// struct tags, the ID is set when it is not already
+func echo(v url.Values, data interface{}, id int) {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	rv = rv.Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := strings.Split(rt.Field(i).Tag.Get("url"), ",")[0]
+		f := rv.Field(i)
+		if name == "" || name == "-" || !f.CanSet() {
+			continue
+		}
+		if name == "id" && id > 0 && f.Kind() == reflect.Int && f.Int() == 0 {
+			f.SetInt(int64(id))
+			continue
+		}
+		if l, ok := v[name]; ok && len(l) > 0 {
+			setValue(f, l)
+		}
+	}
+}
+
+// setValue sets a field from its form values, values that do not parse
+// are left unset. A struct with an ID field is set from a single ID
+func setValue(f reflect.Value, l []string) {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(l[0])
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(l[0]); err == nil {
+			f.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(l[0], 10, 64); err == nil {
+			f.SetInt(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(l[0], 64); err == nil {
+			f.SetFloat(n)
+		}
+	case reflect.Ptr:
+		e := reflect.New(f.Type().Elem())
+		if setValue(e.Elem(), l); !e.Elem().IsZero() {
+			f.Set(e)
+		}
+	case reflect.Struct:
+		if id := f.FieldByName("ID"); id.IsValid() && id.CanSet() {
+			setValue(id, l)
+		}
+	case reflect.Slice:
+		s := reflect.MakeSlice(f.Type(), 0, len(l))
+		for _, v := range l {
+			e := reflect.New(f.Type().Elem()).Elem()
+			if setValue(e, []string{v}); !e.IsZero() {
+				s = reflect.Append(s, e)
+			}
+		}
+		f.Set(s)
+	}
+}
@@ -0,0 +1,141 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	var changes int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			atomic.AddInt32(&changes, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 1, "name": "example.com"}`)
+	}))
+	defer server.Close()
+
+	buf := &bytes.Buffer{}
+	c, err := getTestClient(server.URL, &Options{DryRun: true, DryRunLog: buf})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if !c.DryRun() {
+		t.Fatalf("Expected the client to be in dry-run mode")
+	}
+
+	d, err := c.GetDomain(1)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if d.Name != "example.com" {
+		t.Errorf("Expected %s got %s", "example.com", d.Name)
+	}
+
+	d = &Domain{Name: "example.net", Enabled: true, LowScore: 5.5}
+	if err = c.CreateDomain(d); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if d.ID != DryRunIDStart+1 || d.Name != "example.net" || !d.Enabled || d.LowScore != 5.5 {
+		t.Errorf("Expected the domain to be echoed got %v", d)
+	}
+
+	alias, err := c.CreateDomainAlias(d.ID, &DomainAliasForm{Name: "example.org", Enabled: true, Domain: d.ID})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if alias.ID != DryRunIDStart+2 || alias.Name != "example.org" || !alias.Enabled || alias.Domain == nil || alias.Domain.ID != d.ID {
+		t.Errorf("Expected the alias to be echoed got %v", alias)
+	}
+
+	username, pw := "jdoe", "s3cr3t"
+	u, err := c.CreateUser(&UserForm{Username: &username, Password1: &pw, Password2: &pw, Domains: []int{d.ID, 7}})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if u.ID != DryRunIDStart+3 || u.Username != username || len(u.Domains) != 2 || u.Domains[1].ID != 7 {
+		t.Errorf("Expected the user to be echoed got %v", u)
+	}
+
+	org := &Organization{ID: 10}
+	if err = c.UpdateOrganization(&OrganizationForm{ID: 10, Name: "Example Inc", Domains: []int{d.ID}}, org); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if org.ID != 10 || org.Name != "Example Inc" || len(org.Domains) != 1 || org.Domains[0].ID != d.ID {
+		t.Errorf("Expected the organization to be echoed got %v", org)
+	}
+
+	if err = c.DeleteDomain(d.ID); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if changes != 0 {
+		t.Errorf("Expected %d got %d", 0, changes)
+	}
+
+	l := c.DryRunRequests()
+	if len(l) != 5 {
+		t.Fatalf("Expected %d got %d", 5, len(l))
+	}
+	expected := []string{"POST", "POST", "POST", "PUT", "DELETE"}
+	for i, r := range l {
+		if r.Method != expected[i] {
+			t.Errorf("Expected %s got %s", expected[i], r.Method)
+		}
+	}
+	if l[0].URL != server.URL+"/api/v1/domains" {
+		t.Errorf("Expected %s got %s", server.URL+"/api/v1/domains", l[0].URL)
+	}
+	if v, _ := url.ParseQuery(l[2].Body); v.Get("password1") != pw {
+		t.Errorf("Expected the recorded body to hold the password got %s", l[2].Body)
+	}
+	if l[3].ID != 0 || l[4].URL != fmt.Sprintf("%s/api/v1/domains/%d", server.URL, d.ID) {
+		t.Errorf("Expected the update and delete to be recorded got %v", l[3:])
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected %d got %d", 5, len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "dry-run: POST "+server.URL+"/api/v1/domains ") {
+		t.Errorf("Expected the request to be logged got %s", lines[0])
+	}
+	if strings.Contains(lines[2], pw) || !strings.Contains(lines[2], "password1=%2A%2A%2A%2A%2A%2A%2A%2A") {
+		t.Errorf("Expected the password to be redacted got %s", lines[2])
+	}
+}
+
+func TestDryRunDisabled(t *testing.T) {
+	server, c, err := getTestServerAndClient(http.StatusOK, `{"id": 1, "name": "example.com"}`)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	defer server.Close()
+
+	if c.DryRun() {
+		t.Errorf("Expected the client not to be in dry-run mode")
+	}
+
+	if err = c.CreateDomain(&Domain{Name: "example.com"}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if l := c.DryRunRequests(); l != nil {
+		t.Errorf("Expected no requests got %v", l)
+	}
+}
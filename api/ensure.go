@@ -0,0 +1,526 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is the change made by an Ensure method
+type Action string
+
+const (
+	// ActionNone the existing resource already matched
+	ActionNone Action = "none"
+	// ActionCreated the resource was created
+	ActionCreated Action = "created"
+	// ActionUpdated the existing resource was updated
+	ActionUpdated Action = "updated"
+)
+
+// EnsureDomain creates the domain when no domain has its name and
+// updates the existing domain when its settings differ. The
+// organizations are only compared when domain.Organizations is set.
+// domain.ID is set to the ID of the domain
+func (c *Client) EnsureDomain(domain *Domain) (action Action, err error) {
+	var current *Domain
+
+	if domain == nil {
+		err = fmt.Errorf(domainParamError)
+		return
+	}
+
+	if current, err = c.GetDomainByName(domain.Name); err != nil {
+		if !IsNotFound(err) {
+			return
+		}
+		domain.ID = 0
+		if err = c.CreateDomain(domain); err == nil {
+			action = ActionCreated
+		}
+		return
+	}
+
+	domain.ID = current.ID
+	if sameDomain(domain, current) {
+		action = ActionNone
+		return
+	}
+
+	d := *domain
+	if d.Organizations == nil {
+		d.Organizations = current.Organizations
+	}
+
+	if err = c.UpdateDomain(&d); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureDomainAlias creates the alias when the domain has no alias with
+// its name and updates the existing alias when its settings differ.
+// form.ID is set to the ID of the alias
+func (c *Client) EnsureDomainAlias(domainID int, form *DomainAliasForm) (action Action, err error) {
+	var current *DomainAlias
+
+	if domainID <= 0 {
+		err = fmt.Errorf(domainIDError)
+		return
+	}
+
+	if form == nil {
+		err = fmt.Errorf(aliasParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *DomainAliasList
+		if l, err = c.GetDomainAliases(domainID, opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Name, form.Name) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		var alias *DomainAlias
+		form.ID = 0
+		if alias, err = c.CreateDomainAlias(domainID, form); err == nil {
+			form.ID = alias.ID
+			action = ActionCreated
+		}
+		return
+	}
+
+	form.ID = current.ID
+	if form.Enabled == current.Enabled && form.AcceptInbound == current.AcceptInbound {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateDomainAlias(domainID, form); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureOrganization creates the organization when no organization has
+// its name and updates the existing organization when its domains or
+// administrators differ. The domains and administrators are only
+// compared when set in the form. form.ID is set to the ID of the
+// organization
+func (c *Client) EnsureOrganization(form *OrganizationForm) (action Action, err error) {
+	var current *Organization
+
+	if form == nil {
+		err = fmt.Errorf(formParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *OrganizationList
+		if l, err = c.GetOrganizations(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Name, form.Name) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		var org *Organization
+		form.ID = 0
+		if org, err = c.CreateOrganization(form); err == nil {
+			form.ID = org.ID
+			action = ActionCreated
+		}
+		return
+	}
+
+	var domains, admins []int
+	for _, d := range current.Domains {
+		domains = append(domains, d.ID)
+	}
+	for _, a := range current.Admins {
+		admins = append(admins, a.ID)
+	}
+
+	form.ID = current.ID
+	if (form.Domains == nil || sameIDs(form.Domains, domains)) && (form.Admins == nil || sameIDs(form.Admins, admins)) {
+		action = ActionNone
+		return
+	}
+
+	f := *form
+	if f.Domains == nil {
+		f.Domains = domains
+	}
+	if f.Admins == nil {
+		f.Admins = admins
+	}
+
+	if err = c.UpdateOrganization(&f, current); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureOrgSmartHost creates the smarthost when the organization has no
+// smarthost with its address and updates the existing smarthost when its
+// settings differ. The password can not be read back and is not
+// compared. server.ID is set to the ID of the smarthost
+func (c *Client) EnsureOrgSmartHost(organizationID int, server *OrgSmartHost) (action Action, err error) {
+	var current *OrgSmartHost
+
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	if server == nil {
+		err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *OrgSmartHostList
+		if l, err = c.GetOrgSmartHosts(organizationID, opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Address, server.Address) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		server.ID = 0
+		if err = c.CreateOrgSmartHost(organizationID, server); err == nil {
+			action = ActionCreated
+		}
+		return
+	}
+
+	server.ID = current.ID
+	if sameSmartHost(server, current) {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateOrgSmartHost(organizationID, server); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureDomainSmartHost creates the smarthost when the domain has no
+// smarthost with its address and updates the existing smarthost when
+// its settings differ. The password can not be read back and is not
+// compared. server.ID is set to the ID of the smarthost
+func (c *Client) EnsureDomainSmartHost(domainID int, server *DomainSmartHost) (action Action, err error) {
+	var current *DomainSmartHost
+
+	if domainID <= 0 {
+		err = fmt.Errorf(domainIDError)
+		return
+	}
+
+	if server == nil {
+		err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *DomainSmartHostList
+		if l, err = c.GetDomainSmartHosts(domainID, opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Address, server.Address) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		server.ID = 0
+		if err = c.CreateDomainSmartHost(domainID, server); err == nil {
+			action = ActionCreated
+		}
+		return
+	}
+
+	server.ID = current.ID
+	if a, b := OrgSmartHost(*server), OrgSmartHost(*current); sameSmartHost(&a, &b) {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateDomainSmartHost(domainID, server); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureDomainDeliveryServer creates the delivery server when the domain
+// has no delivery server with its address and updates the existing
+// delivery server when its settings differ. form.ID is set to the ID of
+// the delivery server
+func (c *Client) EnsureDomainDeliveryServer(domainID int, form *DomainDeliveryServerForm) (action Action, err error) {
+	var current *DomainDeliveryServer
+
+	if domainID <= 0 {
+		err = fmt.Errorf(domainIDError)
+		return
+	}
+
+	if form == nil {
+		err = fmt.Errorf(formParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *DomainDeliveryServerList
+		if l, err = c.GetDomainDeliveryServers(domainID, opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Address, form.Address) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		var server *DomainDeliveryServer
+		form.ID = 0
+		if server, err = c.CreateDomainDeliveryServer(domainID, form); err == nil {
+			form.ID = server.ID
+			action = ActionCreated
+		}
+		return
+	}
+
+	form.ID = current.ID
+	if form.Protocol == current.Protocol && form.Port == current.Port && form.RequireTLS == current.RequireTLS &&
+		form.VerificationOnly == current.VerificationOnly && form.Enabled == current.Enabled {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateDomainDeliveryServer(domainID, form); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureRelaySetting creates the relay setting when server.ID is 0 and
+// updates the existing relay setting when its settings differ. The API
+// can not list relay settings so they are looked up by ID, the
+// passwords can not be read back and are not compared
+func (c *Client) EnsureRelaySetting(organizationID int, server *RelaySetting) (action Action, err error) {
+	var current *RelaySetting
+
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	if server == nil {
+		err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	if server.ID <= 0 {
+		if err = c.CreateRelaySetting(organizationID, server); err == nil {
+			action = ActionCreated
+		}
+		return
+	}
+
+	if current, err = c.GetRelaySetting(server.ID); err != nil {
+		return
+	}
+
+	if sameRelaySetting(server, current) {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateRelaySetting(server); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+// EnsureAliasAddress creates the alias address when the user account
+// has no alias with its address and updates the existing alias when it
+// is enabled or disabled. alias.ID is set to the ID of the alias address
+func (c *Client) EnsureAliasAddress(userID int, alias *AliasAddress) (action Action, err error) {
+	var current *AliasAddress
+
+	if userID <= 0 {
+		err = fmt.Errorf(userIDError)
+		return
+	}
+
+	if alias == nil {
+		err = fmt.Errorf(aliasParamError)
+		return
+	}
+
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *AliasAddressList
+		if l, err = c.GetUserAliasAddresses(userID, opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Address, alias.Address) {
+				current, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+	if err != nil {
+		return
+	}
+
+	if current == nil {
+		alias.ID = 0
+		if err = c.CreateAliasAddress(userID, alias); err == nil {
+			action = ActionCreated
+		}
+		return
+	}
+
+	alias.ID = current.ID
+	if alias.Enabled == current.Enabled {
+		action = ActionNone
+		return
+	}
+
+	if err = c.UpdateAliasAddress(alias); err == nil {
+		action = ActionUpdated
+	}
+
+	return
+}
+
+func sameDomain(a, b *Domain) bool {
+	return a.SiteURL == b.SiteURL &&
+		a.Enabled == b.Enabled &&
+		a.AcceptInbound == b.AcceptInbound &&
+		a.DiscardMail == b.DiscardMail &&
+		a.SMTPCallout == b.SMTPCallout &&
+		a.LdapCallout == b.LdapCallout &&
+		a.VirusChecks == b.VirusChecks &&
+		a.VirusChecksAtSMTP == b.VirusChecksAtSMTP &&
+		a.BlockMacros == b.BlockMacros &&
+		a.SpamChecks == b.SpamChecks &&
+		a.SpamActions == b.SpamActions &&
+		a.HighspamActions == b.HighspamActions &&
+		a.VirusActions == b.VirusActions &&
+		a.LowScore.String() == b.LowScore.String() &&
+		a.HighScore.String() == b.HighScore.String() &&
+		a.MessageSize == b.MessageSize &&
+		a.DeliveryMode == b.DeliveryMode &&
+		a.Language == b.Language &&
+		a.Timezone == b.Timezone &&
+		a.ReportEvery == b.ReportEvery &&
+		(a.Organizations == nil || sameIDs(a.Organizations, b.Organizations))
+}
+
+func sameSmartHost(a, b *OrgSmartHost) bool {
+	return a.Username == b.Username &&
+		a.Port == b.Port &&
+		a.RequireTLS == b.RequireTLS &&
+		a.Enabled == b.Enabled &&
+		a.Description == b.Description
+}
+
+func sameRelaySetting(a, b *RelaySetting) bool {
+	return strings.EqualFold(a.Address, b.Address) &&
+		a.Username == b.Username &&
+		a.Enabled == b.Enabled &&
+		a.RequireTLS == b.RequireTLS &&
+		a.Description == b.Description &&
+		a.LowScore.String() == b.LowScore.String() &&
+		a.HighScore.String() == b.HighScore.String() &&
+		a.SpamActions == b.SpamActions &&
+		a.HighSpamActions == b.HighSpamActions &&
+		a.BlockMacros == b.BlockMacros &&
+		a.RateLimit == b.RateLimit &&
+		a.AllowAllSenders == b.AllowAllSenders
+}
+
+// sameIDs returns true if a and b hold the same IDs in any order
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	x := append([]int(nil), a...)
+	y := append([]int(nil), b...)
+	sort.Ints(x)
+	sort.Ints(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+
+	return true
+}
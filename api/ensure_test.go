@@ -0,0 +1,315 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// getEnsureTestServer serves the GET responses in pages and records the
+// other requests, POST requests return {"id": 50}
+func getEnsureTestServer(pages map[string]string) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	var changes []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		p := strings.TrimPrefix(r.URL.Path, "/api/v1/")
+		if q := r.URL.Query().Get("page"); q != "" {
+			p = fmt.Sprintf("%s?page=%s", p, q)
+		}
+		if r.Method != http.MethodGet {
+			r.ParseForm()
+			mu.Lock()
+			changes = append(changes, fmt.Sprintf("%s %s %s", r.Method, p, r.PostForm.Encode()))
+			mu.Unlock()
+			fmt.Fprint(w, `{"id": 50}`)
+			return
+		}
+		body, ok := pages[p]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": "Not Found", "code": 404}`)
+			return
+		}
+		fmt.Fprint(w, strings.Replace(body, "SERVER", "http://"+r.Host, -1))
+	}))
+
+	return server, &changes
+}
+
+func checkEnsure(t *testing.T, action Action, err error, expected Action, changes *[]string, n int) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if action != expected {
+		t.Errorf("Expected %s got %s", expected, action)
+	}
+	if len(*changes) != n {
+		t.Errorf("Expected %d got %d: %v", n, len(*changes), *changes)
+	}
+}
+
+func TestEnsureDomain(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"domains/byname/example.com": `{"id": 3, "name": "example.com", "status": true, "low_score": 5.0, "organizations": [1, 2]}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	d := &Domain{Name: "example.com", Enabled: true, LowScore: 5}
+	action, err := c.EnsureDomain(d)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if d.ID != 3 {
+		t.Errorf("Expected %d got %d", 3, d.ID)
+	}
+
+	d.Organizations = []int{2, 1}
+	action, err = c.EnsureDomain(d)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+
+	d.Organizations = nil
+	d.SpamChecks = true
+	action, err = c.EnsureDomain(d)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+	if !strings.HasPrefix((*changes)[0], "PUT domains/3 ") || !strings.Contains((*changes)[0], "organizations=1&organizations=2") {
+		t.Errorf("Expected the update to keep the organizations got %s", (*changes)[0])
+	}
+
+	d = &Domain{ID: 3, Name: "example.net"}
+	action, err = c.EnsureDomain(d)
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+	if !strings.HasPrefix((*changes)[1], "POST domains ") || d.ID != 50 {
+		t.Errorf("Expected the domain to be created got %s %d", (*changes)[1], d.ID)
+	}
+
+	if _, err = c.EnsureDomain(nil); err == nil || err.Error() != domainParamError {
+		t.Errorf("Expected '%s' got '%v'", domainParamError, err)
+	}
+}
+
+func TestEnsureDomainAlias(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"domainaliases/1":        `{"items": [{"id": 7, "name": "example.org", "status": true}], "links": {"pages": {"next": "SERVER/api/v1/domainaliases/1?page=2"}}}`,
+		"domainaliases/1?page=2": `{"items": [{"id": 8, "name": "example.net", "status": true, "accept_inbound": true}], "links": {"pages": {}}}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	form := &DomainAliasForm{Name: "EXAMPLE.NET", Enabled: true, AcceptInbound: true}
+	action, err := c.EnsureDomainAlias(1, form)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if form.ID != 8 {
+		t.Errorf("Expected %d got %d", 8, form.ID)
+	}
+
+	form.AcceptInbound = false
+	action, err = c.EnsureDomainAlias(1, form)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+
+	form = &DomainAliasForm{Name: "example.info"}
+	action, err = c.EnsureDomainAlias(1, form)
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+	if form.ID != 50 {
+		t.Errorf("Expected %d got %d", 50, form.ID)
+	}
+
+	if _, err = c.EnsureDomainAlias(0, form); err == nil || err.Error() != domainIDError {
+		t.Errorf("Expected '%s' got '%v'", domainIDError, err)
+	}
+
+	if _, err = c.EnsureDomainAlias(2, form); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestEnsureOrganization(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"organizations": `{"items": [{"id": 4, "name": "Example Inc", "domains": [{"id": 1}, {"id": 2}], "admins": [{"id": 9}]}], "links": {"pages": {}}}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	form := &OrganizationForm{Name: "Example Inc"}
+	action, err := c.EnsureOrganization(form)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if form.ID != 4 {
+		t.Errorf("Expected %d got %d", 4, form.ID)
+	}
+
+	form.Domains = []int{2, 1}
+	action, err = c.EnsureOrganization(form)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+
+	form.Domains = []int{1, 2, 3}
+	action, err = c.EnsureOrganization(form)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+	if !strings.Contains((*changes)[0], "admins=9") {
+		t.Errorf("Expected the update to keep the admins got %s", (*changes)[0])
+	}
+
+	action, err = c.EnsureOrganization(&OrganizationForm{Name: "Example Ltd"})
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+}
+
+func TestEnsureSmartHosts(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"organizations/smarthosts/1": `{"items": [{"id": 5, "address": "192.168.1.20", "port": 25, "enabled": true}], "links": {"pages": {}}}`,
+		"domains/smarthosts/1":       `{"items": [{"id": 6, "address": "192.168.1.21", "port": 25, "enabled": true}], "links": {"pages": {}}}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	org := &OrgSmartHost{Address: "192.168.1.20", Port: 25, Enabled: true, Password: "s3cr3t"}
+	action, err := c.EnsureOrgSmartHost(1, org)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if org.ID != 5 {
+		t.Errorf("Expected %d got %d", 5, org.ID)
+	}
+
+	org.Port = 587
+	action, err = c.EnsureOrgSmartHost(1, org)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+
+	action, err = c.EnsureOrgSmartHost(1, &OrgSmartHost{Address: "192.168.1.22", Port: 25})
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+
+	domain := &DomainSmartHost{Address: "192.168.1.21", Port: 25, Enabled: true}
+	action, err = c.EnsureDomainSmartHost(1, domain)
+	checkEnsure(t, action, err, ActionNone, changes, 2)
+	if domain.ID != 6 {
+		t.Errorf("Expected %d got %d", 6, domain.ID)
+	}
+
+	domain.RequireTLS = true
+	action, err = c.EnsureDomainSmartHost(1, domain)
+	checkEnsure(t, action, err, ActionUpdated, changes, 3)
+
+	action, err = c.EnsureDomainSmartHost(1, &DomainSmartHost{Address: "192.168.1.22", Port: 25})
+	checkEnsure(t, action, err, ActionCreated, changes, 4)
+
+	if _, err = c.EnsureOrgSmartHost(1, nil); err == nil || err.Error() != serverParamError {
+		t.Errorf("Expected '%s' got '%v'", serverParamError, err)
+	}
+}
+
+func TestEnsureDomainDeliveryServer(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"deliveryservers/1": `{"items": [{"id": 2, "address": "192.168.1.10", "protocol": 1, "port": 25, "enabled": true}], "links": {"pages": {}}}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	form := &DomainDeliveryServerForm{Address: "192.168.1.10", Protocol: 1, Port: 25, Enabled: true}
+	action, err := c.EnsureDomainDeliveryServer(1, form)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if form.ID != 2 {
+		t.Errorf("Expected %d got %d", 2, form.ID)
+	}
+
+	form.Enabled = false
+	action, err = c.EnsureDomainDeliveryServer(1, form)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+
+	form = &DomainDeliveryServerForm{Address: "192.168.1.11", Protocol: 1, Port: 25}
+	action, err = c.EnsureDomainDeliveryServer(1, form)
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+	if form.ID != 50 {
+		t.Errorf("Expected %d got %d", 50, form.ID)
+	}
+}
+
+func TestEnsureRelaySetting(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"relays/3": `{"id": 3, "address": "192.168.1.30", "enabled": true, "low_score": 0.0, "high_score": 10.0}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	relay := &RelaySetting{ID: 3, Address: "192.168.1.30", Enabled: true, HighScore: 10, Password1: "s3cr3t", Password2: "s3cr3t"}
+	action, err := c.EnsureRelaySetting(1, relay)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+
+	relay.RateLimit = 100
+	action, err = c.EnsureRelaySetting(1, relay)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+
+	relay = &RelaySetting{Address: "192.168.1.31"}
+	action, err = c.EnsureRelaySetting(1, relay)
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+	if relay.ID != 50 {
+		t.Errorf("Expected %d got %d", 50, relay.ID)
+	}
+
+	if _, err = c.EnsureRelaySetting(1, &RelaySetting{ID: 4}); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestEnsureAliasAddress(t *testing.T) {
+	server, changes := getEnsureTestServer(map[string]string{
+		"aliasaddresses/list/1": `{"items": [{"id": 12, "address": "info@example.com", "enabled": true}], "links": {"pages": {}}}`,
+	})
+	defer server.Close()
+
+	c, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	alias := &AliasAddress{Address: "info@example.com", Enabled: true}
+	action, err := c.EnsureAliasAddress(1, alias)
+	checkEnsure(t, action, err, ActionNone, changes, 0)
+	if alias.ID != 12 {
+		t.Errorf("Expected %d got %d", 12, alias.ID)
+	}
+
+	alias.Enabled = false
+	action, err = c.EnsureAliasAddress(1, alias)
+	checkEnsure(t, action, err, ActionUpdated, changes, 1)
+	if !strings.HasPrefix((*changes)[0], "PUT aliasaddresses/12 ") {
+		t.Errorf("Expected the alias to be updated got %s", (*changes)[0])
+	}
+
+	action, err = c.EnsureAliasAddress(1, &AliasAddress{Address: "sales@example.com", Enabled: true})
+	checkEnsure(t, action, err, ActionCreated, changes, 2)
+
+	if _, err = c.EnsureAliasAddress(0, alias); err == nil || err.Error() != userIDError {
+		t.Errorf("Expected '%s' got '%v'", userIDError, err)
+	}
+}
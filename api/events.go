@@ -0,0 +1,207 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change an Event reports
+type EventType string
+
+const (
+	// EventCreated a resource was created
+	EventCreated EventType = "created"
+	// EventUpdated a resource was updated
+	EventUpdated EventType = "updated"
+	// EventDeleted a resource was deleted
+	EventDeleted EventType = "deleted"
+)
+
+// Event is published after a successful Create, Update or Delete
+// request. POST requests that do not return a resource, such as
+// changing a password or flushing the mail queue, are published as
+// updates with the redacted form
+type Event struct {
+	Type EventType `json:"type"`
+	// Resource is the API path of the resource without the IDs, such
+	// as domains or domains/smarthosts
+	Resource string `json:"resource"`
+	// ID is the ID of the resource, 0 when it is not known
+	ID int `json:"id,omitempty"`
+	// Path is the API path of the request
+	Path string `json:"path"`
+	// Form is the form sent with the request, passwords are removed
+	Form url.Values `json:"form,omitempty"`
+	// Before is the resource before the change when the response cache
+	// holds it, of the same type as After or the cached JSON when After
+	// is not known
+	Before interface{} `json:"before,omitempty"`
+	// After is the resource returned by the server, nil when the
+	// request does not return the resource
+	After interface{} `json:"after,omitempty"`
+	Time  time.Time   `json:"time"`
+}
+
+// EventHandler is called with each event, handlers are called in the
+// goroutine that made the request and should not block
+type EventHandler func(e *Event)
+
+// EventBus publishes events to its subscribers, a bus can be shared by
+// several clients
+type EventBus struct {
+	mu          sync.RWMutex
+	lastID      int
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	id      int
+	handler EventHandler
+}
+
+// NewEventBus returns an EventBus
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds a handler, calling the returned function removes it
+func (b *EventBus) Subscribe(h EventHandler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	id := b.lastID
+	b.subscribers = append(b.subscribers, subscriber{id: id, handler: h})
+
+	unsubscribe = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subscribers {
+			if s.id == id {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+// Publish calls the handlers with the event in the order they subscribed
+func (b *EventBus) Publish(e *Event) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.handler(e)
+	}
+}
+
+// before returns the cached resource at the request URL, decoded into
+// the type of data when data is set
+func (c *Client) before(req *http.Request, data interface{}) interface{} {
+	if c.events == nil || c.cache == nil || req.Method == http.MethodPost {
+		return nil
+	}
+
+	entry, ok := c.cache.Get(c.cacheKey(req))
+	if !ok {
+		return nil
+	}
+
+	if data == nil || reflect.TypeOf(data).Kind() != reflect.Ptr {
+		return json.RawMessage(entry.Body)
+	}
+
+	v := reflect.New(reflect.TypeOf(data).Elem()).Interface()
+	if err := json.Unmarshal(entry.Body, v); err != nil {
+		return nil
+	}
+
+	return scrub(v)
+}
+
+// publish sends the event for a successful request
+func (c *Client) publish(method, p string, v url.Values, before, data interface{}) {
+	if c.events == nil {
+		return
+	}
+
+	e := &Event{
+		Path:   p,
+		Before: before,
+		Form:   redact(v),
+		Time:   time.Now(),
+	}
+
+	switch method {
+	case http.MethodPost:
+		if data == nil {
+			e.Type = EventUpdated
+			break
+		}
+		e.Type = EventCreated
+	case http.MethodPut:
+		e.Type = EventUpdated
+	default:
+		e.Type = EventDeleted
+	}
+
+	var segments []string
+	for _, s := range strings.Split(p, "/") {
+		if n, err := strconv.Atoi(s); err == nil {
+			e.ID = n
+			continue
+		}
+		segments = append(segments, s)
+	}
+	e.Resource = strings.Join(segments, "/")
+
+	if data != nil {
+		e.After = scrub(data)
+		if f := reflect.Indirect(reflect.ValueOf(data)); f.Kind() == reflect.Struct {
+			if id := f.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.Int && id.Int() > 0 {
+				e.ID = int(id.Int())
+			}
+		}
+	}
+
+	c.events.Publish(e)
+}
+
+// scrub returns a copy of a struct with the password fields cleared
+func scrub(data interface{}) interface{} {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return data
+	}
+
+	cp := reflect.New(rv.Elem().Type())
+	cp.Elem().Set(rv.Elem())
+
+	t := cp.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("url"), ",")[0]
+		for _, s := range secretFields {
+			if name == s && cp.Elem().Field(i).CanSet() {
+				cp.Elem().Field(i).Set(reflect.Zero(t.Field(i).Type))
+			}
+		}
+	}
+
+	return cp.Interface()
+}
@@ -0,0 +1,196 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	var calls []string
+
+	b := NewEventBus()
+	b.Subscribe(func(e *Event) {
+		calls = append(calls, "a "+e.Resource)
+	})
+	unsubscribe := b.Subscribe(func(e *Event) {
+		calls = append(calls, "b "+e.Resource)
+	})
+	b.Subscribe(func(e *Event) {
+		calls = append(calls, "c "+e.Resource)
+	})
+
+	b.Publish(&Event{Resource: "domains"})
+	unsubscribe()
+	unsubscribe()
+	b.Publish(&Event{Resource: "users"})
+
+	expected := "[a domains b domains c domains a users c users]"
+	if fmt.Sprint(calls) != expected {
+		t.Errorf("Expected %s got %v", expected, calls)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var events []*Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			fmt.Fprint(w, `{"id": 3, "name": "example.com", "status": true}`)
+		case r.URL.Path == "/api/v1/relays/9":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "Bad Request", "code": 400}`)
+		case r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"id": 12, "address": "192.168.1.30"}`)
+		default:
+			fmt.Fprint(w, `{"id": 3, "name": "example.com", "status": false}`)
+		}
+	}))
+	defer server.Close()
+
+	bus := NewEventBus()
+	bus.Subscribe(func(e *Event) {
+		events = append(events, e)
+	})
+
+	c, err := getTestClient(server.URL, &Options{Events: bus, Cache: NewLRUCache(0)})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if _, err = c.GetDomain(3); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected %d got %d", 0, len(events))
+	}
+
+	d := &Domain{ID: 3, Name: "example.com"}
+	if err = c.UpdateDomain(d); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	relay := &RelaySetting{Address: "192.168.1.30", Password1: "s3cr3t", Password2: "s3cr3t"}
+	if err = c.CreateRelaySetting(5, relay); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if err = c.DeleteDomain(3); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if err = c.UpdateRelaySetting(&RelaySetting{ID: 9, Address: "192.168.1.31"}); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+
+	if err = c.FlushMailQueueItem(8); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("Expected %d got %d", 4, len(events))
+	}
+
+	e := events[0]
+	if e.Type != EventUpdated || e.Resource != "domains" || e.ID != 3 || e.Path != "domains/3" {
+		t.Errorf("Expected the domain update got %v", e)
+	}
+	if before, ok := e.Before.(*Domain); !ok || !before.Enabled {
+		t.Errorf("Expected the cached domain got %v", e.Before)
+	}
+	if after, ok := e.After.(*Domain); !ok || after.Enabled || after == d {
+		t.Errorf("Expected a copy of the updated domain got %v", e.After)
+	}
+	if e.Form.Get("name") != "example.com" {
+		t.Errorf("Expected %s got %s", "example.com", e.Form.Get("name"))
+	}
+
+	e = events[1]
+	if e.Type != EventCreated || e.Resource != "relays" || e.ID != 12 || e.Before != nil {
+		t.Errorf("Expected the relay creation got %v", e)
+	}
+	if after := e.After.(*RelaySetting); after.Password1 != "" || relay.Password1 != "s3cr3t" {
+		t.Errorf("Expected the passwords to be removed from the event only")
+	}
+	if e.Form.Get("password1") == "s3cr3t" {
+		t.Errorf("Expected the password to be removed from the form")
+	}
+
+	e = events[2]
+	if e.Type != EventDeleted || e.ID != 3 || e.After != nil || e.Before != nil {
+		t.Errorf("Expected the domain deletion got %v", e)
+	}
+
+	e = events[3]
+	if e.Type != EventUpdated || e.Resource != "mailqueue/flush" || e.ID != 8 || e.After != nil {
+		t.Errorf("Expected the mail queue flush got %v", e)
+	}
+
+	b, err := json.Marshal(events[1])
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	var m map[string]interface{}
+	json.Unmarshal(b, &m)
+	if m["type"] != "created" || m["resource"] != "relays" {
+		t.Errorf("Expected the event to be encoded got %s", b)
+	}
+
+	c, err = getTestClient(server.URL, &Options{Events: bus, DryRun: true})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if err = c.DeleteDomain(3); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(events) != 4 {
+		t.Errorf("Expected no events in dry-run mode got %d", len(events))
+	}
+}
+
+func TestEventsPasswordChange(t *testing.T) {
+	var events []*Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"code": 200, "message": "The password has been changed"}`)
+	}))
+	defer server.Close()
+
+	bus := NewEventBus()
+	bus.Subscribe(func(e *Event) {
+		events = append(events, e)
+	})
+
+	c, err := getTestClient(server.URL, &Options{Events: bus})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	form := &PasswordForm{Password1: "s3cr3t", Password2: "s3cr3t"}
+	if err = c.ChangeUserPassword(4, form); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected %d got %d", 1, len(events))
+	}
+	e := events[0]
+	if e.Type != EventUpdated || e.Resource != "users/chpw" || e.ID != 4 || e.After != nil {
+		t.Errorf("Expected the password change got %v", e)
+	}
+	if e.Form.Get("password1") == "s3cr3t" || e.Form.Get("password2") == "s3cr3t" {
+		t.Errorf("Expected the passwords to be removed from the form")
+	}
+}
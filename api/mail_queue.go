@@ -0,0 +1,236 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+)
+
+// MailQueueItem holds a queued message
+type MailQueueItem struct {
+	ID          int      `json:"id" url:"id"`
+	MessageID   string   `json:"messageid" url:"messageid"`
+	Timestamp   MyTime   `json:"timestamp" url:"timestamp"`
+	FromAddress string   `json:"from_address" url:"from_address"`
+	ToAddress   []string `json:"to_address" url:"to_address"`
+	Subject     string   `json:"subject" url:"subject"`
+	Hostname    string   `json:"hostname" url:"hostname"`
+	Size        int      `json:"size" url:"size"`
+	Attempts    int      `json:"attempts" url:"attempts"`
+	LastAttempt MyTime   `json:"lastattempt" url:"lastattempt"`
+	Direction   int      `json:"direction" url:"direction"`
+	Reason      string   `json:"reason" url:"reason"`
+	Flag        int      `json:"flag" url:"flag"`
+}
+
+// MailQueueList holds queued messages
+type MailQueueList struct {
+	Items []MailQueueItem `json:"items"`
+	Links Links           `json:"links"`
+	Meta  Meta            `json:"meta"`
+}
+
+// MailQueueFilter holds mail queue list filters
+type MailQueueFilter struct {
+	FromAddress string `url:"from_address,omitempty"`
+	ToAddress   string `url:"to_address,omitempty"`
+	Domain      string `url:"domain,omitempty"`
+	Hostname    string `url:"hostname,omitempty"`
+	Reason      string `url:"reason,omitempty"`
+	MinAttempts int    `url:"min_attempts,omitempty"`
+}
+
+// MailQueueGroup holds a count of queued messages sharing a key
+type MailQueueGroup struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	Size  int    `json:"size"`
+}
+
+// MailQueueSummary holds a grouped view of the mail queue
+type MailQueueSummary struct {
+	Total    int              `json:"total"`
+	Size     int              `json:"size"`
+	ByDomain []MailQueueGroup `json:"by_domain"`
+	ByReason []MailQueueGroup `json:"by_reason"`
+}
+
+// GetMailQueue returns a MailQueueList object
+// This contains a paginated list of queued messages and links
+// to the neighbouring pages. The filter is optional and can be nil.
+func (c *Client) GetMailQueue(direction string, filter *MailQueueFilter, opts *ListOptions) (l *MailQueueList, err error) {
+	var v url.Values
+	var p string
+
+	if direction != QueueInbound && direction != QueueOutbound {
+		err = fmt.Errorf(directionParamError)
+		return
+	}
+
+	p = fmt.Sprintf("mailqueue/%s", direction)
+
+	if filter != nil {
+		v, _ = query.Values(filter)
+		if len(v) > 0 {
+			p = fmt.Sprintf("%s?%s", p, v.Encode())
+		}
+	}
+
+	l = &MailQueueList{}
+
+	err = c.get(p, opts, l)
+
+	return
+}
+
+// GetMailQueueItem returns a queued message
+func (c *Client) GetMailQueueItem(itemID int) (item *MailQueueItem, err error) {
+	if itemID <= 0 {
+		err = fmt.Errorf(itemIDError)
+		return
+	}
+
+	item = &MailQueueItem{}
+
+	err = c.get(fmt.Sprintf("mailqueue/item/%d", itemID), nil, item)
+
+	return
+}
+
+// FlushMailQueueItem attempts immediate delivery of a queued message
+func (c *Client) FlushMailQueueItem(itemID int) (err error) {
+	if itemID <= 0 {
+		err = fmt.Errorf(itemIDError)
+		return
+	}
+
+	err = c.post(fmt.Sprintf("mailqueue/flush/%d", itemID), url.Values{}, nil)
+
+	return
+}
+
+// RequeueMailQueueItem resets the retry state of a queued message
+func (c *Client) RequeueMailQueueItem(itemID int) (err error) {
+	if itemID <= 0 {
+		err = fmt.Errorf(itemIDError)
+		return
+	}
+
+	err = c.post(fmt.Sprintf("mailqueue/requeue/%d", itemID), url.Values{}, nil)
+
+	return
+}
+
+// DeleteMailQueueItem removes a message from the queue
+func (c *Client) DeleteMailQueueItem(itemID int) (err error) {
+	if itemID <= 0 {
+		err = fmt.Errorf(itemIDError)
+		return
+	}
+
+	err = c.delete(fmt.Sprintf("mailqueue/item/%d", itemID), nil)
+
+	return
+}
+
+// GetMailQueueSummary pages through the mail queue and returns
+// the messages grouped by destination domain and deferral reason
+func (c *Client) GetMailQueueSummary(direction string, filter *MailQueueFilter) (s *MailQueueSummary, err error) {
+	var items []MailQueueItem
+
+	if err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *MailQueueList
+		if l, err = c.GetMailQueue(direction, filter, opts); err != nil {
+			return
+		}
+		items = append(items, l.Items...)
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	s = SummarizeMailQueue(items)
+
+	return
+}
+
+// SummarizeMailQueue groups queued messages by destination domain
+// and deferral reason, largest groups first
+func SummarizeMailQueue(items []MailQueueItem) (s *MailQueueSummary) {
+	var domain, reason string
+	var domains, reasons map[string]*MailQueueGroup
+
+	s = &MailQueueSummary{}
+	domains = make(map[string]*MailQueueGroup)
+	reasons = make(map[string]*MailQueueGroup)
+
+	for _, item := range items {
+		s.Total++
+		s.Size += item.Size
+
+		seen := make(map[string]bool)
+		for _, addr := range item.ToAddress {
+			domain = addressDomain(addr)
+			if seen[domain] {
+				continue
+			}
+			seen[domain] = true
+			addToGroup(domains, domain, item.Size)
+		}
+
+		if reason = strings.TrimSpace(item.Reason); reason == "" {
+			reason = "unknown"
+		}
+		addToGroup(reasons, reason, item.Size)
+	}
+
+	s.ByDomain = sortedGroups(domains)
+	s.ByReason = sortedGroups(reasons)
+
+	return
+}
+
+func addressDomain(addr string) string {
+	if i := strings.LastIndex(addr, "@"); i != -1 {
+		return strings.ToLower(addr[i+1:])
+	}
+
+	return strings.ToLower(addr)
+}
+
+func addToGroup(m map[string]*MailQueueGroup, key string, size int) {
+	g, ok := m[key]
+	if !ok {
+		g = &MailQueueGroup{Key: key}
+		m[key] = g
+	}
+	g.Count++
+	g.Size += size
+}
+
+func sortedGroups(m map[string]*MailQueueGroup) (groups []MailQueueGroup) {
+	groups = make([]MailQueueGroup, 0, len(m))
+	for _, g := range m {
+		groups = append(groups, *g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+
+	return
+}
@@ -0,0 +1,230 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetMailQueueError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	l, err := client.GetMailQueue("sideways", nil, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != directionParamError {
+		t.Errorf("Expected '%s' got '%s'", directionParamError, err)
+	}
+	if l != nil {
+		t.Errorf("Expected %v got %v", nil, l)
+	}
+}
+
+func TestGetMailQueueOK(t *testing.T) {
+	var query string
+	n := 2
+	data := fmt.Sprintf(`
+	{
+		"items": [{
+			"id": 1,
+			"messageid": "1kXyZa-0004Qm-Ab",
+			"timestamp": "2019:08:29:11:05:00",
+			"from_address": "a@example.com",
+			"to_address": ["b@example.net"],
+			"subject": "Test",
+			"hostname": "ms1.example.com",
+			"size": 1024,
+			"attempts": 3,
+			"lastattempt": "2019:08:29:12:05:00",
+			"direction": 2,
+			"reason": "Connection timed out",
+			"flag": 0
+		}],
+		"links": {
+			"pages": {
+				"last": "http://baruwa.example.com/api/v1/mailqueue/outbound?page=%d",
+				"next": "http://baruwa.example.com/api/v1/mailqueue/outbound?page=%d"
+			}
+		},
+		"meta": {
+			"total": 11
+		}
+	}
+	`, n, n)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, data)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	f := &MailQueueFilter{
+		Domain: "example.net",
+	}
+	l, err := client.GetMailQueue(QueueOutbound, f, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(l.Items) != 1 {
+		t.Fatalf("Expected %d got %d", 1, len(l.Items))
+	}
+	if l.Items[0].Attempts != 3 {
+		t.Errorf("Expected %d got %d", 3, l.Items[0].Attempts)
+	}
+	if query != "domain=example.net" {
+		t.Errorf("Expected '%s' got '%s'", "domain=example.net", query)
+	}
+	opts := &ListOptions{
+		Page: fmt.Sprintf(MailQueueListURL, server.URL, APIVersion, QueueOutbound, n),
+	}
+	_, err = client.GetMailQueue(QueueOutbound, f, opts)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if query != "domain=example.net&page=2" {
+		t.Errorf("Expected '%s' got '%s'", "domain=example.net&page=2", query)
+	}
+}
+
+func TestGetMailQueueItemError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	item, err := client.GetMailQueueItem(0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != itemIDError {
+		t.Errorf("Expected '%s' got '%s'", itemIDError, err)
+	}
+	if item != nil {
+		t.Errorf("Expected %v got %v", nil, item)
+	}
+}
+
+func TestGetMailQueueItemOK(t *testing.T) {
+	itemID := 4
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	item, err := client.GetMailQueueItem(itemID)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if item.ID != itemID {
+		t.Errorf("Expected %d got %d", itemID, item.ID)
+	}
+	if len(item.ToAddress) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(item.ToAddress))
+	}
+}
+
+func TestMailQueueActionsError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	for _, fn := range []func(int) error{
+		client.FlushMailQueueItem,
+		client.RequeueMailQueueItem,
+		client.DeleteMailQueueItem,
+	} {
+		err = fn(0)
+		if err == nil {
+			t.Fatalf("An error should be returned")
+		}
+		if err.Error() != itemIDError {
+			t.Errorf("Expected '%s' got '%s'", itemIDError, err)
+		}
+	}
+}
+
+func TestMailQueueActionsOK(t *testing.T) {
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, fn := range []func(int) error{
+		client.FlushMailQueueItem,
+		client.RequeueMailQueueItem,
+		client.DeleteMailQueueItem,
+	} {
+		if err = fn(2); err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+	}
+}
+
+func TestGetMailQueueSummary(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"items": [
+				{"id": 1, "to_address": ["a@example.net", "b@example.net"], "size": 10, "reason": "Connection refused"},
+				{"id": 2, "to_address": ["a@example.org"], "size": 5, "reason": "Connection refused"}
+			], "links": {"pages": {"next": "http://%s/api/v1/mailqueue/inbound?page=2"}}, "meta": {"total": 3}}`, r.Host)
+			return
+		}
+		fmt.Fprint(w, `{"items": [
+			{"id": 3, "to_address": ["c@EXAMPLE.net"], "size": 20, "reason": ""}
+		], "links": {"pages": {}}, "meta": {"total": 3}}`)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	s, err := client.GetMailQueueSummary(QueueInbound, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected %d got %d", 2, calls)
+	}
+	if s.Total != 3 {
+		t.Errorf("Expected %d got %d", 3, s.Total)
+	}
+	if s.Size != 35 {
+		t.Errorf("Expected %d got %d", 35, s.Size)
+	}
+	if len(s.ByDomain) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(s.ByDomain))
+	}
+	if s.ByDomain[0].Key != "example.net" || s.ByDomain[0].Count != 2 {
+		t.Errorf("Expected %s/%d got %s/%d", "example.net", 2, s.ByDomain[0].Key, s.ByDomain[0].Count)
+	}
+	if len(s.ByReason) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(s.ByReason))
+	}
+	if s.ByReason[0].Key != "Connection refused" || s.ByReason[1].Key != "unknown" {
+		t.Errorf("Expected %s got %s", "Connection refused", s.ByReason[0].Key)
+	}
+	_, err = client.GetMailQueueSummary("", nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
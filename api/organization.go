@@ -20,11 +20,18 @@ type OrgDomain struct {
 	Name string `json:"name" url:"name"`
 }
 
+// OrgAdmin hold organization administrator entries
+type OrgAdmin struct {
+	ID       int    `json:"id" url:"id"`
+	Username string `json:"username" url:"username"`
+}
+
 // Organization holds organizations
 type Organization struct {
 	ID      int         `json:"id,omitempty" url:"id,omitempty"`
 	Name    string      `json:"name" url:"name"`
 	Domains []OrgDomain `json:"domains,omitempty" url:"domains,omitempty"`
+	Admins  []OrgAdmin  `json:"admins,omitempty" url:"admins,omitempty"`
 }
 
 // OrganizationForm used for creation and update of organizations
@@ -0,0 +1,104 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// OrgAdminList holds organization administrators
+type OrgAdminList struct {
+	Items []OrgAdmin `json:"items"`
+	Links Links      `json:"links"`
+	Meta  Meta       `json:"meta"`
+}
+
+// GetOrganizationAdmins returns a OrgAdminList object
+// This contains a paginated list of organization administrators
+// and links to the neighbouring pages.
+func (c *Client) GetOrganizationAdmins(organizationID int, opts *ListOptions) (l *OrgAdminList, err error) {
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	l = &OrgAdminList{}
+
+	err = c.get(fmt.Sprintf("organizations/admins/%d", organizationID), opts, l)
+
+	return
+}
+
+// AddOrganizationAdmin makes a user account an administrator
+// of an organization
+func (c *Client) AddOrganizationAdmin(organizationID, adminID int) (admin *OrgAdmin, err error) {
+	var v url.Values
+
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	if adminID <= 0 {
+		err = fmt.Errorf(adminIDError)
+		return
+	}
+
+	v = url.Values{}
+	v.Set("admin", strconv.Itoa(adminID))
+
+	admin = &OrgAdmin{}
+
+	err = c.post(fmt.Sprintf("organizations/admins/%d", organizationID), v, admin)
+
+	return
+}
+
+// RemoveOrganizationAdmin removes a user account from the
+// administrators of an organization
+func (c *Client) RemoveOrganizationAdmin(organizationID, adminID int) (err error) {
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	if adminID <= 0 {
+		err = fmt.Errorf(adminIDError)
+		return
+	}
+
+	err = c.delete(fmt.Sprintf("organizations/admins/%d/%d", organizationID, adminID), nil)
+
+	return
+}
+
+// GetOrganizationAdminUsers returns the full user accounts of all
+// the administrators of an organization
+func (c *Client) GetOrganizationAdminUsers(organizationID int) (users []User, err error) {
+	err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var u *User
+		var l *OrgAdminList
+		if l, err = c.GetOrganizationAdmins(organizationID, opts); err != nil {
+			return
+		}
+
+		for _, admin := range l.Items {
+			if u, err = c.GetUser(admin.ID); err != nil {
+				return
+			}
+			users = append(users, *u)
+		}
+
+		links, done = l.Links, len(l.Items) == 0
+		return
+	})
+
+	return
+}
@@ -0,0 +1,170 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetOrganizationAdminsError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	l, err := client.GetOrganizationAdmins(0, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != organizationIDError {
+		t.Errorf("Expected '%s' got '%s'", organizationIDError, err)
+	}
+	if l != nil {
+		t.Errorf("Expected %v got %v", nil, l)
+	}
+}
+
+func TestGetOrganizationAdminsOK(t *testing.T) {
+	n := 2
+	organizationID := 1
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	l, err := client.GetOrganizationAdmins(organizationID, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(l.Items) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(l.Items))
+	}
+	next := fmt.Sprintf(OrgAdminListURL, "http://baruwa.example.com", APIVersion, organizationID, n)
+	if l.Links.Pages.Next != next {
+		t.Errorf("Expected '%s' got '%s'", next, l.Links.Pages.Next)
+	}
+}
+
+func TestAddOrganizationAdminError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	_, err = client.AddOrganizationAdmin(0, 1)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != organizationIDError {
+		t.Errorf("Expected '%s' got '%s'", organizationIDError, err)
+	}
+	_, err = client.AddOrganizationAdmin(1, 0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != adminIDError {
+		t.Errorf("Expected '%s' got '%s'", adminIDError, err)
+	}
+}
+
+func TestAddOrganizationAdminOK(t *testing.T) {
+	var form string
+	adminID := 3
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm.Encode()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id": %d, "username": "admin@example.com"}`, adminID)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	admin, err := client.AddOrganizationAdmin(1, adminID)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if admin.ID != adminID {
+		t.Errorf("Expected %d got %d", adminID, admin.ID)
+	}
+	if form != "admin=3" {
+		t.Errorf("Expected '%s' got '%s'", "admin=3", form)
+	}
+}
+
+func TestRemoveOrganizationAdminError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	err = client.RemoveOrganizationAdmin(0, 1)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != organizationIDError {
+		t.Errorf("Expected '%s' got '%s'", organizationIDError, err)
+	}
+	err = client.RemoveOrganizationAdmin(1, 0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != adminIDError {
+		t.Errorf("Expected '%s' got '%s'", adminIDError, err)
+	}
+}
+
+func TestRemoveOrganizationAdminOK(t *testing.T) {
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	err = client.RemoveOrganizationAdmin(1, 3)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+}
+
+func TestGetOrganizationAdminUsers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/organizations/admins/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items": [
+			{"id": 3, "username": "admin@example.com"},
+			{"id": 4, "username": "admin2@example.com"}
+		], "links": {"pages": {}}, "meta": {"total": 2}}`)
+	})
+	mux.HandleFunc("/api/v1/users/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": %s, "username": "admin", "account_type": 2}`, r.URL.Path[len("/api/v1/users/"):])
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	users, err := client.GetOrganizationAdminUsers(1)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(users))
+	}
+	if users[1].ID != 4 {
+		t.Errorf("Expected %d got %d", 4, users[1].ID)
+	}
+	_, err = client.GetOrganizationAdminUsers(0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -33,29 +32,10 @@ func TestGetFallBackServersError(t *testing.T) {
 }
 
 func TestGetFallBackServersOK(t *testing.T) {
-	data := `
-	{
-		"items": [{
-			"organization": {
-				"name": "Baruwa",
-				"id": 2
-			},
-			"protocol": 1,
-			"enabled": true,
-			"id": 2,
-			"address": "192.168.1.150",
-			"port": 25
-		}],
-		"meta": {
-			"total": 1
-		}
-	}
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	s, err := client.GetFallBackServers(1, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -89,25 +69,10 @@ func TestGetFallBackServerError(t *testing.T) {
 
 func TestGetFallBackServerOK(t *testing.T) {
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"organization": {
-			"name": "Baruwa",
-			"id": 2
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as, err := client.GetFallBackServer(serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -146,25 +111,10 @@ func TestCreateFallBackServerError(t *testing.T) {
 func TestCreateFallBackServerOK(t *testing.T) {
 	organizationID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"organization": {
-			"name": "Baruwa",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, organizationID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	fs := &FallBackServer{
 		Address:      "192.168.1.151",
 		Protocol:     1,
@@ -218,25 +168,10 @@ func TestUpdateFallBackServerError(t *testing.T) {
 func TestUpdateFallBackServerOK(t *testing.T) {
 	organizationID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"organization": {
-			"name": "Baruwa",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": false,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, organizationID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	fs := &FallBackServer{
 		ID:         serverID,
 		Address:    "192.168.1.151",
@@ -255,6 +190,9 @@ func TestUpdateFallBackServerOK(t *testing.T) {
 	if fs.Enabled {
 		t.Errorf("Expected %t got %t", false, fs.Enabled)
 	}
+	if fs.Organization == nil || fs.Organization.ID != organizationID {
+		t.Errorf("Expected organization %d got %v", organizationID, fs.Organization)
+	}
 }
 
 func TestDeleteFallBackServerError(t *testing.T) {
@@ -289,12 +227,10 @@ func TestDeleteFallBackServerError(t *testing.T) {
 
 func TestDeleteFallBackServerOK(t *testing.T) {
 	serverID := 4
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	fs := &FallBackServer{
 		ID:         serverID,
 		Address:    "192.168.1.151",
@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -34,25 +33,10 @@ func TestGetRelaySettingError(t *testing.T) {
 
 func TestGetRelaySettingOK(t *testing.T) {
 	serverID := 3
-	data := fmt.Sprintf(`
-	{
-		"username": "outboundsmtp",
-		"description": "Backup-outbound-smtp",
-		"enabled": true,
-		"require_tls": false,
-		"spam_actions": 2,
-		"low_score": 10.0,
-		"high_score": 15.0,
-		"address": "192.168.1.20",
-		"id": %d,
-		"highspam_actions": 3
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	rs, err := client.GetRelaySetting(serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned")
@@ -87,25 +71,10 @@ func TestCreateRelaySettingError(t *testing.T) {
 
 func TestCreateRelaySettingOK(t *testing.T) {
 	serverID := 3
-	data := fmt.Sprintf(`
-	{
-		"username": "outboundsmtp",
-		"description": "Backup-outbound-smtp",
-		"enabled": true,
-		"require_tls": false,
-		"spam_actions": 2,
-		"low_score": 10.0,
-		"high_score": 15.0,
-		"address": "192.168.1.20",
-		"id": %d,
-		"highspam_actions": 3
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	rs := &RelaySetting{
 		Username:        "outboundsmtp",
 		Description:     "Backup-outbound-smtp",
@@ -160,25 +129,10 @@ func TestUpdateRelaySettingError(t *testing.T) {
 
 func TestUpdateRelaySettingOK(t *testing.T) {
 	serverID := 3
-	data := fmt.Sprintf(`
-	{
-		"username": "outboundsmtp",
-		"description": "Backup-outbound-smtp",
-		"enabled": true,
-		"require_tls": false,
-		"spam_actions": 2,
-		"low_score": 10.0,
-		"high_score": 15.0,
-		"address": "192.168.1.20",
-		"id": %d,
-		"highspam_actions": 3
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	rs := &RelaySetting{
 		ID:              serverID,
 		Username:        "outboundsmtp",
@@ -231,12 +185,10 @@ func TestDeleteRelaySettingError(t *testing.T) {
 
 func TestDeleteRelaySettingOK(t *testing.T) {
 	serverID := 3
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	rs := &RelaySetting{
 		ID:              serverID,
 		Username:        "outboundsmtp",
@@ -31,41 +31,10 @@ func TestGetOrgSmartHostsError(t *testing.T) {
 
 func TestGetOrgSmartHostsOK(t *testing.T) {
 	organizationID := 2
-	data := fmt.Sprintf(`{
-		"items": [{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.1.150",
-			"username": "andrew",
-			"description": "outbound-archiver",
-			"port": 25
-		},
-		{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.2.150",
-			"username": "andrew",
-			"description": "outbound-archiver2",
-			"port": 25
-		}],
-		"meta": {
-			"total": 2
-		},
-		"links": {
-			"pages": {
-				"last": "http://baruwa.example.com/api/v1/organizations/smarthosts/%d?page=2",
-				"next": "http://baruwa.example.com/api/v1/organizations/smarthosts/%d?page=2"
-			}
-		}
-	}
-`, organizationID, organizationID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetOrgSmartHosts(organizationID, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -113,22 +82,10 @@ func TestGetOrgSmartHostError(t *testing.T) {
 
 func TestGetOrgSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds, err := client.GetOrgSmartHost(1, serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -163,22 +120,10 @@ func TestCreateOrgSmartHostError(t *testing.T) {
 
 func TestCreateOrgSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": true,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &OrgSmartHost{
 		Username:    "andrew",
 		Password:    "p4ssw0rd",
@@ -235,22 +180,10 @@ func TestUpdateOrgSmartHostError(t *testing.T) {
 
 func TestUpdateOrgSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &OrgSmartHost{
 		ID:          serverID,
 		Username:    "andrew",
@@ -307,22 +240,10 @@ func TestDeleteOrgSmartHostError(t *testing.T) {
 
 func TestDeleteOrgSmartHostOK(t *testing.T) {
 	serverID := 2
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"require_tls": false,
-		"id": %d,
-		"address": "192.168.1.150",
-		"username": "andrew",
-		"description": "outbound-archiver",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	ds := &OrgSmartHost{
 		ID:          serverID,
 		Username:    "andrew",
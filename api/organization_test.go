@@ -8,41 +8,15 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
 
 func TestGetOrganizationsOK(t *testing.T) {
-	data := `{
-		"items": [{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.1.150",
-			"username": "andrew",
-			"description": "outbound-archiver",
-			"port": 25
-		},
-		{
-			"enabled": true,
-			"require_tls": false,
-			"id": 2,
-			"address": "192.168.2.150",
-			"username": "andrew",
-			"description": "outbound-archiver2",
-			"port": 25
-		}],
-		"meta": {
-			"total": 2
-		}
-	}
-`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetOrganizations(nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -79,24 +53,10 @@ func TestGetOrganizationError(t *testing.T) {
 
 func TestGetOrganizationOK(t *testing.T) {
 	organizationID := 2
-	data := fmt.Sprintf(`
-	{
-		"domains": [{
-			"name": "example.com",
-			"id": 2
-		}, {
-			"name": "example.net",
-			"id": 4
-		}],
-		"name": "My Org",
-		"id": %d
-	}
-	`, organizationID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	o, err := client.GetOrganization(organizationID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -104,6 +64,9 @@ func TestGetOrganizationOK(t *testing.T) {
 	if o.ID != organizationID {
 		t.Errorf("Expected %d got %d", organizationID, o.ID)
 	}
+	if len(o.Admins) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(o.Admins))
+	}
 }
 
 func TestCreateOrganizationError(t *testing.T) {
@@ -124,24 +87,10 @@ func TestCreateOrganizationError(t *testing.T) {
 
 func TestCreateOrganizationOK(t *testing.T) {
 	organizationID := 2
-	data := fmt.Sprintf(`
-	{
-		"domains": [{
-			"name": "example.com",
-			"id": 2
-		}, {
-			"name": "example.net",
-			"id": 4
-		}],
-		"name": "My Org",
-		"id": %d
-	}
-	`, organizationID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &OrganizationForm{
 		Name:    "My Org",
 		Domains: []int{2, 4},
@@ -202,24 +151,10 @@ func TestUpdateOrganizationError(t *testing.T) {
 
 func TestUpdateOrganizationOK(t *testing.T) {
 	organizationID := 2
-	data := fmt.Sprintf(`
-	{
-		"domains": [{
-			"name": "example.com",
-			"id": 2
-		}, {
-			"name": "example.net",
-			"id": 4
-		}],
-		"name": "My Org",
-		"id": %d
-	}
-	`, organizationID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &OrganizationForm{
 		ID:      organizationID,
 		Name:    "My Org",
@@ -251,12 +186,10 @@ func TestDeleteOrganizationError(t *testing.T) {
 }
 
 func TestDeleteOrganizationOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	err = client.DeleteOrganization(1)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
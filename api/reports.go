@@ -0,0 +1,251 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// ReportOptions holds the time range and grouping of a report
+type ReportOptions struct {
+	Start    time.Time `url:"start,omitempty"`
+	End      time.Time `url:"end,omitempty"`
+	Interval string    `url:"interval,omitempty"`
+	Limit    int       `url:"limit,omitempty"`
+}
+
+// ReportAddress holds message totals for a sender or recipient
+type ReportAddress struct {
+	Address string `json:"address" url:"address"`
+	Count   int    `json:"count" url:"count"`
+	Size    int64  `json:"size" url:"size"`
+}
+
+// ReportPoint holds message totals for a single interval
+type ReportPoint struct {
+	Timestamp MyTime `json:"timestamp" url:"timestamp"`
+	Total     int    `json:"total" url:"total"`
+	Clean     int    `json:"clean" url:"clean"`
+	Spam      int    `json:"spam" url:"spam"`
+	HighSpam  int    `json:"highspam" url:"highspam"`
+	LowSpam   int    `json:"lowspam" url:"lowspam"`
+	Virii     int    `json:"virii" url:"virii"`
+	Infected  int    `json:"infected" url:"infected"`
+	Size      int64  `json:"size" url:"size"`
+}
+
+// Report holds message statistics for a time range
+type Report struct {
+	Start         MyTime          `json:"start" url:"start"`
+	End           MyTime          `json:"end" url:"end"`
+	Interval      string          `json:"interval" url:"interval"`
+	Totals        SystemTotal     `json:"totals" url:"totals"`
+	Size          int64           `json:"size" url:"size"`
+	TopSenders    []ReportAddress `json:"top_senders" url:"top_senders"`
+	TopRecipients []ReportAddress `json:"top_recipients" url:"top_recipients"`
+	Series        []ReportPoint   `json:"series" url:"series"`
+}
+
+// SeriesPoint holds a single time series value
+type SeriesPoint struct {
+	Time  time.Time `json:"time"`
+	Value int64     `json:"value"`
+}
+
+var reportMetrics = map[string]func(*ReportPoint) int64{
+	"total":    func(p *ReportPoint) int64 { return int64(p.Total) },
+	"clean":    func(p *ReportPoint) int64 { return int64(p.Clean) },
+	"spam":     func(p *ReportPoint) int64 { return int64(p.Spam) },
+	"highspam": func(p *ReportPoint) int64 { return int64(p.HighSpam) },
+	"lowspam":  func(p *ReportPoint) int64 { return int64(p.LowSpam) },
+	"virii":    func(p *ReportPoint) int64 { return int64(p.Virii) },
+	"infected": func(p *ReportPoint) int64 { return int64(p.Infected) },
+	"size":     func(p *ReportPoint) int64 { return p.Size },
+}
+
+// GetDomainReport returns message statistics for a domain
+func (c *Client) GetDomainReport(domainID int, opts *ReportOptions) (r *Report, err error) {
+	if domainID <= 0 {
+		err = fmt.Errorf(domainIDError)
+		return
+	}
+
+	r, err = c.getReport(fmt.Sprintf("reports/domains/%d", domainID), opts)
+
+	return
+}
+
+// GetOrganizationReport returns message statistics for an organization
+func (c *Client) GetOrganizationReport(organizationID int, opts *ReportOptions) (r *Report, err error) {
+	if organizationID <= 0 {
+		err = fmt.Errorf(organizationIDError)
+		return
+	}
+
+	r, err = c.getReport(fmt.Sprintf("reports/organizations/%d", organizationID), opts)
+
+	return
+}
+
+// GetUserReport returns message statistics for a user account
+func (c *Client) GetUserReport(userID int, opts *ReportOptions) (r *Report, err error) {
+	if userID <= 0 {
+		err = fmt.Errorf(userIDError)
+		return
+	}
+
+	r, err = c.getReport(fmt.Sprintf("reports/users/%d", userID), opts)
+
+	return
+}
+
+func (c *Client) getReport(p string, opts *ReportOptions) (r *Report, err error) {
+	var v url.Values
+
+	if err = opts.validate(); err != nil {
+		return
+	}
+
+	if v, _ = query.Values(opts); len(v) > 0 {
+		p = fmt.Sprintf("%s?%s", p, v.Encode())
+	}
+
+	r = &Report{}
+
+	err = c.get(p, nil, r)
+
+	return
+}
+
+func (o *ReportOptions) validate() (err error) {
+	if o == nil {
+		err = fmt.Errorf(reportOptsError)
+		return
+	}
+
+	if !o.Start.IsZero() && !o.End.IsZero() && !o.Start.Before(o.End) {
+		err = fmt.Errorf(reportRangeError)
+		return
+	}
+
+	if o.Interval != "" && o.Interval != ReportHourly && o.Interval != ReportDaily {
+		err = fmt.Errorf(reportIntervalError)
+		return
+	}
+
+	return
+}
+
+// TimeSeries returns the values of a metric ordered by time with
+// the missing intervals filled in as zero. Valid metrics are
+// total, clean, spam, highspam, lowspam, virii, infected and size.
+func (r *Report) TimeSeries(metric string) (s []SeriesPoint, err error) {
+	var step time.Duration
+	var start, end time.Time
+	var points []ReportPoint
+	var values map[int64]int64
+
+	fn, ok := reportMetrics[metric]
+	if !ok {
+		err = fmt.Errorf(reportMetricError)
+		return
+	}
+
+	if len(r.Series) == 0 {
+		return
+	}
+
+	points = make([]ReportPoint, len(r.Series))
+	copy(points, r.Series)
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp.Time)
+	})
+
+	step = time.Hour
+	if r.Interval == ReportDaily {
+		step = 24 * time.Hour
+	}
+
+	values = make(map[int64]int64, len(points))
+	for i := range points {
+		values[points[i].Timestamp.Truncate(step).Unix()] += fn(&points[i])
+	}
+
+	start = points[0].Timestamp.Truncate(step)
+	end = points[len(points)-1].Timestamp.Truncate(step)
+	for t := start; !t.After(end); t = t.Add(step) {
+		s = append(s, SeriesPoint{Time: t, Value: values[t.Unix()]})
+	}
+
+	return
+}
+
+// WriteCSV writes the report series to w as CSV
+func (r *Report) WriteCSV(w io.Writer) (err error) {
+	cw := csv.NewWriter(w)
+
+	if err = cw.Write([]string{
+		"timestamp", "total", "clean", "spam", "highspam",
+		"lowspam", "virii", "infected", "size",
+	}); err != nil {
+		return
+	}
+
+	for _, p := range r.Series {
+		if err = cw.Write([]string{
+			p.Timestamp.Format(time.RFC3339),
+			strconv.Itoa(p.Total),
+			strconv.Itoa(p.Clean),
+			strconv.Itoa(p.Spam),
+			strconv.Itoa(p.HighSpam),
+			strconv.Itoa(p.LowSpam),
+			strconv.Itoa(p.Virii),
+			strconv.Itoa(p.Infected),
+			strconv.FormatInt(p.Size, 10),
+		}); err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+	err = cw.Error()
+
+	return
+}
+
+// WriteAddressesCSV writes top senders or recipients to w as CSV
+func WriteAddressesCSV(w io.Writer, addrs []ReportAddress) (err error) {
+	cw := csv.NewWriter(w)
+
+	if err = cw.Write([]string{"address", "count", "size"}); err != nil {
+		return
+	}
+
+	for _, a := range addrs {
+		if err = cw.Write([]string{
+			a.Address,
+			strconv.Itoa(a.Count),
+			strconv.FormatInt(a.Size, 10),
+		}); err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+	err = cw.Error()
+
+	return
+}
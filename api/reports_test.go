@@ -0,0 +1,216 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var reportData = `
+{
+	"start": "2019:08:29:00:00:00",
+	"end": "2019:08:29:04:00:00",
+	"interval": "hour",
+	"totals": {
+		"spam": 3,
+		"highspam": 1,
+		"lowspam": 2,
+		"infected": 0,
+		"clean": 10,
+		"total": 14,
+		"virii": 1
+	},
+	"size": 20480,
+	"top_senders": [
+		{"address": "a@example.com", "count": 9, "size": 10240},
+		{"address": "b@example.com", "count": 5, "size": 10240}
+	],
+	"top_recipients": [
+		{"address": "c@example.net", "count": 14, "size": 20480}
+	],
+	"series": [
+		{"timestamp": "2019:08:29:03:00:00", "total": 4, "clean": 2, "spam": 2, "size": 4096},
+		{"timestamp": "2019:08:29:00:00:00", "total": 10, "clean": 8, "spam": 1, "virii": 1, "size": 16384}
+	]
+}
+`
+
+func TestGetReportsError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	_, err = client.GetDomainReport(0, nil)
+	if err == nil || err.Error() != domainIDError {
+		t.Errorf("Expected '%s' got '%v'", domainIDError, err)
+	}
+	_, err = client.GetOrganizationReport(0, nil)
+	if err == nil || err.Error() != organizationIDError {
+		t.Errorf("Expected '%s' got '%v'", organizationIDError, err)
+	}
+	_, err = client.GetUserReport(0, nil)
+	if err == nil || err.Error() != userIDError {
+		t.Errorf("Expected '%s' got '%v'", userIDError, err)
+	}
+	_, err = client.GetDomainReport(1, nil)
+	if err == nil || err.Error() != reportOptsError {
+		t.Errorf("Expected '%s' got '%v'", reportOptsError, err)
+	}
+	now := time.Now()
+	opts := &ReportOptions{
+		Start: now,
+		End:   now.Add(-time.Hour),
+	}
+	_, err = client.GetDomainReport(1, opts)
+	if err == nil || err.Error() != reportRangeError {
+		t.Errorf("Expected '%s' got '%v'", reportRangeError, err)
+	}
+	opts = &ReportOptions{
+		Interval: "week",
+	}
+	_, err = client.GetDomainReport(1, opts)
+	if err == nil || err.Error() != reportIntervalError {
+		t.Errorf("Expected '%s' got '%v'", reportIntervalError, err)
+	}
+}
+
+func TestGetReportsOK(t *testing.T) {
+	var path, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, reportData)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	start := time.Date(2019, 8, 29, 0, 0, 0, 0, time.UTC)
+	opts := &ReportOptions{
+		Start:    start,
+		End:      start.Add(4 * time.Hour),
+		Interval: ReportHourly,
+		Limit:    10,
+	}
+	r, err := client.GetDomainReport(1, opts)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if path != "/api/v1/reports/domains/1" {
+		t.Errorf("Expected '%s' got '%s'", "/api/v1/reports/domains/1", path)
+	}
+	expected := "end=2019-08-29T04%3A00%3A00Z&interval=hour&limit=10&start=2019-08-29T00%3A00%3A00Z"
+	if query != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, query)
+	}
+	if r.Totals.Total != 14 {
+		t.Errorf("Expected %d got %d", 14, r.Totals.Total)
+	}
+	if len(r.TopSenders) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(r.TopSenders))
+	}
+	_, err = client.GetOrganizationReport(1, &ReportOptions{})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if path != "/api/v1/reports/organizations/1" {
+		t.Errorf("Expected '%s' got '%s'", "/api/v1/reports/organizations/1", path)
+	}
+	if query != "" {
+		t.Errorf("Expected '' got '%s'", query)
+	}
+	_, err = client.GetUserReport(1, opts)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if path != "/api/v1/reports/users/1" {
+		t.Errorf("Expected '%s' got '%s'", "/api/v1/reports/users/1", path)
+	}
+}
+
+func TestReportTimeSeries(t *testing.T) {
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	r, err := client.GetDomainReport(1, &ReportOptions{})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	_, err = r.TimeSeries("ham")
+	if err == nil || err.Error() != reportMetricError {
+		t.Errorf("Expected '%s' got '%v'", reportMetricError, err)
+	}
+	s, err := r.TimeSeries("total")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(s) != 4 {
+		t.Fatalf("Expected %d got %d", 4, len(s))
+	}
+	values := []int64{10, 0, 0, 4}
+	for i, v := range values {
+		if s[i].Value != v {
+			t.Errorf("Expected %d got %d", v, s[i].Value)
+		}
+	}
+	r.Interval = ReportDaily
+	s, err = r.TimeSeries("size")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(s) != 1 || s[0].Value != 20480 {
+		t.Errorf("Expected %d got %v", 20480, s)
+	}
+	empty := &Report{}
+	s, err = empty.TimeSeries("total")
+	if err != nil || len(s) != 0 {
+		t.Errorf("Expected empty series got %v %v", s, err)
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	r, err := client.GetDomainReport(1, &ReportOptions{})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	buf := &bytes.Buffer{}
+	if err = r.WriteCSV(buf); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected %d got %d", 3, len(lines))
+	}
+	expected := "2019-08-29T03:00:00Z,4,2,2,0,0,0,0,4096"
+	if lines[1] != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, lines[1])
+	}
+	buf.Reset()
+	if err = WriteAddressesCSV(buf, r.TopSenders); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected = "address,count,size\na@example.com,9,10240\nb@example.com,5,10240\n"
+	if buf.String() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, buf.String())
+	}
+}
@@ -0,0 +1,206 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+)
+
+// NodeDiskThreshold is the disk usage percentage at which
+// a node is reported as degraded
+const NodeDiskThreshold = 90.0
+
+// Node holds a scanner node
+type Node struct {
+	ID       int    `json:"id,omitempty" url:"id,omitempty"`
+	Hostname string `json:"hostname" url:"hostname"`
+	Address  string `json:"address" url:"address"`
+	Enabled  bool   `json:"enabled" url:"enabled"`
+}
+
+// NodeList holds scanner nodes
+type NodeList struct {
+	Items []Node `json:"items"`
+	Links Links  `json:"links"`
+	Meta  Meta   `json:"meta"`
+}
+
+// NodeDisk holds node partition usage
+type NodeDisk struct {
+	Mount   string  `json:"mount" url:"mount"`
+	Total   int64   `json:"total" url:"total"`
+	Used    int64   `json:"used" url:"used"`
+	Percent float64 `json:"percent" url:"percent"`
+}
+
+// NodeStatus holds the status of a scanner node
+type NodeStatus struct {
+	ID          int               `json:"id" url:"id"`
+	Hostname    string            `json:"hostname" url:"hostname"`
+	MTA         bool              `json:"mta" url:"mta"`
+	Scanners    bool              `json:"scanners" url:"scanners"`
+	Database    bool              `json:"database" url:"database"`
+	Load        []float64         `json:"load" url:"load"`
+	Disks       []NodeDisk        `json:"disks" url:"disks"`
+	Versions    map[string]string `json:"versions" url:"versions"`
+	LastUpdated MyTime            `json:"last_updated" url:"last_updated"`
+}
+
+// NodeHealth holds the evaluated health of a node
+type NodeHealth struct {
+	Node     Node        `json:"node"`
+	Status   *NodeStatus `json:"status,omitempty"`
+	Degraded bool        `json:"degraded"`
+	Reasons  []string    `json:"reasons,omitempty"`
+}
+
+// ClusterHealth holds the health of all nodes in a cluster
+type ClusterHealth struct {
+	// Status is the aggregate reported by GetSystemStatus
+	Status bool `json:"status"`
+	// Healthy is true when no node is degraded
+	Healthy bool         `json:"healthy"`
+	Nodes   []NodeHealth `json:"nodes"`
+	// Degraded lists the hostnames of degraded nodes
+	Degraded []string `json:"degraded,omitempty"`
+	// Mismatch is true when the aggregate status disagrees
+	// with the per node status
+	Mismatch bool `json:"mismatch"`
+}
+
+// GetNodes returns a NodeList object
+// This contains a paginated list of scanner nodes and links
+// to the neighbouring pages.
+func (c *Client) GetNodes(opts *ListOptions) (l *NodeList, err error) {
+	l = &NodeList{}
+
+	err = c.get("nodes", opts, l)
+
+	return
+}
+
+// GetNode returns a scanner node
+func (c *Client) GetNode(nodeID int) (node *Node, err error) {
+	if nodeID <= 0 {
+		err = fmt.Errorf(nodeIDError)
+		return
+	}
+
+	node = &Node{}
+
+	err = c.get(fmt.Sprintf("nodes/%d", nodeID), nil, node)
+
+	return
+}
+
+// GetNodeStatus returns the status of a scanner node
+func (c *Client) GetNodeStatus(nodeID int) (status *NodeStatus, err error) {
+	if nodeID <= 0 {
+		err = fmt.Errorf(nodeIDError)
+		return
+	}
+
+	status = &NodeStatus{}
+
+	err = c.get(fmt.Sprintf("nodes/status/%d", nodeID), nil, status)
+
+	return
+}
+
+// GetClusterHealth returns the health of every node in the cluster
+// compared against the aggregate system status
+func (c *Client) GetClusterHealth() (h *ClusterHealth, err error) {
+	var nodes []NodeHealth
+	var status *SystemStatus
+	var nodeStatus *NodeStatus
+
+	if status, err = c.GetSystemStatus(); err != nil {
+		return
+	}
+
+	if err = EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *NodeList
+		if l, err = c.GetNodes(opts); err != nil {
+			return
+		}
+
+		for _, node := range l.Items {
+			nh := NodeHealth{Node: node}
+			if node.Enabled {
+				if nodeStatus, err = c.GetNodeStatus(node.ID); err != nil {
+					nh.Degraded = true
+					nh.Reasons = []string{err.Error()}
+					err = nil
+				} else {
+					nh.Status = nodeStatus
+				}
+			}
+			nodes = append(nodes, nh)
+		}
+
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	h = EvaluateClusterHealth(status, nodes)
+
+	return
+}
+
+// EvaluateClusterHealth marks the nodes that are degraded and
+// compares the result with the aggregate system status
+func EvaluateClusterHealth(status *SystemStatus, nodes []NodeHealth) (h *ClusterHealth) {
+	h = &ClusterHealth{
+		Nodes: nodes,
+	}
+
+	if status != nil {
+		h.Status = status.Status
+	}
+
+	for i := range h.Nodes {
+		n := &h.Nodes[i]
+		if n.Status != nil {
+			n.Reasons = append(n.Reasons, n.Status.Problems()...)
+		}
+		if len(n.Reasons) > 0 {
+			n.Degraded = true
+			h.Degraded = append(h.Degraded, n.Node.Hostname)
+		}
+	}
+
+	h.Healthy = len(h.Degraded) == 0
+	h.Mismatch = h.Healthy != h.Status
+
+	return
+}
+
+// Problems returns the reasons a node should be considered degraded
+func (s *NodeStatus) Problems() (p []string) {
+	if !s.MTA {
+		p = append(p, "MTA is not running")
+	}
+
+	if !s.Scanners {
+		p = append(p, "scanners are not running")
+	}
+
+	if !s.Database {
+		p = append(p, "database is not reachable")
+	}
+
+	for _, d := range s.Disks {
+		if d.Percent >= NodeDiskThreshold {
+			p = append(p, fmt.Sprintf("disk %s is %.1f%% full", d.Mount, d.Percent))
+		}
+	}
+
+	return
+}
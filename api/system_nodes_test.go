@@ -0,0 +1,175 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetNodesOK(t *testing.T) {
+	n := 2
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	l, err := client.GetNodes(nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(l.Items) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(l.Items))
+	}
+	next := fmt.Sprintf(NodeListURL, "http://baruwa.example.com", APIVersion, n)
+	if l.Links.Pages.Next != next {
+		t.Errorf("Expected '%s' got '%s'", next, l.Links.Pages.Next)
+	}
+}
+
+func TestGetNodeError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	node, err := client.GetNode(0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != nodeIDError {
+		t.Errorf("Expected '%s' got '%s'", nodeIDError, err)
+	}
+	if node != nil {
+		t.Errorf("Expected %v got %v", nil, node)
+	}
+}
+
+func TestGetNodeOK(t *testing.T) {
+	nodeID := 1
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	node, err := client.GetNode(nodeID)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if node.ID != nodeID {
+		t.Errorf("Expected %d got %d", nodeID, node.ID)
+	}
+}
+
+func TestGetNodeStatusError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	status, err := client.GetNodeStatus(0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != nodeIDError {
+		t.Errorf("Expected '%s' got '%s'", nodeIDError, err)
+	}
+	if status != nil {
+		t.Errorf("Expected %v got %v", nil, status)
+	}
+}
+
+func TestGetNodeStatusOK(t *testing.T) {
+	nodeID := 1
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	status, err := client.GetNodeStatus(nodeID)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if status.Versions["exim"] != "4.92" {
+		t.Errorf("Expected %s got %s", "4.92", status.Versions["exim"])
+	}
+	if len(status.Load) != 3 {
+		t.Errorf("Expected %d got %d", 3, len(status.Load))
+	}
+	if p := status.Problems(); len(p) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(p))
+	}
+}
+
+func TestGetClusterHealth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"inbound": 0, "status": true, "outbound": 0}`)
+	})
+	mux.HandleFunc("/api/v1/nodes", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items": [
+			{"id": 1, "hostname": "ms1.example.com", "enabled": true},
+			{"id": 2, "hostname": "ms2.example.com", "enabled": true},
+			{"id": 3, "hostname": "ms3.example.com", "enabled": true},
+			{"id": 4, "hostname": "ms4.example.com", "enabled": false}
+		], "links": {"pages": {}}, "meta": {"total": 4}}`)
+	})
+	mux.HandleFunc("/api/v1/nodes/status/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 1, "hostname": "ms1.example.com", "mta": true, "scanners": true, "database": true}`)
+	})
+	mux.HandleFunc("/api/v1/nodes/status/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 2, "hostname": "ms2.example.com", "mta": false, "scanners": true, "database": true}`)
+	})
+	mux.HandleFunc("/api/v1/nodes/status/3", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	h, err := client.GetClusterHealth()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(h.Nodes) != 4 {
+		t.Fatalf("Expected %d got %d", 4, len(h.Nodes))
+	}
+	if h.Healthy {
+		t.Errorf("Expected %t got %t", false, h.Healthy)
+	}
+	if !h.Mismatch {
+		t.Errorf("Expected %t got %t", true, h.Mismatch)
+	}
+	if len(h.Degraded) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(h.Degraded))
+	}
+	if h.Degraded[0] != "ms2.example.com" || h.Degraded[1] != "ms3.example.com" {
+		t.Errorf("Expected %v got %v", []string{"ms2.example.com", "ms3.example.com"}, h.Degraded)
+	}
+	if h.Nodes[3].Degraded {
+		t.Errorf("Expected %t got %t", false, h.Nodes[3].Degraded)
+	}
+}
+
+func TestEvaluateClusterHealth(t *testing.T) {
+	h := EvaluateClusterHealth(&SystemStatus{Status: true}, []NodeHealth{
+		{
+			Node:   Node{ID: 1, Hostname: "ms1.example.com", Enabled: true},
+			Status: &NodeStatus{MTA: true, Scanners: true, Database: true},
+		},
+	})
+	if !h.Healthy {
+		t.Errorf("Expected %t got %t", true, h.Healthy)
+	}
+	if h.Mismatch {
+		t.Errorf("Expected %t got %t", false, h.Mismatch)
+	}
+}
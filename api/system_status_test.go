@@ -8,32 +8,14 @@
 package api
 
 import (
-	"net/http"
 	"testing"
 )
 
 func TestGetSystemStatus(t *testing.T) {
-	data := `
-	{
-		"inbound": 0,
-		"status": true,
-		"total": {
-		  "spam": 0,
-		  "highspam": 0,
-		  "lowspam": 0,
-		  "infected": 0,
-		  "clean": 16,
-		  "total": 16,
-		  "virii": 0
-		},
-		"outbound": 0
-	  }
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	_, err = client.GetSystemStatus()
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -26,8 +26,11 @@ type UserOrganization struct {
 	Name string `json:"name" url:"name"`
 }
 
-// UserAddress addresses
+// UserAddress holds user alias addresses
 type UserAddress struct {
+	ID      int    `json:"id" url:"id"`
+	Address string `json:"address" url:"address"`
+	Enabled bool   `json:"enabled" url:"enabled"`
 }
 
 // User holds users
@@ -49,6 +52,7 @@ type User struct {
 	LastLogin     MyTime             `json:"last_login" url:"last_login"`
 	Domains       []UserDomain       `json:"domains,omitempty" url:"domains,omitempty"`
 	Organizations []UserOrganization `json:"organizations,omitempty" url:"organizations,omitempty"`
+	Addresses     []UserAddress      `json:"addresses,omitempty" url:"addresses,omitempty"`
 }
 
 // UserForm holds users
@@ -23,6 +23,29 @@ type AliasAddress struct {
 	Enabled bool   `json:"enabled" url:"enabled"`
 }
 
+// AliasAddressList holds alias addresses
+type AliasAddressList struct {
+	Items []AliasAddress `json:"items"`
+	Links Links          `json:"links"`
+	Meta  Meta           `json:"meta"`
+}
+
+// GetUserAliasAddresses returns a AliasAddressList object
+// This contains a paginated list of the alias addresses of a
+// user account and links to the neighbouring pages.
+func (c *Client) GetUserAliasAddresses(userID int, opts *ListOptions) (l *AliasAddressList, err error) {
+	if userID <= 0 {
+		err = fmt.Errorf(userIDError)
+		return
+	}
+
+	l = &AliasAddressList{}
+
+	err = c.get(fmt.Sprintf("aliasaddresses/list/%d", userID), opts, l)
+
+	return
+}
+
 // GetAliasAddress returns an alias address
 //
 // Baruwa API Docs: https://www.baruwa.com/docs/api/#retrieve-an-existing-alias-address
@@ -107,3 +130,101 @@ func (c *Client) DeleteAliasAddress(alias *AliasAddress) (err error) {
 
 	return
 }
+
+// EnableUserAliasAddress enables an alias address of a user account
+func (c *Client) EnableUserAliasAddress(userID, aliasID int) (err error) {
+	err = c.setUserAliasAddressEnabled(userID, aliasID, true)
+
+	return
+}
+
+// DisableUserAliasAddress disables an alias address of a user account
+func (c *Client) DisableUserAliasAddress(userID, aliasID int) (err error) {
+	err = c.setUserAliasAddressEnabled(userID, aliasID, false)
+
+	return
+}
+
+// MoveAliasAddress moves an alias address from one user account
+// to another. If the alias address cannot be created on the target
+// account it is restored on the source account, if that also fails
+// both errors are returned.
+func (c *Client) MoveAliasAddress(fromUserID, toUserID, aliasID int) (alias *AliasAddress, err error) {
+	var src *AliasAddress
+
+	if toUserID <= 0 {
+		err = fmt.Errorf(userIDError)
+		return
+	}
+
+	if src, err = c.getUserAliasAddress(fromUserID, aliasID); err != nil {
+		return
+	}
+
+	if err = c.DeleteAliasAddress(src); err != nil {
+		return
+	}
+
+	alias = &AliasAddress{
+		Address: src.Address,
+		Enabled: src.Enabled,
+	}
+
+	if err = c.CreateAliasAddress(toUserID, alias); err != nil {
+		alias = nil
+		if rerr := c.CreateAliasAddress(fromUserID, &AliasAddress{
+			Address: src.Address,
+			Enabled: src.Enabled,
+		}); rerr != nil {
+			err = fmt.Errorf("%v; restoring %s on user %d: %v", err, src.Address, fromUserID, rerr)
+		}
+	}
+
+	return
+}
+
+func (c *Client) setUserAliasAddressEnabled(userID, aliasID int, enabled bool) (err error) {
+	var alias *AliasAddress
+
+	if alias, err = c.getUserAliasAddress(userID, aliasID); err != nil {
+		return
+	}
+
+	if alias.Enabled == enabled {
+		return
+	}
+
+	alias.Enabled = enabled
+
+	err = c.UpdateAliasAddress(alias)
+
+	return
+}
+
+func (c *Client) getUserAliasAddress(userID, aliasID int) (alias *AliasAddress, err error) {
+	var u *User
+
+	if aliasID <= 0 {
+		err = fmt.Errorf(aliasIDError)
+		return
+	}
+
+	if u, err = c.GetUser(userID); err != nil {
+		return
+	}
+
+	for _, a := range u.Addresses {
+		if a.ID == aliasID {
+			alias = &AliasAddress{
+				ID:      a.ID,
+				Address: a.Address,
+				Enabled: a.Enabled,
+			}
+			return
+		}
+	}
+
+	err = fmt.Errorf(aliasOwnerError)
+
+	return
+}
@@ -10,6 +10,8 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -31,18 +33,10 @@ func TestGetAliasAddressError(t *testing.T) {
 
 func TestGetAliasAddressOK(t *testing.T) {
 	aliasID := 3
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"id": %d,
-		"address": "info@example.com"
-	}
-	`, aliasID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	alias, err := client.GetAliasAddress(aliasID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -77,18 +71,10 @@ func TestCreateAliasAddressError(t *testing.T) {
 
 func TestCreateAliasAddressOK(t *testing.T) {
 	aliasID := 3
-	data := fmt.Sprintf(`
-	{
-		"enabled": false,
-		"id": %d,
-		"address": "info@example.com"
-	}
-	`, aliasID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	a := &AliasAddress{
 		Address: "a@example.com",
 		Enabled: false,
@@ -130,12 +116,10 @@ func TestUpdateAliasAddressError(t *testing.T) {
 }
 
 func TestUpdateAliasAddressOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	a := &AliasAddress{
 		ID:      2,
 		Address: "a@example.com",
@@ -175,12 +159,10 @@ func TestDeleteAliasAddressError(t *testing.T) {
 }
 
 func TestDeleteAliasAddressOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	a := &AliasAddress{
 		ID:      2,
 		Address: "a@example.com",
@@ -191,3 +173,186 @@ func TestDeleteAliasAddressOK(t *testing.T) {
 		t.Fatalf("An error should not be returned: %s", err)
 	}
 }
+
+func TestGetUserAliasAddressesError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	l, err := client.GetUserAliasAddresses(0, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != userIDError {
+		t.Errorf("Expected '%s' got '%s'", userIDError, err)
+	}
+	if l != nil {
+		t.Errorf("Expected %v got %v", nil, l)
+	}
+}
+
+func TestGetUserAliasAddressesOK(t *testing.T) {
+	n := 2
+	userID := 2
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	l, err := client.GetUserAliasAddresses(userID, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(l.Items) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(l.Items))
+	}
+	next := fmt.Sprintf(AliasListURL, "http://baruwa.example.com", APIVersion, userID, n)
+	if l.Links.Pages.Next != next {
+		t.Errorf("Expected '%s' got '%s'", next, l.Links.Pages.Next)
+	}
+}
+
+func getTestAliasServer(calls *[]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/users/2", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 2, "username": "andrew", "addresses": [
+			{"id": 3, "address": "info@example.com", "enabled": false}
+		]}`)
+	})
+	mux.HandleFunc("/api/v1/users/4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id": 4, "username": "bob", "addresses": [
+			{"id": 3, "address": "info@example.com", "enabled": false}
+		]}`)
+	})
+	mux.HandleFunc("/api/v1/aliasaddresses/", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*calls = append(*calls, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, r.PostForm.Encode()))
+		if r.Method == http.MethodPost && (r.URL.Path == "/api/v1/aliasaddresses/6" || r.URL.Path == "/api/v1/aliasaddresses/4") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"id": 7, "address": "info@example.com", "enabled": false}`)
+		}
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestUserAliasAddressEnabledError(t *testing.T) {
+	var calls []string
+	server := getTestAliasServer(&calls)
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	err = client.EnableUserAliasAddress(2, 0)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != aliasIDError {
+		t.Errorf("Expected '%s' got '%s'", aliasIDError, err)
+	}
+	err = client.EnableUserAliasAddress(0, 3)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != userIDError {
+		t.Errorf("Expected '%s' got '%s'", userIDError, err)
+	}
+	err = client.DisableUserAliasAddress(2, 4)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != aliasOwnerError {
+		t.Errorf("Expected '%s' got '%s'", aliasOwnerError, err)
+	}
+}
+
+func TestUserAliasAddressEnabledOK(t *testing.T) {
+	var calls []string
+	server := getTestAliasServer(&calls)
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	if err = client.DisableUserAliasAddress(2, 3); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("Expected %d got %d", 0, len(calls))
+	}
+	if err = client.EnableUserAliasAddress(2, 3); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected := "PUT /api/v1/aliasaddresses/3 address=info%40example.com&enabled=true&id=3"
+	if len(calls) != 1 || calls[0] != expected {
+		t.Errorf("Expected %v got %v", []string{expected}, calls)
+	}
+}
+
+func TestMoveAliasAddress(t *testing.T) {
+	var calls []string
+	server := getTestAliasServer(&calls)
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	_, err = client.MoveAliasAddress(2, 0, 3)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != userIDError {
+		t.Errorf("Expected '%s' got '%s'", userIDError, err)
+	}
+	alias, err := client.MoveAliasAddress(2, 5, 3)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if alias.ID != 7 {
+		t.Errorf("Expected %d got %d", 7, alias.ID)
+	}
+	expected := []string{
+		"DELETE /api/v1/aliasaddresses/3 ",
+		"POST /api/v1/aliasaddresses/5 address=info%40example.com&enabled=false",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v got %v", expected, calls)
+	}
+	calls = calls[:0]
+	alias, err = client.MoveAliasAddress(2, 6, 3)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if alias != nil {
+		t.Errorf("Expected %v got %v", nil, alias)
+	}
+	expected = []string{
+		"DELETE /api/v1/aliasaddresses/3 ",
+		"POST /api/v1/aliasaddresses/6 address=info%40example.com&enabled=false",
+		"POST /api/v1/aliasaddresses/2 address=info%40example.com&enabled=false",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v got %v", expected, calls)
+	}
+	if strings.Contains(err.Error(), "restoring") {
+		t.Errorf("Expected only the move error got '%s'", err)
+	}
+	calls = calls[:0]
+	alias, err = client.MoveAliasAddress(4, 6, 3)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if alias != nil {
+		t.Errorf("Expected %v got %v", nil, alias)
+	}
+	if !strings.Contains(err.Error(), "/api/v1/aliasaddresses/6") || !strings.Contains(err.Error(), "; restoring info@example.com on user 4: ") || !strings.Contains(err.Error(), "/api/v1/aliasaddresses/4") {
+		t.Errorf("Expected both errors got '%s'", err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("Expected %d got %d", 3, len(calls))
+	}
+}
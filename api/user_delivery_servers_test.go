@@ -8,7 +8,6 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
@@ -30,30 +29,10 @@ func TestGetUserDeliveryServersError(t *testing.T) {
 }
 
 func TestGetUserDeliveryServersOK(t *testing.T) {
-	data := `
-	{
-		"items": [{
-			"domain": {
-				"name": "example.com",
-				"id": 2
-			},
-			"protocol": 1,
-			"enabled": true,
-			"verification_only": false,
-			"id": 2,
-			"address": "192.168.1.150",
-			"port": 25
-		}],
-		"meta": {
-			"total": 1
-		}
-	}
-	`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	s, err := client.GetUserDeliveryServers(1, nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -95,26 +74,10 @@ func TestGetUserDeliveryServerError(t *testing.T) {
 func TestGetUserDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": 2
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	as, err := client.GetUserDeliveryServer(domainID, serverID)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err)
@@ -153,26 +116,10 @@ func TestCreateUserDeliveryServerError(t *testing.T) {
 func TestCreateUserDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": true,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, domainID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &UserDeliveryServerForm{
 		Address:          "192.168.1.151",
 		Protocol:         1,
@@ -235,26 +182,10 @@ func TestUpdateUserDeliveryServerError(t *testing.T) {
 func TestUpdateUserDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := fmt.Sprintf(`
-	{
-		"domain": {
-			"name": "example.com",
-			"id": %d
-		},
-		"protocol": 1,
-		"enabled": false,
-		"require_tls": false,
-		"verification_only": false,
-		"id": %d,
-		"address": "192.168.1.151",
-		"port": 25
-	}
-	`, domainID, serverID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &UserDeliveryServerForm{
 		ID:               serverID,
 		Address:          "192.168.1.151",
@@ -311,12 +242,10 @@ func TestDeleteUserDeliveryServerError(t *testing.T) {
 func TestDeleteUserDeliveryServerOK(t *testing.T) {
 	domainID := 2
 	serverID := 4
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusNoContent, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &UserDeliveryServerForm{
 		ID:               serverID,
 		Address:          "192.168.1.151",
@@ -0,0 +1,178 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// UserMapUser is the template variable holding the local part
+	UserMapUser = "user"
+	// UserMapDomain is the template variable holding the domain part
+	UserMapDomain = "domain"
+)
+
+// UserMapTemplate holds a parsed AuthServer.UserMapTemplate, the
+// template uses Python mapping format, %(user)s and %(domain)s are
+// replaced with the parts of the address and %% is a literal %
+type UserMapTemplate struct {
+	Template string
+	parts    []templatePart
+}
+
+type templatePart struct {
+	text     string
+	variable bool
+}
+
+// ParseUserMapTemplate parses and validates a user map template
+func ParseUserMapTemplate(template string) (t *UserMapTemplate, err error) {
+	var text strings.Builder
+
+	t = &UserMapTemplate{Template: template}
+
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' {
+			text.WriteByte(template[i])
+			continue
+		}
+
+		if i+1 == len(template) {
+			err = fmt.Errorf(templateError, i, "incomplete format")
+			t = nil
+			return
+		}
+
+		switch template[i+1] {
+		case '%':
+			text.WriteByte('%')
+			i++
+			continue
+		case '(':
+		default:
+			err = fmt.Errorf(templateError, i, "expected %(name)s or %%")
+			t = nil
+			return
+		}
+
+		end := strings.IndexByte(template[i+2:], ')')
+		if end == -1 {
+			err = fmt.Errorf(templateError, i, "missing )")
+			t = nil
+			return
+		}
+
+		name := template[i+2 : i+2+end]
+		if name != UserMapUser && name != UserMapDomain {
+			err = fmt.Errorf(templateError, i, fmt.Sprintf("unknown variable %q", name))
+			t = nil
+			return
+		}
+
+		next := i + 2 + end + 1
+		if next == len(template) || template[next] != 's' {
+			err = fmt.Errorf(templateError, i, "only the s conversion is supported")
+			t = nil
+			return
+		}
+
+		if text.Len() > 0 {
+			t.parts = append(t.parts, templatePart{text: text.String()})
+			text.Reset()
+		}
+		t.parts = append(t.parts, templatePart{text: name, variable: true})
+		i = next
+	}
+
+	if text.Len() > 0 {
+		t.parts = append(t.parts, templatePart{text: text.String()})
+	}
+
+	return
+}
+
+// Variables returns the variables used in the template in order of
+// first use
+func (t *UserMapTemplate) Variables() (names []string) {
+	seen := make(map[string]bool)
+
+	for _, p := range t.parts {
+		if p.variable && !seen[p.text] {
+			seen[p.text] = true
+			names = append(names, p.text)
+		}
+	}
+
+	return
+}
+
+// Render returns the login for an email address
+func (t *UserMapTemplate) Render(address string) (login string, err error) {
+	var user, domain string
+	var b strings.Builder
+
+	if user, domain, err = splitAddress(address); err != nil {
+		return
+	}
+
+	for _, p := range t.parts {
+		switch {
+		case !p.variable:
+			b.WriteString(p.text)
+		case p.text == UserMapUser:
+			b.WriteString(user)
+		case p.text == UserMapDomain:
+			b.WriteString(domain)
+		}
+	}
+
+	login = b.String()
+
+	return
+}
+
+// MapUsername returns the login Baruwa uses to authenticate an email
+// address against the server, the local part is used when
+// SplitAddress is set and the UserMapTemplate, if any, is applied
+func (s *AuthServer) MapUsername(address string) (login string, err error) {
+	var user string
+	var t *UserMapTemplate
+
+	if user, _, err = splitAddress(address); err != nil {
+		return
+	}
+
+	if s.UserMapTemplate != "" {
+		if t, err = ParseUserMapTemplate(s.UserMapTemplate); err != nil {
+			return
+		}
+		login, err = t.Render(address)
+		return
+	}
+
+	login = address
+	if s.SplitAddress {
+		login = user
+	}
+
+	return
+}
+
+func splitAddress(address string) (user, domain string, err error) {
+	i := strings.LastIndex(address, "@")
+	if i < 1 || i == len(address)-1 {
+		err = fmt.Errorf(addressParamError)
+		return
+	}
+
+	user, domain = address[:i], address[i+1:]
+
+	return
+}
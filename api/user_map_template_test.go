@@ -0,0 +1,149 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseUserMapTemplateError(t *testing.T) {
+	tests := []struct {
+		template string
+		position int
+		reason   string
+	}{
+		{"example_%", 8, "incomplete format"},
+		{"%s", 0, "expected %(name)s or %%"},
+		{"example_%(user", 8, "missing )"},
+		{"%(username)s", 0, `unknown variable "username"`},
+		{"%(user)d", 0, "only the s conversion is supported"},
+		{"%(user)", 0, "only the s conversion is supported"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseUserMapTemplate(tt.template)
+		if err == nil {
+			t.Fatalf("An error should be returned")
+		}
+		if tmpl != nil {
+			t.Errorf("Expected nil template for %s", tt.template)
+		}
+		expected := fmt.Sprintf(templateError, tt.position, tt.reason)
+		if err.Error() != expected {
+			t.Errorf("Expected '%s' got '%s'", expected, err)
+		}
+	}
+}
+
+func TestParseUserMapTemplateOK(t *testing.T) {
+	tests := []struct {
+		template  string
+		variables []string
+		login     string
+	}{
+		{"", nil, ""},
+		{"%(user)s", []string{"user"}, "andrew"},
+		{"example_%(user)s", []string{"user"}, "example_andrew"},
+		{"example_%%(user)s", nil, "example_%(user)s"},
+		{"%(domain)s\\%(user)s", []string{"domain", "user"}, "example.com\\andrew"},
+		{"%(user)s@%(domain)s.local", []string{"user", "domain"}, "andrew@example.com.local"},
+		{"%(user)s.%(user)s", []string{"user"}, "andrew.andrew"},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseUserMapTemplate(tt.template)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		vars := tmpl.Variables()
+		if strings.Join(vars, ",") != strings.Join(tt.variables, ",") {
+			t.Errorf("Expected %v got %v", tt.variables, vars)
+		}
+		login, err := tmpl.Render("andrew@example.com")
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if login != tt.login {
+			t.Errorf("Expected '%s' got '%s'", tt.login, login)
+		}
+	}
+}
+
+func TestUserMapTemplateRenderError(t *testing.T) {
+	tmpl, err := ParseUserMapTemplate("%(user)s")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, addr := range []string{"", "andrew", "@example.com", "andrew@"} {
+		_, err = tmpl.Render(addr)
+		if err == nil {
+			t.Fatalf("An error should be returned")
+		}
+		if err.Error() != addressParamError {
+			t.Errorf("Expected '%s' got '%s'", addressParamError, err)
+		}
+	}
+}
+
+func TestAuthServerMapUsername(t *testing.T) {
+	tests := []struct {
+		server AuthServer
+		login  string
+	}{
+		{AuthServer{}, "andrew@example.com"},
+		{AuthServer{SplitAddress: true}, "andrew"},
+		{AuthServer{SplitAddress: true, UserMapTemplate: "EXAMPLE\\%(user)s"}, "EXAMPLE\\andrew"},
+	}
+
+	for _, tt := range tests {
+		login, err := tt.server.MapUsername("andrew@example.com")
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if login != tt.login {
+			t.Errorf("Expected '%s' got '%s'", tt.login, login)
+		}
+	}
+
+	s := &AuthServer{UserMapTemplate: "%(uid)s"}
+	if _, err := s.MapUsername("andrew@example.com"); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestCreateAuthServerTemplateError(t *testing.T) {
+	server, client, err := getTestServerAndClient(http.StatusOK, ``)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	as := &AuthServer{
+		ID:              1,
+		Address:         "192.168.1.151",
+		Protocol:        2,
+		UserMapTemplate: "example_%(usr)s",
+	}
+	expected := fmt.Sprintf(templateError, 8, `unknown variable "usr"`)
+	err = client.CreateAuthServer(1, as)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+	err = client.UpdateAuthServer(1, as)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+}
@@ -37,12 +37,10 @@ func TestChangeUserPasswordError(t *testing.T) {
 }
 
 func TestChangeUserPasswordOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusAccepted, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	f := &PasswordForm{
 		Password1: "password",
 		Password2: "password",
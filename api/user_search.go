@@ -0,0 +1,203 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// UserSearchFilter holds user account search filters
+type UserSearchFilter struct {
+	DomainID        int       `url:"domain,omitempty"`
+	OrganizationID  int       `url:"organization,omitempty"`
+	AccountType     int       `url:"account_type,omitempty"`
+	Active          *bool     `url:"active,omitempty"`
+	LastLoginAfter  time.Time `url:"last_login_after,omitempty"`
+	LastLoginBefore time.Time `url:"last_login_before,omitempty"`
+}
+
+// GetUserByUsername returns a user account
+// When the server does not provide the lookup endpoint the
+// user accounts are scanned client side.
+func (c *Client) GetUserByUsername(username string) (user *User, err error) {
+	if username == "" {
+		err = fmt.Errorf(usernameParamError)
+		return
+	}
+
+	user = &User{}
+
+	if err = c.get(fmt.Sprintf("users/byname/%s", url.PathEscape(username)), nil, user); err != nil && isMissingEndpoint(err) {
+		user, err = c.findUser(func(u *User) bool {
+			return strings.EqualFold(u.Username, username)
+		})
+	}
+
+	return
+}
+
+// GetUserByEmail returns a user account
+// When the server does not provide the lookup endpoint the
+// user accounts are scanned client side.
+func (c *Client) GetUserByEmail(email string) (user *User, err error) {
+	if email == "" {
+		err = fmt.Errorf(emailParamError)
+		return
+	}
+
+	user = &User{}
+
+	if err = c.get(fmt.Sprintf("users/byemail/%s", url.PathEscape(email)), nil, user); err != nil && isMissingEndpoint(err) {
+		user, err = c.findUser(func(u *User) bool {
+			return strings.EqualFold(u.Email, email)
+		})
+	}
+
+	return
+}
+
+// SearchUsers returns a UserList object
+// This contains a paginated list of the user accounts matching
+// the filter. When the server does not provide the search endpoint
+// the user accounts are scanned client side and all matches are
+// returned in a single page.
+func (c *Client) SearchUsers(filter *UserSearchFilter, opts *ListOptions) (l *UserList, err error) {
+	var v url.Values
+	var p string
+
+	if filter == nil {
+		filter = &UserSearchFilter{}
+	}
+
+	p = "users/search"
+	if v, _ = query.Values(filter); len(v) > 0 {
+		p = fmt.Sprintf("%s?%s", p, v.Encode())
+	}
+
+	l = &UserList{}
+
+	if err = c.get(p, opts, l); err != nil && isMissingEndpoint(err) {
+		l = &UserList{}
+		err = c.eachUser(func(u *User) bool {
+			if filter.Match(u) {
+				l.Items = append(l.Items, *u)
+			}
+			return true
+		})
+		l.Meta.Total = len(l.Items)
+	}
+
+	return
+}
+
+// eachUser pages through all user accounts calling fn for each
+// account until fn returns false
+func (c *Client) eachUser(fn func(u *User) bool) error {
+	return EachPage(func(opts *ListOptions) (links Links, done bool, err error) {
+		var l *UserList
+		if l, err = c.GetUsers(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if !fn(&l.Items[i]) {
+				done = true
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	})
+}
+
+// Match returns true if the user account matches the filter
+func (f *UserSearchFilter) Match(u *User) bool {
+	if f.DomainID > 0 && !userInDomain(u, f.DomainID) {
+		return false
+	}
+
+	if f.OrganizationID > 0 && !userInOrganization(u, f.OrganizationID) {
+		return false
+	}
+
+	if f.AccountType > 0 && u.AccountType != f.AccountType {
+		return false
+	}
+
+	if f.Active != nil && u.Enabled != *f.Active {
+		return false
+	}
+
+	if !f.LastLoginAfter.IsZero() && !u.LastLogin.After(f.LastLoginAfter) {
+		return false
+	}
+
+	if !f.LastLoginBefore.IsZero() && (u.LastLogin.IsZero() || !u.LastLogin.Before(f.LastLoginBefore)) {
+		return false
+	}
+
+	return true
+}
+
+func (c *Client) findUser(fn func(u *User) bool) (user *User, err error) {
+	err = c.eachUser(func(u *User) bool {
+		if fn(u) {
+			user = u
+			return false
+		}
+		return true
+	})
+
+	if err == nil && user == nil {
+		err = fmt.Errorf(userNotFoundError)
+	}
+
+	return
+}
+
+func userInDomain(u *User, domainID int) bool {
+	for _, d := range u.Domains {
+		if d.ID == domainID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func userInOrganization(u *User, organizationID int) bool {
+	for _, o := range u.Organizations {
+		if o.ID == organizationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMissingEndpoint returns true if the server does not provide the
+// endpoint. A lookup endpoint reports an unknown account with a JSON
+// error body, a route the server does not have returns the default
+// not found page.
+func isMissingEndpoint(err error) bool {
+	if e, ok := err.(*ErrorResponse); ok {
+		switch e.Code {
+		case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+			return true
+		case http.StatusNotFound:
+			return e.Response == nil || !strings.Contains(e.Response.Header.Get("Content-Type"), "json")
+		}
+	}
+
+	return false
+}
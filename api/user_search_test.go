@@ -0,0 +1,238 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getTestUsersServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "" {
+			fmt.Fprintf(w, `{"items": [
+				{"id": 1, "username": "admin", "email": "admin@example.com", "account_type": 1, "active": true,
+				 "last_login": "2019:08:01:10:00:00"},
+				{"id": 2, "username": "andrew", "email": "andrew@example.com", "account_type": 3, "active": true,
+				 "last_login": "2019:08:20:10:00:00", "domains": [{"id": 2, "name": "example.com"}],
+				 "organizations": [{"id": 1, "name": "My Org"}]}
+			], "links": {"pages": {"next": "http://%s/api/v1/users?page=2"}}, "meta": {"total": 3}}`, r.Host)
+			return
+		}
+		fmt.Fprint(w, `{"items": [
+			{"id": 3, "username": "bob", "email": "Bob@example.net", "account_type": 3, "active": false,
+			 "domains": [{"id": 4, "name": "example.net"}]}
+		], "links": {"pages": {}}, "meta": {"total": 3}}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestGetUserByUsernameError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	u, err := client.GetUserByUsername("")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != usernameParamError {
+		t.Errorf("Expected '%s' got '%s'", usernameParamError, err)
+	}
+	if u != nil {
+		t.Errorf("Expected %v got %v", nil, u)
+	}
+}
+
+func TestGetUserByUsernameOK(t *testing.T) {
+	userID := 2
+	client, err := getTestFixtureClient(t)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	u, err := client.GetUserByUsername("andrew")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if u.ID != userID {
+		t.Errorf("Expected %d got %d", userID, u.ID)
+	}
+}
+
+func TestGetUserByUsernameFallback(t *testing.T) {
+	server := getTestUsersServer()
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	u, err := client.GetUserByUsername("BOB")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if u.ID != 3 {
+		t.Errorf("Expected %d got %d", 3, u.ID)
+	}
+	u, err = client.GetUserByUsername("nobody")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != userNotFoundError {
+		t.Errorf("Expected '%s' got '%s'", userNotFoundError, err)
+	}
+	if u != nil {
+		t.Errorf("Expected %v got %v", nil, u)
+	}
+}
+
+func TestGetUserByUsernameNotFound(t *testing.T) {
+	var lists int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/users" {
+			lists++
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "Not Found", "code": 404}`)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	_, err = client.GetUserByUsername("nobody")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if e, ok := err.(*ErrorResponse); !ok || e.Code != http.StatusNotFound {
+		t.Errorf("Expected a 404 got '%s'", err)
+	}
+	if _, err = client.GetUserByEmail("nobody@example.com"); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if lists != 0 {
+		t.Errorf("Expected %d got %d", 0, lists)
+	}
+}
+
+func TestGetUserByEmailError(t *testing.T) {
+	data := ``
+	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	u, err := client.GetUserByEmail("")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != emailParamError {
+		t.Errorf("Expected '%s' got '%s'", emailParamError, err)
+	}
+	if u != nil {
+		t.Errorf("Expected %v got %v", nil, u)
+	}
+	server, client, err = getTestServerAndClient(http.StatusForbidden, data)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	defer server.Close()
+	_, err = client.GetUserByEmail("andrew@example.com")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestGetUserByEmailFallback(t *testing.T) {
+	server := getTestUsersServer()
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	u, err := client.GetUserByEmail("andrew@example.com")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if u.ID != 2 {
+		t.Errorf("Expected %d got %d", 2, u.ID)
+	}
+}
+
+func TestSearchUsersOK(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"items": [{"id": 2, "username": "andrew"}], "links": {"pages": {}}, "meta": {"total": 1}}`)
+	}))
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	active := true
+	f := &UserSearchFilter{
+		DomainID: 2,
+		Active:   &active,
+	}
+	l, err := client.SearchUsers(f, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(l.Items) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(l.Items))
+	}
+	if query != "active=true&domain=2" {
+		t.Errorf("Expected '%s' got '%s'", "active=true&domain=2", query)
+	}
+}
+
+func TestSearchUsersFallback(t *testing.T) {
+	server := getTestUsersServer()
+	defer server.Close()
+	client, err := getTestClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned")
+	}
+	active := true
+	tests := []struct {
+		filter   *UserSearchFilter
+		expected int
+	}{
+		{nil, 3},
+		{&UserSearchFilter{AccountType: 3}, 2},
+		{&UserSearchFilter{Active: &active}, 2},
+		{&UserSearchFilter{DomainID: 4}, 1},
+		{&UserSearchFilter{OrganizationID: 1}, 1},
+		{&UserSearchFilter{LastLoginAfter: time.Date(2019, 8, 10, 0, 0, 0, 0, time.UTC)}, 1},
+		{&UserSearchFilter{LastLoginBefore: time.Date(2019, 8, 10, 0, 0, 0, 0, time.UTC)}, 1},
+	}
+	for _, tt := range tests {
+		l, err := client.SearchUsers(tt.filter, nil)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if len(l.Items) != tt.expected {
+			t.Errorf("Expected %d got %d", tt.expected, len(l.Items))
+		}
+		if l.Meta.Total != tt.expected {
+			t.Errorf("Expected %d got %d", tt.expected, l.Meta.Total)
+		}
+	}
+}
@@ -8,18 +8,16 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"testing"
 )
 
 func Test_User_NotFoundError(t *testing.T) {
 	nf := "Not Found"
-	server, client, err := getTestServerAndClient(http.StatusNotFound, fmt.Sprintf(`{"code":%d, "error":"%s"}`, http.StatusNotFound, nf))
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetUser(5)
 	if err == nil {
 		t.Fatalf("An error should be returned")
@@ -41,11 +39,10 @@ func Test_User_NotFoundError(t *testing.T) {
 }
 
 func Test_User_ServerError(t *testing.T) {
-	server, client, err := getTestServerAndClient(http.StatusInternalServerError, ``)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetUser(5)
 	if err == nil {
 		t.Fatalf("An error should be returned: %v", u)
@@ -64,11 +61,10 @@ func Test_User_ServerError(t *testing.T) {
 }
 
 func Test_User_UnAuthError(t *testing.T) {
-	server, client, err := getTestServerAndClient(http.StatusUnauthorized, ``)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetUser(5)
 	if err == nil {
 		t.Fatalf("An error should be returned: %v", u)
@@ -105,35 +101,10 @@ func Test_User_InvalidID(t *testing.T) {
 }
 
 func Test_GetUserOK(t *testing.T) {
-	data := `{
-	"username": "rowdyrough",
-	"send_report": false,
-	"account_type": 3,
-	"addresses": [],
-	"firstname": "Rowdy",
-	"organizations": [],
-	"lastname": "Rough",
-	"spam_checks": false,
-	"email": "rowdyrough@example.com",
-	"low_score": 0.0,
-	"high_score": 0.0,
-	"created_on": "2014:10:07:06:35:48",
-	"last_login": "2014:10:11:22:38:11",
-	"active": true,
-	"timezone": "Africa/Johannesburg",
-	"local": true,
-	"id": 2,
-	"domains": [{
-		"name": "example.com",
-		"id": 4
-	}]
-	}
-`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetUser(2)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -141,71 +112,16 @@ func Test_GetUserOK(t *testing.T) {
 	if u.ID != 2 {
 		t.Errorf("Expected %d got %d", 2, u.ID)
 	}
+	if len(u.Addresses) != 1 || u.Addresses[0].ID != 3 {
+		t.Errorf("Expected %d got %v", 3, u.Addresses)
+	}
 }
 
 func Test_GetUsersOK(t *testing.T) {
-	data := `{
-		"items": [{
-			"username": "fuzzy@example.com",
-			"send_report": false,
-			"account_type": 3,
-			"addresses": [],
-			"firstname": "Fuzzy",
-			"organizations": [],
-			"lastname": "Lumpkins",
-			"spam_checks": false,
-			"email": "fuzzy@example.com",
-			"low_score": 2.0,
-			"high_score": 12.0,
-			"created_on": "2014:09:20:15:14:30",
-			"last_login": "2014:10:03:08:54:28",
-			"active": true,
-			"timezone": "Africa/Abidjan",
-			"local": true,
-			"id": 4,
-			"domains": [{
-				"name": "example.com",
-				"id": 4
-			}]
-		}, {
-			"username": "rowdyrough",
-			"send_report": false,
-			"account_type": 3,
-			"addresses": [],
-			"firstname": "Rowdy",
-			"organizations": [],
-			"lastname": "Rough",
-			"spam_checks": false,
-			"email": "rowdyrough@example.com",
-			"low_score": 0.0,
-			"high_score": 0.0,
-			"created_on": "2014:10:07:06:35:48",
-			"last_login": "2014:10:11:22:38:11",
-			"active": true,
-			"timezone": "Africa/Johannesburg",
-			"local": true,
-			"id": 5,
-			"domains": [{
-				"name": "example.com",
-				"id": 4
-			}]
-		}],
-		"meta": {
-			"total": 2
-		},
-		"links": {
-			"pages": {
-				"last": "http://baruwa.example.com/api/v1/users?page=2",
-				"next": "http://baruwa.example.com/api/v1/users?page=2"
-			}
-		}
-	}
-`
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	u, err := client.GetUsers(nil)
 	if err != nil {
 		t.Fatalf("An error should not be returned: %s", err.Error())
@@ -244,35 +160,10 @@ func Test_CreateUserError(t *testing.T) {
 
 func Test_CreateUserOK(t *testing.T) {
 	userID := 2
-	data := fmt.Sprintf(`{
-		"username": "rowdyrough",
-		"send_report": false,
-		"account_type": 3,
-		"addresses": [],
-		"firstname": "Rowdy",
-		"organizations": [],
-		"lastname": "Rough",
-		"spam_checks": false,
-		"email": "rowdyrough@example.com",
-		"low_score": 0.0,
-		"high_score": 0.0,
-		"created_on": "2014:10:07:06:35:48",
-		"last_login": "2014:10:11:22:38:11",
-		"active": true,
-		"timezone": "Africa/Johannesburg",
-		"local": true,
-		"id": %d,
-		"domains": [{
-			"name": "example.com",
-			"id": 4
-		}]
-		}
-	`, userID)
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	n := "andrew"
 	e := "andrew@example.com"
 	tz := "Africa/Johannesburg"
@@ -322,12 +213,10 @@ func Test_UpdateUserError(t *testing.T) {
 }
 
 func Test_UpdateUserOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	id := 2
 	n := "andrew"
 	e := "andrew@example.com"
@@ -361,12 +250,10 @@ func Test_DeleteUserError(t *testing.T) {
 }
 
 func Test_DeleteUserOK(t *testing.T) {
-	data := ``
-	server, client, err := getTestServerAndClient(http.StatusOK, data)
+	client, err := getTestFixtureClient(t)
 	if err != nil {
-		t.Fatalf("An error should not be returned")
+		t.Fatalf("An error should not be returned: %s", err)
 	}
-	defer server.Close()
 	err = client.DeleteUser(2)
 	if err != nil {
 		t.Fatalf("An error not should be returned: %s", err)
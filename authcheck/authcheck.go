@@ -0,0 +1,230 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package authcheck Connectivity tests for domain authentication servers
+
+A Tester maps a test email address to a login the same way Baruwa does,
+using the SplitAddress and UserMapTemplate settings of the server, then
+authenticates against the POP3, IMAP, SMTP, LDAP or RADIUS server and
+reports the stage at which the test failed.
+*/
+package authcheck
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	serverParamError   = "The server param is required"
+	protocolError      = "Unsupported protocol %d"
+	ldapSettingsError  = "LDAP settings are required for LDAP servers"
+	radiusSettingError = "RADIUS settings are required for RADIUS servers"
+)
+
+// Stage is the step of a test
+type Stage string
+
+const (
+	// StageMap mapping the address to a login
+	StageMap Stage = "map"
+	// StageConnect connecting and reading the greeting
+	StageConnect Stage = "connect"
+	// StageTLS negotiating TLS
+	StageTLS Stage = "tls"
+	// StageBind binding with the LDAP service account
+	StageBind Stage = "bind"
+	// StageSearch searching for the LDAP user entry
+	StageSearch Stage = "search"
+	// StageAuth authenticating the user
+	StageAuth Stage = "auth"
+)
+
+var protocolNames = map[int]string{
+	api.AuthProtocolPOP3:   "pop3",
+	api.AuthProtocolIMAP:   "imap",
+	api.AuthProtocolSMTP:   "smtp",
+	api.AuthProtocolRADIUS: "radius",
+	api.AuthProtocolLDAP:   "ldap",
+}
+
+var defaultPorts = map[int]int{
+	api.AuthProtocolPOP3:   110,
+	api.AuthProtocolIMAP:   143,
+	api.AuthProtocolSMTP:   25,
+	api.AuthProtocolRADIUS: 1812,
+	api.AuthProtocolLDAP:   389,
+}
+
+// implicit TLS ports, as used by Baruwa
+var tlsPorts = map[int]bool{
+	993: true,
+	995: true,
+	465: true,
+	636: true,
+}
+
+// Result holds the outcome of a test
+type Result struct {
+	Protocol string        `json:"protocol"`
+	Server   string        `json:"server"`
+	Username string        `json:"username"`
+	TLS      bool          `json:"tls"`
+	Latency  time.Duration `json:"latency"`
+	Stage    Stage         `json:"stage,omitempty"`
+	Detail   string        `json:"detail,omitempty"`
+	Err      error         `json:"-"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// OK returns true if the test authenticated successfully
+func (r *Result) OK() bool {
+	return r.Err == nil
+}
+
+// Tester tests authentication servers
+type Tester struct {
+	// Timeout for each test, defaults to 10 seconds
+	Timeout time.Duration
+	// TLSConfig used for STARTTLS and implicit TLS, the ServerName
+	// defaults to the server address
+	TLSConfig *tls.Config
+	// LDAPSettings of the server, required for LDAP
+	LDAPSettings *api.LDAPSettings
+	// RadiusSettings of the server, required for RADIUS
+	RadiusSettings *api.RadiusSettings
+}
+
+// MapUsername returns the login used for an email address, see
+// api.AuthServer.MapUsername
+func MapUsername(server *api.AuthServer, address string) (username string, err error) {
+	if server == nil {
+		err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	username, err = server.MapUsername(address)
+
+	return
+}
+
+// Test authenticates the address and password against the server
+func (t *Tester) Test(server *api.AuthServer, address, password string) (r Result) {
+	var err error
+
+	start := time.Now()
+	defer func() {
+		r.Latency = time.Since(start)
+		if r.Err != nil {
+			r.Error = r.Err.Error()
+		}
+	}()
+
+	r.Stage = StageMap
+	if server == nil {
+		r.Err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	r.Protocol = protocolNames[server.Protocol]
+	r.Server = net.JoinHostPort(server.Address, strconv.Itoa(t.port(server)))
+
+	if r.Username, err = MapUsername(server, address); err != nil {
+		r.Err = err
+		return
+	}
+
+	switch server.Protocol {
+	case api.AuthProtocolPOP3:
+		t.pop3(&r, server, password)
+	case api.AuthProtocolIMAP:
+		t.imap(&r, server, password)
+	case api.AuthProtocolSMTP:
+		t.smtp(&r, server, password)
+	case api.AuthProtocolLDAP:
+		if t.LDAPSettings == nil {
+			r.Err = fmt.Errorf(ldapSettingsError)
+			return
+		}
+		t.ldap(&r, server, password)
+	case api.AuthProtocolRADIUS:
+		if t.RadiusSettings == nil {
+			r.Err = fmt.Errorf(radiusSettingError)
+			return
+		}
+		t.radius(&r, server, password)
+	default:
+		r.Err = fmt.Errorf(protocolError, server.Protocol)
+		return
+	}
+
+	if r.Err == nil {
+		r.Stage = ""
+	}
+
+	return
+}
+
+func (t *Tester) timeout() time.Duration {
+	if t.Timeout <= 0 {
+		return defaultTimeout
+	}
+
+	return t.Timeout
+}
+
+func (t *Tester) port(server *api.AuthServer) int {
+	if server.Port > 0 {
+		return server.Port
+	}
+
+	return defaultPorts[server.Protocol]
+}
+
+func (t *Tester) tlsConfig(server *api.AuthServer) (config *tls.Config) {
+	if t.TLSConfig != nil {
+		config = t.TLSConfig.Clone()
+	} else {
+		config = &tls.Config{}
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = server.Address
+	}
+
+	return
+}
+
+// dial connects to the server, using TLS on the implicit TLS ports
+func (t *Tester) dial(r *Result, server *api.AuthServer) (conn net.Conn, err error) {
+	r.Stage = StageConnect
+	if conn, err = net.DialTimeout("tcp", r.Server, t.timeout()); err != nil {
+		return
+	}
+	conn.SetDeadline(time.Now().Add(t.timeout()))
+
+	if tlsPorts[t.port(server)] {
+		r.Stage = StageTLS
+		tc := tls.Client(conn, t.tlsConfig(server))
+		if err = tc.Handshake(); err != nil {
+			conn.Close()
+			return
+		}
+		conn = tc
+		r.TLS = true
+		r.Stage = StageConnect
+	}
+
+	return
+}
@@ -0,0 +1,288 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// lineServer is a stand-in for line based mail protocols, the handler
+// returns the reply lines for each command
+type lineServer struct {
+	ln       net.Listener
+	greeting string
+	handler  func(line string) []string
+}
+
+func newLineServer(t *testing.T, greeting string, handler func(line string) []string) (s *lineServer) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	s = &lineServer{ln: ln, greeting: greeting, handler: handler}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	return
+}
+
+func (s *lineServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	fmt.Fprintf(conn, "%s\r\n", s.greeting)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		for _, reply := range s.handler(strings.TrimRight(line, "\r\n")) {
+			fmt.Fprintf(conn, "%s\r\n", reply)
+		}
+	}
+}
+
+func (s *lineServer) server(protocol int) *api.AuthServer {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return &api.AuthServer{
+		ID:       1,
+		Address:  host,
+		Port:     p,
+		Protocol: protocol,
+		Enabled:  true,
+	}
+}
+
+func pop3Handler(line string) []string {
+	switch line {
+	case "USER andrew", "USER andrew@example.com":
+		return []string{"+OK"}
+	case "PASS secret":
+		return []string{"+OK logged in"}
+	case "QUIT":
+		return []string{"+OK bye"}
+	}
+	return []string{"-ERR authentication failed"}
+}
+
+func imapHandler(line string) []string {
+	switch line {
+	case `a1 LOGIN "andrew" "secret"`:
+		return []string{"* CAPABILITY IMAP4rev1", "a1 OK LOGIN completed"}
+	case "a2 LOGOUT":
+		return []string{"* BYE", "a2 OK LOGOUT completed"}
+	}
+	return []string{"a1 NO [AUTHENTICATIONFAILED] Authentication failed"}
+}
+
+func smtpHandler(line string) []string {
+	plain := base64.StdEncoding.EncodeToString([]byte("\x00andrew\x00secret"))
+	switch {
+	case strings.HasPrefix(line, "EHLO"):
+		return []string{"250-stub.example.com", "250-AUTH PLAIN LOGIN", "250 8BITMIME"}
+	case line == "AUTH PLAIN "+plain:
+		return []string{"235 2.7.0 Authentication successful"}
+	case strings.HasPrefix(line, "AUTH"):
+		return []string{"535 5.7.8 Authentication credentials invalid"}
+	case line == "QUIT":
+		return []string{"221 2.0.0 Bye"}
+	}
+	return []string{"502 5.5.2 Error: command not recognized"}
+}
+
+func TestMapUsername(t *testing.T) {
+	tests := []struct {
+		server   api.AuthServer
+		address  string
+		expected string
+	}{
+		{api.AuthServer{}, "andrew@example.com", "andrew@example.com"},
+		{api.AuthServer{SplitAddress: true}, "andrew@example.com", "andrew"},
+		{api.AuthServer{UserMapTemplate: "%(user)s"}, "andrew@example.com", "andrew"},
+		{api.AuthServer{UserMapTemplate: "EXAMPLE\\%(user)s"}, "andrew@example.com", "EXAMPLE\\andrew"},
+		{api.AuthServer{UserMapTemplate: "%(user)s@%(domain)s.local"}, "andrew@example.com", "andrew@example.com.local"},
+		{api.AuthServer{SplitAddress: true, UserMapTemplate: "%(domain)s\\%(user)s"}, "andrew@example.com", "example.com\\andrew"},
+	}
+
+	for _, tt := range tests {
+		u, err := MapUsername(&tt.server, tt.address)
+		if err != nil {
+			t.Fatalf("Expected nil got %s", err)
+		}
+		if u != tt.expected {
+			t.Errorf("Expected %s got %s", tt.expected, u)
+		}
+	}
+
+	if _, err := MapUsername(&api.AuthServer{}, "andrew"); err == nil {
+		t.Fatalf("An error should be returned")
+	} else if err.Error() != "The address param should be an email address" {
+		t.Errorf("Expected an address error got %s", err)
+	}
+
+	if _, err := MapUsername(nil, "andrew@example.com"); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestTestError(t *testing.T) {
+	tester := &Tester{Timeout: time.Second}
+
+	r := tester.Test(nil, "andrew@example.com", "secret")
+	if r.OK() || r.Stage != StageMap {
+		t.Errorf("Expected a map error got %s", r.Stage)
+	}
+
+	r = tester.Test(&api.AuthServer{Address: "127.0.0.1", Protocol: 9}, "andrew@example.com", "secret")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Err.Error() != fmt.Sprintf(protocolError, 9) {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(protocolError, 9), r.Err)
+	}
+
+	r = tester.Test(&api.AuthServer{Address: "127.0.0.1", Protocol: api.AuthProtocolLDAP}, "andrew@example.com", "secret")
+	if r.OK() || r.Err.Error() != ldapSettingsError {
+		t.Errorf("Expected '%s' got '%v'", ldapSettingsError, r.Err)
+	}
+
+	r = tester.Test(&api.AuthServer{Address: "127.0.0.1", Protocol: api.AuthProtocolRADIUS}, "andrew@example.com", "secret")
+	if r.OK() || r.Err.Error() != radiusSettingError {
+		t.Errorf("Expected '%s' got '%v'", radiusSettingError, r.Err)
+	}
+}
+
+func TestTestConnectError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	tester := &Tester{Timeout: time.Second}
+	for _, p := range []int{api.AuthProtocolPOP3, api.AuthProtocolIMAP, api.AuthProtocolSMTP} {
+		r := tester.Test(&api.AuthServer{Address: "127.0.0.1", Port: port, Protocol: p}, "andrew@example.com", "secret")
+		if r.OK() {
+			t.Fatalf("An error should be returned")
+		}
+		if r.Stage != StageConnect {
+			t.Errorf("Expected %s got %s", StageConnect, r.Stage)
+		}
+	}
+}
+
+func TestTestPOP3(t *testing.T) {
+	s := newLineServer(t, "+OK POP3 ready", pop3Handler)
+	defer s.ln.Close()
+
+	tester := &Tester{Timeout: time.Second}
+	server := s.server(api.AuthProtocolPOP3)
+	server.SplitAddress = true
+
+	r := tester.Test(server, "andrew@example.com", "secret")
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+	if r.Username != "andrew" {
+		t.Errorf("Expected %s got %s", "andrew", r.Username)
+	}
+	if r.Protocol != "pop3" {
+		t.Errorf("Expected %s got %s", "pop3", r.Protocol)
+	}
+
+	r = tester.Test(server, "andrew@example.com", "wrong")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageAuth {
+		t.Errorf("Expected %s got %s", StageAuth, r.Stage)
+	}
+	if r.Err.Error() != "-ERR authentication failed" {
+		t.Errorf("Expected '%s' got '%s'", "-ERR authentication failed", r.Err)
+	}
+	if r.Error != "-ERR authentication failed" {
+		t.Errorf("Expected '%s' got '%s'", "-ERR authentication failed", r.Error)
+	}
+}
+
+func TestTestIMAP(t *testing.T) {
+	s := newLineServer(t, "* OK IMAP4rev1 ready", imapHandler)
+	defer s.ln.Close()
+
+	tester := &Tester{Timeout: time.Second}
+	server := s.server(api.AuthProtocolIMAP)
+	server.UserMapTemplate = "%(user)s"
+
+	r := tester.Test(server, "andrew@example.com", "secret")
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+
+	r = tester.Test(server, "andrew@example.com", "wrong")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageAuth {
+		t.Errorf("Expected %s got %s", StageAuth, r.Stage)
+	}
+}
+
+func TestTestIMAPGreetingError(t *testing.T) {
+	s := newLineServer(t, "* BYE too many connections", imapHandler)
+	defer s.ln.Close()
+
+	tester := &Tester{Timeout: time.Second}
+	r := tester.Test(s.server(api.AuthProtocolIMAP), "andrew@example.com", "secret")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageConnect {
+		t.Errorf("Expected %s got %s", StageConnect, r.Stage)
+	}
+}
+
+func TestTestSMTP(t *testing.T) {
+	s := newLineServer(t, "220 stub.example.com ESMTP", smtpHandler)
+	defer s.ln.Close()
+
+	tester := &Tester{Timeout: time.Second}
+	server := s.server(api.AuthProtocolSMTP)
+	server.SplitAddress = true
+
+	r := tester.Test(server, "andrew@example.com", "secret")
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+
+	r = tester.Test(server, "andrew@example.com", "wrong")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageAuth {
+		t.Errorf("Expected %s got %s", StageAuth, r.Stage)
+	}
+}
@@ -0,0 +1,191 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	defaultNameAttr = "uid"
+	noEntryError    = "no directory entry found for %s"
+	multiEntryError = "%d directory entries found for %s"
+)
+
+func (t *Tester) ldap(r *Result, server *api.AuthServer, password string) {
+	var err error
+	var dn string
+	var conn *ldap.Conn
+
+	s := t.LDAPSettings
+	u, implicitTLS := LDAPURL(server)
+	r.TLS = implicitTLS
+
+	r.Stage = StageConnect
+	if conn, err = ldap.DialURL(
+		u,
+		ldap.DialWithDialer(&net.Dialer{Timeout: t.timeout()}),
+		ldap.DialWithTLSConfig(t.tlsConfig(server)),
+	); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+	conn.SetTimeout(t.timeout())
+
+	if s.UseTLS && !r.TLS {
+		r.Stage = StageTLS
+		if err = conn.StartTLS(t.tlsConfig(server)); err != nil {
+			r.Err = err
+			return
+		}
+		r.TLS = true
+	}
+
+	if s.UseSearch {
+		if s.BindDN != "" {
+			r.Stage = StageBind
+			if err = conn.Bind(s.BindDN, s.BindPw); err != nil {
+				r.Err = err
+				return
+			}
+		}
+		r.Stage = StageSearch
+		if dn, err = searchDN(conn, s, r.Username); err != nil {
+			r.Err = err
+			return
+		}
+	} else {
+		dn = UserDN(s, r.Username)
+	}
+	r.Detail = dn
+
+	r.Stage = StageAuth
+	if err = conn.Bind(dn, password); err != nil {
+		r.Err = err
+		return
+	}
+}
+
+// UserDN returns the DN Baruwa binds as when search is disabled, a
+// username that is already a DN is used as is
+func UserDN(s *api.LDAPSettings, username string) string {
+	if _, err := ldap.ParseDN(username); err == nil && strings.Contains(username, "=") {
+		return username
+	}
+
+	attr := s.NameAttribute
+	if attr == "" {
+		attr = defaultNameAttr
+	}
+
+	return fmt.Sprintf("%s=%s,%s", attr, escapeDN(username), s.Basedn)
+}
+
+// SearchFilter returns the filter used to find the user entry, the
+// %(user)s and %s placeholders in the settings filter are replaced
+// with the escaped username
+func SearchFilter(s *api.LDAPSettings, username string) string {
+	escaped := ldap.EscapeFilter(username)
+
+	if s.SearchFilter == "" {
+		attr := s.NameAttribute
+		if attr == "" {
+			attr = defaultNameAttr
+		}
+		return fmt.Sprintf("(%s=%s)", attr, escaped)
+	}
+
+	return strings.NewReplacer(
+		"%(user)s", escaped,
+		"%s", escaped,
+	).Replace(s.SearchFilter)
+}
+
+func searchDN(conn *ldap.Conn, s *api.LDAPSettings, username string) (dn string, err error) {
+	var result *ldap.SearchResult
+
+	req := ldap.NewSearchRequest(
+		s.Basedn,
+		SearchScope(s.SearchScope),
+		ldap.NeverDerefAliases,
+		2, 0, false,
+		SearchFilter(s, username),
+		[]string{"dn"},
+		nil,
+	)
+
+	if result, err = conn.Search(req); err != nil {
+		return
+	}
+
+	switch len(result.Entries) {
+	case 0:
+		err = fmt.Errorf(noEntryError, username)
+	case 1:
+		dn = result.Entries[0].DN
+	default:
+		err = fmt.Errorf(multiEntryError, len(result.Entries), username)
+	}
+
+	return
+}
+
+// escapeDN escapes an attribute value as described in RFC 4514
+func escapeDN(value string) string {
+	var b strings.Builder
+
+	for i, c := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, c),
+			c == '#' && i == 0,
+			c == ' ' && (i == 0 || i == len(value)-1):
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// LDAPURL returns the URL of an LDAP server, ldaps is used when the
+// server listens on the implicit TLS port
+func LDAPURL(server *api.AuthServer) (u string, implicitTLS bool) {
+	port := server.Port
+	if port <= 0 {
+		port = defaultPorts[api.AuthProtocolLDAP]
+	}
+
+	scheme := "ldap"
+	if tlsPorts[port] {
+		scheme = "ldaps"
+		implicitTLS = true
+	}
+
+	u = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(server.Address, strconv.Itoa(port)))
+
+	return
+}
+
+// SearchScope returns the ldap scope of an LDAP settings search scope
+func SearchScope(scope string) int {
+	switch scope {
+	case "base":
+		return ldap.ScopeBaseObject
+	case "onelevel":
+		return ldap.ScopeSingleLevel
+	}
+
+	return ldap.ScopeWholeSubtree
+}
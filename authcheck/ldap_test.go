@@ -0,0 +1,113 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestUserDN(t *testing.T) {
+	s := &api.LDAPSettings{
+		Basedn:        "ou=users,dc=example,dc=com",
+		NameAttribute: "cn",
+	}
+
+	tests := []struct {
+		username string
+		expected string
+	}{
+		{"andrew", "cn=andrew,ou=users,dc=example,dc=com"},
+		{"Kissa, Andrew", "cn=Kissa\\, Andrew,ou=users,dc=example,dc=com"},
+		{"uid=andrew,dc=example,dc=com", "uid=andrew,dc=example,dc=com"},
+	}
+
+	for _, tt := range tests {
+		if dn := UserDN(s, tt.username); dn != tt.expected {
+			t.Errorf("Expected %s got %s", tt.expected, dn)
+		}
+	}
+
+	s.NameAttribute = ""
+	if dn := UserDN(s, "andrew"); dn != "uid=andrew,ou=users,dc=example,dc=com" {
+		t.Errorf("Expected %s got %s", "uid=andrew,ou=users,dc=example,dc=com", dn)
+	}
+}
+
+func TestSearchFilter(t *testing.T) {
+	tests := []struct {
+		settings api.LDAPSettings
+		expected string
+	}{
+		{api.LDAPSettings{}, "(uid=andrew\\2a)"},
+		{api.LDAPSettings{NameAttribute: "sAMAccountName"}, "(sAMAccountName=andrew\\2a)"},
+		{api.LDAPSettings{SearchFilter: "(&(objectClass=person)(uid=%(user)s))"}, "(&(objectClass=person)(uid=andrew\\2a))"},
+		{api.LDAPSettings{SearchFilter: "(mail=%s)"}, "(mail=andrew\\2a)"},
+	}
+
+	for _, tt := range tests {
+		if f := SearchFilter(&tt.settings, "andrew*"); f != tt.expected {
+			t.Errorf("Expected %s got %s", tt.expected, f)
+		}
+	}
+}
+
+func TestLDAPURL(t *testing.T) {
+	tests := []struct {
+		server   api.AuthServer
+		expected string
+		tls      bool
+	}{
+		{api.AuthServer{Address: "dc1.example.com"}, "ldap://dc1.example.com:389", false},
+		{api.AuthServer{Address: "dc1.example.com", Port: 636}, "ldaps://dc1.example.com:636", true},
+		{api.AuthServer{Address: "::1", Port: 3268}, "ldap://[::1]:3268", false},
+	}
+
+	for _, tt := range tests {
+		u, implicitTLS := LDAPURL(&tt.server)
+		if u != tt.expected || implicitTLS != tt.tls {
+			t.Errorf("Expected %s %t got %s %t", tt.expected, tt.tls, u, implicitTLS)
+		}
+	}
+}
+
+func TestSearchScope(t *testing.T) {
+	if SearchScope("base") != ldap.ScopeBaseObject || SearchScope("onelevel") != ldap.ScopeSingleLevel || SearchScope("") != ldap.ScopeWholeSubtree {
+		t.Errorf("Expected search scopes to be mapped")
+	}
+}
+
+func TestTestLDAPConnectError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	tester := &Tester{
+		Timeout:      time.Second,
+		LDAPSettings: &api.LDAPSettings{Basedn: "dc=example,dc=com"},
+	}
+	server := &api.AuthServer{Address: "127.0.0.1", Port: port, Protocol: api.AuthProtocolLDAP}
+
+	r := tester.Test(server, "andrew@example.com", "secret")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageConnect {
+		t.Errorf("Expected %s got %s", StageConnect, r.Stage)
+	}
+	if r.Protocol != "ldap" {
+		t.Errorf("Expected %s got %s", "ldap", r.Protocol)
+	}
+}
@@ -0,0 +1,219 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	imapTag          = "a1"
+	noAuthError      = "AUTH is not offered"
+	noMechError      = "no supported AUTH mechanism offered"
+	unencryptedError = "refusing to authenticate over an unencrypted connection"
+)
+
+func (t *Tester) pop3(r *Result, server *api.AuthServer, password string) {
+	var err error
+	var line string
+	var conn net.Conn
+
+	if conn, err = t.dial(r, server); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	if line, err = pop3Response(tp); err != nil {
+		r.Err = err
+		return
+	}
+	r.Detail = line
+
+	r.Stage = StageAuth
+	for _, cmd := range []string{"USER " + r.Username, "PASS " + password} {
+		if err = tp.PrintfLine("%s", cmd); err != nil {
+			r.Err = err
+			return
+		}
+		if _, err = pop3Response(tp); err != nil {
+			r.Err = err
+			return
+		}
+	}
+
+	tp.PrintfLine("QUIT")
+}
+
+func pop3Response(tp *textproto.Conn) (line string, err error) {
+	if line, err = tp.ReadLine(); err != nil {
+		return
+	}
+
+	if !strings.HasPrefix(line, "+OK") {
+		err = errors.New(line)
+	}
+
+	return
+}
+
+func (t *Tester) imap(r *Result, server *api.AuthServer, password string) {
+	var err error
+	var line string
+	var conn net.Conn
+
+	if conn, err = t.dial(r, server); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	if line, err = tp.ReadLine(); err != nil {
+		r.Err = err
+		return
+	}
+	if !strings.HasPrefix(line, "* OK") {
+		r.Err = errors.New(line)
+		return
+	}
+	r.Detail = line
+
+	r.Stage = StageAuth
+	if err = tp.PrintfLine("%s LOGIN %s %s", imapTag, imapQuote(r.Username), imapQuote(password)); err != nil {
+		r.Err = err
+		return
+	}
+
+	for {
+		if line, err = tp.ReadLine(); err != nil {
+			r.Err = err
+			return
+		}
+		if strings.HasPrefix(line, imapTag+" ") {
+			break
+		}
+	}
+
+	if !strings.HasPrefix(line, imapTag+" OK") {
+		r.Err = errors.New(strings.TrimPrefix(line, imapTag+" "))
+		return
+	}
+
+	tp.PrintfLine("a2 LOGOUT")
+}
+
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (t *Tester) smtp(r *Result, server *api.AuthServer, password string) {
+	var err error
+	var conn net.Conn
+	var c *smtp.Client
+	var a smtp.Auth
+
+	if conn, err = t.dial(r, server); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+
+	if c, err = smtp.NewClient(conn, server.Address); err != nil {
+		r.Err = err
+		return
+	}
+
+	if err = c.Hello("localhost"); err != nil {
+		r.Err = err
+		return
+	}
+
+	if ok, _ := c.Extension("STARTTLS"); ok && !r.TLS {
+		r.Stage = StageTLS
+		if err = c.StartTLS(t.tlsConfig(server)); err != nil {
+			r.Err = err
+			return
+		}
+		r.TLS = true
+	}
+
+	r.Stage = StageAuth
+	ok, mechs := c.Extension("AUTH")
+	if !ok {
+		r.Err = fmt.Errorf(noAuthError)
+		return
+	}
+
+	mechs = " " + strings.ToUpper(mechs) + " "
+	switch {
+	case strings.Contains(mechs, " PLAIN "):
+		a = smtp.PlainAuth("", r.Username, password, server.Address)
+	case strings.Contains(mechs, " LOGIN "):
+		a = &loginAuth{username: r.Username, password: password, host: server.Address}
+	case strings.Contains(mechs, " CRAM-MD5 "):
+		a = smtp.CRAMMD5Auth(r.Username, password)
+	default:
+		r.Err = fmt.Errorf(noMechError)
+		return
+	}
+
+	if err = c.Auth(a); err != nil {
+		r.Err = err
+		return
+	}
+
+	c.Quit()
+}
+
+// loginAuth implements the LOGIN mechanism which net/smtp lacks
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (proto string, toServer []byte, err error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		err = fmt.Errorf(unencryptedError)
+		return
+	}
+
+	proto = "LOGIN"
+
+	return
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) (toServer []byte, err error) {
+	if !more {
+		return
+	}
+
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		toServer = []byte(a.username)
+	case "password:":
+		toServer = []byte(a.password)
+	default:
+		err = fmt.Errorf("unexpected server challenge: %s", fromServer)
+	}
+
+	return
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,197 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// RADIUS packet codes and attributes, RFC 2865
+const (
+	radiusAccessRequest   = 1
+	radiusAccessAccept    = 2
+	radiusAccessReject    = 3
+	radiusAccessChallenge = 11
+	radiusUserName        = 1
+	radiusUserPassword    = 2
+	radiusNASIdentifier   = 32
+	radiusReplyMessage    = 18
+	radiusHeaderLen       = 20
+	radiusMaxPacket       = 4096
+	radiusNASID           = "baruwa"
+)
+
+const (
+	radiusRejectError    = "access rejected"
+	radiusChallengeError = "access challenge, a second factor is required"
+	radiusResponseError  = "invalid response authenticator, check the shared secret"
+	radiusPacketError    = "invalid RADIUS response"
+	radiusPasswordError  = "The password is longer than 128 bytes"
+)
+
+func (t *Tester) radius(r *Result, server *api.AuthServer, password string) {
+	var err error
+	var n int
+	var conn net.Conn
+	var req, authenticator []byte
+
+	s := t.RadiusSettings
+	timeout := t.timeout()
+	if s.Timeout > 0 {
+		timeout = time.Duration(s.Timeout) * time.Second
+	}
+
+	r.Stage = StageConnect
+	if conn, err = net.DialTimeout("udp", r.Server, timeout); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	r.Stage = StageAuth
+	if req, authenticator, err = radiusRequest(s.Secret, r.Username, password); err != nil {
+		r.Err = err
+		return
+	}
+
+	if _, err = conn.Write(req); err != nil {
+		r.Err = err
+		return
+	}
+
+	resp := make([]byte, radiusMaxPacket)
+	if n, err = conn.Read(resp); err != nil {
+		r.Err = err
+		return
+	}
+
+	r.Detail, r.Err = radiusResponse(resp[:n], req[1], authenticator, s.Secret)
+}
+
+// radiusRequest builds an Access-Request packet
+func radiusRequest(secret, username, password string) (pkt, authenticator []byte, err error) {
+	var id [1]byte
+
+	if len(password) > 128 {
+		err = fmt.Errorf(radiusPasswordError)
+		return
+	}
+
+	authenticator = make([]byte, 16)
+	if _, err = rand.Read(authenticator); err != nil {
+		return
+	}
+	if _, err = rand.Read(id[:]); err != nil {
+		return
+	}
+
+	attrs := &bytes.Buffer{}
+	radiusAttribute(attrs, radiusUserName, []byte(username))
+	radiusAttribute(attrs, radiusUserPassword, radiusPassword(secret, password, authenticator))
+	radiusAttribute(attrs, radiusNASIdentifier, []byte(radiusNASID))
+
+	pkt = make([]byte, radiusHeaderLen, radiusHeaderLen+attrs.Len())
+	pkt[0] = radiusAccessRequest
+	pkt[1] = id[0]
+	binary.BigEndian.PutUint16(pkt[2:4], uint16(radiusHeaderLen+attrs.Len()))
+	copy(pkt[4:20], authenticator)
+	pkt = append(pkt, attrs.Bytes()...)
+
+	return
+}
+
+func radiusAttribute(b *bytes.Buffer, typ byte, value []byte) {
+	b.WriteByte(typ)
+	b.WriteByte(byte(len(value) + 2))
+	b.Write(value)
+}
+
+// radiusPassword hides the password as described in RFC 2865 5.2
+func radiusPassword(secret, password string, authenticator []byte) (hidden []byte) {
+	size := (len(password) + 15) / 16 * 16
+	if size == 0 {
+		size = 16
+	}
+
+	padded := make([]byte, size)
+	copy(padded, password)
+
+	prev := authenticator
+	for i := 0; i < size; i += 16 {
+		h := md5.New()
+		h.Write([]byte(secret))
+		h.Write(prev)
+		b := h.Sum(nil)
+		for j := 0; j < 16; j++ {
+			padded[i+j] ^= b[j]
+		}
+		prev = padded[i : i+16]
+	}
+
+	hidden = padded
+
+	return
+}
+
+// radiusResponse validates a response and returns its Reply-Message
+func radiusResponse(pkt []byte, id byte, authenticator []byte, secret string) (msg string, err error) {
+	if len(pkt) < radiusHeaderLen || pkt[1] != id {
+		err = fmt.Errorf(radiusPacketError)
+		return
+	}
+
+	length := int(binary.BigEndian.Uint16(pkt[2:4]))
+	if length < radiusHeaderLen || length > len(pkt) {
+		err = fmt.Errorf(radiusPacketError)
+		return
+	}
+	pkt = pkt[:length]
+
+	h := md5.New()
+	h.Write(pkt[:4])
+	h.Write(authenticator)
+	h.Write(pkt[radiusHeaderLen:])
+	h.Write([]byte(secret))
+	if !bytes.Equal(h.Sum(nil), pkt[4:20]) {
+		err = fmt.Errorf(radiusResponseError)
+		return
+	}
+
+	for attrs := pkt[radiusHeaderLen:]; len(attrs) >= 2; {
+		l := int(attrs[1])
+		if l < 2 || l > len(attrs) {
+			err = fmt.Errorf(radiusPacketError)
+			return
+		}
+		if attrs[0] == radiusReplyMessage {
+			msg = string(attrs[2:l])
+		}
+		attrs = attrs[l:]
+	}
+
+	switch pkt[0] {
+	case radiusAccessAccept:
+	case radiusAccessReject:
+		err = fmt.Errorf(radiusRejectError)
+	case radiusAccessChallenge:
+		err = fmt.Errorf(radiusChallengeError)
+	default:
+		err = fmt.Errorf(radiusPacketError)
+	}
+
+	return
+}
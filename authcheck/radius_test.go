@@ -0,0 +1,128 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package authcheck
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// radiusServer is a stand-in RADIUS server that accepts a single
+// username and password
+func radiusServer(t *testing.T, secret, username, password string) (conn net.PacketConn, server *api.AuthServer) {
+	var err error
+
+	if conn, err = net.ListenPacket("udp", "127.0.0.1:0"); err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	go func() {
+		buf := make([]byte, radiusMaxPacket)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			var user, hidden []byte
+			for attrs := req[radiusHeaderLen:]; len(attrs) >= 2; attrs = attrs[attrs[1]:] {
+				switch attrs[0] {
+				case radiusUserName:
+					user = attrs[2:attrs[1]]
+				case radiusUserPassword:
+					hidden = attrs[2:attrs[1]]
+				}
+			}
+			code := byte(radiusAccessReject)
+			attrs := &bytes.Buffer{}
+			if string(user) == username && bytes.Equal(hidden, radiusPassword(secret, password, req[4:20])) {
+				code = radiusAccessAccept
+			} else {
+				radiusAttribute(attrs, radiusReplyMessage, []byte("Invalid credentials"))
+			}
+			resp := make([]byte, radiusHeaderLen)
+			resp[0] = code
+			resp[1] = req[1]
+			binary.BigEndian.PutUint16(resp[2:4], uint16(radiusHeaderLen+attrs.Len()))
+			resp = append(resp, attrs.Bytes()...)
+			h := md5.New()
+			h.Write(resp[:4])
+			h.Write(req[4:20])
+			h.Write(resp[radiusHeaderLen:])
+			h.Write([]byte(secret))
+			copy(resp[4:20], h.Sum(nil))
+			conn.WriteTo(resp, addr)
+		}
+	}()
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	server = &api.AuthServer{
+		ID:           1,
+		Address:      "127.0.0.1",
+		Port:         addr.Port,
+		Protocol:     api.AuthProtocolRADIUS,
+		SplitAddress: true,
+	}
+
+	return
+}
+
+func TestRadiusPassword(t *testing.T) {
+	authenticator := bytes.Repeat([]byte{1}, 16)
+
+	hidden := radiusPassword("secret", "", authenticator)
+	if len(hidden) != 16 {
+		t.Errorf("Expected %d got %d", 16, len(hidden))
+	}
+
+	hidden = radiusPassword("secret", "a-password-longer-than-16", authenticator)
+	if len(hidden) != 32 {
+		t.Errorf("Expected %d got %d", 32, len(hidden))
+	}
+}
+
+func TestTestRadius(t *testing.T) {
+	conn, server := radiusServer(t, "sharedsecret", "andrew", "secret")
+	defer conn.Close()
+
+	tester := &Tester{
+		Timeout:        time.Second,
+		RadiusSettings: &api.RadiusSettings{Secret: "sharedsecret"},
+	}
+
+	r := tester.Test(server, "andrew@example.com", "secret")
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+
+	r = tester.Test(server, "andrew@example.com", "wrong")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Err.Error() != radiusRejectError {
+		t.Errorf("Expected '%s' got '%s'", radiusRejectError, r.Err)
+	}
+	if r.Detail != "Invalid credentials" {
+		t.Errorf("Expected %s got %s", "Invalid credentials", r.Detail)
+	}
+
+	tester.RadiusSettings.Secret = "wrongsecret"
+	r = tester.Test(server, "andrew@example.com", "secret")
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Err.Error() != radiusResponseError {
+		t.Errorf("Expected '%s' got '%s'", radiusResponseError, r.Err)
+	}
+}
@@ -0,0 +1,249 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package batch Run many Baruwa API changes with partial-failure reporting
+
+An Executor runs a list of Operations with bounded concurrency and an
+optional rate limit, recording a Result for each. A failure either stops
+the remaining operations or is recorded while the rest continue, and
+the completed operations can be rolled back using the state they
+captured before making their change.
+
+Operations wrap any Create, Update or Delete call, helpers such as
+ModifyDomain, CreateUser and DeleteOrganization capture the previous
+state needed to undo them.
+
+	var ops []batch.Operation
+	for _, d := range domains {
+		ops = append(ops, batch.ModifyDomain(c, d.ID, func(d *api.Domain) {
+			d.VirusChecks = true
+		}))
+	}
+	e := batch.New(&batch.Options{Workers: 8, Rate: 20})
+	r := e.Run(ops)
+	fmt.Printf("%d failed\n", r.Failed)
+*/
+package batch
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWorkers is the number of operations run concurrently
+	DefaultWorkers = 4
+)
+
+const (
+	skippedError = "skipped after an earlier operation failed"
+	noUndoError  = "the operation can not be rolled back"
+)
+
+// Operation is a single change, Undo reverts it once Do has succeeded
+// and is nil for operations that can not be rolled back
+type Operation struct {
+	Name string
+	Do   func() error
+	Undo func() error
+}
+
+// Result holds the outcome of an operation
+type Result struct {
+	Index         int    `json:"index"`
+	Name          string `json:"name"`
+	Done          bool   `json:"done"`
+	Skipped       bool   `json:"skipped"`
+	Err           error  `json:"-"`
+	Error         string `json:"error,omitempty"`
+	RolledBack    bool   `json:"rolled_back"`
+	RollbackErr   error  `json:"-"`
+	RollbackError string `json:"rollback_error,omitempty"`
+}
+
+// OK returns true if the operation succeeded
+func (r *Result) OK() bool {
+	return r.Done && r.Err == nil
+}
+
+// Report holds the results in the order of the operations
+type Report struct {
+	Results     []Result `json:"results"`
+	Succeeded   int      `json:"succeeded"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"`
+	RolledBack  int      `json:"rolled_back"`
+	Unrecovered int      `json:"unrecovered"`
+}
+
+// Errors returns the failed results
+func (r *Report) Errors() (l []Result) {
+	for _, v := range r.Results {
+		if v.Err != nil && !v.Skipped {
+			l = append(l, v)
+		}
+	}
+
+	return
+}
+
+// Options represents optional settings that can be passed to New
+type Options struct {
+	// Workers defaults to DefaultWorkers
+	Workers int
+	// Rate limits the operations started per second, unlimited when 0
+	Rate float64
+	// StopOnError skips the operations not yet started once one fails
+	StopOnError bool
+	// Rollback undoes the completed operations, in reverse order, when
+	// any operation fails
+	Rollback bool
+}
+
+// Executor runs operations
+type Executor struct {
+	opts Options
+}
+
+// New returns an Executor
+func New(opts *Options) (e *Executor) {
+	e = &Executor{}
+
+	if opts != nil {
+		e.opts = *opts
+	}
+
+	if e.opts.Workers < 1 {
+		e.opts.Workers = DefaultWorkers
+	}
+
+	return
+}
+
+// Run runs the operations and waits for them to complete
+func (e *Executor) Run(ops []Operation) (r *Report) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed bool
+	var limit <-chan time.Time
+
+	r = &Report{
+		Results: make([]Result, len(ops)),
+	}
+
+	if e.opts.Rate > 0 {
+		t := time.NewTicker(interval(e.opts.Rate))
+		defer t.Stop()
+		limit = t.C
+	}
+
+	jobs := make(chan int)
+	for w := 0; w < e.opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				res := &r.Results[i]
+				mu.Lock()
+				stop := failed && e.opts.StopOnError
+				mu.Unlock()
+				if stop {
+					res.Skipped = true
+					res.Err = fmt.Errorf(skippedError)
+					continue
+				}
+				res.Err = ops[i].Do()
+				res.Done = true
+				if res.Err != nil {
+					mu.Lock()
+					failed = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range ops {
+		r.Results[i].Index = i
+		r.Results[i].Name = ops[i].Name
+		if limit != nil && i > 0 {
+			mu.Lock()
+			stop := failed && e.opts.StopOnError
+			mu.Unlock()
+			if !stop {
+				<-limit
+			}
+		}
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	if failed && e.opts.Rollback {
+		e.rollback(ops, r)
+	}
+
+	for i := range r.Results {
+		v := &r.Results[i]
+		if v.Err != nil {
+			v.Error = v.Err.Error()
+		}
+		if v.RollbackErr != nil {
+			v.RollbackError = v.RollbackErr.Error()
+		}
+		switch {
+		case v.Skipped:
+			r.Skipped++
+		case v.Err != nil:
+			r.Failed++
+		default:
+			r.Succeeded++
+		}
+		if v.RolledBack {
+			r.RolledBack++
+		} else if v.RollbackErr != nil {
+			r.Unrecovered++
+		}
+	}
+
+	return
+}
+
+func (e *Executor) rollback(ops []Operation, r *Report) {
+	for i := len(ops) - 1; i >= 0; i-- {
+		res := &r.Results[i]
+		if !res.OK() {
+			continue
+		}
+		if ops[i].Undo == nil {
+			res.RollbackErr = fmt.Errorf(noUndoError)
+			continue
+		}
+		if res.RollbackErr = ops[i].Undo(); res.RollbackErr == nil {
+			res.RolledBack = true
+		}
+	}
+}
+
+// interval returns the time between operations for a rate per second,
+// clamped to the range of a time.Duration
+func interval(rate float64) time.Duration {
+	d := float64(time.Second) / rate
+
+	switch {
+	case d < 1:
+		return 1
+	case d >= math.MaxInt64:
+		return math.MaxInt64
+	default:
+		return time.Duration(d)
+	}
+}
@@ -0,0 +1,199 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	done    []int
+	undone  []int
+}
+
+func (r *recorder) op(i int, fail bool) Operation {
+	return Operation{
+		Name: fmt.Sprintf("op %d", i),
+		Do: func() error {
+			r.mu.Lock()
+			r.running++
+			if r.running > r.peak {
+				r.peak = r.running
+			}
+			r.mu.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.running--
+			if fail {
+				return fmt.Errorf("op %d failed", i)
+			}
+			r.done = append(r.done, i)
+			return nil
+		},
+		Undo: func() error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.undone = append(r.undone, i)
+			return nil
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	if e := New(nil); e.opts.Workers != DefaultWorkers {
+		t.Errorf("Expected %d got %d", DefaultWorkers, e.opts.Workers)
+	}
+	if e := New(&Options{Workers: 9}); e.opts.Workers != 9 {
+		t.Errorf("Expected %d got %d", 9, e.opts.Workers)
+	}
+}
+
+func TestRun(t *testing.T) {
+	rec := &recorder{}
+	var ops []Operation
+	for i := 0; i < 20; i++ {
+		ops = append(ops, rec.op(i, i == 7 || i == 12))
+	}
+
+	r := New(&Options{Workers: 3}).Run(ops)
+	if rec.peak > 3 {
+		t.Errorf("Expected at most %d concurrent operations got %d", 3, rec.peak)
+	}
+	if r.Succeeded != 18 || r.Failed != 2 || r.Skipped != 0 {
+		t.Errorf("Expected 18/2/0 got %d/%d/%d", r.Succeeded, r.Failed, r.Skipped)
+	}
+	for i, res := range r.Results {
+		if res.Index != i || res.Name != fmt.Sprintf("op %d", i) {
+			t.Errorf("Expected the results in order got %d %s", res.Index, res.Name)
+		}
+	}
+	errs := r.Errors()
+	if len(errs) != 2 || errs[0].Index != 7 || errs[1].Err.Error() != "op 12 failed" {
+		t.Errorf("Expected operations 7 and 12 to fail got %v", errs)
+	}
+	if len(rec.undone) != 0 {
+		t.Errorf("Expected no rollback got %v", rec.undone)
+	}
+}
+
+func TestRunStopOnError(t *testing.T) {
+	rec := &recorder{}
+	var ops []Operation
+	for i := 0; i < 10; i++ {
+		ops = append(ops, rec.op(i, i == 2))
+	}
+
+	r := New(&Options{Workers: 1, StopOnError: true}).Run(ops)
+	if r.Succeeded != 2 || r.Failed != 1 || r.Skipped != 7 {
+		t.Errorf("Expected 2/1/7 got %d/%d/%d", r.Succeeded, r.Failed, r.Skipped)
+	}
+	if res := r.Results[3]; !res.Skipped || res.Done || res.Err == nil || res.Err.Error() != skippedError {
+		t.Errorf("Expected operation 3 to be skipped got %v", res)
+	}
+	if len(r.Errors()) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(r.Errors()))
+	}
+}
+
+func TestRunRollback(t *testing.T) {
+	rec := &recorder{}
+	var ops []Operation
+	for i := 0; i < 6; i++ {
+		ops = append(ops, rec.op(i, i == 4))
+	}
+	ops[1].Undo = nil
+	ops[3].Undo = func() error {
+		return fmt.Errorf("undo failed")
+	}
+
+	r := New(&Options{Workers: 1, StopOnError: true, Rollback: true}).Run(ops)
+	if fmt.Sprint(rec.undone) != "[2 0]" {
+		t.Errorf("Expected [2 0] got %v", rec.undone)
+	}
+	if r.RolledBack != 2 || r.Unrecovered != 2 {
+		t.Errorf("Expected 2/2 got %d/%d", r.RolledBack, r.Unrecovered)
+	}
+	if res := r.Results[1]; res.RolledBack || res.RollbackErr == nil || res.RollbackErr.Error() != noUndoError {
+		t.Errorf("Expected '%s' got '%v'", noUndoError, res.RollbackErr)
+	}
+	if res := r.Results[3]; res.RolledBack || res.RollbackErr == nil {
+		t.Errorf("Expected the rollback of operation 3 to fail")
+	}
+	if res := r.Results[4]; res.RolledBack || res.RollbackErr != nil {
+		t.Errorf("Expected the failed operation not to be rolled back")
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	var decoded Report
+	json.Unmarshal(b, &decoded)
+	if res := decoded.Results[4]; res.Error != r.Results[4].Err.Error() {
+		t.Errorf("Expected '%s' got '%s'", r.Results[4].Err, res.Error)
+	}
+	if res := decoded.Results[3]; res.RollbackError != "undo failed" || res.Error != "" {
+		t.Errorf("Expected '%s' got '%s'", "undo failed", res.RollbackError)
+	}
+	if res := decoded.Results[5]; res.Error != skippedError {
+		t.Errorf("Expected '%s' got '%s'", skippedError, res.Error)
+	}
+
+	rec = &recorder{}
+	ops = []Operation{rec.op(0, false), rec.op(1, false)}
+	r = New(&Options{Rollback: true}).Run(ops)
+	if r.Succeeded != 2 || len(rec.undone) != 0 {
+		t.Errorf("Expected no rollback when all operations succeed")
+	}
+}
+
+func TestRunRate(t *testing.T) {
+	rec := &recorder{}
+	var ops []Operation
+	for i := 0; i < 5; i++ {
+		ops = append(ops, rec.op(i, false))
+	}
+
+	start := time.Now()
+	r := New(&Options{Workers: 5, Rate: 100}).Run(ops)
+	if d := time.Since(start); d < 35*time.Millisecond {
+		t.Errorf("Expected the operations to be rate limited took %s", d)
+	}
+	if r.Succeeded != 5 {
+		t.Errorf("Expected %d got %d", 5, r.Succeeded)
+	}
+
+	if r = New(nil).Run(nil); len(r.Results) != 0 {
+		t.Errorf("Expected %d got %d", 0, len(r.Results))
+	}
+
+	if r = New(&Options{Rate: 2e9}).Run(ops); r.Succeeded != 5 {
+		t.Errorf("Expected %d got %d", 5, r.Succeeded)
+	}
+
+	for rate, expected := range map[float64]time.Duration{
+		100:   10 * time.Millisecond,
+		2e9:   time.Nanosecond,
+		1e-12: time.Duration(math.MaxInt64),
+	} {
+		if d := interval(rate); d != expected {
+			t.Errorf("Expected %s got %s", expected, d)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package batch
+
+import (
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// DomainClient is the subset of api.Client used by the domain operations
+type DomainClient interface {
+	GetDomain(domainID int) (*api.Domain, error)
+	CreateDomain(domain *api.Domain) error
+	UpdateDomain(domain *api.Domain) error
+	DeleteDomain(domainID int) error
+}
+
+// UserClient is the subset of api.Client used by the user operations
+type UserClient interface {
+	GetUser(userID int) (*api.User, error)
+	CreateUser(user *api.UserForm) (*api.User, error)
+	UpdateUser(user *api.UserForm) error
+	DeleteUser(userID int) error
+}
+
+// OrganizationClient is the subset of api.Client used by the
+// organization operations
+type OrganizationClient interface {
+	GetOrganization(organizationID int) (*api.Organization, error)
+	CreateOrganization(form *api.OrganizationForm) (*api.Organization, error)
+	UpdateOrganization(form *api.OrganizationForm, org *api.Organization) error
+	DeleteOrganization(organizationID int) error
+}
+
+var (
+	_ DomainClient       = (*api.Client)(nil)
+	_ UserClient         = (*api.Client)(nil)
+	_ OrganizationClient = (*api.Client)(nil)
+)
+
+// CreateDomain creates a domain, rollback deletes it
+func CreateDomain(c DomainClient, domain *api.Domain) Operation {
+	return Operation{
+		Name: fmt.Sprintf("create domain %s", domain.Name),
+		Do: func() error {
+			return c.CreateDomain(domain)
+		},
+		Undo: func() error {
+			return c.DeleteDomain(domain.ID)
+		},
+	}
+}
+
+// UpdateDomain updates a domain, rollback restores the settings it had
+// before the update
+func UpdateDomain(c DomainClient, domain *api.Domain) Operation {
+	var prev *api.Domain
+
+	return Operation{
+		Name: fmt.Sprintf("update domain %d", domain.ID),
+		Do: func() (err error) {
+			if prev, err = c.GetDomain(domain.ID); err != nil {
+				return
+			}
+			err = c.UpdateDomain(domain)
+			return
+		},
+		Undo: func() error {
+			return c.UpdateDomain(prev)
+		},
+	}
+}
+
+// ModifyDomain reads a domain, applies fn and updates it, rollback
+// restores the settings it had before the update
+func ModifyDomain(c DomainClient, domainID int, fn func(d *api.Domain)) Operation {
+	var prev *api.Domain
+
+	return Operation{
+		Name: fmt.Sprintf("update domain %d", domainID),
+		Do: func() (err error) {
+			if prev, err = c.GetDomain(domainID); err != nil {
+				return
+			}
+			d := *prev
+			d.Organizations = append([]int(nil), prev.Organizations...)
+			fn(&d)
+			err = c.UpdateDomain(&d)
+			return
+		},
+		Undo: func() error {
+			return c.UpdateDomain(prev)
+		},
+	}
+}
+
+// DeleteDomain deletes a domain, rollback recreates it with a new ID
+// without the servers and aliases that were deleted with it
+func DeleteDomain(c DomainClient, domainID int) Operation {
+	var prev *api.Domain
+
+	return Operation{
+		Name: fmt.Sprintf("delete domain %d", domainID),
+		Do: func() (err error) {
+			if prev, err = c.GetDomain(domainID); err != nil {
+				return
+			}
+			err = c.DeleteDomain(domainID)
+			return
+		},
+		Undo: func() error {
+			d := *prev
+			d.ID = 0
+			return c.CreateDomain(&d)
+		},
+	}
+}
+
+// CreateUser creates a user account, rollback deletes it
+func CreateUser(c UserClient, form *api.UserForm) Operation {
+	var created *api.User
+
+	name := ""
+	if form.Username != nil {
+		name = *form.Username
+	}
+
+	return Operation{
+		Name: fmt.Sprintf("create user %s", name),
+		Do: func() (err error) {
+			created, err = c.CreateUser(form)
+			return
+		},
+		Undo: func() error {
+			return c.DeleteUser(created.ID)
+		},
+	}
+}
+
+// UpdateUser updates a user account, rollback restores the settings it
+// had before the update
+func UpdateUser(c UserClient, form *api.UserForm) Operation {
+	var prev *api.User
+
+	id := 0
+	if form.ID != nil {
+		id = *form.ID
+	}
+
+	return Operation{
+		Name: fmt.Sprintf("update user %d", id),
+		Do: func() (err error) {
+			if prev, err = c.GetUser(id); err != nil {
+				return
+			}
+			err = c.UpdateUser(form)
+			return
+		},
+		Undo: func() error {
+			return c.UpdateUser(userForm(prev, true))
+		},
+	}
+}
+
+// DeleteUser deletes a user account, rollback recreates it with a new
+// ID and without a password
+func DeleteUser(c UserClient, userID int) Operation {
+	var prev *api.User
+
+	return Operation{
+		Name: fmt.Sprintf("delete user %d", userID),
+		Do: func() (err error) {
+			if prev, err = c.GetUser(userID); err != nil {
+				return
+			}
+			err = c.DeleteUser(userID)
+			return
+		},
+		Undo: func() error {
+			_, err := c.CreateUser(userForm(prev, false))
+			return err
+		},
+	}
+}
+
+// CreateOrganization creates an organization, rollback deletes it
+func CreateOrganization(c OrganizationClient, form *api.OrganizationForm) Operation {
+	var created *api.Organization
+
+	return Operation{
+		Name: fmt.Sprintf("create organization %s", form.Name),
+		Do: func() (err error) {
+			created, err = c.CreateOrganization(form)
+			return
+		},
+		Undo: func() error {
+			return c.DeleteOrganization(created.ID)
+		},
+	}
+}
+
+// UpdateOrganization updates an organization, rollback restores the
+// name, domains and administrators it had before the update
+func UpdateOrganization(c OrganizationClient, form *api.OrganizationForm) Operation {
+	var prev *api.Organization
+
+	return Operation{
+		Name: fmt.Sprintf("update organization %d", form.ID),
+		Do: func() (err error) {
+			if prev, err = c.GetOrganization(form.ID); err != nil {
+				return
+			}
+			err = c.UpdateOrganization(form, &api.Organization{ID: form.ID})
+			return
+		},
+		Undo: func() error {
+			return c.UpdateOrganization(organizationForm(prev, true), &api.Organization{ID: prev.ID})
+		},
+	}
+}
+
+// DeleteOrganization deletes an organization, rollback recreates it
+// with a new ID
+func DeleteOrganization(c OrganizationClient, organizationID int) Operation {
+	var prev *api.Organization
+
+	return Operation{
+		Name: fmt.Sprintf("delete organization %d", organizationID),
+		Do: func() (err error) {
+			if prev, err = c.GetOrganization(organizationID); err != nil {
+				return
+			}
+			err = c.DeleteOrganization(organizationID)
+			return
+		},
+		Undo: func() error {
+			_, err := c.CreateOrganization(organizationForm(prev, false))
+			return err
+		},
+	}
+}
+
+// userForm converts a user to the form that restores it
+func userForm(u *api.User, withID bool) (form *api.UserForm) {
+	v := *u
+
+	form = &api.UserForm{
+		Username:    &v.Username,
+		Firstname:   &v.Firstname,
+		Lastname:    &v.Lastname,
+		Email:       &v.Email,
+		Timezone:    &v.Timezone,
+		AccountType: &v.AccountType,
+		Enabled:     &v.Enabled,
+		SendReport:  &v.SendReport,
+		SpamChecks:  &v.SpamChecks,
+		LowScore:    &v.LowScore,
+		HighScore:   &v.HighScore,
+		BlockMacros: &v.BlockMacros,
+	}
+
+	if withID {
+		form.ID = &v.ID
+	}
+
+	for _, d := range u.Domains {
+		form.Domains = append(form.Domains, d.ID)
+	}
+
+	for _, o := range u.Organizations {
+		form.Organizations = append(form.Organizations, o.ID)
+	}
+
+	return
+}
+
+// organizationForm converts an organization to the form that restores it
+func organizationForm(org *api.Organization, withID bool) (form *api.OrganizationForm) {
+	form = &api.OrganizationForm{
+		Name: org.Name,
+	}
+
+	if withID {
+		form.ID = org.ID
+	}
+
+	for _, d := range org.Domains {
+		form.Domains = append(form.Domains, d.ID)
+	}
+
+	for _, a := range org.Admins {
+		form.Admins = append(form.Admins, a.ID)
+	}
+
+	return
+}
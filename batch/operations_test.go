@@ -0,0 +1,282 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package batch
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeClient struct {
+	mu      sync.Mutex
+	nextID  int
+	domains map[int]api.Domain
+	users   map[int]api.User
+	orgs    map[int]api.Organization
+	fail    map[int]bool
+}
+
+func newFakeClient() *fakeClient {
+	c := &fakeClient{
+		nextID:  100,
+		domains: make(map[int]api.Domain),
+		users:   make(map[int]api.User),
+		orgs:    make(map[int]api.Organization),
+		fail:    make(map[int]bool),
+	}
+	for i := 1; i <= 5; i++ {
+		c.domains[i] = api.Domain{ID: i, Name: fmt.Sprintf("example%d.com", i), Organizations: []int{1}}
+	}
+	c.users[1] = api.User{ID: 1, Username: "jdoe", Email: "jdoe@example1.com", AccountType: 3, Enabled: true, Domains: []api.UserDomain{{ID: 1}}}
+	c.orgs[1] = api.Organization{ID: 1, Name: "Example Inc", Domains: []api.OrgDomain{{ID: 1}}, Admins: []api.OrgAdmin{{ID: 1}}}
+	return c
+}
+
+func (c *fakeClient) GetDomain(domainID int) (*api.Domain, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.domains[domainID]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return &d, nil
+}
+
+func (c *fakeClient) CreateDomain(domain *api.Domain) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	domain.ID = c.nextID
+	c.domains[domain.ID] = *domain
+	return nil
+}
+
+func (c *fakeClient) UpdateDomain(domain *api.Domain) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail[domain.ID] {
+		return fmt.Errorf("500 Internal Server Error")
+	}
+	c.domains[domain.ID] = *domain
+	return nil
+}
+
+func (c *fakeClient) DeleteDomain(domainID int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.domains, domainID)
+	return nil
+}
+
+func (c *fakeClient) GetUser(userID int) (*api.User, error) {
+	u, ok := c.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return &u, nil
+}
+
+func (c *fakeClient) CreateUser(form *api.UserForm) (*api.User, error) {
+	c.nextID++
+	u := api.User{ID: c.nextID, Username: *form.Username, Email: *form.Email}
+	if form.Enabled != nil {
+		u.Enabled = *form.Enabled
+	}
+	for _, d := range form.Domains {
+		u.Domains = append(u.Domains, api.UserDomain{ID: d})
+	}
+	c.users[u.ID] = u
+	return &u, nil
+}
+
+func (c *fakeClient) UpdateUser(form *api.UserForm) error {
+	u := c.users[*form.ID]
+	if form.Email != nil {
+		u.Email = *form.Email
+	}
+	if form.Enabled != nil {
+		u.Enabled = *form.Enabled
+	}
+	c.users[u.ID] = u
+	return nil
+}
+
+func (c *fakeClient) DeleteUser(userID int) error {
+	delete(c.users, userID)
+	return nil
+}
+
+func (c *fakeClient) GetOrganization(organizationID int) (*api.Organization, error) {
+	o, ok := c.orgs[organizationID]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+	return &o, nil
+}
+
+func (c *fakeClient) CreateOrganization(form *api.OrganizationForm) (*api.Organization, error) {
+	c.nextID++
+	o := api.Organization{ID: c.nextID, Name: form.Name}
+	for _, d := range form.Domains {
+		o.Domains = append(o.Domains, api.OrgDomain{ID: d})
+	}
+	c.orgs[o.ID] = o
+	return &o, nil
+}
+
+func (c *fakeClient) UpdateOrganization(form *api.OrganizationForm, org *api.Organization) error {
+	o := c.orgs[form.ID]
+	o.Name = form.Name
+	c.orgs[o.ID] = o
+	*org = o
+	return nil
+}
+
+func (c *fakeClient) DeleteOrganization(organizationID int) error {
+	delete(c.orgs, organizationID)
+	return nil
+}
+
+func TestDomainOperations(t *testing.T) {
+	c := newFakeClient()
+	c.fail[4] = true
+
+	var ops []Operation
+	for i := 1; i <= 5; i++ {
+		ops = append(ops, ModifyDomain(c, i, func(d *api.Domain) {
+			d.VirusChecks = true
+		}))
+	}
+
+	r := New(&Options{Workers: 2}).Run(ops)
+	if r.Succeeded != 4 || r.Failed != 1 {
+		t.Fatalf("Expected 4/1 got %d/%d", r.Succeeded, r.Failed)
+	}
+	if errs := r.Errors(); errs[0].Name != "update domain 4" {
+		t.Errorf("Expected %s got %s", "update domain 4", errs[0].Name)
+	}
+	for i := 1; i <= 5; i++ {
+		if c.domains[i].VirusChecks != (i != 4) {
+			t.Errorf("Expected domain %d to be updated", i)
+		}
+		if !reflect.DeepEqual(c.domains[i].Organizations, []int{1}) {
+			t.Errorf("Expected %v got %v", []int{1}, c.domains[i].Organizations)
+		}
+	}
+
+	r = New(&Options{Workers: 2, Rollback: true}).Run(ops)
+	if r.RolledBack != 4 {
+		t.Errorf("Expected %d got %d", 4, r.RolledBack)
+	}
+	for i := 1; i <= 5; i++ {
+		if c.domains[i].VirusChecks != (i != 4) {
+			t.Errorf("Expected domain %d to be restored", i)
+		}
+	}
+
+	d := &api.Domain{Name: "example.net"}
+	update := &api.Domain{ID: 2, Name: "example2.com", SpamChecks: true}
+	ops = []Operation{
+		CreateDomain(c, d),
+		UpdateDomain(c, update),
+		DeleteDomain(c, 3),
+		ModifyDomain(c, 4, func(d *api.Domain) {}),
+	}
+	r = New(&Options{Workers: 1, Rollback: true}).Run(ops)
+	if r.Failed != 1 || r.RolledBack != 3 {
+		t.Errorf("Expected 1/3 got %d/%d", r.Failed, r.RolledBack)
+	}
+	if _, ok := c.domains[d.ID]; ok {
+		t.Errorf("Expected the created domain to be deleted")
+	}
+	if c.domains[2].SpamChecks {
+		t.Errorf("Expected the domain update to be reverted")
+	}
+	var restored bool
+	for _, v := range c.domains {
+		restored = restored || v.Name == "example3.com"
+	}
+	if !restored {
+		t.Errorf("Expected the deleted domain to be recreated")
+	}
+}
+
+func TestUserOperations(t *testing.T) {
+	c := newFakeClient()
+
+	username, email := "jane", "jane@example1.com"
+	newEmail, id, disabled := "john@example1.com", 1, false
+	ops := []Operation{
+		CreateUser(c, &api.UserForm{Username: &username, Email: &email}),
+		UpdateUser(c, &api.UserForm{ID: &id, Email: &newEmail, Enabled: &disabled}),
+	}
+	r := New(&Options{Workers: 1}).Run(ops)
+	if r.Succeeded != 2 || len(c.users) != 2 || c.users[1].Email != newEmail {
+		t.Fatalf("Expected the operations to succeed got %v", r.Errors())
+	}
+	if r.Results[0].Name != "create user jane" {
+		t.Errorf("Expected %s got %s", "create user jane", r.Results[0].Name)
+	}
+
+	New(nil).rollback(ops, r)
+	if len(c.users) != 1 || c.users[1].Email != "jdoe@example1.com" || !c.users[1].Enabled {
+		t.Errorf("Expected the user changes to be reverted got %v", c.users)
+	}
+
+	op := DeleteUser(c, 1)
+	if err := op.Do(); err != nil || len(c.users) != 0 {
+		t.Fatalf("Expected the user to be deleted")
+	}
+	if err := op.Undo(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, u := range c.users {
+		if u.Username != "jdoe" || !reflect.DeepEqual(u.Domains, []api.UserDomain{{ID: 1}}) {
+			t.Errorf("Expected the user to be recreated got %v", u)
+		}
+	}
+
+	if err := DeleteUser(c, 99).Do(); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestOrganizationOperations(t *testing.T) {
+	c := newFakeClient()
+
+	ops := []Operation{
+		CreateOrganization(c, &api.OrganizationForm{Name: "Example Ltd"}),
+		UpdateOrganization(c, &api.OrganizationForm{ID: 1, Name: "Renamed"}),
+	}
+	r := New(&Options{Workers: 1}).Run(ops)
+	if r.Succeeded != 2 || len(c.orgs) != 2 || c.orgs[1].Name != "Renamed" {
+		t.Fatalf("Expected the operations to succeed got %v", r.Errors())
+	}
+
+	New(nil).rollback(ops, r)
+	if len(c.orgs) != 1 || c.orgs[1].Name != "Example Inc" {
+		t.Errorf("Expected the organization changes to be reverted got %v", c.orgs)
+	}
+
+	op := DeleteOrganization(c, 1)
+	if err := op.Do(); err != nil || len(c.orgs) != 0 {
+		t.Fatalf("Expected the organization to be deleted")
+	}
+	if err := op.Undo(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, o := range c.orgs {
+		if o.Name != "Example Inc" || !reflect.DeepEqual(o.Domains, []api.OrgDomain{{ID: 1}}) {
+			t.Errorf("Expected the organization to be recreated got %v", o)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package bulk Bulk import and export of Baruwa user accounts
+
+Rows are read from CSV or JSON, validated, and the user accounts
+and their alias addresses created concurrently. Each row produces a
+Result which can be written out as a CSV report.
+
+The CSV format uses a header row, list values are separated by a
+semicolon:
+
+	username,firstname,lastname,email,password,timezone,account_type,active,send_report,spam_checks,low_score,high_score,block_macros,domains,organizations,aliases
+	andrew,Andrew,Kissa,andrew@example.com,s3cr3t,Africa/Johannesburg,3,true,true,true,0,0,false,2;4,,info@example.com;!sales@example.com
+
+Alias addresses are enabled unless prefixed with "!".
+
+The JSON format is an array of objects using the api.UserForm field
+names plus an aliases list. Aliases are either an address, which is
+enabled, or an object with the address and enabled fields as exported.
+*/
+package bulk
+
+import (
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Format is the encoding of an import or export file
+type Format int
+
+const (
+	// CSV comma separated values
+	CSV Format = iota
+	// JSON array of objects
+	JSON
+)
+
+const (
+	listSep          = ";"
+	disabledPrefix   = "!"
+	defaultWorkers   = 4
+	formatError      = "The format param is not supported"
+	headerError      = "The CSV header row is missing"
+	columnError      = "The CSV column %s is required"
+	valueError       = "The %s value %q is invalid"
+	usernameError    = "The username is required"
+	emailError       = "The email %q is invalid"
+	passwordError    = "The passwords do not match"
+	accountTypeError = "The account_type should be 1, 2 or 3"
+	scoreError       = "The low_score should be less than the high_score"
+	aliasError       = "The alias address %q is invalid"
+	duplicateError   = "The username %q is duplicated on line %d"
+)
+
+// Columns are the CSV columns in the order they are exported
+var Columns = []string{
+	"username",
+	"firstname",
+	"lastname",
+	"email",
+	"password",
+	"timezone",
+	"account_type",
+	"active",
+	"send_report",
+	"spam_checks",
+	"low_score",
+	"high_score",
+	"block_macros",
+	"domains",
+	"organizations",
+	"aliases",
+}
+
+// Client is the subset of api.Client used for import and export
+type Client interface {
+	CreateUser(user *api.UserForm) (*api.User, error)
+	CreateAliasAddress(userID int, alias *api.AliasAddress) error
+	GetUsers(opts *api.ListOptions) (*api.UserList, error)
+}
+
+var _ Client = (*api.Client)(nil)
+
+// Row holds a user account and its alias addresses
+type Row struct {
+	// Line is the line number for CSV or the index for JSON
+	Line    int
+	User    api.UserForm
+	Aliases []api.AliasAddress
+	// Err is set when the row could not be parsed
+	Err error
+}
+
+// Result holds the outcome of importing a row
+type Result struct {
+	Line     int
+	Username string
+	UserID   int
+	Aliases  int
+	Err      error
+}
+
+// OK returns true if the row was imported without errors
+func (r *Result) OK() bool {
+	return r.Err == nil
+}
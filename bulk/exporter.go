@@ -0,0 +1,164 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Export writes all user accounts matching the filter to w in a
+// format that can be read back by Read. Use the filter DomainID or
+// OrganizationID to export a single domain or organization, the
+// filter can be nil to export every account. Passwords are not
+// returned by the API so they are left empty.
+func Export(c Client, filter *api.UserSearchFilter, w io.Writer, format Format) (err error) {
+	var items []jsonRow
+	var cw *csv.Writer
+
+	switch format {
+	case CSV:
+		cw = csv.NewWriter(w)
+		if err = cw.Write(Columns); err != nil {
+			return
+		}
+	case JSON:
+		items = []jsonRow{}
+	default:
+		err = fmt.Errorf(formatError)
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.UserList
+		if l, err = c.GetUsers(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			u := &l.Items[i]
+			if filter != nil && !filter.Match(u) {
+				continue
+			}
+			if format == JSON {
+				items = append(items, jsonRow{
+					UserForm: formFromUser(u),
+					Aliases:  userAliases(u),
+				})
+				continue
+			}
+			if err = cw.Write(userRecord(u)); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if format == JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(items)
+		return
+	}
+
+	cw.Flush()
+	err = cw.Error()
+
+	return
+}
+
+func formFromUser(u *api.User) (f api.UserForm) {
+	username, firstname, lastname := u.Username, u.Firstname, u.Lastname
+	email, timezone, accountType := u.Email, u.Timezone, u.AccountType
+	enabled, sendReport, spamChecks := u.Enabled, u.SendReport, u.SpamChecks
+	lowScore, highScore, blockMacros := u.LowScore, u.HighScore, u.BlockMacros
+
+	f = api.UserForm{
+		Username:    &username,
+		Firstname:   &firstname,
+		Lastname:    &lastname,
+		Email:       &email,
+		Timezone:    &timezone,
+		AccountType: &accountType,
+		Enabled:     &enabled,
+		SendReport:  &sendReport,
+		SpamChecks:  &spamChecks,
+		LowScore:    &lowScore,
+		HighScore:   &highScore,
+		BlockMacros: &blockMacros,
+	}
+
+	for _, d := range u.Domains {
+		f.Domains = append(f.Domains, d.ID)
+	}
+
+	for _, o := range u.Organizations {
+		f.Organizations = append(f.Organizations, o.ID)
+	}
+
+	return
+}
+
+func userAliases(u *api.User) (aliases []jsonAlias) {
+	for _, a := range u.Addresses {
+		aliases = append(aliases, jsonAlias{Address: a.Address, Enabled: a.Enabled})
+	}
+
+	return
+}
+
+func aliasRecord(u *api.User) (aliases []string) {
+	for _, a := range u.Addresses {
+		if a.Enabled {
+			aliases = append(aliases, a.Address)
+		} else {
+			aliases = append(aliases, disabledPrefix+a.Address)
+		}
+	}
+
+	return
+}
+
+func userRecord(u *api.User) []string {
+	var domains, orgs []string
+
+	for _, d := range u.Domains {
+		domains = append(domains, strconv.Itoa(d.ID))
+	}
+
+	for _, o := range u.Organizations {
+		orgs = append(orgs, strconv.Itoa(o.ID))
+	}
+
+	return []string{
+		u.Username,
+		u.Firstname,
+		u.Lastname,
+		u.Email,
+		"",
+		u.Timezone,
+		strconv.Itoa(u.AccountType),
+		strconv.FormatBool(u.Enabled),
+		strconv.FormatBool(u.SendReport),
+		strconv.FormatBool(u.SpamChecks),
+		u.LowScore.String(),
+		u.HighScore.String(),
+		strconv.FormatBool(u.BlockMacros),
+		strings.Join(domains, listSep),
+		strings.Join(orgs, listSep),
+		strings.Join(aliasRecord(u), listSep),
+	}
+}
@@ -0,0 +1,121 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+func getTestExportClient() *fakeClient {
+	return &fakeClient{
+		users: []api.User{
+			{
+				ID:          1,
+				Username:    "andrew",
+				Email:       "andrew@example.com",
+				AccountType: 3,
+				Enabled:     true,
+				Domains:     []api.UserDomain{{ID: 2, Name: "example.com"}},
+				Addresses: []api.UserAddress{
+					{ID: 1, Address: "info@example.com", Enabled: true},
+					{ID: 2, Address: "sales@example.com"},
+				},
+			},
+			{
+				ID:          2,
+				Username:    "bob",
+				Email:       "bob@example.net",
+				AccountType: 3,
+				Domains:     []api.UserDomain{{ID: 4, Name: "example.net"}},
+			},
+		},
+	}
+}
+
+func TestExportError(t *testing.T) {
+	err := Export(getTestExportClient(), nil, &bytes.Buffer{}, Format(9))
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != formatError {
+		t.Errorf("Expected '%s' got '%s'", formatError, err)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &api.UserSearchFilter{DomainID: 2}
+	if err := Export(getTestExportClient(), f, buf, CSV); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(lines))
+	}
+	expected := "andrew,,,andrew@example.com,,,3,true,false,false,0.0,0.0,false,2,,info@example.com;!sales@example.com"
+	if lines[1] != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, lines[1])
+	}
+	rows, err := ReadCSV(buf)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(rows) != 1 || rows[0].Err != nil || len(rows[0].Aliases) != 2 {
+		t.Errorf("Expected the export to be readable got %v", rows)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Export(getTestExportClient(), nil, buf, JSON); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	rows, err := ReadJSON(buf)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(rows))
+	}
+	if *rows[1].User.Username != "bob" || rows[1].User.Domains[0] != 4 {
+		t.Errorf("Expected %s got %s", "bob", *rows[1].User.Username)
+	}
+	if len(rows[0].Aliases) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(rows[0].Aliases))
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	for _, format := range []Format{CSV, JSON} {
+		buf := &bytes.Buffer{}
+		if err := Export(getTestExportClient(), nil, buf, format); err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		rows, err := Read(buf, format)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		c := &fakeClient{}
+		for _, r := range NewImporter(c, 1).Import(rows) {
+			if !r.OK() {
+				t.Fatalf("An error should not be returned: %s", r.Err)
+			}
+		}
+		addrs := c.users[0].Addresses
+		if len(addrs) != 2 {
+			t.Fatalf("Expected %d got %d", 2, len(addrs))
+		}
+		if !addrs[0].Enabled || addrs[1].Enabled {
+			t.Errorf("Expected the disabled alias to stay disabled got %v", addrs)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Importer creates user accounts and alias addresses
+type Importer struct {
+	client  Client
+	workers int
+}
+
+// NewImporter creates a new Importer. Workers is the number of
+// accounts created concurrently, values < 1 use the default.
+func NewImporter(c Client, workers int) *Importer {
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+
+	return &Importer{
+		client:  c,
+		workers: workers,
+	}
+}
+
+// Validate checks a row, returning the first problem found
+func Validate(row *Row) (err error) {
+	var pw1, pw2 string
+
+	u := &row.User
+
+	if row.Err != nil {
+		err = row.Err
+		return
+	}
+
+	if u.Username == nil || strings.TrimSpace(*u.Username) == "" {
+		err = fmt.Errorf(usernameError)
+		return
+	}
+
+	if u.Email == nil || !isAddress(*u.Email) {
+		err = fmt.Errorf(emailError, deref(u.Email))
+		return
+	}
+
+	if u.Password1 != nil {
+		pw1 = *u.Password1
+	}
+
+	if u.Password2 != nil {
+		pw2 = *u.Password2
+	}
+
+	if pw1 != pw2 {
+		err = fmt.Errorf(passwordError)
+		return
+	}
+
+	if u.AccountType != nil && (*u.AccountType < 1 || *u.AccountType > 3) {
+		err = fmt.Errorf(accountTypeError)
+		return
+	}
+
+	if u.LowScore != nil && u.HighScore != nil && *u.HighScore > 0 && *u.LowScore >= *u.HighScore {
+		err = fmt.Errorf(scoreError)
+		return
+	}
+
+	for _, a := range row.Aliases {
+		if !isAddress(a.Address) {
+			err = fmt.Errorf(aliasError, a.Address)
+			return
+		}
+	}
+
+	return
+}
+
+// Import validates the rows and creates the valid ones. Results are
+// returned in the same order as rows.
+func (i *Importer) Import(rows []Row) (results []Result) {
+	var wg sync.WaitGroup
+	var seen map[string]int
+	var jobs chan int
+
+	results = make([]Result, len(rows))
+	seen = make(map[string]int, len(rows))
+	jobs = make(chan int)
+
+	for n := range rows {
+		results[n] = Result{
+			Line:     rows[n].Line,
+			Username: deref(rows[n].User.Username),
+		}
+		if results[n].Err = Validate(&rows[n]); results[n].Err != nil {
+			continue
+		}
+		name := strings.ToLower(results[n].Username)
+		if line, ok := seen[name]; ok {
+			results[n].Err = fmt.Errorf(duplicateError, results[n].Username, line)
+			continue
+		}
+		seen[name] = rows[n].Line
+	}
+
+	for w := 0; w < i.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				i.importRow(&rows[n], &results[n])
+			}
+		}()
+	}
+
+	for n := range rows {
+		if results[n].Err == nil {
+			jobs <- n
+		}
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return
+}
+
+func (i *Importer) importRow(row *Row, result *Result) {
+	var u *api.User
+
+	if u, result.Err = i.client.CreateUser(&row.User); result.Err != nil {
+		return
+	}
+
+	result.UserID = u.ID
+
+	for n := range row.Aliases {
+		alias := row.Aliases[n]
+		if result.Err = i.client.CreateAliasAddress(u.ID, &alias); result.Err != nil {
+			result.Err = fmt.Errorf("alias %s: %s", alias.Address, result.Err)
+			return
+		}
+		result.Aliases++
+	}
+}
+
+// WriteReport writes the per row results to w as CSV
+func WriteReport(w io.Writer, results []Result) (err error) {
+	cw := csv.NewWriter(w)
+
+	if err = cw.Write([]string{"line", "username", "status", "user_id", "aliases", "error"}); err != nil {
+		return
+	}
+
+	for _, r := range results {
+		status, msg := "ok", ""
+		if !r.OK() {
+			status, msg = "failed", r.Err.Error()
+		}
+		if err = cw.Write([]string{
+			strconv.Itoa(r.Line),
+			r.Username,
+			status,
+			strconv.Itoa(r.UserID),
+			strconv.Itoa(r.Aliases),
+			msg,
+		}); err != nil {
+			return
+		}
+	}
+
+	cw.Flush()
+	err = cw.Error()
+
+	return
+}
+
+func isAddress(s string) bool {
+	a, err := mail.ParseAddress(s)
+
+	return err == nil && a.Address == s && strings.Contains(s, "@")
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,150 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeClient struct {
+	sync.Mutex
+	users    []api.User
+	failUser string
+	failAddr string
+}
+
+func (f *fakeClient) CreateUser(form *api.UserForm) (u *api.User, err error) {
+	f.Lock()
+	defer f.Unlock()
+	if *form.Username == f.failUser {
+		err = fmt.Errorf("400 Bad Request")
+		return
+	}
+	u = &api.User{
+		ID:       len(f.users) + 1,
+		Username: *form.Username,
+		Email:    *form.Email,
+	}
+	for _, d := range form.Domains {
+		u.Domains = append(u.Domains, api.UserDomain{ID: d})
+	}
+	f.users = append(f.users, *u)
+	return
+}
+
+func (f *fakeClient) CreateAliasAddress(userID int, alias *api.AliasAddress) (err error) {
+	f.Lock()
+	defer f.Unlock()
+	if alias.Address == f.failAddr {
+		err = fmt.Errorf("400 Bad Request")
+		return
+	}
+	alias.ID = userID*100 + len(f.users[userID-1].Addresses)
+	f.users[userID-1].Addresses = append(f.users[userID-1].Addresses, api.UserAddress{
+		ID:      alias.ID,
+		Address: alias.Address,
+		Enabled: alias.Enabled,
+	})
+	return
+}
+
+func (f *fakeClient) GetUsers(opts *api.ListOptions) (l *api.UserList, err error) {
+	l = &api.UserList{Items: f.users}
+	return
+}
+
+func TestValidate(t *testing.T) {
+	s := func(v string) *string { return &v }
+	i := func(v int) *int { return &v }
+	f := func(v float64) *api.LocalFloat64 { lf := api.LocalFloat64(v); return &lf }
+	tests := []struct {
+		row      Row
+		expected string
+	}{
+		{Row{Err: fmt.Errorf("bad")}, "bad"},
+		{Row{User: api.UserForm{}}, usernameError},
+		{Row{User: api.UserForm{Username: s("a")}}, fmt.Sprintf(emailError, "")},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a")}}, fmt.Sprintf(emailError, "a")},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a@example.com"), Password1: s("x")}}, passwordError},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a@example.com"), AccountType: i(4)}}, accountTypeError},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a@example.com"), LowScore: f(5), HighScore: f(2)}}, scoreError},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a@example.com")}, Aliases: []api.AliasAddress{{Address: "info"}}}, fmt.Sprintf(aliasError, "info")},
+		{Row{User: api.UserForm{Username: s("a"), Email: s("a@example.com"), LowScore: f(5)}}, ""},
+	}
+	for _, tt := range tests {
+		err := Validate(&tt.row)
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("An error should not be returned: %s", err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("Expected '%s' got '%v'", tt.expected, err)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	data := `username,email,domains,aliases
+andrew,andrew@example.com,2,info@example.com;sales@example.com
+bob,bob@example.com,2,
+carol,carol@example.com,2,bad@example.com
+Andrew,andrew2@example.com,2,
+dave,dave,2,
+erin,erin@example.com,2,
+`
+	rows, err := ReadCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	c := &fakeClient{
+		failUser: "bob",
+		failAddr: "bad@example.com",
+	}
+	results := NewImporter(c, 0).Import(rows)
+	if len(results) != 6 {
+		t.Fatalf("Expected %d got %d", 6, len(results))
+	}
+	expected := []bool{true, false, false, false, false, true}
+	for n, ok := range expected {
+		if results[n].OK() != ok {
+			t.Errorf("Expected %t got %t for line %d: %v", ok, results[n].OK(), results[n].Line, results[n].Err)
+		}
+	}
+	if results[0].Aliases != 2 || results[0].UserID == 0 {
+		t.Errorf("Expected %d got %d", 2, results[0].Aliases)
+	}
+	dup := fmt.Sprintf(duplicateError, "Andrew", 2)
+	if results[3].Err.Error() != dup {
+		t.Errorf("Expected '%s' got '%s'", dup, results[3].Err)
+	}
+	if len(c.users) != 3 {
+		t.Errorf("Expected %d got %d", 3, len(c.users))
+	}
+	buf := &bytes.Buffer{}
+	if err = WriteReport(buf, results); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected %d got %d", 7, len(lines))
+	}
+	if lines[0] != "line,username,status,user_id,aliases,error" {
+		t.Errorf("Expected header got '%s'", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], "3,bob,failed,0,0,") {
+		t.Errorf("Expected failed row got '%s'", lines[2])
+	}
+}
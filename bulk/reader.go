@@ -0,0 +1,270 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type jsonRow struct {
+	api.UserForm
+	Password string      `json:"password,omitempty"`
+	Aliases  []jsonAlias `json:"aliases,omitempty"`
+}
+
+type jsonAlias api.AliasAddress
+
+// UnmarshalJSON accepts an address, which is enabled, or an object
+// with the address and enabled fields
+func (a *jsonAlias) UnmarshalJSON(b []byte) (err error) {
+	var addr string
+	var v struct {
+		Address string `json:"address"`
+		Enabled *bool  `json:"enabled"`
+	}
+
+	if err = json.Unmarshal(b, &addr); err == nil {
+		*a = jsonAlias{Address: addr, Enabled: true}
+		return
+	}
+
+	if err = json.Unmarshal(b, &v); err != nil {
+		return
+	}
+
+	*a = jsonAlias{Address: v.Address, Enabled: v.Enabled == nil || *v.Enabled}
+
+	return
+}
+
+// Read reads rows in the given format
+func Read(r io.Reader, format Format) (rows []Row, err error) {
+	switch format {
+	case CSV:
+		rows, err = ReadCSV(r)
+	case JSON:
+		rows, err = ReadJSON(r)
+	default:
+		err = fmt.Errorf(formatError)
+	}
+
+	return
+}
+
+// ReadCSV reads rows from CSV with a header row. Rows with values
+// that cannot be parsed are returned with Err set.
+func ReadCSV(r io.Reader) (rows []Row, err error) {
+	var line int
+	var rec []string
+	var header map[string]int
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	if rec, err = cr.Read(); err != nil {
+		if err == io.EOF {
+			err = fmt.Errorf(headerError)
+		}
+		return
+	}
+
+	header = make(map[string]int, len(rec))
+	for i, col := range rec {
+		header[strings.ToLower(strings.TrimSpace(col))] = i
+	}
+
+	for _, col := range []string{"username", "email"} {
+		if _, ok := header[col]; !ok {
+			err = fmt.Errorf(columnError, col)
+			return
+		}
+	}
+
+	line = 1
+	for {
+		if rec, err = cr.Read(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		line++
+
+		row := Row{Line: line}
+		row.Err = parseRecord(&row, header, rec)
+		rows = append(rows, row)
+	}
+}
+
+// ReadJSON reads rows from a JSON array
+func ReadJSON(r io.Reader) (rows []Row, err error) {
+	var items []jsonRow
+
+	if err = json.NewDecoder(r).Decode(&items); err != nil {
+		return
+	}
+
+	rows = make([]Row, len(items))
+	for i, item := range items {
+		rows[i] = Row{
+			Line: i + 1,
+			User: item.UserForm,
+		}
+		if item.Password != "" {
+			pw := item.Password
+			rows[i].User.Password1 = &pw
+			rows[i].User.Password2 = &pw
+		}
+		for _, a := range item.Aliases {
+			if a.Address = strings.TrimSpace(a.Address); a.Address != "" {
+				rows[i].Aliases = append(rows[i].Aliases, api.AliasAddress(a))
+			}
+		}
+	}
+
+	return
+}
+
+func parseRecord(row *Row, header map[string]int, rec []string) (err error) {
+	var i int
+	var b bool
+	var f float64
+
+	value := func(col string) (string, bool) {
+		idx, found := header[col]
+		if !found || idx >= len(rec) {
+			return "", false
+		}
+		v := strings.TrimSpace(rec[idx])
+		return v, v != ""
+	}
+
+	u := &row.User
+
+	for _, c := range []struct {
+		col string
+		dst **string
+	}{
+		{"username", &u.Username},
+		{"firstname", &u.Firstname},
+		{"lastname", &u.Lastname},
+		{"email", &u.Email},
+		{"timezone", &u.Timezone},
+	} {
+		if v, ok := value(c.col); ok {
+			s := v
+			*c.dst = &s
+		}
+	}
+
+	if v, ok := value("password"); ok {
+		pw := v
+		u.Password1 = &pw
+		u.Password2 = &pw
+	}
+
+	if v, ok := value("account_type"); ok {
+		if i, err = strconv.Atoi(v); err != nil {
+			err = fmt.Errorf(valueError, "account_type", v)
+			return
+		}
+		at := i
+		u.AccountType = &at
+	}
+
+	for _, c := range []struct {
+		col string
+		dst **bool
+	}{
+		{"active", &u.Enabled},
+		{"send_report", &u.SendReport},
+		{"spam_checks", &u.SpamChecks},
+		{"block_macros", &u.BlockMacros},
+	} {
+		if v, ok := value(c.col); ok {
+			if b, err = strconv.ParseBool(v); err != nil {
+				err = fmt.Errorf(valueError, c.col, v)
+				return
+			}
+			bv := b
+			*c.dst = &bv
+		}
+	}
+
+	for _, c := range []struct {
+		col string
+		dst **api.LocalFloat64
+	}{
+		{"low_score", &u.LowScore},
+		{"high_score", &u.HighScore},
+	} {
+		if v, ok := value(c.col); ok {
+			if f, err = strconv.ParseFloat(v, 64); err != nil {
+				err = fmt.Errorf(valueError, c.col, v)
+				return
+			}
+			lf := api.LocalFloat64(f)
+			*c.dst = &lf
+		}
+	}
+
+	for _, c := range []struct {
+		col string
+		dst *[]int
+	}{
+		{"domains", &u.Domains},
+		{"organizations", &u.Organizations},
+	} {
+		if v, ok := value(c.col); ok {
+			for _, s := range strings.Split(v, listSep) {
+				if s = strings.TrimSpace(s); s == "" {
+					continue
+				}
+				if i, err = strconv.Atoi(s); err != nil {
+					err = fmt.Errorf(valueError, c.col, v)
+					return
+				}
+				*c.dst = append(*c.dst, i)
+			}
+		}
+	}
+
+	if v, ok := value("aliases"); ok {
+		row.Aliases = aliasesFrom(strings.Split(v, listSep))
+	}
+
+	return
+}
+
+// aliasesFrom parses the CSV alias addresses, those prefixed with
+// disabledPrefix are disabled
+func aliasesFrom(addrs []string) (aliases []api.AliasAddress) {
+	for _, a := range addrs {
+		if a = strings.TrimSpace(a); a == "" {
+			continue
+		}
+		enabled := !strings.HasPrefix(a, disabledPrefix)
+		if !enabled {
+			a = strings.TrimSpace(strings.TrimPrefix(a, disabledPrefix))
+		}
+		aliases = append(aliases, api.AliasAddress{
+			Address: a,
+			Enabled: enabled,
+		})
+	}
+
+	return
+}
@@ -0,0 +1,134 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package bulk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVError(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader(""))
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != headerError {
+		t.Errorf("Expected '%s' got '%s'", headerError, err)
+	}
+	_, err = ReadCSV(strings.NewReader("username,firstname\n"))
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	expected := fmt.Sprintf(columnError, "email")
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+	_, err = Read(strings.NewReader(""), Format(9))
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != formatError {
+		t.Errorf("Expected '%s' got '%s'", formatError, err)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	data := `username,email,password,account_type,active,low_score,high_score,domains,aliases
+andrew,andrew@example.com,s3cr3t,3,true,1.5,10,2;4,info@example.com; sales@example.com
+bob,bob@example.com,,x,,,,,
+carol,carol@example.com,,,yes,,,,
+dave,dave@example.com,,,,abc,,,
+erin,erin@example.com,,,,,,2;x,
+`
+	rows, err := Read(strings.NewReader(data), CSV)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("Expected %d got %d", 5, len(rows))
+	}
+	r := rows[0]
+	if r.Err != nil {
+		t.Fatalf("An error should not be returned: %s", r.Err)
+	}
+	if r.Line != 2 {
+		t.Errorf("Expected %d got %d", 2, r.Line)
+	}
+	if *r.User.Username != "andrew" || *r.User.Email != "andrew@example.com" {
+		t.Errorf("Expected %s got %s", "andrew", *r.User.Username)
+	}
+	if *r.User.Password1 != "s3cr3t" || *r.User.Password2 != "s3cr3t" {
+		t.Errorf("Expected %s got %s", "s3cr3t", *r.User.Password1)
+	}
+	if *r.User.AccountType != 3 || !*r.User.Enabled {
+		t.Errorf("Expected %d got %d", 3, *r.User.AccountType)
+	}
+	if r.User.LowScore.String() != "1.5" {
+		t.Errorf("Expected %s got %s", "1.5", r.User.LowScore)
+	}
+	if len(r.User.Domains) != 2 || r.User.Domains[1] != 4 {
+		t.Errorf("Expected %v got %v", []int{2, 4}, r.User.Domains)
+	}
+	if len(r.Aliases) != 2 || r.Aliases[1].Address != "sales@example.com" {
+		t.Errorf("Expected %d got %v", 2, r.Aliases)
+	}
+	if rows[1].User.Firstname != nil {
+		t.Errorf("Expected %v got %v", nil, rows[1].User.Firstname)
+	}
+	for _, n := range []int{1, 2, 3, 4} {
+		if rows[n].Err == nil {
+			t.Errorf("An error should be returned for line %d", rows[n].Line)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	data := `[
+		{
+			"username": "andrew",
+			"email": "andrew@example.com",
+			"password": "s3cr3t",
+			"account_type": 3,
+			"active": true,
+			"low_score": 1.5,
+			"domains": [2],
+			"aliases": ["info@example.com"]
+		},
+		{
+			"username": "bob",
+			"email": "bob@example.com",
+			"password1": "a",
+			"password2": "b"
+		}
+	]`
+	rows, err := Read(strings.NewReader(data), JSON)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(rows))
+	}
+	r := rows[0]
+	if *r.User.Password1 != "s3cr3t" || *r.User.Password2 != "s3cr3t" {
+		t.Errorf("Expected %s got %s", "s3cr3t", *r.User.Password1)
+	}
+	if r.User.LowScore.String() != "1.5" {
+		t.Errorf("Expected %s got %s", "1.5", r.User.LowScore)
+	}
+	if len(r.Aliases) != 1 || !r.Aliases[0].Enabled {
+		t.Errorf("Expected %d got %v", 1, r.Aliases)
+	}
+	if *rows[1].User.Password2 != "b" {
+		t.Errorf("Expected %s got %s", "b", *rows[1].User.Password2)
+	}
+	_, err = ReadJSON(strings.NewReader("{"))
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
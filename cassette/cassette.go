@@ -0,0 +1,122 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package cassette Record and replay HTTP interactions with a Baruwa server
+
+A Recorder is an http.RoundTripper that either captures the requests
+and responses made through it into a cassette file, with credentials
+redacted, or replays a cassette file without any network access so
+that tests are deterministic.
+
+	rec, err := cassette.New("testdata/fixtures/TestGetDomain.json", cassette.ModeReplay, nil)
+	c, err := api.New(endpoint, token, &api.Options{HTTPClient: rec.HTTPClient()})
+	...
+	err = rec.Stop()
+*/
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Request holds a recorded request
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response holds a recorded response, JSON bodies are stored as JSON
+// so that the cassette files are readable
+type Response struct {
+	Code    int             `json:"code"`
+	Headers http.Header     `json:"headers,omitempty"`
+	JSON    json.RawMessage `json:"json,omitempty"`
+	Body    string          `json:"body,omitempty"`
+}
+
+// Interaction holds a request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette holds recorded interactions
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load reads a cassette file
+func Load(path string) (c *Cassette, err error) {
+	var data []byte
+
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+
+	c = &Cassette{}
+	if err = json.Unmarshal(data, c); err != nil {
+		c = nil
+	}
+
+	return
+}
+
+// Save writes a cassette file, creating the directory if required
+func (c *Cassette) Save(path string) (err error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(c); err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+
+	err = ioutil.WriteFile(path, buf.Bytes(), 0644)
+
+	return
+}
+
+// body returns the response body, JSON bodies are compacted as the
+// cassette files are indented
+func (r *Response) body() []byte {
+	var buf bytes.Buffer
+
+	if len(r.JSON) > 0 {
+		if err := json.Compact(&buf, r.JSON); err != nil {
+			return r.JSON
+		}
+		return buf.Bytes()
+	}
+
+	return []byte(r.Body)
+}
+
+// setBody stores a response body, compacting valid JSON
+func (r *Response) setBody(data []byte) {
+	var buf bytes.Buffer
+
+	if len(bytes.TrimSpace(data)) > 0 && json.Valid(data) {
+		json.Compact(&buf, data)
+		r.JSON = buf.Bytes()
+		return
+	}
+
+	r.Body = string(data)
+}
@@ -0,0 +1,239 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cassette
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func getTestServer(hits *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		switch r.URL.Path {
+		case "/api/v1/status":
+			fmt.Fprint(w, `{"inbound": 1, "outbound": 2}`)
+		case "/api/v1/users":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 1, "username": "andrew", "password": "s3cret", "settings": [{"bindpw": "pw"}]}`)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "Not Found")
+		}
+	}))
+}
+
+func doRequests(t *testing.T, c *http.Client, base string) []string {
+	var bodies []string
+
+	req, _ := http.NewRequest(http.MethodGet, base+"/api/v1/status", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("User-Agent", "test")
+	post, _ := http.NewRequest(http.MethodPost, base+"/api/v1/users", strings.NewReader("username=andrew&password1=s3cret"))
+	post.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	missing, _ := http.NewRequest(http.MethodGet, base+"/api/v1/missing?page=2", nil)
+
+	for _, r := range []*http.Request{req, post, missing} {
+		resp, err := c.Do(r)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		data, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		bodies = append(bodies, fmt.Sprintf("%d %s", resp.StatusCode, data))
+	}
+
+	return bodies
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New("", ModeReplay, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != pathParamError {
+		t.Errorf("Expected '%s' got '%s'", pathParamError, err)
+	}
+	_, err = New(filepath.Join(tempDir(t), "missing.json"), ModeReplay, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	hits := 0
+	server := getTestServer(&hits)
+	defer server.Close()
+
+	path := filepath.Join(tempDir(t), "fixtures", "TestRecordReplay.json")
+	rec, err := New(path, ModeAuto, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if rec.Mode() != ModeRecord {
+		t.Fatalf("Expected %d got %d", ModeRecord, rec.Mode())
+	}
+	recorded := doRequests(t, rec.HTTPClient(), server.URL)
+	if err = rec.Stop(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected %d got %d", 3, hits)
+	}
+	if !strings.Contains(recorded[1], "s3cret") {
+		t.Errorf("Expected the live response to be returned unchanged got %s", recorded[1])
+	}
+
+	rec, err = New(path, ModeAuto, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if rec.Mode() != ModeReplay {
+		t.Fatalf("Expected %d got %d", ModeReplay, rec.Mode())
+	}
+	replayed := doRequests(t, rec.HTTPClient(), "http://baruwa.example.com")
+	if err = rec.Stop(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected %d got %d", 3, hits)
+	}
+	if replayed[0] != `200 {"inbound":1,"outbound":2}` {
+		t.Errorf("Expected %s got %s", `200 {"inbound":1,"outbound":2}`, replayed[0])
+	}
+	if replayed[2] != "404 Not Found" {
+		t.Errorf("Expected %s got %s", "404 Not Found", replayed[2])
+	}
+	if !strings.HasPrefix(replayed[1], "201 ") || strings.Contains(replayed[1], "s3cret") {
+		t.Errorf("Expected a redacted 201 response got %s", replayed[1])
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	hits := 0
+	server := getTestServer(&hits)
+	defer server.Close()
+
+	path := filepath.Join(tempDir(t), "TestRedaction.json")
+	rec, err := New(path, ModeRecord, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	rec.Redact("Username")
+	doRequests(t, rec.HTTPClient(), server.URL)
+	if err = rec.Stop(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, secret := range []string{"test-token", "s3cret", "andrew", "session=abc", `"pw"`, "User-Agent"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %s to be removed from the cassette", secret)
+		}
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Fatalf("Expected %d got %d", 3, len(c.Interactions))
+	}
+	in := c.Interactions[0]
+	if in.Request.Headers.Get("Authorization") != Redacted {
+		t.Errorf("Expected %s got %s", Redacted, in.Request.Headers.Get("Authorization"))
+	}
+	if in.Response.Headers.Get("Date") != "" {
+		t.Errorf("Expected the Date header to be dropped")
+	}
+	if b := in.Response.body(); string(b) != `{"inbound":1,"outbound":2}` {
+		t.Errorf("Expected %s got %s", `{"inbound":1,"outbound":2}`, b)
+	}
+	if b := c.Interactions[1].Request.Body; b != "password1=REDACTED&username=REDACTED" {
+		t.Errorf("Expected %s got %s", "password1=REDACTED&username=REDACTED", b)
+	}
+	if b := c.Interactions[2].Response.Body; b != "Not Found" {
+		t.Errorf("Expected %s got %s", "Not Found", b)
+	}
+}
+
+func TestReplayErrors(t *testing.T) {
+	path := filepath.Join(tempDir(t), "TestReplayErrors.json")
+	c := &Cassette{
+		Interactions: []Interaction{
+			{
+				Request:  Request{Method: http.MethodGet, URL: "/api/v1/status"},
+				Response: Response{Code: http.StatusOK, JSON: []byte(`{"inbound":1}`)},
+			},
+			{
+				Request:  Request{Method: http.MethodGet, URL: "/api/v1/status"},
+				Response: Response{Code: http.StatusOK, JSON: []byte(`{"inbound":2}`)},
+			},
+		},
+	}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	rec, err := New(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	client := rec.HTTPClient()
+
+	resp, err := client.Get("http://baruwa.example.com/api/v1/status")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != `{"inbound":1}` {
+		t.Errorf("Expected %s got %s", `{"inbound":1}`, data)
+	}
+
+	_, err = client.Get("http://baruwa.example.com/api/v1/domains")
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	expected := fmt.Sprintf(noMatchError, http.MethodGet, "/api/v1/domains")
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+
+	err = rec.Stop()
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	expected = fmt.Sprintf(replayStopError, 1)
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+}
@@ -0,0 +1,234 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Mode is the mode of a Recorder
+type Mode int
+
+const (
+	// ModeReplay replays the cassette, requests not in the cassette fail
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the server and records them
+	ModeRecord
+	// ModeAuto replays the cassette if it exists, otherwise records it
+	ModeAuto
+)
+
+const (
+	pathParamError  = "The path param is required"
+	noMatchError    = "cassette: no interaction recorded for %s %s"
+	replayStopError = "cassette: %d interactions were not replayed"
+)
+
+// Recorder records or replays HTTP interactions
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+	cassette  *Cassette
+	used      []bool
+	redactor  *redactor
+	mu        sync.Mutex
+}
+
+// New returns a Recorder for the cassette file at path, the transport
+// is used when recording and defaults to http.DefaultTransport
+func New(path string, mode Mode, transport http.RoundTripper) (r *Recorder, err error) {
+	if path == "" {
+		err = fmt.Errorf(pathParamError)
+		return
+	}
+
+	if mode == ModeAuto {
+		mode = ModeRecord
+		if _, err = os.Stat(path); err == nil {
+			mode = ModeReplay
+		}
+		err = nil
+	}
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	r = &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		redactor:  newRedactor(DefaultHeaders, DefaultFields),
+	}
+
+	if mode == ModeReplay {
+		if r.cassette, err = Load(path); err != nil {
+			r = nil
+			return
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+		return
+	}
+
+	r.cassette = &Cassette{}
+
+	return
+}
+
+// Mode returns the mode of the recorder
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Redact adds form and JSON fields to redact when recording
+func (r *Recorder) Redact(fields ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range fields {
+		r.redactor.fields[strings.ToLower(f)] = true
+	}
+}
+
+// HTTPClient returns an http.Client using the recorder
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	var body []byte
+
+	if req.Body != nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.mode == ModeReplay {
+		resp, err = r.replay(req, body)
+		return
+	}
+
+	resp, err = r.record(req, body)
+
+	return
+}
+
+// Stop saves the cassette when recording, when replaying it returns
+// an error if some interactions were not replayed
+func (r *Recorder) Stop() (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mode == ModeRecord {
+		err = r.cassette.Save(r.path)
+		return
+	}
+
+	unused := 0
+	for _, u := range r.used {
+		if !u {
+			unused++
+		}
+	}
+
+	if unused > 0 {
+		err = fmt.Errorf(replayStopError, unused)
+	}
+
+	return
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (resp *http.Response, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u := req.URL.RequestURI()
+	b := r.redactor.form(string(body))
+
+	for i := range r.cassette.Interactions {
+		in := &r.cassette.Interactions[i]
+		if r.used[i] || in.Request.Method != req.Method || in.Request.URL != u || in.Request.Body != b {
+			continue
+		}
+		r.used[i] = true
+		data := in.Response.body()
+		resp = &http.Response{
+			Status:        strconv.Itoa(in.Response.Code) + " " + http.StatusText(in.Response.Code),
+			StatusCode:    in.Response.Code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Headers.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(data)),
+			ContentLength: int64(len(data)),
+			Request:       req,
+		}
+		if resp.Header == nil {
+			resp.Header = make(http.Header)
+		}
+		return
+	}
+
+	err = fmt.Errorf(noMatchError, req.Method, u)
+
+	return
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (resp *http.Response, err error) {
+	var data []byte
+
+	if resp, err = r.transport.RoundTrip(req); err != nil {
+		return
+	}
+
+	data, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	in := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: r.redactor.header(req.Header, requestHeaders),
+			Body:    r.redactor.form(string(body)),
+		},
+		Response: Response{
+			Code:    resp.StatusCode,
+			Headers: r.redactor.header(resp.Header, nil),
+		},
+	}
+
+	in.Response.setBody(r.redactor.json(data))
+	in.Response.Headers.Del("Date")
+	in.Response.Headers.Del("Content-Length")
+	if len(in.Response.Headers) == 0 {
+		in.Response.Headers = nil
+	}
+
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+
+	return
+}
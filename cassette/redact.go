@@ -0,0 +1,173 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces secrets in recorded interactions
+const Redacted = "REDACTED"
+
+// DefaultHeaders are the headers redacted by default
+var DefaultHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// DefaultFields are the form and JSON fields redacted by default
+var DefaultFields = []string{
+	"password",
+	"password1",
+	"password2",
+	"bindpw",
+	"secret",
+	"client_secret",
+	"access_token",
+	"refresh_token",
+}
+
+// recorded request headers, the rest are dropped as they add noise
+var requestHeaders = []string{
+	"Accept",
+	"Authorization",
+	"Content-Type",
+	"If-None-Match",
+	"If-Modified-Since",
+}
+
+type redactor struct {
+	headers map[string]bool
+	fields  map[string]bool
+}
+
+func newRedactor(headers, fields []string) *redactor {
+	r := &redactor{
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	for _, f := range fields {
+		r.fields[strings.ToLower(f)] = true
+	}
+
+	return r
+}
+
+func (r *redactor) header(h http.Header, keep []string) (out http.Header) {
+	for k, v := range h {
+		k = http.CanonicalHeaderKey(k)
+		if keep != nil && !contains(keep, k) {
+			continue
+		}
+		if out == nil {
+			out = make(http.Header)
+		}
+		if r.headers[k] {
+			out[k] = []string{Redacted}
+			continue
+		}
+		out[k] = append([]string(nil), v...)
+	}
+
+	return
+}
+
+// form redacts the fields of a form encoded body, other bodies are
+// returned unchanged
+func (r *redactor) form(body string) string {
+	if body == "" || strings.ContainsAny(body, " \n{") {
+		return body
+	}
+
+	v, err := url.ParseQuery(body)
+	if err != nil {
+		return body
+	}
+
+	changed := false
+	for k := range v {
+		if r.fields[strings.ToLower(k)] {
+			v[k] = []string{Redacted}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return body
+	}
+
+	return v.Encode()
+}
+
+// json redacts the fields of a JSON body at any depth
+func (r *redactor) json(data []byte) []byte {
+	var v interface{}
+	var buf bytes.Buffer
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+
+	if !r.value(v) {
+		return data
+	}
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return data
+	}
+
+	return bytes.TrimSpace(buf.Bytes())
+}
+
+func (r *redactor) value(v interface{}) (changed bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if r.fields[strings.ToLower(k)] {
+				if s, ok := val.(string); ok && s != "" {
+					t[k] = Redacted
+					changed = true
+				}
+				continue
+			}
+			if r.value(val) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, val := range t {
+			if r.value(val) {
+				changed = true
+			}
+		}
+	}
+
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
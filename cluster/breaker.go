@@ -0,0 +1,130 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cluster
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// Closed requests are sent to the node
+	Closed State = iota
+	// Open requests are not sent to the node until the cooldown expires
+	Open
+	// HalfOpen a single trial request is sent to the node
+	HalfOpen
+)
+
+var stateNames = map[State]string{
+	Closed:   "closed",
+	Open:     "open",
+	HalfOpen: "half-open",
+}
+
+func (s State) String() string {
+	return stateNames[s]
+}
+
+// Breaker is a circuit breaker, it opens after Threshold consecutive
+// failures and allows a trial request once Cooldown has passed
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+	state     State
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// NewBreaker returns a closed Breaker
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+
+	return &Breaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.update()
+
+	return b.state
+}
+
+// Allow reports whether a request may be sent, in the half-open state
+// only one trial request is allowed until its result is recorded
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.update()
+
+	switch b.state {
+	case Closed:
+		return true
+	case HalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+
+	return false
+}
+
+// Success records a successful request and closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = Closed
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records a failed request, the breaker opens when the
+// threshold is reached or when a trial request fails
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.update()
+	b.failures++
+	b.trial = false
+
+	if b.state == HalfOpen || b.failures >= b.Threshold {
+		b.state = Open
+		b.openedAt = b.now()
+	}
+}
+
+func (b *Breaker) update() {
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.Cooldown {
+		b.state = HalfOpen
+		b.trial = false
+	}
+}
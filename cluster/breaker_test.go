@@ -0,0 +1,77 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time {
+		return now
+	}
+
+	if !b.Allow() || b.State() != Closed {
+		t.Fatalf("Expected %s got %s", Closed, b.State())
+	}
+	b.Failure()
+	if b.State() != Closed {
+		t.Errorf("Expected %s got %s", Closed, b.State())
+	}
+	b.Failure()
+	if b.State() != Open {
+		t.Fatalf("Expected %s got %s", Open, b.State())
+	}
+	if b.Allow() {
+		t.Errorf("Expected requests to be blocked while open")
+	}
+
+	now = now.Add(time.Minute)
+	if b.State() != HalfOpen {
+		t.Fatalf("Expected %s got %s", HalfOpen, b.State())
+	}
+	if !b.Allow() {
+		t.Errorf("Expected a trial request to be allowed")
+	}
+	if b.Allow() {
+		t.Errorf("Expected a single trial request to be allowed")
+	}
+	b.Failure()
+	if b.State() != Open {
+		t.Fatalf("Expected %s got %s", Open, b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.Allow() {
+		t.Fatalf("Expected a trial request to be allowed")
+	}
+	b.Success()
+	if b.State() != Closed {
+		t.Errorf("Expected %s got %s", Closed, b.State())
+	}
+	b.Failure()
+	if b.State() != Closed {
+		t.Errorf("Expected %s got %s", Closed, b.State())
+	}
+}
+
+func TestBreakerDefaults(t *testing.T) {
+	b := NewBreaker(0, 0)
+	if b.Threshold != DefaultThreshold {
+		t.Errorf("Expected %d got %d", DefaultThreshold, b.Threshold)
+	}
+	if b.Cooldown != DefaultCooldown {
+		t.Errorf("Expected %s got %s", DefaultCooldown, b.Cooldown)
+	}
+	if HalfOpen.String() != "half-open" {
+		t.Errorf("Expected %s got %s", "half-open", HalfOpen)
+	}
+}
@@ -0,0 +1,312 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package cluster Baruwa API client for primary and standby clusters
+
+A Cluster holds several Baruwa endpoints, each with its own token and
+circuit breaker. The api.Client it returns sends writes to the primary
+and spreads reads over the healthy nodes, failing over to the next node
+when a connection error occurs. Nodes are health checked using the
+system status endpoint.
+
+	c, err := cluster.New([]cluster.Node{
+		{Name: "primary", Endpoint: "https://baruwa1.example.com", Token: t1, Primary: true},
+		{Name: "standby", Endpoint: "https://baruwa2.example.com", Token: t2},
+	}, nil)
+	c.Start()
+	defer c.Stop()
+	domains, err := c.Client().GetDomains(nil)
+*/
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	// DefaultThreshold is the number of consecutive failures that open
+	// the circuit breaker of a node
+	DefaultThreshold = 3
+	// DefaultCooldown is how long a breaker stays open before a trial
+	// request is sent to the node
+	DefaultCooldown = 30 * time.Second
+	// DefaultInterval is the health check interval used by Start
+	DefaultInterval = 10 * time.Second
+)
+
+const (
+	nodesParamError    = "The nodes param is required"
+	nodeEndpointError  = "The endpoint of node %d is required"
+	primaryNodeError   = "Only one node can be the primary"
+	noHealthyNodeError = "cluster: no healthy nodes available for %s %s"
+	unhealthyNodeError = "The node %s reports an unhealthy status"
+)
+
+// Node is a Baruwa API endpoint
+type Node struct {
+	Name     string
+	Endpoint string
+	Token    string
+	Primary  bool
+}
+
+// Options represents optional settings that can be passed to New
+type Options struct {
+	// HTTPClient supplies the transport and timeout used to reach the nodes
+	HTTPClient *http.Client
+	// UserAgent for the API clients
+	UserAgent string
+	// Threshold defaults to DefaultThreshold
+	Threshold int
+	// Cooldown defaults to DefaultCooldown
+	Cooldown time.Duration
+	// Interval defaults to DefaultInterval
+	Interval time.Duration
+}
+
+// NodeStatus holds the health of a node
+type NodeStatus struct {
+	Name    string
+	Primary bool
+	State   State
+	Status  *api.SystemStatus
+	Checked time.Time
+	Err     error
+}
+
+// Healthy reports whether requests are sent to the node
+func (s NodeStatus) Healthy() bool {
+	return s.State != Open
+}
+
+type node struct {
+	Node
+	url     *url.URL
+	client  *api.Client
+	breaker *Breaker
+	status  *api.SystemStatus
+	checked time.Time
+	err     error
+	mu      sync.Mutex
+}
+
+// Cluster routes API requests to a set of nodes
+type Cluster struct {
+	nodes     []*node
+	primary   int
+	next      int
+	interval  time.Duration
+	transport http.RoundTripper
+	client    *api.Client
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+}
+
+// New returns a Cluster for the nodes, the first node is the primary
+// unless another node is marked as Primary
+func New(nodes []Node, opts *Options) (c *Cluster, err error) {
+	var ua string
+	var hc *http.Client
+	var timeout time.Duration
+
+	if len(nodes) == 0 {
+		err = fmt.Errorf(nodesParamError)
+		return
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	c = &Cluster{
+		interval:  opts.Interval,
+		transport: http.DefaultTransport,
+	}
+
+	if c.interval <= 0 {
+		c.interval = DefaultInterval
+	}
+
+	if opts.HTTPClient != nil {
+		timeout = opts.HTTPClient.Timeout
+		if opts.HTTPClient.Transport != nil {
+			c.transport = opts.HTTPClient.Transport
+		}
+	}
+
+	ua = opts.UserAgent
+	primaries := 0
+	for i, n := range nodes {
+		if n.Endpoint == "" {
+			c, err = nil, fmt.Errorf(nodeEndpointError, i)
+			return
+		}
+		if n.Primary {
+			primaries++
+			c.primary = i
+		}
+		if n.Name == "" {
+			n.Name = n.Endpoint
+		}
+		nd := &node{
+			Node:    n,
+			breaker: NewBreaker(opts.Threshold, opts.Cooldown),
+		}
+		if nd.url, err = url.Parse(n.Endpoint); err != nil {
+			c = nil
+			return
+		}
+		hc = &http.Client{Transport: c.transport, Timeout: timeout}
+		if nd.client, err = api.New(n.Endpoint, n.Token, &api.Options{HTTPClient: hc, UserAgent: ua}); err != nil {
+			c = nil
+			return
+		}
+		c.nodes = append(c.nodes, nd)
+	}
+
+	if primaries > 1 {
+		c, err = nil, fmt.Errorf(primaryNodeError)
+		return
+	}
+
+	c.nodes[c.primary].Primary = true
+
+	p := c.nodes[c.primary]
+	hc = &http.Client{Transport: &transport{cluster: c}, Timeout: timeout}
+	if c.client, err = api.New(p.Endpoint, p.Token, &api.Options{HTTPClient: hc, UserAgent: ua}); err != nil {
+		c = nil
+	}
+
+	return
+}
+
+// Client returns an api.Client that routes requests to the nodes
+func (c *Cluster) Client() *api.Client {
+	return c.client
+}
+
+// Check health checks all the nodes and returns their status
+func (c *Cluster) Check() []NodeStatus {
+	var wg sync.WaitGroup
+
+	for _, n := range c.nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			n.check()
+		}(n)
+	}
+	wg.Wait()
+
+	return c.Status()
+}
+
+// Status returns the last known status of the nodes
+func (c *Cluster) Status() (s []NodeStatus) {
+	for _, n := range c.nodes {
+		n.mu.Lock()
+		s = append(s, NodeStatus{
+			Name:    n.Name,
+			Primary: n.Primary,
+			State:   n.breaker.State(),
+			Status:  n.status,
+			Checked: n.checked,
+			Err:     n.err,
+		})
+		n.mu.Unlock()
+	}
+
+	return
+}
+
+// Start health checks the nodes in the background until Stop is called
+func (c *Cluster) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stop != nil {
+		return
+	}
+
+	c.stop = make(chan struct{})
+	c.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer c.wg.Done()
+		t := time.NewTicker(c.interval)
+		defer t.Stop()
+		c.Check()
+		for {
+			select {
+			case <-t.C:
+				c.Check()
+			case <-stop:
+				return
+			}
+		}
+	}(c.stop)
+}
+
+// Stop stops the background health checks
+func (c *Cluster) Stop() {
+	c.mu.Lock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	c.mu.Unlock()
+
+	c.wg.Wait()
+}
+
+// candidates returns the nodes to try in order, writes go to the
+// primary first and reads are spread over the nodes
+func (c *Cluster) candidates(write bool) (nodes []*node) {
+	start := c.primary
+
+	if !write {
+		c.mu.Lock()
+		start = c.next % len(c.nodes)
+		c.next++
+		c.mu.Unlock()
+	}
+
+	for i := range c.nodes {
+		nodes = append(nodes, c.nodes[(start+i)%len(c.nodes)])
+	}
+
+	return
+}
+
+func (n *node) check() {
+	status, err := n.client.GetSystemStatus()
+	if err != nil {
+		status = nil
+	} else if !status.Status {
+		err = fmt.Errorf(unhealthyNodeError, n.Name)
+	}
+
+	n.mu.Lock()
+	n.status = status
+	n.checked = time.Now()
+	n.err = err
+	n.mu.Unlock()
+
+	if err != nil {
+		n.breaker.Failure()
+		return
+	}
+
+	n.breaker.Success()
+}
@@ -0,0 +1,263 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type testNode struct {
+	*httptest.Server
+	name    string
+	token   string
+	healthy bool
+	code    int
+	mu      sync.Mutex
+	hits    map[string]int
+}
+
+func newTestNode(t *testing.T, name string) *testNode {
+	n := &testNode{
+		name:    name,
+		token:   name + "-token",
+		healthy: true,
+		hits:    make(map[string]int),
+	}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+n.token {
+			t.Errorf("Expected %s got %s", "Bearer "+n.token, r.Header.Get("Authorization"))
+		}
+		n.hits[r.Method]++
+		w.Header().Set("Content-Type", "application/json")
+		if n.code != 0 {
+			w.WriteHeader(n.code)
+			fmt.Fprintf(w, `{"code": %d, "message": "%s"}`, n.code, http.StatusText(n.code))
+			return
+		}
+		if r.URL.Path == "/api/v1/status" {
+			fmt.Fprintf(w, `{"inbound": 1, "status": %t, "outbound": 2}`, n.healthy)
+			return
+		}
+		fmt.Fprintf(w, `{"id": 1, "name": "%s"}`, n.name)
+	}))
+	return n
+}
+
+func (n *testNode) count(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.hits[method]
+}
+
+func (n *testNode) node(primary bool) Node {
+	return Node{Name: n.name, Endpoint: n.URL, Token: n.token, Primary: primary}
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(nil, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != nodesParamError {
+		t.Errorf("Expected '%s' got '%s'", nodesParamError, err)
+	}
+	_, err = New([]Node{{Name: "a", Endpoint: "http://a"}, {Name: "b"}}, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if expected := fmt.Sprintf(nodeEndpointError, 1); err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+	_, err = New([]Node{{Endpoint: "http://a", Primary: true}, {Endpoint: "http://b", Primary: true}}, nil)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if err.Error() != primaryNodeError {
+		t.Errorf("Expected '%s' got '%s'", primaryNodeError, err)
+	}
+}
+
+func TestRouting(t *testing.T) {
+	standby := newTestNode(t, "standby")
+	defer standby.Close()
+	primary := newTestNode(t, "primary")
+	defer primary.Close()
+
+	c, err := New([]Node{standby.node(false), primary.node(true)}, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	client := c.Client()
+
+	names := make(map[string]bool)
+	for i := 0; i < 4; i++ {
+		d, err := client.GetDomain(1)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		names[d.Name] = true
+	}
+	if !names["primary"] || !names["standby"] {
+		t.Errorf("Expected reads to be spread over the nodes got %v", names)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err = client.UpdateDomain(&api.Domain{ID: 1, Name: "example.com"}); err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+	}
+	if n := primary.count(http.MethodPut); n != 3 {
+		t.Errorf("Expected %d got %d", 3, n)
+	}
+	if n := standby.count(http.MethodPut); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	primary := newTestNode(t, "primary")
+	standby := newTestNode(t, "standby")
+	defer standby.Close()
+	primary.Close()
+
+	c, err := New([]Node{primary.node(true), standby.node(false)}, &Options{Threshold: 2})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	client := c.Client()
+
+	for i := 0; i < 4; i++ {
+		d, err := client.GetDomain(1)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if d.Name != "standby" {
+			t.Errorf("Expected %s got %s", "standby", d.Name)
+		}
+	}
+	if s := c.Status(); s[0].State != Open || s[0].Healthy() {
+		t.Errorf("Expected %s got %s", Open, s[0].State)
+	}
+
+	if err = client.UpdateDomain(&api.Domain{ID: 1, Name: "example.com"}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if n := standby.count(http.MethodPut); n != 1 {
+		t.Errorf("Expected %d got %d", 1, n)
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	primary := newTestNode(t, "primary")
+	defer primary.Close()
+	standby := newTestNode(t, "standby")
+	defer standby.Close()
+	standby.code = http.StatusServiceUnavailable
+
+	c, err := New([]Node{primary.node(true), standby.node(false)}, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		d, err := c.Client().GetDomain(1)
+		if err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+		if d.Name != "primary" {
+			t.Errorf("Expected %s got %s", "primary", d.Name)
+		}
+	}
+
+	primary.mu.Lock()
+	primary.code = http.StatusServiceUnavailable
+	primary.mu.Unlock()
+	_, err = c.Client().GetDomain(1)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if !strings.Contains(err.Error(), "503") {
+		t.Errorf("Expected a 503 error got %s", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	primary := newTestNode(t, "primary")
+	defer primary.Close()
+	standby := newTestNode(t, "standby")
+	defer standby.Close()
+	standby.healthy = false
+
+	c, err := New([]Node{primary.node(true), standby.node(false)}, &Options{Threshold: 1, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	s := c.Check()
+	if len(s) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(s))
+	}
+	if !s[0].Healthy() || !s[0].Primary || s[0].Status == nil || s[0].Err != nil {
+		t.Errorf("Expected the primary to be healthy got %+v", s[0])
+	}
+	if s[1].Healthy() || s[1].Err == nil {
+		t.Fatalf("Expected the standby to be unhealthy got %+v", s[1])
+	}
+	if expected := fmt.Sprintf(unhealthyNodeError, "standby"); s[1].Err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, s[1].Err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = c.Client().GetDomain(1); err != nil {
+			t.Fatalf("An error should not be returned: %s", err)
+		}
+	}
+	if n := standby.count(http.MethodGet); n != 1 {
+		t.Errorf("Expected %d got %d", 1, n)
+	}
+
+	standby.mu.Lock()
+	standby.healthy = true
+	standby.mu.Unlock()
+	c.Start()
+	c.Start()
+	c.Stop()
+	if s = c.Status(); !s[1].Healthy() {
+		t.Errorf("Expected the standby to recover got %+v", s[1])
+	}
+}
+
+func TestNoHealthyNodes(t *testing.T) {
+	primary := newTestNode(t, "primary")
+	primary.Close()
+
+	c, err := New([]Node{primary.node(true)}, &Options{Threshold: 1})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if _, err = c.Client().GetDomain(1); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	_, err = c.Client().GetDomain(1)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if expected := fmt.Sprintf(noHealthyNodeError, http.MethodGet, "/api/v1/domains/1"); !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+}
@@ -0,0 +1,123 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// transport sends each request to the first node whose breaker allows
+// it, moving on to the next node when the request fails
+type transport struct {
+	cluster *Cluster
+}
+
+func (t *transport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	var body []byte
+	var r *http.Request
+	var last *http.Response
+
+	write := req.Method != http.MethodGet && req.Method != http.MethodHead
+
+	if req.Body != nil && req.GetBody == nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return
+		}
+		req.Body.Close()
+	}
+
+	tried := false
+	for _, n := range t.cluster.candidates(write) {
+		if !n.breaker.Allow() {
+			continue
+		}
+
+		if r, err = n.request(req, body); err != nil {
+			return
+		}
+
+		tried = true
+		resp, err = t.cluster.transport.RoundTrip(r)
+		if err != nil {
+			n.breaker.Failure()
+			// a write may only be retried if it never reached the node
+			if write && !isDialError(err) {
+				return
+			}
+			continue
+		}
+
+		if unavailable(resp.StatusCode) {
+			n.breaker.Failure()
+			if write {
+				return
+			}
+			// keep the response in case no other node can be reached
+			if last != nil {
+				last.Body.Close()
+			}
+			last, resp = resp, nil
+			continue
+		}
+
+		n.breaker.Success()
+		if last != nil {
+			last.Body.Close()
+		}
+		return
+	}
+
+	if last != nil {
+		resp, err = last, nil
+		return
+	}
+
+	if !tried {
+		err = fmt.Errorf(noHealthyNodeError, req.Method, req.URL.Path)
+	}
+
+	return
+}
+
+// request returns a copy of req addressed to the node with its token
+func (n *node) request(req *http.Request, body []byte) (r *http.Request, err error) {
+	r = req.Clone(req.Context())
+	r.URL.Scheme = n.url.Scheme
+	r.URL.Host = n.url.Host
+	r.Host = ""
+
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return
+		}
+	} else if body != nil {
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		r.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+
+	return
+}
+
+func unavailable(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
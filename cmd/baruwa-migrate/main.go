@@ -0,0 +1,124 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Command baruwa-migrate copies an organization between Baruwa clusters
+
+The API tokens are read from the BARUWA_SRC_TOKEN and BARUWA_DST_TOKEN
+environment variables.
+
+	baruwa-migrate -src https://baruwa1.example.com -dst https://baruwa2.example.com \
+		-org 10 -secrets secrets.json -state org-10.state -relays 4,5 -auth-settings 7:2
+
+Rerunning the command with the same state file resumes an interrupted
+migration.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/baruwa-enterprise/baruwa-go/migrate"
+)
+
+func main() {
+	var err error
+	var src, dst *api.Client
+	var r *migrate.Result
+
+	srcURL := flag.String("src", "", "source Baruwa URL")
+	dstURL := flag.String("dst", "", "destination Baruwa URL")
+	orgID := flag.Int("org", 0, "source organization ID")
+	name := flag.String("name", "", "destination organization name, defaults to the source name")
+	secrets := flag.String("secrets", "", "JSON file holding the smarthost, relay, LDAP, RADIUS and user passwords")
+	state := flag.String("state", "", "state file used to resume the migration")
+	relays := flag.String("relays", "", "comma separated relay setting IDs of the source organization")
+	settings := flag.String("auth-settings", "", "comma separated auth server ID:settings ID pairs")
+	flag.Parse()
+
+	opts := &migrate.Options{
+		Name:         *name,
+		StateFile:    *state,
+		AuthSettings: make(map[int]int),
+	}
+
+	if *secrets != "" {
+		if opts.Secrets, err = loadSecrets(*secrets); err != nil {
+			fail(err)
+		}
+	}
+
+	for _, v := range split(*relays) {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			fail(fmt.Errorf("invalid relay ID %q", v))
+		}
+		opts.RelayIDs = append(opts.RelayIDs, id)
+	}
+
+	for _, v := range split(*settings) {
+		var serverID, settingsID int
+		if _, err = fmt.Sscanf(v, "%d:%d", &serverID, &settingsID); err != nil {
+			fail(fmt.Errorf("invalid auth settings %q", v))
+		}
+		opts.AuthSettings[serverID] = settingsID
+	}
+
+	if src, err = api.New(*srcURL, os.Getenv("BARUWA_SRC_TOKEN"), nil); err != nil {
+		fail(err)
+	}
+
+	if dst, err = api.New(*dstURL, os.Getenv("BARUWA_DST_TOKEN"), nil); err != nil {
+		fail(err)
+	}
+
+	m, err := migrate.New(src, dst, opts)
+	if err != nil {
+		fail(err)
+	}
+
+	r, _, err = m.Migrate(*orgID)
+	if r != nil {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(r)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func loadSecrets(path string) (migrate.Secrets, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return migrate.LoadSecrets(f)
+}
+
+func split(s string) (l []string) {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+
+	return
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "baruwa-migrate:", err)
+	os.Exit(1)
+}
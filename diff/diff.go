@@ -0,0 +1,348 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package diff Compare Baruwa API resources field by field
+
+Compare walks two values of the same resource type and returns the
+fields that differ, named by their JSON path. It understands the API
+types: LocalFloat64 values are compared to one decimal place, nested
+structs with an ID such as AliasDomain are compared by ID, lists are
+compared as sets and the write-only password fields and server-managed
+fields such as the ID and timestamps are skipped.
+
+Unset parts of the desired value, nil pointers, nil lists, zero MyTime
+values and references with a zero ID, are not compared.
+
+	current, err := c.GetDomainByName("example.com")
+	changes, err := diff.Compare(current, desired, nil)
+	if len(changes) > 0 {
+		fmt.Print(changes.Unified("server", "desired"))
+	}
+*/
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	structError = "The values to compare should be structs or pointers to structs"
+	typeError   = "The values to compare should be of the same type"
+)
+
+// Op is the kind of change made to a field
+type Op string
+
+const (
+	// Modified the value of the field differs
+	Modified Op = "modified"
+	// Added the desired list holds an entry the current list does not
+	Added Op = "added"
+	// Removed the current list holds an entry the desired list does not
+	Removed Op = "removed"
+)
+
+// writeOnly fields are never returned by the API
+var writeOnly = map[string]bool{
+	"password":  true,
+	"password1": true,
+	"password2": true,
+	"bindpw":    true,
+}
+
+// managed fields are set by the server on the top level resource
+var managed = map[string]bool{
+	"id":           true,
+	"created_on":   true,
+	"last_login":   true,
+	"last_updated": true,
+	"timestamp":    true,
+	"lastattempt":  true,
+}
+
+var (
+	localFloat64Type = reflect.TypeOf(api.LocalFloat64(0))
+	myTimeType       = reflect.TypeOf(api.MyTime{})
+)
+
+// Change is a field that differs, lists have a change for each entry
+// added or removed
+type Change struct {
+	// Path is the JSON name of the field, nested fields are joined by "."
+	Path string `json:"path"`
+	Op   Op     `json:"op"`
+	// From is the current value, empty for Added
+	From string `json:"from,omitempty"`
+	// To is the desired value, empty for Removed
+	To string `json:"to,omitempty"`
+}
+
+// String returns the change in a single line
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("%s: +%s", c.Path, c.To)
+	case Removed:
+		return fmt.Sprintf("%s: -%s", c.Path, c.From)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Path, c.From, c.To)
+	}
+}
+
+// Changes is the list of changes in field order
+type Changes []Change
+
+// Paths returns the paths of the changed fields
+func (c Changes) Paths() (l []string) {
+	for _, v := range c {
+		if len(l) == 0 || l[len(l)-1] != v.Path {
+			l = append(l, v.Path)
+		}
+	}
+
+	return
+}
+
+// Unified returns the changes as a unified diff, from and to name the
+// current and desired values. An empty string is returned when there
+// are no changes
+func (c Changes) Unified(from, to string) string {
+	var b strings.Builder
+
+	if len(c) == 0 {
+		return ""
+	}
+
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+	for _, v := range c {
+		if v.Op != Added {
+			fmt.Fprintf(&b, "-%s: %s\n", v.Path, v.From)
+		}
+		if v.Op != Removed {
+			fmt.Fprintf(&b, "+%s: %s\n", v.Path, v.To)
+		}
+	}
+
+	return b.String()
+}
+
+// String returns the changes as a unified diff
+func (c Changes) String() string {
+	return c.Unified("current", "desired")
+}
+
+// Options represents optional settings that can be passed to Compare
+type Options struct {
+	// Ignore lists the paths of fields that are not compared
+	Ignore []string
+}
+
+// Compare returns the changes needed to turn current into desired, both
+// must be the same resource type
+func Compare(current, desired interface{}, opts *Options) (c Changes, err error) {
+	cv := reflect.Indirect(reflect.ValueOf(current))
+	dv := reflect.Indirect(reflect.ValueOf(desired))
+
+	if cv.Kind() != reflect.Struct || dv.Kind() != reflect.Struct {
+		err = fmt.Errorf(structError)
+		return
+	}
+
+	if cv.Type() != dv.Type() {
+		err = fmt.Errorf(typeError)
+		return
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	c = Changes{}
+	opts.walk(&c, "", cv, dv)
+
+	return
+}
+
+// Equal returns true if Compare finds no changes
+func Equal(current, desired interface{}, opts *Options) bool {
+	c, err := Compare(current, desired, opts)
+	return err == nil && len(c) == 0
+}
+
+func (o *Options) walk(c *Changes, path string, cv, dv reflect.Value) {
+	t := dv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonName(t.Field(i))
+		if name == "" || writeOnly[name] || path == "" && managed[name] {
+			continue
+		}
+		p := name
+		if path != "" {
+			p = path + "." + name
+		}
+		if o.ignored(p) {
+			continue
+		}
+		o.field(c, p, cv.Field(i), dv.Field(i))
+	}
+}
+
+func (o *Options) field(c *Changes, path string, cv, dv reflect.Value) {
+	switch {
+	case dv.Type() == myTimeType:
+		d := dv.Interface().(api.MyTime)
+		if d.IsZero() {
+			return
+		}
+		changed(c, path, formatTime(cv.Interface().(api.MyTime)), formatTime(d))
+	case dv.Type() == localFloat64Type:
+		changed(c, path, fmt.Sprintf("%.1f", cv.Float()), fmt.Sprintf("%.1f", dv.Float()))
+	case dv.Kind() == reflect.Ptr:
+		if dv.IsNil() {
+			return
+		}
+		if cv.IsNil() {
+			cv = reflect.New(dv.Type().Elem())
+		}
+		o.field(c, path, cv.Elem(), dv.Elem())
+	case dv.Kind() == reflect.Struct:
+		if id := dv.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.Int {
+			if id.Int() != 0 {
+				changed(c, path, formatID(cv.FieldByName("ID").Int()), formatID(id.Int()))
+			}
+			return
+		}
+		o.walk(c, path, cv, dv)
+	case dv.Kind() == reflect.Slice:
+		if dv.IsNil() {
+			return
+		}
+		compareSet(c, path, cv, dv)
+	case dv.Kind() == reflect.Map || dv.Kind() == reflect.Interface || dv.Kind() == reflect.Func:
+		return
+	default:
+		changed(c, path, fmt.Sprint(cv.Interface()), fmt.Sprint(dv.Interface()))
+	}
+}
+
+func (o *Options) ignored(path string) bool {
+	for _, v := range o.Ignore {
+		if v == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func changed(c *Changes, path, from, to string) {
+	if from != to {
+		*c = append(*c, Change{Path: path, Op: Modified, From: from, To: to})
+	}
+}
+
+// compareSet records the entries removed from and added to a list,
+// structs with an ID are identified by the ID
+func compareSet(c *Changes, path string, cv, dv reflect.Value) {
+	current := keys(cv)
+	desired := keys(dv)
+
+	for _, k := range current {
+		if !contains(desired, k) {
+			*c = append(*c, Change{Path: path, Op: Removed, From: k})
+		}
+	}
+
+	for _, k := range desired {
+		if !contains(current, k) {
+			*c = append(*c, Change{Path: path, Op: Added, To: k})
+		}
+	}
+}
+
+func keys(v reflect.Value) (l []string) {
+	for i := 0; i < v.Len(); i++ {
+		l = append(l, key(v.Index(i)))
+	}
+
+	return
+}
+
+func key(v reflect.Value) string {
+	v = reflect.Indirect(v)
+
+	switch {
+	case !v.IsValid():
+		return ""
+	case v.Type() == localFloat64Type:
+		return fmt.Sprintf("%.1f", v.Float())
+	case v.Type() == myTimeType:
+		return formatTime(v.Interface().(api.MyTime))
+	case v.Kind() == reflect.Struct:
+		if id := v.FieldByName("ID"); id.IsValid() && id.Kind() == reflect.Int {
+			return formatID(id.Int())
+		}
+		return fmt.Sprintf("%+v", v.Interface())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+func formatTime(t api.MyTime) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(id, 10)
+}
+
+func jsonName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+
+	if tag == "" {
+		return f.Name
+	}
+
+	return tag
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
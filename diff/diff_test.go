@@ -0,0 +1,184 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+func TestCompareDomain(t *testing.T) {
+	var current api.Domain
+
+	body := `{"id": 3, "name": "example.com", "status": true, "low_score": 5.0,
+		"high_score": 10.0, "organizations": [1, 2], "timezone": "UTC"}`
+	if err := json.Unmarshal([]byte(body), &current); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	desired := current
+	desired.ID = 0
+	desired.LowScore = 5.04
+	if !Equal(&current, &desired, nil) {
+		t.Errorf("Expected the domains to be equal")
+	}
+
+	desired.LowScore = 6
+	desired.Enabled = false
+	desired.Organizations = []int{2, 3}
+	c, err := Compare(&current, desired, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected := Changes{
+		{Path: "status", Op: Modified, From: "true", To: "false"},
+		{Path: "low_score", Op: Modified, From: "5.0", To: "6.0"},
+		{Path: "organizations", Op: Removed, From: "1"},
+		{Path: "organizations", Op: Added, To: "3"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("Expected %v got %v", expected, c)
+	}
+	if p := c.Paths(); !reflect.DeepEqual(p, []string{"status", "low_score", "organizations"}) {
+		t.Errorf("Expected [status low_score organizations] got %v", p)
+	}
+
+	desired.Organizations = nil
+	if c, _ = Compare(&current, &desired, &Options{Ignore: []string{"status"}}); len(c) != 1 || c[0].Path != "low_score" {
+		t.Errorf("Expected only low_score to differ got %v", c)
+	}
+}
+
+func TestCompareNested(t *testing.T) {
+	current := &api.DomainDeliveryServer{
+		ID:      2,
+		Address: "192.168.1.10",
+		Port:    25,
+		Domain:  &api.AliasDomain{ID: 1, Name: "example.com"},
+	}
+
+	desired := &api.DomainDeliveryServer{Address: "192.168.1.10", Port: 25}
+	if !Equal(current, desired, nil) {
+		t.Errorf("Expected a nil domain not to be compared")
+	}
+
+	desired.Domain = &api.AliasDomain{ID: 1}
+	if !Equal(current, desired, nil) {
+		t.Errorf("Expected the domains to be compared by ID")
+	}
+
+	desired.Domain.ID = 4
+	c, _ := Compare(current, desired, nil)
+	if len(c) != 1 || c[0] != (Change{Path: "domain", Op: Modified, From: "1", To: "4"}) {
+		t.Errorf("Expected the domain to differ got %v", c)
+	}
+
+	current.Domain = nil
+	if c, _ = Compare(current, desired, nil); len(c) != 1 || c[0].From != "" || c[0].To != "4" {
+		t.Errorf("Expected the domain to be set got %v", c)
+	}
+
+	ldap := &api.LDAPSettings{ID: 9, Basedn: "dc=example,dc=com", BindPw: "s3cr3t", AuthServer: api.SettingsAS{ID: 3}}
+	want := &api.LDAPSettings{Basedn: "dc=example,dc=com", BindPw: "changed"}
+	if !Equal(ldap, want, nil) {
+		t.Errorf("Expected the password and unset auth server not to be compared")
+	}
+	want.AuthServer.ID = 4
+	if c, _ = Compare(ldap, want, nil); len(c) != 1 || c[0].Path != "authserver" {
+		t.Errorf("Expected the auth server to differ got %v", c)
+	}
+}
+
+func TestCompareUser(t *testing.T) {
+	current := &api.User{
+		ID:        5,
+		Username:  "jdoe",
+		Email:     "jdoe@example.com",
+		CreatedOn: api.MyTime{Time: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Domains:   []api.UserDomain{{ID: 1, Name: "example.com"}},
+		Addresses: []api.UserAddress{{ID: 7, Address: "info@example.com"}},
+	}
+
+	desired := &api.User{
+		Username:  "jdoe",
+		Email:     "jdoe@example.com",
+		Domains:   []api.UserDomain{{ID: 1}},
+		Addresses: []api.UserAddress{{ID: 7}, {ID: 8}},
+	}
+	c, err := Compare(current, desired, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(c) != 1 || c[0] != (Change{Path: "addresses", Op: Added, To: "8"}) {
+		t.Errorf("Expected the address to be added got %v", c)
+	}
+
+	q := &api.MailQueueItem{}
+	w := &api.MailQueueItem{LastAttempt: api.MyTime{Time: time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("SAST", 7200))}}
+	if c, _ = Compare(q, w, &Options{}); len(c) != 0 {
+		t.Errorf("Expected the server-managed timestamps not to be compared got %v", c)
+	}
+
+	r := &api.Report{}
+	want := &api.Report{Start: api.MyTime{Time: time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("SAST", 7200))}}
+	if c, _ = Compare(r, want, nil); len(c) != 1 || c[0].From != "" || c[0].To != "2019-01-02T01:04:05Z" {
+		t.Errorf("Expected the start to differ got %v", c)
+	}
+	if c, _ = Compare(want, r, nil); len(c) != 0 {
+		t.Errorf("Expected a zero time not to be compared got %v", c)
+	}
+}
+
+func TestCompareErrors(t *testing.T) {
+	if _, err := Compare(&api.Domain{}, &api.Organization{}, nil); err == nil || err.Error() != typeError {
+		t.Errorf("Expected '%s' got '%v'", typeError, err)
+	}
+
+	if _, err := Compare(1, 2, nil); err == nil || err.Error() != structError {
+		t.Errorf("Expected '%s' got '%v'", structError, err)
+	}
+
+	if _, err := Compare(nil, &api.Domain{}, nil); err == nil || err.Error() != structError {
+		t.Errorf("Expected '%s' got '%v'", structError, err)
+	}
+
+	if Equal(&api.Domain{}, &api.Organization{}, nil) {
+		t.Errorf("Expected different types not to be equal")
+	}
+}
+
+func TestUnified(t *testing.T) {
+	c := Changes{
+		{Path: "status", Op: Modified, From: "true", To: "false"},
+		{Path: "organizations", Op: Removed, From: "1"},
+		{Path: "organizations", Op: Added, To: "3"},
+	}
+
+	expected := "--- server\n+++ desired\n-status: true\n+status: false\n-organizations: 1\n+organizations: 3\n"
+	if s := c.Unified("server", "desired"); s != expected {
+		t.Errorf("Expected %q got %q", expected, s)
+	}
+
+	if s := c.String(); s[:21] != "--- current\n+++ desir" {
+		t.Errorf("Expected the default names got %q", s)
+	}
+
+	if s := (Changes{}).Unified("a", "b"); s != "" {
+		t.Errorf("Expected an empty diff got %q", s)
+	}
+
+	for i, expected := range []string{"status: true -> false", "organizations: -1", "organizations: +3"} {
+		if s := c[i].String(); s != expected {
+			t.Errorf("Expected %s got %s", expected, s)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package dirsync Directory to Baruwa user account synchronization
+
+An Engine reads user entries from a Source, such as an LDAP or Active
+Directory server, compares them with the Baruwa user accounts and
+alias addresses and creates, updates or disables accounts so that
+Baruwa matches the directory. In dry-run mode the changes are only
+reported.
+*/
+package dirsync
+
+import (
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	defaultAccountType = 3
+	superAdminType     = 1
+	sourceParamError   = "The source param is required"
+	clientParamError   = "The client param is required"
+)
+
+// Action is a change made by the sync engine
+type Action int
+
+const (
+	// CreateUser a user account was created
+	CreateUser Action = iota + 1
+	// UpdateUser a user account was updated
+	UpdateUser
+	// DisableUser a user account was disabled
+	DisableUser
+	// AddAlias an alias address was added
+	AddAlias
+	// RemoveAlias an alias address was removed
+	RemoveAlias
+)
+
+var actionNames = map[Action]string{
+	CreateUser:  "create-user",
+	UpdateUser:  "update-user",
+	DisableUser: "disable-user",
+	AddAlias:    "add-alias",
+	RemoveAlias: "remove-alias",
+}
+
+func (a Action) String() string {
+	return actionNames[a]
+}
+
+// Entry holds a directory user
+type Entry struct {
+	DN        string
+	Username  string
+	Email     string
+	Firstname string
+	Lastname  string
+	Aliases   []string
+	Disabled  bool
+}
+
+// Source reads user entries from a directory
+type Source interface {
+	Entries() ([]Entry, error)
+}
+
+// Client is the subset of api.Client used by the sync engine
+type Client interface {
+	GetUsers(opts *api.ListOptions) (*api.UserList, error)
+	GetUser(userID int) (*api.User, error)
+	CreateUser(user *api.UserForm) (*api.User, error)
+	UpdateUser(user *api.UserForm) error
+	CreateAliasAddress(userID int, alias *api.AliasAddress) error
+	DeleteAliasAddress(alias *api.AliasAddress) error
+}
+
+var _ Client = (*api.Client)(nil)
+
+// Options holds sync settings
+type Options struct {
+	// DomainID limits the sync to accounts in this domain and is
+	// assigned to new accounts
+	DomainID int
+	// AccountType of new accounts, defaults to a normal user
+	AccountType int
+	// Timezone of new accounts
+	Timezone string
+	// Password returns the initial password of a new account,
+	// when nil no password is set
+	Password func(e *Entry) string
+	// DisableMissing disables accounts that are not in the directory
+	DisableMissing bool
+	// RemoveAliases removes alias addresses that are not in the directory
+	RemoveAliases bool
+	// DryRun reports the changes without making them
+	DryRun bool
+}
+
+// Change holds a single change
+type Change struct {
+	Action   Action `json:"action"`
+	Username string `json:"username"`
+	UserID   int    `json:"user_id,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Err      error  `json:"-"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Report holds the result of a sync run
+type Report struct {
+	DryRun    bool     `json:"dry_run"`
+	Changes   []Change `json:"changes"`
+	Unchanged int      `json:"unchanged"`
+	Errors    int      `json:"errors"`
+}
+
+// ParseProxyAddresses splits Active Directory proxyAddresses values
+// into the primary SMTP address and the secondary SMTP addresses.
+// Non SMTP addresses such as X500 are ignored.
+func ParseProxyAddresses(values []string) (primary string, aliases []string) {
+	for _, v := range values {
+		i := strings.Index(v, ":")
+		if i == -1 {
+			continue
+		}
+		switch v[:i] {
+		case "SMTP":
+			primary = v[i+1:]
+		case "smtp":
+			aliases = append(aliases, v[i+1:])
+		}
+	}
+
+	return
+}
@@ -0,0 +1,363 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dirsync
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Engine synchronizes directory entries to Baruwa
+type Engine struct {
+	client Client
+	source Source
+	opts   Options
+}
+
+// New creates a new sync Engine
+func New(c Client, s Source, opts *Options) (e *Engine, err error) {
+	if c == nil {
+		err = fmt.Errorf(clientParamError)
+		return
+	}
+
+	if s == nil {
+		err = fmt.Errorf(sourceParamError)
+		return
+	}
+
+	e = &Engine{
+		client: c,
+		source: s,
+	}
+
+	if opts != nil {
+		e.opts = *opts
+	}
+
+	if e.opts.AccountType == 0 {
+		e.opts.AccountType = defaultAccountType
+	}
+
+	return
+}
+
+// Run performs a single sync, errors for individual accounts are
+// recorded in the report and do not stop the run
+func (e *Engine) Run() (r *Report, err error) {
+	var entries []Entry
+	var users []api.User
+	var byName, byEmail map[string]int
+	var matched map[int]bool
+
+	if entries, err = e.source.Entries(); err != nil {
+		return
+	}
+
+	byName = make(map[string]int)
+	byEmail = make(map[string]int)
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.UserList
+		if l, err = e.client.GetUsers(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			u := &l.Items[i]
+			if e.opts.DomainID > 0 && !inDomain(u, e.opts.DomainID) {
+				continue
+			}
+			byName[strings.ToLower(u.Username)] = len(users)
+			byEmail[strings.ToLower(u.Email)] = len(users)
+			users = append(users, *u)
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	r = &Report{
+		DryRun: e.opts.DryRun,
+	}
+	matched = make(map[int]bool)
+
+	for i := range entries {
+		entry := &entries[i]
+		idx, ok := byName[strings.ToLower(entry.Username)]
+		if !ok {
+			idx, ok = byEmail[strings.ToLower(entry.Email)]
+		}
+		if !ok {
+			if !entry.Disabled {
+				e.create(r, entry)
+			}
+			continue
+		}
+		matched[idx] = true
+		e.update(r, entry, &users[idx])
+	}
+
+	if e.opts.DisableMissing {
+		for i := range users {
+			u := &users[i]
+			if matched[i] || !u.Enabled || u.AccountType == superAdminType {
+				continue
+			}
+			e.disable(r, u)
+		}
+	}
+
+	for i := range r.Changes {
+		if c := &r.Changes[i]; c.Err != nil {
+			c.Error = c.Err.Error()
+			r.Errors++
+		}
+	}
+
+	return
+}
+
+func (e *Engine) create(r *Report, entry *Entry) {
+	var u *api.User
+	var err error
+
+	enabled := true
+	accountType := e.opts.AccountType
+	username, email := entry.Username, entry.Email
+	firstname, lastname := entry.Firstname, entry.Lastname
+
+	form := &api.UserForm{
+		Username:    &username,
+		Email:       &email,
+		Firstname:   &firstname,
+		Lastname:    &lastname,
+		AccountType: &accountType,
+		Enabled:     &enabled,
+	}
+
+	if e.opts.Timezone != "" {
+		tz := e.opts.Timezone
+		form.Timezone = &tz
+	}
+
+	if e.opts.DomainID > 0 {
+		form.Domains = []int{e.opts.DomainID}
+	}
+
+	if e.opts.Password != nil {
+		if pw := e.opts.Password(entry); pw != "" {
+			form.Password1 = &pw
+			form.Password2 = &pw
+		}
+	}
+
+	change := Change{
+		Action:   CreateUser,
+		Username: entry.Username,
+	}
+
+	if !e.opts.DryRun {
+		if u, err = e.client.CreateUser(form); err != nil {
+			change.Err = err
+			r.Changes = append(r.Changes, change)
+			return
+		}
+		change.UserID = u.ID
+	}
+
+	r.Changes = append(r.Changes, change)
+
+	for _, addr := range normalize(entry.Aliases, entry.Email) {
+		e.addAlias(r, entry.Username, change.UserID, addr)
+	}
+}
+
+func (e *Engine) update(r *Report, entry *Entry, u *api.User) {
+	var err error
+	var fields []string
+	var full *api.User
+
+	form := &api.UserForm{ID: &u.ID}
+
+	if entry.Firstname != "" && entry.Firstname != u.Firstname {
+		v := entry.Firstname
+		form.Firstname = &v
+		fields = append(fields, "firstname")
+	}
+
+	if entry.Lastname != "" && entry.Lastname != u.Lastname {
+		v := entry.Lastname
+		form.Lastname = &v
+		fields = append(fields, "lastname")
+	}
+
+	if entry.Email != "" && !strings.EqualFold(entry.Email, u.Email) {
+		v := entry.Email
+		form.Email = &v
+		fields = append(fields, "email")
+	}
+
+	if entry.Disabled == u.Enabled {
+		v := !entry.Disabled
+		form.Enabled = &v
+		fields = append(fields, "active")
+	}
+
+	if len(fields) > 0 {
+		action := UpdateUser
+		if entry.Disabled && u.Enabled {
+			action = DisableUser
+		}
+		change := Change{
+			Action:   action,
+			Username: u.Username,
+			UserID:   u.ID,
+			Detail:   strings.Join(fields, ","),
+		}
+		if !e.opts.DryRun {
+			change.Err = e.client.UpdateUser(form)
+		}
+		r.Changes = append(r.Changes, change)
+	}
+
+	if full, err = e.client.GetUser(u.ID); err != nil {
+		r.Changes = append(r.Changes, Change{
+			Action:   AddAlias,
+			Username: u.Username,
+			UserID:   u.ID,
+			Err:      err,
+		})
+		return
+	}
+
+	added, removed := diffAliases(normalize(entry.Aliases, entry.Email), full.Addresses)
+
+	for _, addr := range added {
+		e.addAlias(r, u.Username, u.ID, addr)
+	}
+
+	if e.opts.RemoveAliases {
+		for _, a := range removed {
+			change := Change{
+				Action:   RemoveAlias,
+				Username: u.Username,
+				UserID:   u.ID,
+				Detail:   a.Address,
+			}
+			if !e.opts.DryRun {
+				change.Err = e.client.DeleteAliasAddress(&api.AliasAddress{
+					ID:      a.ID,
+					Address: a.Address,
+					Enabled: a.Enabled,
+				})
+			}
+			r.Changes = append(r.Changes, change)
+		}
+	}
+
+	if len(fields) == 0 && len(added) == 0 && (!e.opts.RemoveAliases || len(removed) == 0) {
+		r.Unchanged++
+	}
+}
+
+func (e *Engine) disable(r *Report, u *api.User) {
+	enabled := false
+
+	change := Change{
+		Action:   DisableUser,
+		Username: u.Username,
+		UserID:   u.ID,
+		Detail:   "not in directory",
+	}
+
+	if !e.opts.DryRun {
+		change.Err = e.client.UpdateUser(&api.UserForm{
+			ID:      &u.ID,
+			Enabled: &enabled,
+		})
+	}
+
+	r.Changes = append(r.Changes, change)
+}
+
+func (e *Engine) addAlias(r *Report, username string, userID int, addr string) {
+	change := Change{
+		Action:   AddAlias,
+		Username: username,
+		UserID:   userID,
+		Detail:   addr,
+	}
+
+	if !e.opts.DryRun {
+		change.Err = e.client.CreateAliasAddress(userID, &api.AliasAddress{
+			Address: addr,
+			Enabled: true,
+		})
+	}
+
+	r.Changes = append(r.Changes, change)
+}
+
+// normalize lower cases, sorts and removes duplicates and the
+// primary address from a list of alias addresses
+func normalize(aliases []string, primary string) (addrs []string) {
+	seen := map[string]bool{
+		strings.ToLower(primary): true,
+	}
+
+	for _, a := range aliases {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		addrs = append(addrs, a)
+	}
+
+	sort.Strings(addrs)
+
+	return
+}
+
+func diffAliases(want []string, have []api.UserAddress) (added []string, removed []api.UserAddress) {
+	wanted := make(map[string]bool, len(want))
+	existing := make(map[string]bool, len(have))
+
+	for _, a := range want {
+		wanted[a] = true
+	}
+
+	for _, a := range have {
+		addr := strings.ToLower(a.Address)
+		existing[addr] = true
+		if !wanted[addr] {
+			removed = append(removed, a)
+		}
+	}
+
+	for _, a := range want {
+		if !existing[a] {
+			added = append(added, a)
+		}
+	}
+
+	return
+}
+
+func inDomain(u *api.User, domainID int) bool {
+	for _, d := range u.Domains {
+		if d.ID == domainID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,293 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dirsync
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeSource struct {
+	entries []Entry
+	err     error
+}
+
+func (s *fakeSource) Entries() ([]Entry, error) {
+	return s.entries, s.err
+}
+
+type fakeClient struct {
+	users   map[int]*api.User
+	order   []int
+	nextID  int
+	calls   []string
+	failFor string
+}
+
+func newFakeClient(users ...api.User) *fakeClient {
+	c := &fakeClient{users: make(map[int]*api.User), nextID: 100}
+	for i := range users {
+		u := users[i]
+		c.users[u.ID] = &u
+		c.order = append(c.order, u.ID)
+	}
+	return c
+}
+
+func (c *fakeClient) GetUsers(opts *api.ListOptions) (l *api.UserList, err error) {
+	l = &api.UserList{}
+	for _, id := range c.order {
+		u := *c.users[id]
+		u.Addresses = nil
+		l.Items = append(l.Items, u)
+	}
+	return
+}
+
+func (c *fakeClient) GetUser(userID int) (u *api.User, err error) {
+	u, ok := c.users[userID]
+	if !ok {
+		err = fmt.Errorf("404 Not Found")
+	}
+	return
+}
+
+func (c *fakeClient) CreateUser(form *api.UserForm) (u *api.User, err error) {
+	c.calls = append(c.calls, "create "+*form.Username)
+	if *form.Username == c.failFor {
+		err = fmt.Errorf("400 Bad Request")
+		return
+	}
+	c.nextID++
+	u = &api.User{
+		ID:       c.nextID,
+		Username: *form.Username,
+		Email:    *form.Email,
+		Enabled:  *form.Enabled,
+	}
+	for _, d := range form.Domains {
+		u.Domains = append(u.Domains, api.UserDomain{ID: d})
+	}
+	c.users[u.ID] = u
+	c.order = append(c.order, u.ID)
+	return
+}
+
+func (c *fakeClient) UpdateUser(form *api.UserForm) (err error) {
+	u := c.users[*form.ID]
+	c.calls = append(c.calls, "update "+u.Username)
+	if form.Enabled != nil {
+		u.Enabled = *form.Enabled
+	}
+	if form.Firstname != nil {
+		u.Firstname = *form.Firstname
+	}
+	return
+}
+
+func (c *fakeClient) CreateAliasAddress(userID int, alias *api.AliasAddress) (err error) {
+	u := c.users[userID]
+	c.calls = append(c.calls, fmt.Sprintf("alias %s %s", u.Username, alias.Address))
+	u.Addresses = append(u.Addresses, api.UserAddress{ID: len(u.Addresses) + 1, Address: alias.Address})
+	return
+}
+
+func (c *fakeClient) DeleteAliasAddress(alias *api.AliasAddress) (err error) {
+	c.calls = append(c.calls, "unalias "+alias.Address)
+	return
+}
+
+func getTestUsers() []api.User {
+	return []api.User{
+		{
+			ID:          1,
+			Username:    "admin",
+			Email:       "admin@example.com",
+			AccountType: 1,
+			Enabled:     true,
+			Domains:     []api.UserDomain{{ID: 2}},
+		},
+		{
+			ID:          2,
+			Username:    "andrew",
+			Email:       "andrew@example.com",
+			Firstname:   "Andy",
+			AccountType: 3,
+			Enabled:     true,
+			Domains:     []api.UserDomain{{ID: 2}},
+			Addresses: []api.UserAddress{
+				{ID: 1, Address: "info@example.com"},
+				{ID: 2, Address: "old@example.com"},
+			},
+		},
+		{
+			ID:          3,
+			Username:    "bob",
+			Email:       "bob@example.com",
+			AccountType: 3,
+			Enabled:     true,
+			Domains:     []api.UserDomain{{ID: 2}},
+		},
+		{
+			ID:          4,
+			Username:    "carol",
+			Email:       "carol@example.com",
+			AccountType: 3,
+			Enabled:     true,
+			Domains:     []api.UserDomain{{ID: 2}},
+		},
+		{
+			ID:          5,
+			Username:    "other",
+			Email:       "other@example.net",
+			AccountType: 3,
+			Enabled:     true,
+			Domains:     []api.UserDomain{{ID: 4}},
+		},
+	}
+}
+
+func getTestEntries() []Entry {
+	return []Entry{
+		{
+			Username:  "Andrew",
+			Email:     "andrew@example.com",
+			Firstname: "Andrew",
+			Aliases:   []string{"INFO@example.com", "sales@example.com", "andrew@example.com"},
+		},
+		{
+			Username: "bob",
+			Email:    "bob@example.com",
+			Disabled: true,
+		},
+		{
+			Username: "dave",
+			Email:    "dave@example.com",
+			Aliases:  []string{"support@example.com"},
+		},
+		{
+			Username: "erin",
+			Email:    "erin@example.com",
+			Disabled: true,
+		},
+	}
+}
+
+func TestNewError(t *testing.T) {
+	_, err := New(nil, &fakeSource{}, nil)
+	if err == nil || err.Error() != clientParamError {
+		t.Errorf("Expected '%s' got '%v'", clientParamError, err)
+	}
+	_, err = New(newFakeClient(), nil, nil)
+	if err == nil || err.Error() != sourceParamError {
+		t.Errorf("Expected '%s' got '%v'", sourceParamError, err)
+	}
+	e, err := New(newFakeClient(), &fakeSource{err: fmt.Errorf("connection refused")}, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if e.opts.AccountType != defaultAccountType {
+		t.Errorf("Expected %d got %d", defaultAccountType, e.opts.AccountType)
+	}
+	if _, err = e.Run(); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	c := newFakeClient(getTestUsers()...)
+	opts := &Options{
+		DomainID:       2,
+		DisableMissing: true,
+		RemoveAliases:  true,
+		DryRun:         true,
+	}
+	e, err := New(c, &fakeSource{entries: getTestEntries()}, opts)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	r, err := e.Run()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(c.calls) != 0 {
+		t.Errorf("Expected no calls got %v", c.calls)
+	}
+	expected := []string{
+		"update-user andrew firstname",
+		"add-alias andrew sales@example.com",
+		"remove-alias andrew old@example.com",
+		"disable-user bob active",
+		"create-user dave ",
+		"add-alias dave support@example.com",
+		"disable-user carol not in directory",
+	}
+	if len(r.Changes) != len(expected) {
+		t.Fatalf("Expected %d got %d: %v", len(expected), len(r.Changes), r.Changes)
+	}
+	for i, c := range r.Changes {
+		got := fmt.Sprintf("%s %s %s", c.Action, c.Username, c.Detail)
+		if got != expected[i] {
+			t.Errorf("Expected '%s' got '%s'", expected[i], got)
+		}
+	}
+	if !r.DryRun {
+		t.Errorf("Expected %t got %t", true, r.DryRun)
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := newFakeClient(getTestUsers()...)
+	c.failFor = "dave"
+	opts := &Options{
+		DomainID: 2,
+		Password: func(e *Entry) string { return "s3cr3t" },
+	}
+	entries := append(getTestEntries(), Entry{Username: "frank", Email: "frank@example.com"})
+	e, err := New(c, &fakeSource{entries: entries}, opts)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	r, err := e.Run()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected := []string{
+		"update andrew",
+		"alias andrew sales@example.com",
+		"update bob",
+		"create dave",
+		"create frank",
+	}
+	if fmt.Sprint(c.calls) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v got %v", expected, c.calls)
+	}
+	if r.Errors != 1 {
+		t.Errorf("Expected %d got %d", 1, r.Errors)
+	}
+	for _, change := range r.Changes {
+		if change.Err != nil && change.Error != change.Err.Error() {
+			t.Errorf("Expected '%s' got '%s'", change.Err, change.Error)
+		}
+	}
+	if c.users[3].Enabled {
+		t.Errorf("Expected %t got %t", false, c.users[3].Enabled)
+	}
+	if c.users[2].Firstname != "Andrew" {
+		t.Errorf("Expected %s got %s", "Andrew", c.users[2].Firstname)
+	}
+	r, err = e.Run()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if r.Unchanged != 3 {
+		t.Errorf("Expected %d got %d: %v", 3, r.Unchanged, r.Changes)
+	}
+}
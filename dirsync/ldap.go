@@ -0,0 +1,169 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dirsync
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/baruwa-enterprise/baruwa-go/authcheck"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// DefaultFilter matches Active Directory user accounts with a mail attribute
+const DefaultFilter = "(&(objectClass=user)(objectCategory=person)(mail=*))"
+
+const (
+	pageSize           = 500
+	accountDisabled    = 0x2
+	defaultNameAttr    = "sAMAccountName"
+	defaultEmailAttr   = "mail"
+	proxyAddressesAttr = "proxyAddresses"
+	serverParamError   = "The server param is required"
+	settingsParamError = "The settings param is required"
+)
+
+// LDAPSource reads user entries from an LDAP or Active Directory
+// server using the Baruwa domain LDAP settings
+type LDAPSource struct {
+	server    *api.AuthServer
+	settings  *api.LDAPSettings
+	filter    string
+	tlsConfig *tls.Config
+}
+
+// NewLDAPSource creates a Source from an authentication server and its
+// LDAP settings. The filter selects the user entries, when empty
+// DefaultFilter is used. The tlsConfig is used for LDAPS on port 636
+// and for StartTLS when the settings enable TLS, it can be nil.
+func NewLDAPSource(server *api.AuthServer, settings *api.LDAPSettings, filter string, tlsConfig *tls.Config) (s *LDAPSource, err error) {
+	if server == nil {
+		err = fmt.Errorf(serverParamError)
+		return
+	}
+
+	if settings == nil {
+		err = fmt.Errorf(settingsParamError)
+		return
+	}
+
+	if filter == "" {
+		filter = DefaultFilter
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: server.Address}
+	}
+
+	s = &LDAPSource{
+		server:    server,
+		settings:  settings,
+		filter:    filter,
+		tlsConfig: tlsConfig,
+	}
+
+	return
+}
+
+// Entries returns the user entries from the directory
+func (s *LDAPSource) Entries() (entries []Entry, err error) {
+	var conn *ldap.Conn
+	var result *ldap.SearchResult
+
+	u, implicitTLS := authcheck.LDAPURL(s.server)
+
+	if conn, err = ldap.DialURL(u, ldap.DialWithTLSConfig(s.tlsConfig)); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if s.settings.UseTLS && !implicitTLS {
+		if err = conn.StartTLS(s.tlsConfig); err != nil {
+			return
+		}
+	}
+
+	if s.settings.BindDN != "" {
+		if err = conn.Bind(s.settings.BindDN, s.settings.BindPw); err != nil {
+			return
+		}
+	}
+
+	req := ldap.NewSearchRequest(
+		s.settings.Basedn,
+		authcheck.SearchScope(s.settings.SearchScope),
+		ldap.NeverDerefAliases,
+		0, 0, false,
+		s.filter,
+		s.attributes(),
+		nil,
+	)
+
+	if result, err = conn.SearchWithPaging(req, pageSize); err != nil {
+		return
+	}
+
+	for _, e := range result.Entries {
+		entries = append(entries, s.entry(e))
+	}
+
+	return
+}
+
+func (s *LDAPSource) attributes() []string {
+	return []string{
+		s.nameAttribute(),
+		s.emailAttribute(),
+		"givenName",
+		"sn",
+		proxyAddressesAttr,
+		"userAccountControl",
+	}
+}
+
+func (s *LDAPSource) nameAttribute() string {
+	if s.settings.NameAttribute != "" {
+		return s.settings.NameAttribute
+	}
+
+	return defaultNameAttr
+}
+
+func (s *LDAPSource) emailAttribute() string {
+	if s.settings.EmailAttribute != "" {
+		return s.settings.EmailAttribute
+	}
+
+	return defaultEmailAttr
+}
+
+func (s *LDAPSource) entry(e *ldap.Entry) (entry Entry) {
+	entry = Entry{
+		DN:        e.DN,
+		Username:  e.GetAttributeValue(s.nameAttribute()),
+		Email:     e.GetAttributeValue(s.emailAttribute()),
+		Firstname: e.GetAttributeValue("givenName"),
+		Lastname:  e.GetAttributeValue("sn"),
+	}
+
+	primary, aliases := ParseProxyAddresses(e.GetAttributeValues(proxyAddressesAttr))
+	if entry.Email == "" {
+		entry.Email = primary
+	} else if primary != "" && primary != entry.Email {
+		aliases = append(aliases, primary)
+	}
+	entry.Aliases = aliases
+
+	if uac, err := strconv.Atoi(e.GetAttributeValue("userAccountControl")); err == nil {
+		entry.Disabled = uac&accountDisabled != 0
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dirsync
+
+import (
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestParseProxyAddresses(t *testing.T) {
+	primary, aliases := ParseProxyAddresses([]string{
+		"smtp:info@example.com",
+		"X500:/o=ExchangeLabs/ou=Exchange",
+		"SMTP:andrew@example.com",
+		"invalid",
+		"smtp:sales@example.com",
+	})
+	if primary != "andrew@example.com" {
+		t.Errorf("Expected %s got %s", "andrew@example.com", primary)
+	}
+	if len(aliases) != 2 || aliases[1] != "sales@example.com" {
+		t.Errorf("Expected %d got %v", 2, aliases)
+	}
+}
+
+func TestNewLDAPSourceError(t *testing.T) {
+	_, err := NewLDAPSource(nil, nil, "", nil)
+	if err == nil || err.Error() != serverParamError {
+		t.Errorf("Expected '%s' got '%v'", serverParamError, err)
+	}
+	_, err = NewLDAPSource(&api.AuthServer{}, nil, "", nil)
+	if err == nil || err.Error() != settingsParamError {
+		t.Errorf("Expected '%s' got '%v'", settingsParamError, err)
+	}
+	s, err := NewLDAPSource(&api.AuthServer{Address: "127.0.0.1", Port: 1}, &api.LDAPSettings{}, "", nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if s.filter != DefaultFilter {
+		t.Errorf("Expected %s got %s", DefaultFilter, s.filter)
+	}
+	if _, err = s.Entries(); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestLDAPSourceEntry(t *testing.T) {
+	s, err := NewLDAPSource(&api.AuthServer{Address: "dc1.example.com"}, &api.LDAPSettings{
+		NameAttribute: "uid",
+	}, "", nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	e := ldap.NewEntry("cn=Andrew,dc=example,dc=com", map[string][]string{
+		"uid":                {"andrew"},
+		"givenName":          {"Andrew"},
+		"sn":                 {"Kissa"},
+		"userAccountControl": {"514"},
+		"proxyAddresses": {
+			"SMTP:andrew@example.com",
+			"smtp:info@example.com",
+		},
+	})
+	entry := s.entry(e)
+	if entry.Username != "andrew" {
+		t.Errorf("Expected %s got %s", "andrew", entry.Username)
+	}
+	if entry.Email != "andrew@example.com" {
+		t.Errorf("Expected %s got %s", "andrew@example.com", entry.Email)
+	}
+	if len(entry.Aliases) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(entry.Aliases))
+	}
+	if !entry.Disabled {
+		t.Errorf("Expected %t got %t", true, entry.Disabled)
+	}
+}
@@ -0,0 +1,462 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package dnscheck DNS readiness checks for domains and domain aliases
+
+A Verifier looks up the MX, SPF, DMARC and DKIM records of a domain and
+its aliases and reports whether mail for them will reach the Baruwa
+cluster, so that Domain.AcceptInbound can be turned on safely. The
+Resolver is injectable, a net.Resolver is used by default.
+*/
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	defaultTimeout  = 5 * time.Second
+	domainNameError = "The domain name is required"
+	mxHostsError    = "At least one MX host is required"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	// Pass the check passed
+	Pass Status = "pass"
+	// Warn the check passed with warnings
+	Warn Status = "warn"
+	// Fail the check failed
+	Fail Status = "fail"
+	// Skip the check was not performed
+	Skip Status = "skip"
+)
+
+// Resolver performs the DNS lookups, net.Resolver satisfies it
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+var _ Resolver = (*net.Resolver)(nil)
+
+// Client is the subset of api.Client used to load domains
+type Client interface {
+	GetDomain(domainID int) (*api.Domain, error)
+	GetDomainAliases(domainID int, opts *api.ListOptions) (*api.DomainAliasList, error)
+}
+
+var _ Client = (*api.Client)(nil)
+
+// Check holds the result of a single check
+type Check struct {
+	Status  Status   `json:"status"`
+	Records []string `json:"records,omitempty"`
+	Detail  string   `json:"detail,omitempty"`
+}
+
+// DomainReport holds the checks for a domain or domain alias
+type DomainReport struct {
+	Name    string `json:"name"`
+	Alias   bool   `json:"alias"`
+	Enabled bool   `json:"enabled"`
+	MX      Check  `json:"mx"`
+	SPF     Check  `json:"spf"`
+	DMARC   Check  `json:"dmarc"`
+	DKIM    Check  `json:"dkim"`
+	Ready   bool   `json:"ready"`
+}
+
+// Report holds the readiness of a domain and its aliases
+type Report struct {
+	DomainID int            `json:"domain_id"`
+	Domain   string         `json:"domain"`
+	Domains  []DomainReport `json:"domains"`
+	Ready    bool           `json:"ready"`
+}
+
+// Verifier checks DNS records
+type Verifier struct {
+	// Resolver used for lookups, defaults to net.DefaultResolver
+	Resolver Resolver
+	// MXHosts are the Baruwa cluster hosts the MX records must point
+	// to, entries starting with a dot match any host in that domain
+	MXHosts []string
+	// SPFIncludes are mechanisms expected in the SPF record such as
+	// include:spf.example.net, a missing one is a warning
+	SPFIncludes []string
+	// DKIMSelectors to look up, DKIM is skipped when empty
+	DKIMSelectors []string
+	// Timeout for each lookup, defaults to 5 seconds
+	Timeout time.Duration
+}
+
+// VerifyDomain loads a domain and its aliases and verifies them
+func (v *Verifier) VerifyDomain(c Client, domainID int) (r *Report, err error) {
+	var domain *api.Domain
+	var aliases []api.DomainAlias
+
+	if domain, err = c.GetDomain(domainID); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainAliasList
+		if l, err = c.GetDomainAliases(domainID, opts); err != nil {
+			return
+		}
+		aliases = append(aliases, l.Items...)
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	r, err = v.Verify(domain, aliases)
+
+	return
+}
+
+// Verify checks a domain and its aliases, the report is ready when the
+// domain and every enabled alias are ready
+func (v *Verifier) Verify(domain *api.Domain, aliases []api.DomainAlias) (r *Report, err error) {
+	if domain == nil || domain.Name == "" {
+		err = fmt.Errorf(domainNameError)
+		return
+	}
+
+	if len(v.MXHosts) == 0 {
+		err = fmt.Errorf(mxHostsError)
+		return
+	}
+
+	r = &Report{
+		DomainID: domain.ID,
+		Domain:   domain.Name,
+	}
+
+	d := v.Check(domain.Name)
+	d.Enabled = domain.Enabled
+	r.Domains = append(r.Domains, d)
+	r.Ready = d.Ready
+
+	for _, a := range aliases {
+		d = v.Check(a.Name)
+		d.Alias = true
+		d.Enabled = a.Enabled
+		r.Domains = append(r.Domains, d)
+		if a.Enabled && !d.Ready {
+			r.Ready = false
+		}
+	}
+
+	return
+}
+
+// Check checks a single domain name, it is ready when the MX records
+// pass and no other check fails
+func (v *Verifier) Check(name string) (d DomainReport) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	d = DomainReport{
+		Name:  name,
+		MX:    v.checkMX(name),
+		SPF:   v.checkSPF(name),
+		DMARC: v.checkDMARC(name),
+		DKIM:  v.checkDKIM(name),
+	}
+
+	d.Ready = d.MX.Status == Pass &&
+		d.SPF.Status != Fail &&
+		d.DMARC.Status != Fail &&
+		d.DKIM.Status != Fail
+
+	return
+}
+
+func (v *Verifier) resolver() Resolver {
+	if v.Resolver == nil {
+		return net.DefaultResolver
+	}
+
+	return v.Resolver
+}
+
+func (v *Verifier) context() (context.Context, context.CancelFunc) {
+	timeout := v.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+func (v *Verifier) lookupTXT(name string) (records []string, err error) {
+	ctx, cancel := v.context()
+	defer cancel()
+
+	if records, err = v.resolver().LookupTXT(ctx, name); err != nil && notFound(err) {
+		err = nil
+	}
+
+	return
+}
+
+func (v *Verifier) checkMX(name string) (c Check) {
+	var matched int
+
+	ctx, cancel := v.context()
+	defer cancel()
+
+	mxs, err := v.resolver().LookupMX(ctx, name)
+	if err != nil && !notFound(err) {
+		c.Status = Fail
+		c.Detail = err.Error()
+		return
+	}
+
+	if len(mxs) == 0 {
+		c.Status = Fail
+		c.Detail = "no MX records"
+		return
+	}
+
+	for _, mx := range mxs {
+		host := strings.TrimSuffix(strings.ToLower(mx.Host), ".")
+		c.Records = append(c.Records, fmt.Sprintf("%d %s", mx.Pref, host))
+		if host == "" {
+			c.Status = Fail
+			c.Detail = "null MX, the domain does not accept mail"
+			return
+		}
+		if v.clusterHost(host) {
+			matched++
+		}
+	}
+
+	switch {
+	case matched == len(mxs):
+		c.Status = Pass
+	case matched > 0:
+		c.Status = Warn
+		c.Detail = "some MX records do not point to the cluster"
+	default:
+		c.Status = Fail
+		c.Detail = "no MX records point to the cluster"
+	}
+
+	return
+}
+
+func (v *Verifier) clusterHost(host string) bool {
+	for _, h := range v.MXHosts {
+		h = strings.TrimSuffix(strings.ToLower(h), ".")
+		if strings.HasPrefix(h, ".") {
+			if strings.HasSuffix(host, h) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *Verifier) checkSPF(name string) (c Check) {
+	records, err := v.lookupTXT(name)
+	if err != nil {
+		c.Status = Fail
+		c.Detail = err.Error()
+		return
+	}
+
+	for _, txt := range records {
+		if isVersion(txt, "v=spf1") {
+			c.Records = append(c.Records, txt)
+		}
+	}
+
+	switch len(c.Records) {
+	case 0:
+		c.Status = Warn
+		c.Detail = "no SPF record"
+		return
+	case 1:
+	default:
+		c.Status = Fail
+		c.Detail = "multiple SPF records"
+		return
+	}
+
+	var all, redirect string
+	terms := strings.Fields(strings.ToLower(c.Records[0]))
+	have := make(map[string]bool, len(terms))
+	for _, term := range terms[1:] {
+		have[strings.TrimPrefix(term, "+")] = true
+		if strings.HasSuffix(term, "all") && len(term) <= 4 {
+			all = term
+		}
+		if strings.HasPrefix(term, "redirect=") {
+			redirect = term
+		}
+	}
+
+	switch all {
+	case "all", "+all":
+		c.Status = Fail
+		c.Detail = "SPF record allows all senders"
+		return
+	case "":
+		if redirect == "" {
+			c.Status = Warn
+			c.Detail = "SPF record has no all mechanism"
+			return
+		}
+	}
+
+	var missing []string
+	for _, inc := range v.SPFIncludes {
+		if !have[strings.ToLower(inc)] {
+			missing = append(missing, inc)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.Status = Warn
+		c.Detail = fmt.Sprintf("SPF record is missing %s", strings.Join(missing, ", "))
+		return
+	}
+
+	c.Status = Pass
+
+	return
+}
+
+func (v *Verifier) checkDMARC(name string) (c Check) {
+	records, err := v.lookupTXT("_dmarc." + name)
+	if err != nil {
+		c.Status = Fail
+		c.Detail = err.Error()
+		return
+	}
+
+	for _, txt := range records {
+		if isVersion(txt, "v=DMARC1") {
+			c.Records = append(c.Records, txt)
+		}
+	}
+
+	switch len(c.Records) {
+	case 0:
+		c.Status = Warn
+		c.Detail = "no DMARC record"
+		return
+	case 1:
+	default:
+		c.Status = Fail
+		c.Detail = "multiple DMARC records"
+		return
+	}
+
+	tags := parseTags(c.Records[0])
+	switch strings.ToLower(tags["p"]) {
+	case "reject", "quarantine":
+		c.Status = Pass
+	case "none":
+		c.Status = Warn
+		c.Detail = "DMARC policy is none"
+	case "":
+		c.Status = Fail
+		c.Detail = "DMARC record has no policy"
+	default:
+		c.Status = Fail
+		c.Detail = fmt.Sprintf("invalid DMARC policy %s", tags["p"])
+	}
+
+	return
+}
+
+func (v *Verifier) checkDKIM(name string) (c Check) {
+	var revoked []string
+
+	if len(v.DKIMSelectors) == 0 {
+		c.Status = Skip
+		return
+	}
+
+	for _, selector := range v.DKIMSelectors {
+		records, err := v.lookupTXT(selector + "._domainkey." + name)
+		if err != nil {
+			c.Status = Fail
+			c.Detail = err.Error()
+			return
+		}
+		if len(records) == 0 {
+			continue
+		}
+		txt := strings.Join(records, "")
+		tags := parseTags(txt)
+		if tags["p"] == "" {
+			revoked = append(revoked, selector)
+			continue
+		}
+		c.Records = append(c.Records, selector)
+	}
+
+	switch {
+	case len(c.Records) == 0 && len(revoked) > 0:
+		c.Status = Warn
+		c.Detail = fmt.Sprintf("DKIM keys revoked for %s", strings.Join(revoked, ", "))
+	case len(c.Records) == 0:
+		c.Status = Warn
+		c.Detail = "no DKIM keys found"
+	default:
+		c.Status = Pass
+	}
+
+	return
+}
+
+func isVersion(txt, version string) bool {
+	txt = strings.TrimSpace(txt)
+	if len(txt) < len(version) || !strings.EqualFold(txt[:len(version)], version) {
+		return false
+	}
+
+	return len(txt) == len(version) || txt[len(version)] == ' ' || txt[len(version)] == ';'
+}
+
+func parseTags(txt string) (tags map[string]string) {
+	tags = make(map[string]string)
+
+	for _, part := range strings.Split(txt, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.Join(strings.Fields(kv[1]), "")
+	}
+
+	return
+}
+
+func notFound(err error) bool {
+	var dnsErr *net.DNSError
+
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
@@ -0,0 +1,258 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package dnscheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeResolver struct {
+	mx  map[string][]*net.MX
+	txt map[string][]string
+	err map[string]error
+}
+
+func (f *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	if err, ok := f.err[name]; ok {
+		return nil, err
+	}
+	if mx, ok := f.mx[name]; ok {
+		return mx, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (f *fakeResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if txt, ok := f.txt[name]; ok {
+		return txt, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+type fakeClient struct {
+	domain  *api.Domain
+	aliases []api.DomainAlias
+}
+
+func (f *fakeClient) GetDomain(domainID int) (*api.Domain, error) {
+	if f.domain == nil || f.domain.ID != domainID {
+		return nil, fmt.Errorf("not found")
+	}
+	return f.domain, nil
+}
+
+func (f *fakeClient) GetDomainAliases(domainID int, opts *api.ListOptions) (*api.DomainAliasList, error) {
+	l := &api.DomainAliasList{}
+	if opts == nil && len(f.aliases) > 0 {
+		l.Items = f.aliases[:1]
+		l.Links.Pages.Next = "page2"
+	} else if opts != nil && opts.Page == "page2" {
+		l.Items = f.aliases[1:]
+	}
+	return l, nil
+}
+
+func getTestResolver() *fakeResolver {
+	return &fakeResolver{
+		mx: map[string][]*net.MX{
+			"example.com": {
+				{Host: "mx1.baruwa.example.net.", Pref: 10},
+				{Host: "mx2.baruwa.example.net.", Pref: 20},
+			},
+			"example.org": {
+				{Host: "mx1.baruwa.example.net.", Pref: 10},
+				{Host: "mail.example.org.", Pref: 20},
+			},
+			"example.info": {
+				{Host: ".", Pref: 0},
+			},
+		},
+		txt: map[string][]string{
+			"example.com":                    {"google-site-verification=abc", "v=spf1 include:spf.baruwa.example.net -all"},
+			"_dmarc.example.com":             {"v=DMARC1; p=reject; rua=mailto:dmarc@example.com"},
+			"baruwa._domainkey.example.com":  {"v=DKIM1; k=rsa; ", "p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"},
+			"example.org":                    {"v=spf1 +all"},
+			"_dmarc.example.org":             {"v=DMARC1; p=none"},
+			"example.net":                    {"v=spf1 mx -all", "v=spf1 a -all"},
+			"_dmarc.example.net":             {"v=DMARC1; rua=mailto:dmarc@example.net"},
+			"baruwa._domainkey.example.net":  {"v=DKIM1; p="},
+			"default._domainkey.example.org": {"v=DKIM1; p=MIGfMA0"},
+			"_dmarc.example.info":            {"v=DMARC1; p=quarantine"},
+			"example.info":                   {"v=spf1 -all"},
+			"baruwa._domainkey.example.info": {"v=DKIM1; p=MIGfMA0"},
+		},
+		err: map[string]error{
+			"example.net": &net.DNSError{Err: "server misbehaving", Name: "example.net", IsTemporary: true},
+		},
+	}
+}
+
+func getTestVerifier() *Verifier {
+	return &Verifier{
+		Resolver:      getTestResolver(),
+		MXHosts:       []string{".baruwa.example.net"},
+		SPFIncludes:   []string{"include:spf.baruwa.example.net"},
+		DKIMSelectors: []string{"baruwa", "default"},
+	}
+}
+
+func TestVerifyError(t *testing.T) {
+	v := getTestVerifier()
+	if _, err := v.Verify(&api.Domain{}, nil); err == nil {
+		t.Fatalf("An error should be returned")
+	} else if err.Error() != domainNameError {
+		t.Errorf("Expected '%s' got '%s'", domainNameError, err)
+	}
+
+	v.MXHosts = nil
+	if _, err := v.Verify(&api.Domain{Name: "example.com"}, nil); err == nil {
+		t.Fatalf("An error should be returned")
+	} else if err.Error() != mxHostsError {
+		t.Errorf("Expected '%s' got '%s'", mxHostsError, err)
+	}
+}
+
+func TestCheckReady(t *testing.T) {
+	v := getTestVerifier()
+	d := v.Check("Example.com.")
+	if !d.Ready {
+		t.Fatalf("Expected example.com to be ready: %+v", d)
+	}
+	for _, c := range []Check{d.MX, d.SPF, d.DMARC, d.DKIM} {
+		if c.Status != Pass {
+			t.Errorf("Expected %s got %s: %s", Pass, c.Status, c.Detail)
+		}
+	}
+	if len(d.MX.Records) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(d.MX.Records))
+	}
+	if len(d.DKIM.Records) != 1 || d.DKIM.Records[0] != "baruwa" {
+		t.Errorf("Expected the baruwa selector got %v", d.DKIM.Records)
+	}
+}
+
+func TestCheckNotReady(t *testing.T) {
+	v := getTestVerifier()
+	d := v.Check("example.org")
+	if d.Ready {
+		t.Fatalf("Expected example.org not to be ready")
+	}
+	if d.MX.Status != Warn {
+		t.Errorf("Expected %s got %s", Warn, d.MX.Status)
+	}
+	if d.SPF.Status != Fail {
+		t.Errorf("Expected %s got %s", Fail, d.SPF.Status)
+	}
+	if d.DMARC.Status != Warn {
+		t.Errorf("Expected %s got %s", Warn, d.DMARC.Status)
+	}
+	if d.DKIM.Status != Pass {
+		t.Errorf("Expected %s got %s", Pass, d.DKIM.Status)
+	}
+
+	d = v.Check("example.net")
+	if d.MX.Status != Fail {
+		t.Errorf("Expected %s got %s", Fail, d.MX.Status)
+	}
+	if d.SPF.Status != Fail || d.SPF.Detail != "multiple SPF records" {
+		t.Errorf("Expected %s got %s: %s", Fail, d.SPF.Status, d.SPF.Detail)
+	}
+	if d.DMARC.Status != Fail || d.DMARC.Detail != "DMARC record has no policy" {
+		t.Errorf("Expected %s got %s: %s", Fail, d.DMARC.Status, d.DMARC.Detail)
+	}
+	if d.DKIM.Status != Warn {
+		t.Errorf("Expected %s got %s", Warn, d.DKIM.Status)
+	}
+
+	d = v.Check("example.info")
+	if d.MX.Status != Fail {
+		t.Errorf("Expected %s got %s", Fail, d.MX.Status)
+	}
+
+	d = v.Check("example.biz")
+	if d.MX.Status != Fail || d.MX.Detail != "no MX records" {
+		t.Errorf("Expected %s got %s: %s", Fail, d.MX.Status, d.MX.Detail)
+	}
+	if d.SPF.Status != Warn || d.DMARC.Status != Warn || d.DKIM.Status != Warn {
+		t.Errorf("Expected missing records to be warnings")
+	}
+}
+
+func TestCheckSPF(t *testing.T) {
+	tests := []struct {
+		record string
+		status Status
+	}{
+		{"v=spf1 include:spf.baruwa.example.net ~all", Pass},
+		{"v=spf1 include:spf.baruwa.example.net", Warn},
+		{"v=spf1 include:spf.baruwa.example.net redirect=_spf.example.com", Pass},
+		{"v=spf1 mx -all", Warn},
+		{"v=spf1 include:spf.baruwa.example.net all", Fail},
+		{"v=spf10 -all", Warn},
+	}
+
+	for _, tt := range tests {
+		v := getTestVerifier()
+		v.Resolver = &fakeResolver{txt: map[string][]string{"example.com": {tt.record}}}
+		if c := v.checkSPF("example.com"); c.Status != tt.status {
+			t.Errorf("%s: expected %s got %s", tt.record, tt.status, c.Status)
+		}
+	}
+}
+
+func TestCheckDKIMSkip(t *testing.T) {
+	v := getTestVerifier()
+	v.DKIMSelectors = nil
+	if c := v.checkDKIM("example.com"); c.Status != Skip {
+		t.Errorf("Expected %s got %s", Skip, c.Status)
+	}
+}
+
+func TestVerifyDomain(t *testing.T) {
+	c := &fakeClient{
+		domain: &api.Domain{ID: 1, Name: "example.com", Enabled: true},
+		aliases: []api.DomainAlias{
+			{ID: 1, Name: "example.org", Enabled: false},
+			{ID: 2, Name: "example.com.au", Enabled: true},
+		},
+	}
+
+	v := getTestVerifier()
+	v.Resolver.(*fakeResolver).mx["example.com.au"] = []*net.MX{{Host: "mx1.baruwa.example.net", Pref: 10}}
+
+	r, err := v.VerifyDomain(c, 1)
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	if len(r.Domains) != 3 {
+		t.Fatalf("Expected %d got %d", 3, len(r.Domains))
+	}
+	if !r.Domains[1].Alias || r.Domains[1].Ready {
+		t.Errorf("Expected example.org to be an alias that is not ready")
+	}
+	if !r.Ready {
+		t.Errorf("Expected report to be ready, disabled aliases are ignored")
+	}
+
+	c.aliases[0].Enabled = true
+	if r, err = v.VerifyDomain(c, 1); err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	if r.Ready {
+		t.Errorf("Expected report not to be ready")
+	}
+
+	if _, err = v.VerifyDomain(c, 2); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
@@ -0,0 +1,256 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package drift
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/diff"
+)
+
+// Severity is the importance of a difference
+type Severity string
+
+const (
+	// Info cosmetic differences such as descriptions
+	Info Severity = "info"
+	// Warning differences that change behaviour in minor ways
+	Warning Severity = "warning"
+	// Critical differences that affect mail flow or security
+	Critical Severity = "critical"
+)
+
+// severities lists the severities from lowest to highest
+var severities = []Severity{Info, Warning, Critical}
+
+func (s Severity) rank() int {
+	switch s {
+	case Info:
+		return 1
+	case Warning:
+		return 2
+	case Critical:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// defaultSeverities holds the severity of fields by JSON name, fields
+// not listed are a Warning. The "missing" and "unexpected" keys are
+// used for resources that are absent or not in the snapshot.
+var defaultSeverities = map[string]Severity{
+	"missing":          Critical,
+	"unexpected":       Warning,
+	"status":           Critical,
+	"enabled":          Critical,
+	"accept_inbound":   Critical,
+	"discard_mail":     Critical,
+	"virus_checks":     Critical,
+	"spam_checks":      Critical,
+	"delivery_mode":    Critical,
+	"address":          Critical,
+	"port":             Critical,
+	"protocol":         Critical,
+	"require_tls":      Critical,
+	"allow_allsenders": Critical,
+	"description":      Info,
+	"site_url":         Info,
+	"language":         Info,
+	"timezone":         Info,
+	"report_every":     Info,
+}
+
+// owners are the server fields that refer back to the organization or
+// domain the server was listed under
+var owners = []string{"domain", "organization"}
+
+func (d *Detector) severity(kind Kind, field string) Severity {
+	if s, ok := d.Severities[string(kind)+"."+field]; ok {
+		return s
+	}
+
+	if s, ok := d.Severities[field]; ok {
+		return s
+	}
+
+	if s, ok := defaultSeverities[field]; ok {
+		return s
+	}
+
+	return Warning
+}
+
+func (d *Detector) ignored(kind Kind, field string) bool {
+	for _, v := range d.Ignore {
+		if v == field || v == string(kind)+"."+field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Detector) missing(r *Report, kind Kind, owner, name string, id int) {
+	r.Differences = append(r.Differences, Difference{
+		Kind:     kind,
+		Change:   Missing,
+		Owner:    owner,
+		Name:     name,
+		ID:       id,
+		Severity: d.severity(kind, "missing"),
+	})
+}
+
+// compare records the differing fields of two values of the same type
+// using diff.Compare, omitted fields are skipped and only the listed
+// fields are compared when fields is not empty. The entries of a list
+// that differ are reported in a single difference, Expected holding the
+// entries the server lacks and Actual those it should not have.
+func (d *Detector) compare(r *Report, kind Kind, owner, name string, id int, expected, actual interface{}, fields, omit []string) {
+	changes, err := diff.Compare(actual, expected, nil)
+	if err != nil {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s %s: %s", kind, name, err))
+		return
+	}
+
+	var last *Difference
+	for _, c := range changes {
+		field := c.Path
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[:i]
+		}
+		if d.ignored(kind, field) || contains(omit, field) || len(fields) > 0 && !contains(fields, field) {
+			continue
+		}
+		if last == nil || last.Field != c.Path {
+			r.Differences = append(r.Differences, Difference{
+				Kind:     kind,
+				Change:   Modified,
+				Owner:    owner,
+				Name:     name,
+				ID:       id,
+				Field:    c.Path,
+				Severity: d.severity(kind, field),
+			})
+			last = &r.Differences[len(r.Differences)-1]
+		}
+		switch c.Op {
+		case diff.Added:
+			last.Expected = join(last.Expected, c.To)
+		case diff.Removed:
+			last.Actual = join(last.Actual, c.From)
+		default:
+			last.Expected, last.Actual = c.To, c.From
+		}
+	}
+}
+
+func join(l, s string) string {
+	if l == "" {
+		return s
+	}
+
+	return l + "," + s
+}
+
+// compareList matches servers by ID, or by address and port when the
+// expected server has no ID, and compares the matched pairs. A zero port
+// matches any port and is not compared.
+func (d *Detector) compareList(r *Report, kind Kind, owner string, expected, actual []interface{}) {
+	matched := make([]bool, len(actual))
+
+	for _, e := range expected {
+		addr, id, port := identify(e)
+		found := -1
+		for i, a := range actual {
+			if matched[i] {
+				continue
+			}
+			aaddr, aid, aport := identify(a)
+			if id > 0 {
+				if id == aid {
+					found = i
+					break
+				}
+				continue
+			}
+			if strings.EqualFold(addr, aaddr) && (port == 0 || port == aport) {
+				found = i
+				break
+			}
+		}
+		if found < 0 {
+			d.missing(r, kind, owner, serverName(addr, port), id)
+			continue
+		}
+		omit := owners
+		if port == 0 {
+			omit = append([]string{"port"}, owners...)
+		}
+		matched[found] = true
+		_, aid, _ := identify(actual[found])
+		d.compare(r, kind, owner, serverName(addr, port), aid, e, actual[found], nil, omit)
+	}
+
+	for i, a := range actual {
+		if matched[i] {
+			continue
+		}
+		addr, id, port := identify(a)
+		r.Differences = append(r.Differences, Difference{
+			Kind:     kind,
+			Change:   Unexpected,
+			Owner:    owner,
+			Name:     serverName(addr, port),
+			ID:       id,
+			Severity: d.severity(kind, "unexpected"),
+		})
+	}
+}
+
+// identify returns the address, ID and port of a server
+func identify(v interface{}) (addr string, id, port int) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	if f := rv.FieldByName("ID"); f.IsValid() {
+		id = int(f.Int())
+	}
+
+	if f := rv.FieldByName("Port"); f.IsValid() {
+		port = int(f.Int())
+	}
+
+	if f := rv.FieldByName("Address"); f.IsValid() {
+		addr = f.String()
+	}
+
+	return
+}
+
+func serverName(addr string, port int) string {
+	if port > 0 {
+		return net.JoinHostPort(addr, strconv.Itoa(port))
+	}
+
+	return addr
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,540 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package drift Configuration drift detection for Baruwa resources
+
+A Snapshot holds the expected state of organizations and domains along
+with their smarthosts, delivery, fallback, relay and authentication
+servers. It is either captured from a running system using Capture or
+declared by hand. A Detector compares a Snapshot with the live state and
+reports field level differences, each with a Severity. Fields are
+compared with diff.Compare so both packages apply the same rules.
+
+	s, err := drift.Capture(c, nil, []int{10})
+	...
+	d := &drift.Detector{}
+	r, err := d.Detect(c, s)
+	for _, diff := range r.Differences {
+		fmt.Println(diff)
+	}
+
+A Monitor runs the Detector on a schedule and writes each Report to
+sinks such as JSONSink or Metrics, which exposes the results in the
+Prometheus text format.
+*/
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	snapshotParamError = "The snapshot param is required"
+	clientParamError   = "The client param is required"
+	relayIDError       = "The relay setting %s of %s has no ID"
+)
+
+// Kind is the type of resource a difference was found in
+type Kind string
+
+const (
+	// Domain a domain
+	Domain Kind = "domain"
+	// DomainDeliveryServer a domain delivery server
+	DomainDeliveryServer Kind = "domain-delivery-server"
+	// DomainSmartHost a domain smarthost
+	DomainSmartHost Kind = "domain-smarthost"
+	// AuthServer a domain authentication server
+	AuthServer Kind = "auth-server"
+	// Organization an organization
+	Organization Kind = "organization"
+	// OrgSmartHost an organization smarthost
+	OrgSmartHost Kind = "org-smarthost"
+	// FallBackServer an organization fallback server
+	FallBackServer Kind = "fallback-server"
+	// RelaySetting an organization relay setting
+	RelaySetting Kind = "relay-setting"
+)
+
+// Change is the type of difference
+type Change string
+
+const (
+	// Modified a field differs
+	Modified Change = "modified"
+	// Missing the resource does not exist
+	Missing Change = "missing"
+	// Unexpected the resource exists but is not in the snapshot
+	Unexpected Change = "unexpected"
+)
+
+// Client is the subset of api.Client used to read the live state
+type Client interface {
+	GetOrganization(orgID int) (*api.Organization, error)
+	GetOrganizations(opts *api.ListOptions) (*api.OrganizationList, error)
+	GetOrgSmartHosts(organizationID int, opts *api.ListOptions) (*api.OrgSmartHostList, error)
+	GetFallBackServers(organizationID int, opts *api.ListOptions) (*api.FallBackServerList, error)
+	GetRelaySetting(relayID int) (*api.RelaySetting, error)
+	GetDomain(domainID int) (*api.Domain, error)
+	GetDomainByName(domainName string) (*api.Domain, error)
+	GetDomainDeliveryServers(domainID int, opts *api.ListOptions) (*api.DomainDeliveryServerList, error)
+	GetDomainSmartHosts(domainID int, opts *api.ListOptions) (*api.DomainSmartHostList, error)
+	GetAuthServers(domainID int, opts *api.ListOptions) (*api.AuthServerList, error)
+}
+
+var _ Client = (*api.Client)(nil)
+
+// Snapshot holds the expected state
+type Snapshot struct {
+	Taken         time.Time           `json:"taken"`
+	Organizations []OrganizationState `json:"organizations"`
+	Domains       []DomainState       `json:"domains"`
+}
+
+// OrganizationState holds an organization and its servers, a nil list
+// is not checked while an empty list expects no servers. The
+// organization is looked up by name when its ID is 0. Relay settings
+// are matched by ID as the API does not list them.
+type OrganizationState struct {
+	Organization    api.Organization     `json:"organization"`
+	SmartHosts      []api.OrgSmartHost   `json:"smarthosts"`
+	FallBackServers []api.FallBackServer `json:"fallback_servers"`
+	RelaySettings   []api.RelaySetting   `json:"relay_settings"`
+}
+
+// DomainState holds a domain and its servers, a nil list is not
+// checked while an empty list expects no servers. Fields limits the
+// domain fields compared, using their JSON names, which allows partial
+// declarations, all fields are compared when empty.
+type DomainState struct {
+	Domain          api.Domain                 `json:"domain"`
+	Fields          []string                   `json:"fields,omitempty"`
+	DeliveryServers []api.DomainDeliveryServer `json:"delivery_servers"`
+	SmartHosts      []api.DomainSmartHost      `json:"smarthosts"`
+	AuthServers     []api.AuthServer           `json:"auth_servers"`
+}
+
+// Difference holds a single difference between the snapshot and the
+// live state
+type Difference struct {
+	Kind     Kind     `json:"kind"`
+	Change   Change   `json:"change"`
+	Owner    string   `json:"owner"`
+	Name     string   `json:"name"`
+	ID       int      `json:"id,omitempty"`
+	Field    string   `json:"field,omitempty"`
+	Expected string   `json:"expected,omitempty"`
+	Actual   string   `json:"actual,omitempty"`
+	Severity Severity `json:"severity"`
+}
+
+func (d Difference) String() string {
+	name := d.Name
+	if d.Owner != "" && d.Owner != d.Name {
+		name = d.Owner + "/" + d.Name
+	}
+
+	switch d.Change {
+	case Missing:
+		return fmt.Sprintf("[%s] %s %s is missing", d.Severity, d.Kind, name)
+	case Unexpected:
+		return fmt.Sprintf("[%s] %s %s is not expected", d.Severity, d.Kind, name)
+	default:
+		return fmt.Sprintf("[%s] %s %s %s: expected %q got %q", d.Severity, d.Kind, name, d.Field, d.Expected, d.Actual)
+	}
+}
+
+// Report holds the outcome of a detection run
+type Report struct {
+	Started     time.Time    `json:"started"`
+	Finished    time.Time    `json:"finished"`
+	Differences []Difference `json:"differences"`
+	Errors      []string     `json:"errors,omitempty"`
+}
+
+// Drifted returns true if any differences were found
+func (r *Report) Drifted() bool {
+	return len(r.Differences) > 0
+}
+
+// Highest returns the highest severity found, an empty Severity is
+// returned when there are no differences
+func (r *Report) Highest() (s Severity) {
+	for _, d := range r.Differences {
+		if d.Severity.rank() > s.rank() {
+			s = d.Severity
+		}
+	}
+
+	return
+}
+
+// Count returns the number of differences with the severity
+func (r *Report) Count(s Severity) (n int) {
+	for _, d := range r.Differences {
+		if d.Severity == s {
+			n++
+		}
+	}
+
+	return
+}
+
+// Detector compares snapshots with the live state
+type Detector struct {
+	// Severities overrides the default severity of fields, keys are
+	// either a JSON field name such as "spam_checks" or a kind and field
+	// such as "domain.spam_checks", the latter takes precedence
+	Severities map[string]Severity
+	// Ignore lists fields that are not compared, using the same keys
+	// as Severities
+	Ignore []string
+}
+
+// Detect compares the snapshot with the live state, errors reading a
+// resource are recorded in the report and do not stop the run
+func (d *Detector) Detect(c Client, s *Snapshot) (r *Report, err error) {
+	if c == nil {
+		err = fmt.Errorf(clientParamError)
+		return
+	}
+
+	if s == nil {
+		err = fmt.Errorf(snapshotParamError)
+		return
+	}
+
+	r = &Report{
+		Started:     time.Now(),
+		Differences: []Difference{},
+	}
+
+	for i := range s.Organizations {
+		if e := d.organization(c, &s.Organizations[i], r); e != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("organization %s: %s", s.Organizations[i].Organization.Name, e))
+		}
+	}
+
+	for i := range s.Domains {
+		if e := d.domain(c, &s.Domains[i], r); e != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("domain %s: %s", s.Domains[i].Domain.Name, e))
+		}
+	}
+
+	r.Finished = time.Now()
+
+	return
+}
+
+func (d *Detector) organization(c Client, s *OrganizationState, r *Report) (err error) {
+	var org *api.Organization
+
+	if s.Organization.ID > 0 {
+		org, err = c.GetOrganization(s.Organization.ID)
+	} else {
+		org, err = organizationByName(c, s.Organization.Name)
+	}
+	if err != nil || org == nil {
+		if err == nil || api.IsNotFound(err) {
+			err = nil
+			d.missing(r, Organization, s.Organization.Name, s.Organization.Name, s.Organization.ID)
+		}
+		return
+	}
+
+	owner := s.Organization.Name
+	d.compare(r, Organization, owner, owner, org.ID, &s.Organization, org, nil, nil)
+
+	if s.SmartHosts != nil {
+		var live []api.OrgSmartHost
+		if live, err = orgSmartHosts(c, org.ID); err != nil {
+			return
+		}
+		expected := make([]interface{}, len(s.SmartHosts))
+		for i := range s.SmartHosts {
+			expected[i] = &s.SmartHosts[i]
+		}
+		actual := make([]interface{}, len(live))
+		for i := range live {
+			actual[i] = &live[i]
+		}
+		d.compareList(r, OrgSmartHost, owner, expected, actual)
+	}
+
+	if s.FallBackServers != nil {
+		var live []api.FallBackServer
+		if live, err = fallBackServers(c, org.ID); err != nil {
+			return
+		}
+		expected := make([]interface{}, len(s.FallBackServers))
+		for i := range s.FallBackServers {
+			expected[i] = &s.FallBackServers[i]
+		}
+		actual := make([]interface{}, len(live))
+		for i := range live {
+			actual[i] = &live[i]
+		}
+		d.compareList(r, FallBackServer, owner, expected, actual)
+	}
+
+	for i := range s.RelaySettings {
+		var relay *api.RelaySetting
+		expected := &s.RelaySettings[i]
+		if expected.ID <= 0 {
+			err = fmt.Errorf(relayIDError, expected.Address, owner)
+			return
+		}
+		if relay, err = c.GetRelaySetting(expected.ID); err != nil {
+			if !api.IsNotFound(err) {
+				return
+			}
+			err = nil
+			d.missing(r, RelaySetting, owner, expected.Address, expected.ID)
+			continue
+		}
+		d.compare(r, RelaySetting, owner, expected.Address, relay.ID, expected, relay, nil, nil)
+	}
+
+	return
+}
+
+func (d *Detector) domain(c Client, s *DomainState, r *Report) (err error) {
+	var domain *api.Domain
+
+	if s.Domain.ID > 0 {
+		domain, err = c.GetDomain(s.Domain.ID)
+	} else {
+		domain, err = c.GetDomainByName(s.Domain.Name)
+	}
+	if err != nil {
+		if api.IsNotFound(err) {
+			err = nil
+			d.missing(r, Domain, s.Domain.Name, s.Domain.Name, s.Domain.ID)
+		}
+		return
+	}
+
+	owner := s.Domain.Name
+	d.compare(r, Domain, owner, owner, domain.ID, &s.Domain, domain, s.Fields, nil)
+
+	if s.DeliveryServers != nil {
+		var live []api.DomainDeliveryServer
+		if live, err = deliveryServers(c, domain.ID); err != nil {
+			return
+		}
+		expected := make([]interface{}, len(s.DeliveryServers))
+		for i := range s.DeliveryServers {
+			expected[i] = &s.DeliveryServers[i]
+		}
+		actual := make([]interface{}, len(live))
+		for i := range live {
+			actual[i] = &live[i]
+		}
+		d.compareList(r, DomainDeliveryServer, owner, expected, actual)
+	}
+
+	if s.SmartHosts != nil {
+		var live []api.DomainSmartHost
+		if live, err = domainSmartHosts(c, domain.ID); err != nil {
+			return
+		}
+		expected := make([]interface{}, len(s.SmartHosts))
+		for i := range s.SmartHosts {
+			expected[i] = &s.SmartHosts[i]
+		}
+		actual := make([]interface{}, len(live))
+		for i := range live {
+			actual[i] = &live[i]
+		}
+		d.compareList(r, DomainSmartHost, owner, expected, actual)
+	}
+
+	if s.AuthServers != nil {
+		var live []api.AuthServer
+		if live, err = authServers(c, domain.ID); err != nil {
+			return
+		}
+		expected := make([]interface{}, len(s.AuthServers))
+		for i := range s.AuthServers {
+			expected[i] = &s.AuthServers[i]
+		}
+		actual := make([]interface{}, len(live))
+		for i := range live {
+			actual[i] = &live[i]
+		}
+		d.compareList(r, AuthServer, owner, expected, actual)
+	}
+
+	return
+}
+
+// Capture reads the current state of the organizations and domains
+// into a Snapshot
+func Capture(c Client, orgIDs, domainIDs []int) (s *Snapshot, err error) {
+	if c == nil {
+		err = fmt.Errorf(clientParamError)
+		return
+	}
+
+	s = &Snapshot{
+		Taken:         time.Now(),
+		Organizations: []OrganizationState{},
+		Domains:       []DomainState{},
+	}
+
+	for _, id := range orgIDs {
+		var org *api.Organization
+		o := OrganizationState{}
+		if org, err = c.GetOrganization(id); err != nil {
+			return
+		}
+		o.Organization = *org
+		if o.SmartHosts, err = orgSmartHosts(c, id); err != nil {
+			return
+		}
+		if o.FallBackServers, err = fallBackServers(c, id); err != nil {
+			return
+		}
+		s.Organizations = append(s.Organizations, o)
+	}
+
+	for _, id := range domainIDs {
+		var domain *api.Domain
+		d := DomainState{}
+		if domain, err = c.GetDomain(id); err != nil {
+			return
+		}
+		d.Domain = *domain
+		if d.DeliveryServers, err = deliveryServers(c, id); err != nil {
+			return
+		}
+		if d.SmartHosts, err = domainSmartHosts(c, id); err != nil {
+			return
+		}
+		if d.AuthServers, err = authServers(c, id); err != nil {
+			return
+		}
+		s.Domains = append(s.Domains, d)
+	}
+
+	return
+}
+
+// LoadSnapshot reads a JSON encoded snapshot
+func LoadSnapshot(r io.Reader) (s *Snapshot, err error) {
+	s = &Snapshot{}
+	if err = json.NewDecoder(r).Decode(s); err != nil {
+		s = nil
+	}
+
+	return
+}
+
+// Save writes the snapshot as JSON
+func (s *Snapshot) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
+
+// organizationByName returns the organization with the name, nil is
+// returned when there is none
+func organizationByName(c Client, name string) (org *api.Organization, err error) {
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrganizationList
+		if l, err = c.GetOrganizations(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if l.Items[i].Name == name {
+				org = &l.Items[i]
+				break
+			}
+		}
+		links, done = l.Links, org != nil || len(l.Items) == 0
+		return
+	})
+
+	return
+}
+
+func orgSmartHosts(c Client, id int) (items []api.OrgSmartHost, err error) {
+	items = []api.OrgSmartHost{}
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrgSmartHostList
+		if l, err = c.GetOrgSmartHosts(id, opts); err == nil {
+			items = append(items, l.Items...)
+			links, done = l.Links, len(l.Items) == 0
+		}
+		return
+	})
+
+	return
+}
+
+func fallBackServers(c Client, id int) (items []api.FallBackServer, err error) {
+	items = []api.FallBackServer{}
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.FallBackServerList
+		if l, err = c.GetFallBackServers(id, opts); err == nil {
+			items = append(items, l.Items...)
+			links, done = l.Links, len(l.Items) == 0
+		}
+		return
+	})
+
+	return
+}
+
+func deliveryServers(c Client, id int) (items []api.DomainDeliveryServer, err error) {
+	items = []api.DomainDeliveryServer{}
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainDeliveryServerList
+		if l, err = c.GetDomainDeliveryServers(id, opts); err == nil {
+			items = append(items, l.Items...)
+			links, done = l.Links, len(l.Items) == 0
+		}
+		return
+	})
+
+	return
+}
+
+func domainSmartHosts(c Client, id int) (items []api.DomainSmartHost, err error) {
+	items = []api.DomainSmartHost{}
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainSmartHostList
+		if l, err = c.GetDomainSmartHosts(id, opts); err == nil {
+			items = append(items, l.Items...)
+			links, done = l.Links, len(l.Items) == 0
+		}
+		return
+	})
+
+	return
+}
+
+func authServers(c Client, id int) (items []api.AuthServer, err error) {
+	items = []api.AuthServer{}
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.AuthServerList
+		if l, err = c.GetAuthServers(id, opts); err == nil {
+			items = append(items, l.Items...)
+			links, done = l.Links, len(l.Items) == 0
+		}
+		return
+	})
+
+	return
+}
@@ -0,0 +1,401 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package drift
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeClient struct {
+	org             *api.Organization
+	orgSmartHosts   []api.OrgSmartHost
+	fallBackServers []api.FallBackServer
+	relays          map[int]*api.RelaySetting
+	domain          *api.Domain
+	deliveryServers []api.DomainDeliveryServer
+	smartHosts      []api.DomainSmartHost
+	authServers     []api.AuthServer
+	fail            error
+}
+
+func notFound() error {
+	return &api.ErrorResponse{
+		Code:     http.StatusNotFound,
+		Message:  "Not Found",
+		Response: &http.Response{Request: &http.Request{Method: "GET", URL: &url.URL{Path: "/"}}},
+	}
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		org: &api.Organization{ID: 1, Name: "Example Inc"},
+		orgSmartHosts: []api.OrgSmartHost{
+			{ID: 11, Address: "smtp.example.net", Port: 587, RequireTLS: true, Enabled: true, Username: "relay", Description: "outbound"},
+		},
+		fallBackServers: []api.FallBackServer{
+			{ID: 12, Address: "192.168.1.150", Port: 25, Protocol: 1, Enabled: true, Organization: &api.FallBackServerOrg{ID: 1, Name: "Example Inc"}},
+		},
+		relays: map[int]*api.RelaySetting{
+			13: {ID: 13, Address: "192.168.1.20", Enabled: true, LowScore: 5, HighScore: 10, SpamActions: 2, RateLimit: 250},
+		},
+		domain: &api.Domain{
+			ID:            2,
+			Name:          "example.com",
+			SiteURL:       "https://mail.example.com",
+			Enabled:       true,
+			AcceptInbound: true,
+			VirusChecks:   true,
+			SpamChecks:    true,
+			LowScore:      0,
+			HighScore:     8.5,
+			DeliveryMode:  1,
+			Language:      "en",
+			Timezone:      "Africa/Johannesburg",
+			Organizations: []int{1, 3},
+		},
+		deliveryServers: []api.DomainDeliveryServer{
+			{ID: 21, Address: "192.168.1.10", Port: 25, Protocol: 1, Enabled: true, Domain: &api.AliasDomain{ID: 2, Name: "example.com"}},
+			{ID: 22, Address: "192.168.1.11", Port: 25, Protocol: 1, Enabled: true},
+		},
+		smartHosts: []api.DomainSmartHost{
+			{ID: 23, Address: "smarthost.example.net", Port: 25, Enabled: true, Password: "secret"},
+		},
+		authServers: []api.AuthServer{
+			{ID: 24, Address: "ldap.example.com", Port: 389, Protocol: 5, Enabled: true, UserMapTemplate: "%(user)s"},
+		},
+	}
+}
+
+func (f *fakeClient) GetOrganization(orgID int) (*api.Organization, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	if f.org == nil || f.org.ID != orgID {
+		return nil, notFound()
+	}
+	o := *f.org
+	return &o, nil
+}
+
+// GetOrganizations returns another organization on the first page
+func (f *fakeClient) GetOrganizations(opts *api.ListOptions) (*api.OrganizationList, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	l := &api.OrganizationList{}
+	if opts == nil {
+		l.Items = []api.Organization{{ID: 5, Name: "Other Org"}}
+		l.Links.Pages.Next = "2"
+	} else if f.org != nil {
+		l.Items = []api.Organization{*f.org}
+	}
+	return l, nil
+}
+
+func (f *fakeClient) GetOrgSmartHosts(organizationID int, opts *api.ListOptions) (*api.OrgSmartHostList, error) {
+	return &api.OrgSmartHostList{Items: append([]api.OrgSmartHost(nil), f.orgSmartHosts...)}, nil
+}
+
+func (f *fakeClient) GetFallBackServers(organizationID int, opts *api.ListOptions) (*api.FallBackServerList, error) {
+	return &api.FallBackServerList{Items: append([]api.FallBackServer(nil), f.fallBackServers...)}, nil
+}
+
+func (f *fakeClient) GetRelaySetting(relayID int) (*api.RelaySetting, error) {
+	r, ok := f.relays[relayID]
+	if !ok {
+		return nil, notFound()
+	}
+	v := *r
+	return &v, nil
+}
+
+func (f *fakeClient) GetDomain(domainID int) (*api.Domain, error) {
+	if f.domain == nil || f.domain.ID != domainID {
+		return nil, notFound()
+	}
+	d := *f.domain
+	return &d, nil
+}
+
+func (f *fakeClient) GetDomainByName(domainName string) (*api.Domain, error) {
+	if f.domain == nil || f.domain.Name != domainName {
+		return nil, notFound()
+	}
+	d := *f.domain
+	return &d, nil
+}
+
+// GetDomainDeliveryServers returns one server per page
+func (f *fakeClient) GetDomainDeliveryServers(domainID int, opts *api.ListOptions) (*api.DomainDeliveryServerList, error) {
+	var page int
+
+	l := &api.DomainDeliveryServerList{}
+	if opts != nil {
+		fmt.Sscanf(opts.Page, "%d", &page)
+	}
+	if page < len(f.deliveryServers) {
+		l.Items = []api.DomainDeliveryServer{f.deliveryServers[page]}
+		if page+1 < len(f.deliveryServers) {
+			l.Links.Pages.Next = fmt.Sprint(page + 1)
+		}
+	}
+	return l, nil
+}
+
+func (f *fakeClient) GetDomainSmartHosts(domainID int, opts *api.ListOptions) (*api.DomainSmartHostList, error) {
+	l := &api.DomainSmartHostList{}
+	for _, s := range f.smartHosts {
+		s.Password = ""
+		l.Items = append(l.Items, s)
+	}
+	return l, nil
+}
+
+func (f *fakeClient) GetAuthServers(domainID int, opts *api.ListOptions) (*api.AuthServerList, error) {
+	return &api.AuthServerList{Items: append([]api.AuthServer(nil), f.authServers...)}, nil
+}
+
+func getSnapshot(t *testing.T, c *fakeClient) *Snapshot {
+	s, err := Capture(c, []int{1}, []int{2})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	s.Organizations[0].RelaySettings = []api.RelaySetting{*c.relays[13]}
+	return s
+}
+
+func diffStrings(r *Report) (l []string) {
+	for _, d := range r.Differences {
+		l = append(l, d.String())
+	}
+	sort.Strings(l)
+	return
+}
+
+func TestCapture(t *testing.T) {
+	c := newFakeClient()
+
+	if _, err := Capture(nil, nil, nil); err == nil || err.Error() != clientParamError {
+		t.Errorf("Expected '%s' got '%v'", clientParamError, err)
+	}
+
+	s := getSnapshot(t, c)
+	if len(s.Organizations) != 1 || len(s.Domains) != 1 {
+		t.Fatalf("Expected 1 organization and domain got %d and %d", len(s.Organizations), len(s.Domains))
+	}
+	if len(s.Domains[0].DeliveryServers) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(s.Domains[0].DeliveryServers))
+	}
+
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	loaded, err := LoadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if !reflect.DeepEqual(loaded.Domains, s.Domains) {
+		t.Errorf("Expected %v got %v", s.Domains, loaded.Domains)
+	}
+
+	if _, err = LoadSnapshot(strings.NewReader("{")); err == nil {
+		t.Errorf("An error should be returned")
+	}
+
+	d := &Detector{}
+	r, err := d.Detect(c, loaded)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if r.Drifted() || len(r.Errors) != 0 {
+		t.Errorf("Expected no drift got %v %v", diffStrings(r), r.Errors)
+	}
+	if r.Highest() != "" {
+		t.Errorf("Expected an empty severity got %s", r.Highest())
+	}
+}
+
+func TestDetect(t *testing.T) {
+	c := newFakeClient()
+	s := getSnapshot(t, c)
+
+	c.org.Name = "Example Ltd"
+	c.orgSmartHosts[0].Description = "changed"
+	c.fallBackServers = nil
+	c.relays[13].HighScore = 12
+	c.relays[13].Password1 = "ignored"
+	c.domain.SpamChecks = false
+	c.domain.HighScore = 9
+	c.domain.Organizations = []int{3, 1}
+	c.deliveryServers[0].Domain = nil
+	c.deliveryServers = append(c.deliveryServers, api.DomainDeliveryServer{ID: 25, Address: "192.168.1.12", Port: 2525})
+	c.smartHosts[0].Port = 587
+	c.authServers[0].Enabled = false
+
+	d := &Detector{}
+	if _, err := d.Detect(nil, s); err == nil || err.Error() != clientParamError {
+		t.Errorf("Expected '%s' got '%v'", clientParamError, err)
+	}
+	if _, err := d.Detect(c, nil); err == nil || err.Error() != snapshotParamError {
+		t.Errorf("Expected '%s' got '%v'", snapshotParamError, err)
+	}
+
+	r, err := d.Detect(c, s)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	expected := []string{
+		`[critical] auth-server example.com/ldap.example.com:389 enabled: expected "true" got "false"`,
+		`[critical] domain example.com spam_checks: expected "true" got "false"`,
+		`[critical] domain-smarthost example.com/smarthost.example.net:25 port: expected "25" got "587"`,
+		`[critical] fallback-server Example Inc/192.168.1.150:25 is missing`,
+		`[info] org-smarthost Example Inc/smtp.example.net:587 description: expected "outbound" got "changed"`,
+		`[warning] domain example.com high_score: expected "8.5" got "9.0"`,
+		`[warning] domain-delivery-server example.com/192.168.1.12:2525 is not expected`,
+		`[warning] organization Example Inc name: expected "Example Inc" got "Example Ltd"`,
+		`[warning] relay-setting Example Inc/192.168.1.20 high_score: expected "10.0" got "12.0"`,
+	}
+	if got := diffStrings(r); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if r.Highest() != Critical {
+		t.Errorf("Expected %s got %s", Critical, r.Highest())
+	}
+	if r.Count(Critical) != 4 || r.Count(Warning) != 4 || r.Count(Info) != 1 {
+		t.Errorf("Expected 4/4/1 got %d/%d/%d", r.Count(Critical), r.Count(Warning), r.Count(Info))
+	}
+	for _, diff := range r.Differences {
+		if diff.Kind == DomainSmartHost && diff.ID != 23 {
+			t.Errorf("Expected %d got %d", 23, diff.ID)
+		}
+	}
+
+	d = &Detector{
+		Severities: map[string]Severity{
+			"description":        Critical,
+			"domain.spam_checks": Info,
+			"unexpected":         Info,
+		},
+		Ignore: []string{"name", "domain.high_score", "auth-server.enabled"},
+	}
+	if r, err = d.Detect(c, s); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected = []string{
+		`[critical] domain-smarthost example.com/smarthost.example.net:25 port: expected "25" got "587"`,
+		`[critical] fallback-server Example Inc/192.168.1.150:25 is missing`,
+		`[critical] org-smarthost Example Inc/smtp.example.net:587 description: expected "outbound" got "changed"`,
+		`[info] domain example.com spam_checks: expected "true" got "false"`,
+		`[info] domain-delivery-server example.com/192.168.1.12:2525 is not expected`,
+		`[warning] relay-setting Example Inc/192.168.1.20 high_score: expected "10.0" got "12.0"`,
+	}
+	if got := diffStrings(r); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestDetectDeclared(t *testing.T) {
+	c := newFakeClient()
+	s := &Snapshot{
+		Domains: []DomainState{
+			{
+				Domain: api.Domain{
+					Name:          "example.com",
+					SpamChecks:    true,
+					Language:      "fr",
+					Organizations: []int{1, 2},
+				},
+				Fields: []string{"spam_checks", "language", "organizations"},
+				DeliveryServers: []api.DomainDeliveryServer{
+					{Address: "192.168.1.11", Protocol: 1, Enabled: true},
+					{Address: "192.168.1.10", Port: 25, Protocol: 1, Enabled: true},
+				},
+				SmartHosts: []api.DomainSmartHost{},
+			},
+			{
+				Domain: api.Domain{Name: "example.org"},
+			},
+		},
+		Organizations: []OrganizationState{
+			{
+				Organization: api.Organization{Name: "Example Inc"},
+				SmartHosts:   []api.OrgSmartHost{},
+			},
+			{
+				Organization: api.Organization{Name: "Missing Org"},
+			},
+		},
+	}
+
+	d := &Detector{}
+	r, err := d.Detect(c, s)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	expected := []string{
+		`[critical] domain example.org is missing`,
+		`[critical] organization Missing Org is missing`,
+		`[info] domain example.com language: expected "fr" got "en"`,
+		`[warning] domain example.com organizations: expected "2" got "3"`,
+		`[warning] domain-smarthost example.com/smarthost.example.net:25 is not expected`,
+		`[warning] org-smarthost Example Inc/smtp.example.net:587 is not expected`,
+	}
+	if got := diffStrings(r); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestDetectErrors(t *testing.T) {
+	c := newFakeClient()
+	s := getSnapshot(t, c)
+
+	s.Organizations[0].RelaySettings = append(s.Organizations[0].RelaySettings, api.RelaySetting{ID: 14, Address: "192.168.1.21"})
+	d := &Detector{}
+	r, err := d.Detect(c, s)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	expected := []string{`[critical] relay-setting Example Inc/192.168.1.21 is missing`}
+	if got := diffStrings(r); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v got %v", expected, got)
+	}
+
+	s.Organizations[0].RelaySettings = []api.RelaySetting{{Address: "192.168.1.22"}}
+	c.domain.ID = 99
+	if r, err = d.Detect(c, s); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	e := fmt.Sprintf("organization Example Inc: "+relayIDError, "192.168.1.22", "Example Inc")
+	if len(r.Errors) != 1 || r.Errors[0] != e {
+		t.Errorf("Expected %v got %v", []string{e}, r.Errors)
+	}
+	expected = []string{`[critical] domain example.com is missing`}
+	if got := diffStrings(r); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v got %v", expected, got)
+	}
+
+	c.fail = fmt.Errorf("connection refused")
+	if r, err = d.Detect(c, s); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(r.Errors) != 1 || r.Errors[0] != "organization Example Inc: connection refused" {
+		t.Errorf("Expected the connection error got %v", r.Errors)
+	}
+}
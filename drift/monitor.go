@@ -0,0 +1,308 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is the interval between runs used by Start
+	DefaultInterval = time.Hour
+	// LatestReport is the name of the file JSONSink keeps the last
+	// report in
+	LatestReport = "latest.json"
+)
+
+const dirParamError = "The directory is required"
+
+var kinds = []Kind{
+	Organization,
+	OrgSmartHost,
+	FallBackServer,
+	RelaySetting,
+	Domain,
+	DomainDeliveryServer,
+	DomainSmartHost,
+	AuthServer,
+}
+
+// Sink receives the report of each run
+type Sink interface {
+	Write(r *Report) error
+}
+
+// Options represents optional settings that can be passed to NewMonitor
+type Options struct {
+	// Interval defaults to DefaultInterval
+	Interval time.Duration
+	// Detector defaults to a Detector using the default severities
+	Detector *Detector
+	// Sinks receive each report
+	Sinks []Sink
+}
+
+// Monitor runs drift detection on a schedule
+type Monitor struct {
+	client   Client
+	snapshot *Snapshot
+	detector *Detector
+	interval time.Duration
+	sinks    []Sink
+	last     *Report
+	err      error
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	run      sync.Mutex
+}
+
+// NewMonitor returns a Monitor comparing the snapshot with the live
+// state read using c
+func NewMonitor(c Client, s *Snapshot, opts *Options) (m *Monitor, err error) {
+	if c == nil {
+		err = fmt.Errorf(clientParamError)
+		return
+	}
+
+	if s == nil {
+		err = fmt.Errorf(snapshotParamError)
+		return
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	m = &Monitor{
+		client:   c,
+		snapshot: s,
+		detector: opts.Detector,
+		interval: opts.Interval,
+		sinks:    opts.Sinks,
+	}
+
+	if m.detector == nil {
+		m.detector = &Detector{}
+	}
+
+	if m.interval <= 0 {
+		m.interval = DefaultInterval
+	}
+
+	return
+}
+
+// Run detects drift once and writes the report to the sinks, every
+// sink is written to and the first sink error is returned
+func (m *Monitor) Run() (r *Report, err error) {
+	m.run.Lock()
+	defer m.run.Unlock()
+
+	if r, err = m.detector.Detect(m.client, m.snapshot); err == nil {
+		for _, s := range m.sinks {
+			if e := s.Write(r); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.last = r
+	m.err = err
+	m.mu.Unlock()
+
+	return
+}
+
+// Last returns the report and error of the last run
+func (m *Monitor) Last() (*Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.last, m.err
+}
+
+// Start runs drift detection in the background until Stop is called
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		return
+	}
+
+	m.stop = make(chan struct{})
+	m.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer m.wg.Done()
+		t := time.NewTicker(m.interval)
+		defer t.Stop()
+		m.Run()
+		for {
+			select {
+			case <-t.C:
+				m.Run()
+			case <-stop:
+				return
+			}
+		}
+	}(m.stop)
+}
+
+// Stop stops the background runs
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+// JSONSink writes each report to a timestamped JSON file in Dir and
+// keeps a copy of the last one in LatestReport
+type JSONSink struct {
+	Dir string
+}
+
+// Write writes the report
+func (s *JSONSink) Write(r *Report) (err error) {
+	var b []byte
+
+	if s.Dir == "" {
+		err = fmt.Errorf(dirParamError)
+		return
+	}
+
+	if b, err = json.MarshalIndent(r, "", "  "); err != nil {
+		return
+	}
+
+	name := fmt.Sprintf("drift-%s.json", r.Started.UTC().Format("20060102T150405.000Z"))
+	if err = writeFile(filepath.Join(s.Dir, name), b); err != nil {
+		return
+	}
+
+	err = writeFile(filepath.Join(s.Dir, LatestReport), b)
+
+	return
+}
+
+// Metrics exposes the last report in the Prometheus text format, it
+// can be registered as an http.Handler or, when Path is set, written
+// to a file for the node exporter textfile collector
+type Metrics struct {
+	// Path of the file the metrics are written to after each run
+	Path string
+
+	mu   sync.Mutex
+	runs int
+	last *Report
+}
+
+// Write records the report
+func (m *Metrics) Write(r *Report) (err error) {
+	m.mu.Lock()
+	m.runs++
+	m.last = r
+	m.mu.Unlock()
+
+	if m.Path != "" {
+		var buf bytes.Buffer
+		m.WriteTo(&buf)
+		err = writeFile(m.Path, buf.Bytes())
+	}
+
+	return
+}
+
+// WriteTo writes the metrics
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+
+	m.mu.Lock()
+	runs, r := m.runs, m.last
+	m.mu.Unlock()
+
+	fmt.Fprintln(&buf, "# HELP baruwa_drift_runs_total Number of drift detection runs.")
+	fmt.Fprintln(&buf, "# TYPE baruwa_drift_runs_total counter")
+	fmt.Fprintf(&buf, "baruwa_drift_runs_total %d\n", runs)
+
+	if r != nil {
+		counts := make(map[Kind]map[Severity]int)
+		for _, d := range r.Differences {
+			if counts[d.Kind] == nil {
+				counts[d.Kind] = make(map[Severity]int)
+			}
+			counts[d.Kind][d.Severity]++
+		}
+
+		fmt.Fprintln(&buf, "# HELP baruwa_drift_differences Number of differences found by the last run.")
+		fmt.Fprintln(&buf, "# TYPE baruwa_drift_differences gauge")
+		for _, k := range kinds {
+			for _, s := range severities {
+				fmt.Fprintf(&buf, "baruwa_drift_differences{kind=%q,severity=%q} %d\n", k, s, counts[k][s])
+			}
+		}
+
+		fmt.Fprintln(&buf, "# HELP baruwa_drift_errors Number of resources the last run failed to read.")
+		fmt.Fprintln(&buf, "# TYPE baruwa_drift_errors gauge")
+		fmt.Fprintf(&buf, "baruwa_drift_errors %d\n", len(r.Errors))
+
+		fmt.Fprintln(&buf, "# HELP baruwa_drift_last_run_timestamp_seconds Time the last run finished.")
+		fmt.Fprintln(&buf, "# TYPE baruwa_drift_last_run_timestamp_seconds gauge")
+		fmt.Fprintf(&buf, "baruwa_drift_last_run_timestamp_seconds %d\n", r.Finished.Unix())
+
+		fmt.Fprintln(&buf, "# HELP baruwa_drift_last_run_duration_seconds Duration of the last run.")
+		fmt.Fprintln(&buf, "# TYPE baruwa_drift_last_run_duration_seconds gauge")
+		fmt.Fprintf(&buf, "baruwa_drift_last_run_duration_seconds %g\n", r.Finished.Sub(r.Started).Seconds())
+	}
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP serves the metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// writeFile replaces a file atomically
+func writeFile(path string, b []byte) (err error) {
+	var f *os.File
+
+	if f, err = ioutil.TempFile(filepath.Dir(path), ".drift-"); err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(f.Name(), path)
+
+	return
+}
@@ -0,0 +1,166 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failSink struct {
+	n int
+}
+
+func (s *failSink) Write(r *Report) error {
+	s.n++
+	return fmt.Errorf("sink failed")
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "drift")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
+
+func TestNewMonitor(t *testing.T) {
+	c := newFakeClient()
+
+	if _, err := NewMonitor(nil, &Snapshot{}, nil); err == nil || err.Error() != clientParamError {
+		t.Errorf("Expected '%s' got '%v'", clientParamError, err)
+	}
+	if _, err := NewMonitor(c, nil, nil); err == nil || err.Error() != snapshotParamError {
+		t.Errorf("Expected '%s' got '%v'", snapshotParamError, err)
+	}
+	m, err := NewMonitor(c, &Snapshot{}, nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if m.interval != DefaultInterval || m.detector == nil {
+		t.Errorf("Expected the default options")
+	}
+}
+
+func TestMonitorRun(t *testing.T) {
+	dir := tempDir(t)
+	c := newFakeClient()
+	s := getSnapshot(t, c)
+	c.domain.SpamChecks = false
+	c.smartHosts = nil
+
+	metrics := &Metrics{Path: filepath.Join(dir, "drift.prom")}
+	m, _ := NewMonitor(c, s, &Options{
+		Sinks: []Sink{&JSONSink{Dir: dir}, metrics},
+	})
+
+	r, err := m.Run()
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if last, lerr := m.Last(); last != r || lerr != nil {
+		t.Errorf("Expected the last report to be returned")
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, LatestReport))
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	saved := &Report{}
+	if err = json.Unmarshal(b, saved); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(saved.Differences) != 2 || saved.Highest() != Critical {
+		t.Errorf("Expected %d critical differences got %v", 2, saved.Differences)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "drift-*.json"))
+	if len(files) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(files))
+	}
+
+	b, err = ioutil.ReadFile(metrics.Path)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for _, line := range []string{
+		"baruwa_drift_runs_total 1",
+		`baruwa_drift_differences{kind="domain",severity="critical"} 1`,
+		`baruwa_drift_differences{kind="domain-smarthost",severity="critical"} 1`,
+		`baruwa_drift_differences{kind="organization",severity="warning"} 0`,
+		"baruwa_drift_errors 0",
+		"# TYPE baruwa_drift_last_run_timestamp_seconds gauge",
+	} {
+		if !strings.Contains(string(b), line+"\n") {
+			t.Errorf("Expected the metrics to contain %q got\n%s", line, b)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected text/plain got %s", ct)
+	}
+	if w.Body.String() != string(b) {
+		t.Errorf("Expected\n%s\ngot\n%s", b, w.Body.String())
+	}
+
+	fail := &failSink{}
+	m, _ = NewMonitor(c, s, &Options{
+		Sinks: []Sink{fail, &JSONSink{}, metrics},
+	})
+	if _, err = m.Run(); err == nil || err.Error() != "sink failed" {
+		t.Errorf("Expected 'sink failed' got '%v'", err)
+	}
+	if _, err = m.Last(); err == nil {
+		t.Errorf("An error should be returned")
+	}
+	if fail.n != 1 || metrics.runs != 2 {
+		t.Errorf("Expected every sink to be written to")
+	}
+}
+
+func TestMonitorStart(t *testing.T) {
+	c := newFakeClient()
+	metrics := &Metrics{}
+	m, _ := NewMonitor(c, getSnapshot(t, c), &Options{
+		Interval: 10 * time.Millisecond,
+		Sinks:    []Sink{metrics},
+	})
+
+	m.Start()
+	m.Start()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		metrics.mu.Lock()
+		runs := metrics.runs
+		metrics.mu.Unlock()
+		if runs >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the monitor")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	m.Stop()
+	m.Stop()
+
+	if r, err := m.Last(); err != nil || r == nil || r.Drifted() {
+		t.Errorf("Expected a report without drift got %v %v", r, err)
+	}
+}
@@ -2,4 +2,7 @@ module github.com/baruwa-enterprise/baruwa-go
 
 go 1.14
 
-require github.com/google/go-querystring v1.0.0
+require (
+	github.com/go-ldap/ldap/v3 v3.4.1
+	github.com/google/go-querystring v1.0.0
+)
@@ -0,0 +1,312 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package migrate Copy an organization between Baruwa clusters
+
+A Migrator reads an organization and everything under it from a source
+cluster and recreates it on a destination cluster: smarthosts, fallback
+servers, relay settings, domains with their aliases, delivery servers,
+smarthosts and authentication servers, user accounts with their alias
+addresses and the organization administrators. Domain, server and user
+IDs are remapped as the resources are created.
+
+The API does not return passwords, they are supplied using Secrets,
+resources without a secret are created without a password and a warning
+is recorded. Relay settings and LDAP/RADIUS settings cannot be listed,
+their IDs are passed in the Options.
+
+Progress is saved to Options.StateFile after every step, running the
+migration again with the same state file skips the completed steps.
+An organization or domain that already exists on the destination
+without a state entry, such as one created by a run interrupted before
+its progress was saved, is adopted by name and a warning recorded.
+
+	m, err := migrate.New(src, dst, &migrate.Options{
+		Secrets:   secrets,
+		StateFile: "org-10.state",
+	})
+	r, state, err := m.Migrate(10)
+*/
+package migrate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Kind is the type of resource migrated
+type Kind string
+
+const (
+	// Organization an organization
+	Organization Kind = "organization"
+	// OrgSmartHost an organization smarthost
+	OrgSmartHost Kind = "org-smarthost"
+	// FallBackServer an organization fallback server
+	FallBackServer Kind = "fallback-server"
+	// RelaySetting an organization relay setting
+	RelaySetting Kind = "relay-setting"
+	// OrgAdmin an organization administrator
+	OrgAdmin Kind = "org-admin"
+	// Domain a domain
+	Domain Kind = "domain"
+	// DomainAlias a domain alias
+	DomainAlias Kind = "domain-alias"
+	// DomainDeliveryServer a domain delivery server
+	DomainDeliveryServer Kind = "domain-delivery-server"
+	// DomainSmartHost a domain smarthost
+	DomainSmartHost Kind = "domain-smarthost"
+	// AuthServer a domain authentication server
+	AuthServer Kind = "auth-server"
+	// LDAPSettings the LDAP settings of an authentication server
+	LDAPSettings Kind = "ldap-settings"
+	// RadiusSettings the RADIUS settings of an authentication server
+	RadiusSettings Kind = "radius-settings"
+	// User a user account
+	User Kind = "user"
+	// AliasAddress a user alias address
+	AliasAddress Kind = "alias-address"
+)
+
+const (
+	srcParamError     = "The source param is required"
+	dstParamError     = "The destination param is required"
+	orgIDError        = "The organization ID should be > 0"
+	stateOrgError     = "The state file belongs to organization %d"
+	stepError         = "%s %s: %s"
+	adoptedWarning    = "%s %s: already exists on the destination, adopted"
+	noSecretWarning   = "%s %s: no secret supplied, created without a password"
+	noSettingsWarning = "%s %s: the settings ID was not supplied, settings not migrated"
+	noAdminWarning    = "%s %s: the user is not part of the organization, not migrated"
+)
+
+// Source is the subset of api.Client used to read the organization
+type Source interface {
+	GetOrganization(organizationID int) (*api.Organization, error)
+	GetOrganizationAdmins(organizationID int, opts *api.ListOptions) (*api.OrgAdminList, error)
+	GetOrgSmartHosts(organizationID int, opts *api.ListOptions) (*api.OrgSmartHostList, error)
+	GetFallBackServers(organizationID int, opts *api.ListOptions) (*api.FallBackServerList, error)
+	GetRelaySetting(relayID int) (*api.RelaySetting, error)
+	GetDomain(domainID int) (*api.Domain, error)
+	GetDomainAliases(domainID int, opts *api.ListOptions) (*api.DomainAliasList, error)
+	GetDomainDeliveryServers(domainID int, opts *api.ListOptions) (*api.DomainDeliveryServerList, error)
+	GetDomainSmartHosts(domainID int, opts *api.ListOptions) (*api.DomainSmartHostList, error)
+	GetAuthServers(domainID int, opts *api.ListOptions) (*api.AuthServerList, error)
+	GetLDAPSettings(domainID, serverID, settingsID int) (*api.LDAPSettings, error)
+	GetRadiusSettings(domainID, serverID, settingsID int) (*api.RadiusSettings, error)
+	GetUsers(opts *api.ListOptions) (*api.UserList, error)
+	GetUserAliasAddresses(userID int, opts *api.ListOptions) (*api.AliasAddressList, error)
+}
+
+// Destination is the subset of api.Client used to recreate the
+// organization
+type Destination interface {
+	GetOrganizations(opts *api.ListOptions) (*api.OrganizationList, error)
+	CreateOrganization(form *api.OrganizationForm) (*api.Organization, error)
+	AddOrganizationAdmin(organizationID, adminID int) (*api.OrgAdmin, error)
+	CreateOrgSmartHost(organizationID int, server *api.OrgSmartHost) error
+	CreateFallBackServer(organizationID int, server *api.FallBackServer) error
+	CreateRelaySetting(organizationID int, server *api.RelaySetting) error
+	GetDomainByName(domainName string) (*api.Domain, error)
+	CreateDomain(domain *api.Domain) error
+	CreateDomainAlias(domainID int, form *api.DomainAliasForm) (*api.DomainAlias, error)
+	CreateDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) (*api.DomainDeliveryServer, error)
+	CreateDomainSmartHost(domainID int, server *api.DomainSmartHost) error
+	CreateAuthServer(domainID int, server *api.AuthServer) error
+	CreateLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) error
+	CreateRadiusSettings(domainID, serverID int, settings *api.RadiusSettings) error
+	CreateUser(user *api.UserForm) (*api.User, error)
+	CreateAliasAddress(userID int, alias *api.AliasAddress) error
+}
+
+var (
+	_ Source      = (*api.Client)(nil)
+	_ Destination = (*api.Client)(nil)
+)
+
+// Options represents optional settings that can be passed to New
+type Options struct {
+	// Name of the destination organization, defaults to the source name
+	Name string
+	// Secrets supplies the passwords the API does not return
+	Secrets Secrets
+	// StateFile is where progress is saved, a migration resumes from
+	// it when it exists. Progress is only kept in memory when empty.
+	StateFile string
+	// RelayIDs are the relay settings of the source organization
+	RelayIDs []int
+	// AuthSettings maps source authentication server IDs to the ID of
+	// their LDAP or RADIUS settings
+	AuthSettings map[int]int
+}
+
+// Result holds the outcome of a migration
+type Result struct {
+	Organization int          `json:"organization"`
+	Created      map[Kind]int `json:"created"`
+	Skipped      map[Kind]int `json:"skipped"`
+	Warnings     []string     `json:"warnings,omitempty"`
+}
+
+// Migrator copies organizations between clusters
+type Migrator struct {
+	src    Source
+	dst    Destination
+	opts   Options
+	state  *State
+	result *Result
+}
+
+// New returns a Migrator
+func New(src Source, dst Destination, opts *Options) (m *Migrator, err error) {
+	if src == nil {
+		err = fmt.Errorf(srcParamError)
+		return
+	}
+
+	if dst == nil {
+		err = fmt.Errorf(dstParamError)
+		return
+	}
+
+	m = &Migrator{
+		src: src,
+		dst: dst,
+	}
+
+	if opts != nil {
+		m.opts = *opts
+	}
+
+	return
+}
+
+// Migrate copies the organization, an error stops the migration after
+// saving the progress made. The returned State maps source IDs to the
+// destination IDs.
+func (m *Migrator) Migrate(orgID int) (r *Result, s *State, err error) {
+	var org *api.Organization
+	var domains []int
+
+	if orgID <= 0 {
+		err = fmt.Errorf(orgIDError)
+		return
+	}
+
+	if err = m.loadState(orgID); err != nil {
+		return
+	}
+
+	r = &Result{
+		Created: make(map[Kind]int),
+		Skipped: make(map[Kind]int),
+	}
+	s = m.state
+	m.result = r
+
+	if org, err = m.src.GetOrganization(orgID); err != nil {
+		return
+	}
+
+	if r.Organization, err = m.organization(org); err != nil {
+		return
+	}
+
+	if err = m.orgServers(org, r.Organization); err != nil {
+		return
+	}
+
+	for _, d := range org.Domains {
+		domains = append(domains, d.ID)
+	}
+	sort.Ints(domains)
+
+	for _, id := range domains {
+		if err = m.domain(id, r.Organization); err != nil {
+			return
+		}
+	}
+
+	if err = m.users(org, domains, r.Organization); err != nil {
+		return
+	}
+
+	if err = m.admins(org, r.Organization); err != nil {
+		return
+	}
+
+	m.state.Completed = true
+	err = m.save()
+
+	return
+}
+
+func (m *Migrator) loadState(orgID int) (err error) {
+	if m.opts.StateFile != "" {
+		if m.state, err = LoadState(m.opts.StateFile); err != nil {
+			return
+		}
+	}
+
+	if m.state == nil {
+		m.state = NewState(orgID)
+		return
+	}
+
+	if m.state.Organization != orgID {
+		err = fmt.Errorf(stateOrgError, m.state.Organization)
+	}
+
+	return
+}
+
+func (m *Migrator) save() error {
+	if m.opts.StateFile == "" {
+		return nil
+	}
+
+	return m.state.Save(m.opts.StateFile)
+}
+
+// step runs create unless the resource was migrated by a previous run,
+// the destination ID is recorded and the state saved
+func (m *Migrator) step(kind Kind, sourceID int, name string, create func() (int, error)) (id int, err error) {
+	var ok bool
+
+	if id, ok = m.state.ID(kind, sourceID); ok {
+		m.result.Skipped[kind]++
+		return
+	}
+
+	if id, err = create(); err != nil {
+		err = fmt.Errorf(stepError, kind, name, err)
+		return
+	}
+
+	m.state.set(kind, sourceID, id)
+	m.result.Created[kind]++
+	err = m.save()
+
+	return
+}
+
+func (m *Migrator) secret(kind Kind, name string, names ...string) (v string) {
+	var ok bool
+
+	if v, ok = m.opts.Secrets.lookup(kind, names...); !ok {
+		m.warn(noSecretWarning, kind, name)
+	}
+
+	return
+}
+
+func (m *Migrator) warn(format string, a ...interface{}) {
+	m.result.Warnings = append(m.result.Warnings, fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,571 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package migrate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+func notFound() error {
+	return &api.ErrorResponse{
+		Code:     http.StatusNotFound,
+		Message:  "Not Found",
+		Response: &http.Response{Request: &http.Request{Method: "GET", URL: &url.URL{Path: "/"}}},
+	}
+}
+
+type fakeSource struct{}
+
+func (f *fakeSource) GetOrganization(organizationID int) (*api.Organization, error) {
+	if organizationID != 10 {
+		return nil, notFound()
+	}
+	return &api.Organization{
+		ID:      10,
+		Name:    "Example Inc",
+		Domains: []api.OrgDomain{{ID: 21, Name: "example.org"}, {ID: 20, Name: "example.com"}},
+	}, nil
+}
+
+func (f *fakeSource) GetOrganizationAdmins(organizationID int, opts *api.ListOptions) (*api.OrgAdminList, error) {
+	return &api.OrgAdminList{Items: []api.OrgAdmin{{ID: 51, Username: "admin"}, {ID: 53, Username: "outsider"}}}, nil
+}
+
+func (f *fakeSource) GetOrgSmartHosts(organizationID int, opts *api.ListOptions) (*api.OrgSmartHostList, error) {
+	return &api.OrgSmartHostList{Items: []api.OrgSmartHost{
+		{ID: 30, Address: "smtp.example.net", Port: 587, Username: "relay", RequireTLS: true, Enabled: true},
+	}}, nil
+}
+
+func (f *fakeSource) GetFallBackServers(organizationID int, opts *api.ListOptions) (*api.FallBackServerList, error) {
+	return &api.FallBackServerList{Items: []api.FallBackServer{
+		{ID: 31, Address: "192.168.1.150", Port: 25, Protocol: 1, Enabled: true, Organization: &api.FallBackServerOrg{ID: 10}},
+	}}, nil
+}
+
+func (f *fakeSource) GetRelaySetting(relayID int) (*api.RelaySetting, error) {
+	if relayID != 32 {
+		return nil, notFound()
+	}
+	return &api.RelaySetting{ID: 32, Address: "192.168.1.20", Username: "outbound", Enabled: true, RateLimit: 250}, nil
+}
+
+func (f *fakeSource) GetDomain(domainID int) (*api.Domain, error) {
+	switch domainID {
+	case 20:
+		return &api.Domain{ID: 20, Name: "example.com", Enabled: true, SpamChecks: true, HighScore: 8.5, Organizations: []int{10, 11}}, nil
+	case 21:
+		return &api.Domain{ID: 21, Name: "example.org", Organizations: []int{10}}, nil
+	}
+	return nil, notFound()
+}
+
+// GetDomainAliases returns the aliases of example.com over two pages
+func (f *fakeSource) GetDomainAliases(domainID int, opts *api.ListOptions) (*api.DomainAliasList, error) {
+	l := &api.DomainAliasList{}
+	if domainID != 20 {
+		return l, nil
+	}
+	if opts == nil {
+		l.Items = []api.DomainAlias{{ID: 40, Name: "example.net", Enabled: true}}
+		l.Links.Pages.Next = "2"
+	} else if opts.Page == "2" {
+		l.Items = []api.DomainAlias{{ID: 46, Name: "example.info", AcceptInbound: true}}
+	}
+	return l, nil
+}
+
+func (f *fakeSource) GetDomainDeliveryServers(domainID int, opts *api.ListOptions) (*api.DomainDeliveryServerList, error) {
+	l := &api.DomainDeliveryServerList{}
+	if domainID == 20 {
+		l.Items = []api.DomainDeliveryServer{{ID: 41, Address: "192.168.1.10", Port: 25, Protocol: 1, Enabled: true, Domain: &api.AliasDomain{ID: 20}}}
+	}
+	return l, nil
+}
+
+func (f *fakeSource) GetDomainSmartHosts(domainID int, opts *api.ListOptions) (*api.DomainSmartHostList, error) {
+	l := &api.DomainSmartHostList{}
+	if domainID == 20 {
+		l.Items = []api.DomainSmartHost{{ID: 42, Address: "smarthost.example.net", Port: 25, Username: "example", Enabled: true}}
+	}
+	return l, nil
+}
+
+func (f *fakeSource) GetAuthServers(domainID int, opts *api.ListOptions) (*api.AuthServerList, error) {
+	l := &api.AuthServerList{}
+	if domainID == 20 {
+		l.Items = []api.AuthServer{
+			{ID: 43, Address: "ldap.example.com", Port: 389, Protocol: api.AuthProtocolLDAP, Enabled: true},
+			{ID: 45, Address: "radius.example.com", Port: 1812, Protocol: api.AuthProtocolRADIUS, Enabled: true},
+			{ID: 47, Address: "mail.example.com", Port: 143, Protocol: 2, Enabled: true},
+		}
+	}
+	return l, nil
+}
+
+func (f *fakeSource) GetLDAPSettings(domainID, serverID, settingsID int) (*api.LDAPSettings, error) {
+	if domainID != 20 || serverID != 43 || settingsID != 44 {
+		return nil, notFound()
+	}
+	return &api.LDAPSettings{ID: 44, Basedn: "dc=example,dc=com", BindDN: "cn=baruwa", AuthServer: api.SettingsAS{ID: 43}}, nil
+}
+
+func (f *fakeSource) GetRadiusSettings(domainID, serverID, settingsID int) (*api.RadiusSettings, error) {
+	return nil, notFound()
+}
+
+func (f *fakeSource) GetUsers(opts *api.ListOptions) (*api.UserList, error) {
+	return &api.UserList{Items: []api.User{
+		{ID: 50, Username: "jdoe", Email: "jdoe@example.com", AccountType: 3, Enabled: true, Domains: []api.UserDomain{{ID: 20}, {ID: 99}}},
+		{ID: 51, Username: "admin", Email: "admin@example.com", AccountType: 2, Organizations: []api.UserOrganization{{ID: 10}}},
+		{ID: 52, Username: "other", Email: "other@example.info", AccountType: 3, Domains: []api.UserDomain{{ID: 99}}},
+	}}, nil
+}
+
+func (f *fakeSource) GetUserAliasAddresses(userID int, opts *api.ListOptions) (*api.AliasAddressList, error) {
+	l := &api.AliasAddressList{}
+	if userID == 50 {
+		l.Items = []api.AliasAddress{{ID: 60, Address: "john@example.com", Enabled: true}}
+	}
+	return l, nil
+}
+
+type fakeDestination struct {
+	nextID    int
+	calls     int
+	failAt    int
+	existing  string
+	orgs      map[int]*api.OrganizationForm
+	admins    map[int][]int
+	servers   map[int]interface{}
+	domains   map[int]*api.Domain
+	aliases   map[int]*api.DomainAliasForm
+	settings  map[int]interface{}
+	users     map[int]*api.UserForm
+	addresses map[int][]api.AliasAddress
+	created   map[string]int
+}
+
+func newFakeDestination() *fakeDestination {
+	return &fakeDestination{
+		nextID:    1000,
+		orgs:      make(map[int]*api.OrganizationForm),
+		admins:    make(map[int][]int),
+		servers:   make(map[int]interface{}),
+		domains:   make(map[int]*api.Domain),
+		aliases:   make(map[int]*api.DomainAliasForm),
+		settings:  make(map[int]interface{}),
+		users:     make(map[int]*api.UserForm),
+		addresses: make(map[int][]api.AliasAddress),
+		created:   make(map[string]int),
+	}
+}
+
+func (f *fakeDestination) create(kind string) (id int, err error) {
+	f.calls++
+	if f.failAt > 0 && f.calls == f.failAt {
+		err = fmt.Errorf("connection reset")
+		return
+	}
+	f.nextID++
+	f.created[kind]++
+	id = f.nextID
+	return
+}
+
+func (f *fakeDestination) GetOrganizations(opts *api.ListOptions) (*api.OrganizationList, error) {
+	l := &api.OrganizationList{}
+	for id, form := range f.orgs {
+		l.Items = append(l.Items, api.Organization{ID: id, Name: form.Name})
+	}
+	return l, nil
+}
+
+func (f *fakeDestination) CreateOrganization(form *api.OrganizationForm) (*api.Organization, error) {
+	id, err := f.create("org")
+	if err != nil {
+		return nil, err
+	}
+	f.orgs[id] = form
+	return &api.Organization{ID: id, Name: form.Name}, nil
+}
+
+func (f *fakeDestination) AddOrganizationAdmin(organizationID, adminID int) (*api.OrgAdmin, error) {
+	if _, err := f.create("admin"); err != nil {
+		return nil, err
+	}
+	f.admins[organizationID] = append(f.admins[organizationID], adminID)
+	return &api.OrgAdmin{ID: adminID}, nil
+}
+
+func (f *fakeDestination) CreateOrgSmartHost(organizationID int, server *api.OrgSmartHost) (err error) {
+	if server.ID, err = f.create("org-smarthost"); err == nil {
+		f.servers[server.ID] = *server
+	}
+	return
+}
+
+func (f *fakeDestination) CreateFallBackServer(organizationID int, server *api.FallBackServer) (err error) {
+	if server.ID, err = f.create("fallback"); err == nil {
+		f.servers[server.ID] = *server
+	}
+	return
+}
+
+func (f *fakeDestination) CreateRelaySetting(organizationID int, server *api.RelaySetting) (err error) {
+	if server.ID, err = f.create("relay"); err == nil {
+		f.servers[server.ID] = *server
+	}
+	return
+}
+
+func (f *fakeDestination) GetDomainByName(domainName string) (*api.Domain, error) {
+	for _, d := range f.domains {
+		if d.Name == domainName {
+			return d, nil
+		}
+	}
+	if domainName == f.existing {
+		return &api.Domain{ID: 1, Name: domainName}, nil
+	}
+	return nil, notFound()
+}
+
+func (f *fakeDestination) CreateDomain(domain *api.Domain) (err error) {
+	if domain.ID, err = f.create("domain"); err == nil {
+		d := *domain
+		f.domains[domain.ID] = &d
+	}
+	return
+}
+
+func (f *fakeDestination) CreateDomainAlias(domainID int, form *api.DomainAliasForm) (*api.DomainAlias, error) {
+	id, err := f.create("domain-alias")
+	if err != nil {
+		return nil, err
+	}
+	f.aliases[id] = form
+	return &api.DomainAlias{ID: id, Name: form.Name}, nil
+}
+
+func (f *fakeDestination) CreateDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) (*api.DomainDeliveryServer, error) {
+	id, err := f.create("delivery-server")
+	if err != nil {
+		return nil, err
+	}
+	f.servers[id] = *form
+	return &api.DomainDeliveryServer{ID: id, Address: form.Address}, nil
+}
+
+func (f *fakeDestination) CreateDomainSmartHost(domainID int, server *api.DomainSmartHost) (err error) {
+	if server.ID, err = f.create("domain-smarthost"); err == nil {
+		f.servers[server.ID] = *server
+	}
+	return
+}
+
+func (f *fakeDestination) CreateAuthServer(domainID int, server *api.AuthServer) (err error) {
+	if server.ID, err = f.create("auth-server"); err == nil {
+		f.servers[server.ID] = *server
+	}
+	return
+}
+
+func (f *fakeDestination) CreateLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) (err error) {
+	if settings.ID, err = f.create("ldap"); err == nil {
+		f.settings[settings.ID] = *settings
+	}
+	return
+}
+
+func (f *fakeDestination) CreateRadiusSettings(domainID, serverID int, settings *api.RadiusSettings) (err error) {
+	if settings.ID, err = f.create("radius"); err == nil {
+		f.settings[settings.ID] = *settings
+	}
+	return
+}
+
+func (f *fakeDestination) CreateUser(user *api.UserForm) (*api.User, error) {
+	id, err := f.create("user")
+	if err != nil {
+		return nil, err
+	}
+	f.users[id] = user
+	return &api.User{ID: id, Username: *user.Username}, nil
+}
+
+func (f *fakeDestination) CreateAliasAddress(userID int, alias *api.AliasAddress) (err error) {
+	if alias.ID, err = f.create("alias-address"); err == nil {
+		f.addresses[userID] = append(f.addresses[userID], *alias)
+	}
+	return
+}
+
+func getOptions() *Options {
+	return &Options{
+		Secrets: Secrets{
+			"org-smarthost/Example Inc/smtp.example.net":         "orgpw",
+			"domain-smarthost/example.com/smarthost.example.net": "domainpw",
+			"ldap-settings/example.com/ldap.example.com":         "bindpw",
+			"user/jdoe": "userpw",
+		},
+		RelayIDs:     []int{32},
+		AuthSettings: map[int]int{43: 44},
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil, newFakeDestination(), nil); err == nil || err.Error() != srcParamError {
+		t.Errorf("Expected '%s' got '%v'", srcParamError, err)
+	}
+	if _, err := New(&fakeSource{}, nil, nil); err == nil || err.Error() != dstParamError {
+		t.Errorf("Expected '%s' got '%v'", dstParamError, err)
+	}
+	m, err := New(&fakeSource{}, newFakeDestination(), nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if _, _, err = m.Migrate(0); err == nil || err.Error() != orgIDError {
+		t.Errorf("Expected '%s' got '%v'", orgIDError, err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	dst := newFakeDestination()
+	m, _ := New(&fakeSource{}, dst, getOptions())
+
+	r, s, err := m.Migrate(10)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if !s.Completed {
+		t.Errorf("Expected the state to be completed")
+	}
+
+	expected := map[Kind]int{
+		Organization:         1,
+		OrgSmartHost:         1,
+		FallBackServer:       1,
+		RelaySetting:         1,
+		Domain:               2,
+		DomainAlias:          2,
+		DomainDeliveryServer: 1,
+		DomainSmartHost:      1,
+		AuthServer:           3,
+		LDAPSettings:         1,
+		User:                 2,
+		AliasAddress:         1,
+		OrgAdmin:             1,
+	}
+	if !reflect.DeepEqual(r.Created, expected) {
+		t.Errorf("Expected %v got %v", expected, r.Created)
+	}
+
+	warnings := []string{
+		fmt.Sprintf(noSecretWarning, RelaySetting, "192.168.1.20"),
+		fmt.Sprintf(noSettingsWarning, RadiusSettings, "radius.example.com"),
+		fmt.Sprintf(noSecretWarning, User, "admin"),
+		fmt.Sprintf(noAdminWarning, OrgAdmin, "outsider"),
+	}
+	if !reflect.DeepEqual(r.Warnings, warnings) {
+		t.Errorf("Expected %v got %v", warnings, r.Warnings)
+	}
+
+	org := r.Organization
+	if dst.orgs[org] == nil || dst.orgs[org].Name != "Example Inc" {
+		t.Fatalf("Expected the organization to be created")
+	}
+
+	domainID, _ := s.ID(Domain, 20)
+	d := dst.domains[domainID]
+	if d.Name != "example.com" || !d.SpamChecks || d.HighScore != 8.5 {
+		t.Errorf("Expected the domain settings to be copied got %v", d)
+	}
+	if !reflect.DeepEqual(d.Organizations, []int{org}) {
+		t.Errorf("Expected %v got %v", []int{org}, d.Organizations)
+	}
+
+	id, _ := s.ID(DomainAlias, 46)
+	if a := dst.aliases[id]; a.Domain != domainID || a.Name != "example.info" || !a.AcceptInbound {
+		t.Errorf("Expected the alias to be remapped got %v", a)
+	}
+	id, _ = s.ID(DomainDeliveryServer, 41)
+	if ds := dst.servers[id].(api.DomainDeliveryServerForm); ds.Domain != domainID || ds.Address != "192.168.1.10" {
+		t.Errorf("Expected the delivery server to be remapped got %v", ds)
+	}
+	id, _ = s.ID(OrgSmartHost, 30)
+	if sh := dst.servers[id].(api.OrgSmartHost); sh.Password != "orgpw" || sh.Username != "relay" {
+		t.Errorf("Expected the smarthost password to be set got %v", sh)
+	}
+	id, _ = s.ID(DomainSmartHost, 42)
+	if sh := dst.servers[id].(api.DomainSmartHost); sh.Password != "domainpw" {
+		t.Errorf("Expected %s got %s", "domainpw", sh.Password)
+	}
+	id, _ = s.ID(FallBackServer, 31)
+	if fb := dst.servers[id].(api.FallBackServer); fb.Organization != nil {
+		t.Errorf("Expected the organization to be cleared got %v", fb.Organization)
+	}
+	authID, _ := s.ID(AuthServer, 43)
+	id, _ = s.ID(LDAPSettings, 43)
+	if l := dst.settings[id].(api.LDAPSettings); l.AuthServer.ID != authID || l.BindPw != "bindpw" || l.Basedn != "dc=example,dc=com" {
+		t.Errorf("Expected the LDAP settings to be remapped got %v", l)
+	}
+
+	userID, _ := s.ID(User, 50)
+	u := dst.users[userID]
+	if *u.Username != "jdoe" || *u.Password1 != "userpw" || *u.Password2 != "userpw" {
+		t.Errorf("Expected the user password to be set")
+	}
+	if !reflect.DeepEqual(u.Domains, []int{domainID}) || len(u.Organizations) != 0 {
+		t.Errorf("Expected %v got %v %v", []int{domainID}, u.Domains, u.Organizations)
+	}
+	if a := dst.addresses[userID]; len(a) != 1 || a[0].Address != "john@example.com" {
+		t.Errorf("Expected the alias address to be created got %v", a)
+	}
+	adminID, _ := s.ID(User, 51)
+	if u = dst.users[adminID]; u.Password1 != nil || !reflect.DeepEqual(u.Organizations, []int{org}) {
+		t.Errorf("Expected the admin to be created without a password in %d", org)
+	}
+	if !reflect.DeepEqual(dst.admins[org], []int{adminID}) {
+		t.Errorf("Expected %v got %v", []int{adminID}, dst.admins[org])
+	}
+}
+
+func TestMigrateResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	full := newFakeDestination()
+	m, _ := New(&fakeSource{}, full, getOptions())
+	if _, _, err = m.Migrate(10); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	opts := getOptions()
+	opts.StateFile = filepath.Join(dir, "state.json")
+	dst := newFakeDestination()
+
+	for _, failAt := range []int{4, 5, 3} {
+		dst.calls = 0
+		dst.failAt = failAt
+		m, _ = New(&fakeSource{}, dst, opts)
+		if _, _, err = m.Migrate(10); err == nil {
+			t.Fatalf("An error should be returned")
+		}
+		s, err := LoadState(opts.StateFile)
+		if err != nil || s == nil {
+			t.Fatalf("Expected the state to be saved: %v", err)
+		}
+		if s.Completed {
+			t.Errorf("Expected the state not to be completed")
+		}
+	}
+
+	dst.failAt = 0
+	m, _ = New(&fakeSource{}, dst, opts)
+	r, s, err := m.Migrate(10)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if !s.Completed {
+		t.Errorf("Expected the state to be completed")
+	}
+	if !reflect.DeepEqual(dst.created, full.created) {
+		t.Errorf("Expected %v got %v", full.created, dst.created)
+	}
+	var skipped int
+	for _, n := range r.Skipped {
+		skipped += n
+	}
+	if skipped == 0 {
+		t.Errorf("Expected completed steps to be skipped")
+	}
+
+	m, _ = New(&fakeSource{}, dst, opts)
+	if _, _, err = m.Migrate(11); err == nil || err.Error() != fmt.Sprintf(stateOrgError, 10) {
+		t.Errorf("Expected '%s' got '%v'", fmt.Sprintf(stateOrgError, 10), err)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	dst := newFakeDestination()
+	dst.existing = "example.com"
+	m, _ := New(&fakeSource{}, dst, getOptions())
+
+	r, s, err := m.Migrate(10)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if id, _ := s.ID(Domain, 20); id != 1 {
+		t.Errorf("Expected the existing domain to be adopted got %d", id)
+	}
+	expected := fmt.Sprintf(adoptedWarning, Domain, "example.com")
+	if !contains(r.Warnings, expected) {
+		t.Errorf("Expected '%s' in %v", expected, r.Warnings)
+	}
+
+	m, _ = New(&fakeSource{}, newFakeDestination(), &Options{RelayIDs: []int{33}})
+	if _, _, err = m.Migrate(10); err == nil {
+		t.Errorf("An error should be returned")
+	}
+
+	m, _ = New(&fakeSource{}, newFakeDestination(), nil)
+	if _, _, err = m.Migrate(12); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestMigrateInterrupted(t *testing.T) {
+	full := newFakeDestination()
+	m, _ := New(&fakeSource{}, full, getOptions())
+	if _, _, err := m.Migrate(10); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	dst := newFakeDestination()
+	dst.failAt = full.calls
+	m, _ = New(&fakeSource{}, dst, getOptions())
+	if _, _, err := m.Migrate(10); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+
+	dst.failAt = 0
+	m, _ = New(&fakeSource{}, dst, getOptions())
+	r, _, err := m.Migrate(10)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if dst.created["org"] != 1 || dst.created["domain"] != full.created["domain"] {
+		t.Errorf("Expected the organization and domains to be adopted got %v", dst.created)
+	}
+	expected := fmt.Sprintf(adoptedWarning, Organization, "Example Inc")
+	if !contains(r.Warnings, expected) {
+		t.Errorf("Expected '%s' in %v", expected, r.Warnings)
+	}
+}
+
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
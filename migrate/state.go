@@ -0,0 +1,129 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// State records the destination IDs of the migrated resources, it is
+// saved after every step so that an interrupted migration can resume
+type State struct {
+	Organization int            `json:"organization"`
+	IDs          map[string]int `json:"ids"`
+	Completed    bool           `json:"completed"`
+}
+
+// NewState returns an empty State for the source organization
+func NewState(orgID int) *State {
+	return &State{
+		Organization: orgID,
+		IDs:          make(map[string]int),
+	}
+}
+
+// ID returns the destination ID of a source resource
+func (s *State) ID(kind Kind, sourceID int) (id int, ok bool) {
+	id, ok = s.IDs[stateKey(kind, sourceID)]
+
+	return
+}
+
+func (s *State) set(kind Kind, sourceID, id int) {
+	s.IDs[stateKey(kind, sourceID)] = id
+}
+
+// LoadState reads a state file, nil is returned when it does not exist
+func LoadState(path string) (s *State, err error) {
+	var f *os.File
+
+	if f, err = os.Open(path); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	defer f.Close()
+
+	s = &State{}
+	if err = json.NewDecoder(f).Decode(s); err != nil {
+		s = nil
+		return
+	}
+
+	if s.IDs == nil {
+		s.IDs = make(map[string]int)
+	}
+
+	return
+}
+
+// Save replaces the state file atomically
+func (s *State) Save(path string) (err error) {
+	var b []byte
+	var f *os.File
+
+	if b, err = json.MarshalIndent(s, "", "  "); err != nil {
+		return
+	}
+
+	if f, err = ioutil.TempFile(filepath.Dir(path), ".migrate-"); err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(f.Name(), path)
+
+	return
+}
+
+func stateKey(kind Kind, id int) string {
+	return fmt.Sprintf("%s/%d", kind, id)
+}
+
+// Secrets supplies the passwords the API does not return, keys are the
+// kind followed by the owner and name separated by a slash:
+//
+//	org-smarthost/<organization>/<address>
+//	relay-setting/<organization>/<address>
+//	domain-smarthost/<domain>/<address>
+//	ldap-settings/<domain>/<auth server address>
+//	radius-settings/<domain>/<auth server address>
+//	user/<username>
+type Secrets map[string]string
+
+// LoadSecrets reads secrets from a JSON object
+func LoadSecrets(r io.Reader) (s Secrets, err error) {
+	s = Secrets{}
+	if err = json.NewDecoder(r).Decode(&s); err != nil {
+		s = nil
+	}
+
+	return
+}
+
+func (s Secrets) lookup(kind Kind, names ...string) (v string, ok bool) {
+	v, ok = s[string(kind)+"/"+strings.Join(names, "/")]
+
+	return
+}
@@ -0,0 +1,69 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+	s, err := LoadState(path)
+	if err != nil || s != nil {
+		t.Errorf("Expected a missing state file to return nil got %v %v", s, err)
+	}
+
+	s = NewState(10)
+	s.set(Domain, 20, 1001)
+	if err = s.Save(path); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if s, err = LoadState(path); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if id, ok := s.ID(Domain, 20); !ok || id != 1001 {
+		t.Errorf("Expected %d got %d", 1001, id)
+	}
+	if _, ok := s.ID(User, 20); ok {
+		t.Errorf("Expected the user not to be found")
+	}
+
+	ioutil.WriteFile(path, []byte("{"), 0600)
+	if _, err = LoadState(path); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestLoadSecrets(t *testing.T) {
+	s, err := LoadSecrets(strings.NewReader(`{"user/jdoe": "s3cr3t", "domain-smarthost/example.com/smtp.example.net": "pw"}`))
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if v, ok := s.lookup(User, "jdoe"); !ok || v != "s3cr3t" {
+		t.Errorf("Expected %s got %s", "s3cr3t", v)
+	}
+	if v, ok := s.lookup(DomainSmartHost, "example.com", "smtp.example.net"); !ok || v != "pw" {
+		t.Errorf("Expected %s got %s", "pw", v)
+	}
+	if _, ok := Secrets(nil).lookup(User, "jdoe"); ok {
+		t.Errorf("Expected the secret not to be found")
+	}
+	if _, err = LoadSecrets(strings.NewReader("[")); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
@@ -0,0 +1,468 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+func (m *Migrator) organization(org *api.Organization) (int, error) {
+	return m.step(Organization, org.ID, org.Name, func() (id int, err error) {
+		var existing, created *api.Organization
+
+		form := &api.OrganizationForm{
+			Name: m.opts.Name,
+		}
+		if form.Name == "" {
+			form.Name = org.Name
+		}
+
+		if existing, err = m.organizationByName(form.Name); err != nil {
+			return
+		} else if existing != nil {
+			m.warn(adoptedWarning, Organization, form.Name)
+			id = existing.ID
+			return
+		}
+
+		if created, err = m.dst.CreateOrganization(form); err != nil {
+			return
+		}
+		id = created.ID
+
+		return
+	})
+}
+
+func (m *Migrator) organizationByName(name string) (org *api.Organization, err error) {
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrganizationList
+		if l, err = m.dst.GetOrganizations(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Name, name) {
+				org, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+
+	return
+}
+
+func (m *Migrator) orgServers(org *api.Organization, orgID int) (err error) {
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrgSmartHostList
+		if l, err = m.src.GetOrgSmartHosts(org.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			server := s
+			if _, err = m.step(OrgSmartHost, s.ID, s.Address, func() (int, error) {
+				server.ID = 0
+				server.Password = m.secret(OrgSmartHost, s.Address, org.Name, s.Address)
+				err := m.dst.CreateOrgSmartHost(orgID, &server)
+				return server.ID, err
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.FallBackServerList
+		if l, err = m.src.GetFallBackServers(org.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			server := s
+			if _, err = m.step(FallBackServer, s.ID, s.Address, func() (int, error) {
+				server.ID = 0
+				server.Organization = nil
+				err := m.dst.CreateFallBackServer(orgID, &server)
+				return server.ID, err
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	for _, relayID := range m.opts.RelayIDs {
+		if _, ok := m.state.ID(RelaySetting, relayID); ok {
+			m.result.Skipped[RelaySetting]++
+			continue
+		}
+		var relay *api.RelaySetting
+		if relay, err = m.src.GetRelaySetting(relayID); err != nil {
+			err = fmt.Errorf(stepError, RelaySetting, fmt.Sprint(relayID), err)
+			return
+		}
+		if _, err = m.step(RelaySetting, relayID, relay.Address, func() (int, error) {
+			relay.ID = 0
+			relay.Password1 = m.secret(RelaySetting, relay.Address, org.Name, relay.Address)
+			relay.Password2 = relay.Password1
+			err := m.dst.CreateRelaySetting(orgID, relay)
+			return relay.ID, err
+		}); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (m *Migrator) domain(srcID, orgID int) (err error) {
+	var id int
+	var domain *api.Domain
+
+	if domain, err = m.src.GetDomain(srcID); err != nil {
+		return
+	}
+
+	if id, err = m.step(Domain, srcID, domain.Name, func() (id int, err error) {
+		var existing *api.Domain
+
+		if existing, err = m.dst.GetDomainByName(domain.Name); err == nil {
+			m.warn(adoptedWarning, Domain, domain.Name)
+			id = existing.ID
+			return
+		} else if !api.IsNotFound(err) {
+			return
+		}
+
+		d := *domain
+		d.ID = 0
+		d.Organizations = []int{orgID}
+		if err = m.dst.CreateDomain(&d); err != nil {
+			return
+		}
+		id = d.ID
+
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainAliasList
+		if l, err = m.src.GetDomainAliases(srcID, opts); err != nil {
+			return
+		}
+		for _, a := range l.Items {
+			alias := a
+			if _, err = m.step(DomainAlias, a.ID, a.Name, func() (int, error) {
+				created, err := m.dst.CreateDomainAlias(id, &api.DomainAliasForm{
+					Name:          alias.Name,
+					Enabled:       alias.Enabled,
+					AcceptInbound: alias.AcceptInbound,
+					Domain:        id,
+				})
+				if err != nil {
+					return 0, err
+				}
+				return created.ID, nil
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainDeliveryServerList
+		if l, err = m.src.GetDomainDeliveryServers(srcID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			server := s
+			if _, err = m.step(DomainDeliveryServer, s.ID, s.Address, func() (int, error) {
+				created, err := m.dst.CreateDomainDeliveryServer(id, &api.DomainDeliveryServerForm{
+					Address:          server.Address,
+					Protocol:         server.Protocol,
+					Port:             server.Port,
+					RequireTLS:       server.RequireTLS,
+					VerificationOnly: server.VerificationOnly,
+					Enabled:          server.Enabled,
+					Domain:           id,
+				})
+				if err != nil {
+					return 0, err
+				}
+				return created.ID, nil
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainSmartHostList
+		if l, err = m.src.GetDomainSmartHosts(srcID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			server := s
+			if _, err = m.step(DomainSmartHost, s.ID, s.Address, func() (int, error) {
+				server.ID = 0
+				server.Password = m.secret(DomainSmartHost, s.Address, domain.Name, s.Address)
+				err := m.dst.CreateDomainSmartHost(id, &server)
+				return server.ID, err
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.AuthServerList
+		if l, err = m.src.GetAuthServers(srcID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if err = m.authServer(domain, id, s); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	return
+}
+
+func (m *Migrator) authServer(domain *api.Domain, domainID int, s api.AuthServer) (err error) {
+	var id int
+
+	server := s
+	if id, err = m.step(AuthServer, s.ID, s.Address, func() (int, error) {
+		server.ID = 0
+		err := m.dst.CreateAuthServer(domainID, &server)
+		return server.ID, err
+	}); err != nil {
+		return
+	}
+
+	if s.Protocol != api.AuthProtocolLDAP && s.Protocol != api.AuthProtocolRADIUS {
+		return
+	}
+
+	kind := LDAPSettings
+	if s.Protocol == api.AuthProtocolRADIUS {
+		kind = RadiusSettings
+	}
+
+	settingsID, ok := m.opts.AuthSettings[s.ID]
+	if !ok {
+		if _, done := m.state.ID(kind, s.ID); !done {
+			m.warn(noSettingsWarning, kind, s.Address)
+		}
+		return
+	}
+
+	// settings are recorded against the source authentication server
+	// as the settings ID is supplied by the caller
+	_, err = m.step(kind, s.ID, s.Address, func() (int, error) {
+		if kind == LDAPSettings {
+			settings, err := m.src.GetLDAPSettings(domain.ID, s.ID, settingsID)
+			if err != nil {
+				return 0, err
+			}
+			settings.ID = 0
+			settings.AuthServer = api.SettingsAS{ID: id}
+			settings.BindPw = m.secret(kind, s.Address, domain.Name, s.Address)
+			err = m.dst.CreateLDAPSettings(domainID, id, settings)
+			return settings.ID, err
+		}
+		settings, err := m.src.GetRadiusSettings(domain.ID, s.ID, settingsID)
+		if err != nil {
+			return 0, err
+		}
+		settings.ID = 0
+		settings.AuthServer = &api.SettingsAS{ID: id}
+		settings.Secret = m.secret(kind, s.Address, domain.Name, s.Address)
+		err = m.dst.CreateRadiusSettings(domainID, id, settings)
+		return settings.ID, err
+	})
+
+	return
+}
+
+// users migrates the accounts that belong to the organization or one
+// of its domains
+func (m *Migrator) users(org *api.Organization, domains []int, orgID int) (err error) {
+	var users []api.User
+
+	member := make(map[int]bool)
+	for _, id := range domains {
+		member[id] = true
+	}
+
+	belongs := func(u *api.User) bool {
+		for _, d := range u.Domains {
+			if member[d.ID] {
+				return true
+			}
+		}
+		for _, o := range u.Organizations {
+			if o.ID == org.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.UserList
+		if l, err = m.src.GetUsers(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if belongs(&l.Items[i]) {
+				users = append(users, l.Items[i])
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	for i := range users {
+		if err = m.user(&users[i], org, orgID); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (m *Migrator) user(u *api.User, org *api.Organization, orgID int) (err error) {
+	var id int
+
+	if id, err = m.step(User, u.ID, u.Username, func() (id int, err error) {
+		var created *api.User
+
+		form := &api.UserForm{
+			Username:    &u.Username,
+			Firstname:   &u.Firstname,
+			Lastname:    &u.Lastname,
+			Email:       &u.Email,
+			Timezone:    &u.Timezone,
+			AccountType: &u.AccountType,
+			Enabled:     &u.Enabled,
+			SendReport:  &u.SendReport,
+			SpamChecks:  &u.SpamChecks,
+			LowScore:    &u.LowScore,
+			HighScore:   &u.HighScore,
+			BlockMacros: &u.BlockMacros,
+		}
+		for _, d := range u.Domains {
+			if v, ok := m.state.ID(Domain, d.ID); ok {
+				form.Domains = append(form.Domains, v)
+			}
+		}
+		for _, o := range u.Organizations {
+			if o.ID == org.ID {
+				form.Organizations = []int{orgID}
+			}
+		}
+		if pw := m.secret(User, u.Username, u.Username); pw != "" {
+			form.Password1 = &pw
+			form.Password2 = &pw
+		}
+
+		if created, err = m.dst.CreateUser(form); err != nil {
+			return
+		}
+		id = created.ID
+
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.AliasAddressList
+		if l, err = m.src.GetUserAliasAddresses(u.ID, opts); err != nil {
+			return
+		}
+		for _, a := range l.Items {
+			alias := a
+			if _, err = m.step(AliasAddress, a.ID, a.Address, func() (int, error) {
+				alias.ID = 0
+				err := m.dst.CreateAliasAddress(id, &alias)
+				return alias.ID, err
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	return
+}
+
+func (m *Migrator) admins(org *api.Organization, orgID int) (err error) {
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrgAdminList
+		if l, err = m.src.GetOrganizationAdmins(org.ID, opts); err != nil {
+			return
+		}
+		for _, a := range l.Items {
+			userID, ok := m.state.ID(User, a.ID)
+			if !ok {
+				m.warn(noAdminWarning, OrgAdmin, a.Username)
+				continue
+			}
+			if _, err = m.step(OrgAdmin, a.ID, a.Username, func() (int, error) {
+				if _, err := m.dst.AddOrganizationAdmin(orgID, userID); err != nil {
+					return 0, err
+				}
+				return userID, nil
+			}); err != nil {
+				return
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,730 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	// DefaultWorkers is the number of keys reconciled at once
+	DefaultWorkers = 2
+	// DefaultResync is how often all the objects are queued
+	DefaultResync = 5 * time.Minute
+	// DefaultBaseDelay is the delay before a failed key is retried
+	DefaultBaseDelay = time.Second
+	// DefaultMaxDelay caps the retry delay of a failing key
+	DefaultMaxDelay = 5 * time.Minute
+)
+
+const defaultAccountType = 3
+
+// kindOrder is the order objects are created in, deletion is reversed
+var kindOrder = map[Kind]int{
+	OrganizationKind: 0,
+	DomainKind:       1,
+	UserKind:         2,
+}
+
+// Options represents optional settings that can be passed to New
+type Options struct {
+	// Workers defaults to DefaultWorkers
+	Workers int
+	// Resync defaults to DefaultResync
+	Resync time.Duration
+	// BaseDelay defaults to DefaultBaseDelay
+	BaseDelay time.Duration
+	// MaxDelay defaults to DefaultMaxDelay
+	MaxDelay time.Duration
+}
+
+// notReadyError is returned when a referenced object is not ready
+type notReadyError struct {
+	key Key
+}
+
+func (e *notReadyError) Error() string {
+	return fmt.Sprintf(dependencyError, e.key.Kind, e.key.Name)
+}
+
+// specError is returned when the spec does not match the kind
+type specError struct {
+	key  Key
+	spec interface{}
+}
+
+func (e *specError) Error() string {
+	return fmt.Sprintf(specTypeError, e.key, e.spec)
+}
+
+// Controller reconciles the objects of a Store with Baruwa
+type Controller struct {
+	client  Client
+	store   Store
+	opts    Options
+	queue   *Queue
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+}
+
+// New creates a new Controller, when the store is a Watcher changed
+// objects are queued as soon as Start is called
+func New(c Client, s Store, opts *Options) (ctl *Controller, err error) {
+	if c == nil {
+		err = fmt.Errorf(clientParamError)
+		return
+	}
+
+	if s == nil {
+		err = fmt.Errorf(storeParamError)
+		return
+	}
+
+	ctl = &Controller{
+		client: c,
+		store:  s,
+	}
+
+	if opts != nil {
+		ctl.opts = *opts
+	}
+
+	if ctl.opts.Workers <= 0 {
+		ctl.opts.Workers = DefaultWorkers
+	}
+
+	if ctl.opts.Resync <= 0 {
+		ctl.opts.Resync = DefaultResync
+	}
+
+	if ctl.opts.BaseDelay <= 0 {
+		ctl.opts.BaseDelay = DefaultBaseDelay
+	}
+
+	if ctl.opts.MaxDelay <= 0 {
+		ctl.opts.MaxDelay = DefaultMaxDelay
+	}
+
+	if w, ok := s.(Watcher); ok {
+		w.Watch(ctl.Enqueue)
+	}
+
+	return
+}
+
+// Enqueue queues a key while the controller is running
+func (c *Controller) Enqueue(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		c.queue.Add(key)
+	}
+}
+
+// Start runs the workers and the periodic resync until Stop is called
+func (c *Controller) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return
+	}
+
+	c.running = true
+	c.stop = make(chan struct{})
+	c.queue = NewQueue(c.opts.BaseDelay, c.opts.MaxDelay)
+
+	for i := 0; i < c.opts.Workers; i++ {
+		c.wg.Add(1)
+		go c.worker(c.queue)
+	}
+
+	c.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer c.wg.Done()
+		t := time.NewTicker(c.opts.Resync)
+		defer t.Stop()
+		c.resync()
+		for {
+			select {
+			case <-t.C:
+				c.resync()
+			case <-stop:
+				return
+			}
+		}
+	}(c.stop)
+}
+
+// Stop stops the controller and waits for the workers to finish
+func (c *Controller) Stop() {
+	c.mu.Lock()
+	if c.running {
+		c.running = false
+		close(c.stop)
+		c.queue.ShutDown()
+	}
+	c.mu.Unlock()
+
+	c.wg.Wait()
+}
+
+// ReconcileAll reconciles every object once, creations run in
+// dependency order and deletions in the reverse order. The first error
+// is returned after all the objects have been processed.
+func (c *Controller) ReconcileAll() (err error) {
+	var objs []*Object
+
+	if objs, err = c.store.List(); err != nil {
+		return
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		a, b := kindOrder[objs[i].Kind], kindOrder[objs[j].Kind]
+		if objs[i].Deleting {
+			a = -a - 1
+		}
+		if objs[j].Deleting {
+			b = -b - 1
+		}
+		return a < b
+	})
+
+	for _, o := range objs {
+		if e := c.Reconcile(o.Key); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	return
+}
+
+// Reconcile makes Baruwa match a single object and records the outcome
+// in the Ready condition of the object
+func (c *Controller) Reconcile(key Key) (err error) {
+	var id int
+	var obj *Object
+
+	if obj, err = c.store.Get(key); err != nil || obj == nil {
+		return
+	}
+
+	if obj.Deleting {
+		err = c.finalize(obj)
+		return
+	}
+
+	if !obj.HasFinalizer(Finalizer) {
+		obj.Finalizers = append(obj.Finalizers, Finalizer)
+		if err = c.store.Update(obj); err != nil {
+			return
+		}
+	}
+
+	switch obj.Kind {
+	case OrganizationKind:
+		id, err = c.organization(obj)
+	case DomainKind:
+		id, err = c.domain(obj)
+	case UserKind:
+		id, err = c.user(obj)
+	default:
+		err = &specError{key: obj.Key, spec: obj.Spec}
+	}
+
+	wasReady := obj.Status.Ready()
+	setStatus(obj, id, err)
+	if e := c.store.UpdateStatus(obj); err == nil {
+		err = e
+	}
+
+	if err == nil && !wasReady {
+		c.enqueueDependents(obj.Key)
+	}
+
+	return
+}
+
+func (c *Controller) worker(q *Queue) {
+	defer c.wg.Done()
+
+	for {
+		key, ok := q.Get()
+		if !ok {
+			return
+		}
+		if err := c.Reconcile(key); err != nil {
+			q.AddRateLimited(key)
+		} else {
+			q.Forget(key)
+		}
+		q.Done(key)
+	}
+}
+
+func (c *Controller) resync() {
+	objs, err := c.store.List()
+	if err != nil {
+		return
+	}
+
+	for _, o := range objs {
+		c.Enqueue(o.Key)
+	}
+}
+
+// enqueueDependents queues the objects that reference key so that they
+// do not wait for their retry delay once key is ready
+func (c *Controller) enqueueDependents(key Key) {
+	var refs func(o *Object) []string
+
+	switch key.Kind {
+	case OrganizationKind:
+		refs = func(o *Object) []string {
+			if s, ok := o.Spec.(*DomainSpec); ok {
+				return s.Organizations
+			}
+			return nil
+		}
+	case DomainKind:
+		refs = func(o *Object) []string {
+			if s, ok := o.Spec.(*UserSpec); ok {
+				return s.Domains
+			}
+			return nil
+		}
+	default:
+		return
+	}
+
+	c.mu.Lock()
+	running := c.running
+	c.mu.Unlock()
+	if !running {
+		return
+	}
+
+	objs, err := c.store.List()
+	if err != nil {
+		return
+	}
+
+	for _, o := range objs {
+		for _, name := range refs(o) {
+			if name == key.Name {
+				c.Enqueue(o.Key)
+				break
+			}
+		}
+	}
+}
+
+// finalize deletes the Baruwa resource of an object that is being
+// deleted and then removes the finalizer
+func (c *Controller) finalize(obj *Object) (err error) {
+	if !obj.HasFinalizer(Finalizer) {
+		return
+	}
+
+	if id := obj.Status.ID; id > 0 {
+		switch obj.Kind {
+		case OrganizationKind:
+			err = c.client.DeleteOrganization(id)
+		case DomainKind:
+			err = c.client.DeleteDomain(id)
+		case UserKind:
+			err = c.client.DeleteUser(id)
+		}
+		if err != nil && !api.IsNotFound(err) {
+			setStatus(obj, 0, err)
+			c.store.UpdateStatus(obj)
+			return
+		}
+	}
+
+	obj.RemoveFinalizer(Finalizer)
+	err = c.store.Update(obj)
+
+	return
+}
+
+func (c *Controller) organization(obj *Object) (id int, err error) {
+	var org *api.Organization
+
+	spec, ok := obj.Spec.(*OrganizationSpec)
+	if !ok {
+		err = &specError{key: obj.Key, spec: obj.Spec}
+		return
+	}
+
+	if org, err = c.findOrganization(obj.Status.ID, spec.Name); err != nil {
+		return
+	}
+
+	if org == nil {
+		if org, err = c.client.CreateOrganization(&api.OrganizationForm{Name: spec.Name}); err != nil {
+			return
+		}
+		id = org.ID
+		return
+	}
+
+	id = org.ID
+	if org.Name != spec.Name {
+		form := &api.OrganizationForm{ID: org.ID, Name: spec.Name}
+		err = c.client.UpdateOrganization(form, &api.Organization{ID: org.ID})
+	}
+
+	return
+}
+
+func (c *Controller) domain(obj *Object) (id int, err error) {
+	var orgs []int
+	var domain *api.Domain
+
+	spec, ok := obj.Spec.(*DomainSpec)
+	if !ok {
+		err = &specError{key: obj.Key, spec: obj.Spec}
+		return
+	}
+
+	if orgs, err = c.resolve(OrganizationKind, spec.Organizations); err != nil {
+		return
+	}
+
+	if domain, err = c.findDomain(obj.Status.ID, spec.Name); err != nil {
+		return
+	}
+
+	if domain == nil {
+		domain = newDomain()
+		spec.apply(domain, orgs)
+		if err = c.client.CreateDomain(domain); err != nil {
+			return
+		}
+		id = domain.ID
+		return
+	}
+
+	id = domain.ID
+	if spec.changed(domain, orgs) {
+		want := *domain
+		spec.apply(&want, orgs)
+		err = c.client.UpdateDomain(&want)
+	}
+
+	return
+}
+
+func (c *Controller) user(obj *Object) (id int, err error) {
+	var domains []int
+	var user *api.User
+
+	spec, ok := obj.Spec.(*UserSpec)
+	if !ok {
+		err = &specError{key: obj.Key, spec: obj.Spec}
+		return
+	}
+
+	if domains, err = c.resolve(DomainKind, spec.Domains); err != nil {
+		return
+	}
+
+	if user, err = c.findUser(obj.Status.ID, spec.Username); err != nil {
+		return
+	}
+
+	if user == nil {
+		if user, err = c.client.CreateUser(spec.form(domains)); err != nil {
+			return
+		}
+		id = user.ID
+		return
+	}
+
+	id = user.ID
+	if form, changed := spec.changes(user, domains); changed {
+		err = c.client.UpdateUser(form)
+	}
+
+	return
+}
+
+// resolve returns the Baruwa IDs of the named objects, an error is
+// returned when one of them is not ready
+func (c *Controller) resolve(kind Kind, names []string) (ids []int, err error) {
+	var o *Object
+
+	for _, name := range names {
+		key := Key{Kind: kind, Name: name}
+		if o, err = c.store.Get(key); err != nil {
+			return
+		}
+		if o == nil || o.Deleting || o.Status.ID == 0 || !o.Status.Ready() {
+			err = &notReadyError{key: key}
+			return
+		}
+		ids = append(ids, o.Status.ID)
+	}
+
+	ids = sortedInts(ids)
+
+	return
+}
+
+// findOrganization returns the organization with the ID or else the
+// name, nil is returned when neither exists
+func (c *Controller) findOrganization(id int, name string) (org *api.Organization, err error) {
+	if id > 0 {
+		if org, err = c.client.GetOrganization(id); !api.IsNotFound(err) {
+			return
+		}
+		org, err = nil, nil
+	}
+
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrganizationList
+		if l, err = c.client.GetOrganizations(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if l.Items[i].Name == name {
+				org = &l.Items[i]
+				break
+			}
+		}
+		links, done = l.Links, org != nil || len(l.Items) == 0
+		return
+	})
+
+	return
+}
+
+func (c *Controller) findDomain(id int, name string) (domain *api.Domain, err error) {
+	if id > 0 {
+		if domain, err = c.client.GetDomain(id); !api.IsNotFound(err) {
+			return
+		}
+	}
+
+	if domain, err = c.client.GetDomainByName(name); api.IsNotFound(err) {
+		domain, err = nil, nil
+	}
+
+	return
+}
+
+func (c *Controller) findUser(id int, username string) (user *api.User, err error) {
+	if id > 0 {
+		if user, err = c.client.GetUser(id); !api.IsNotFound(err) {
+			return
+		}
+		user = nil
+	}
+
+	err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.UserList
+		if l, err = c.client.GetUsers(opts); err != nil {
+			return
+		}
+		for i := range l.Items {
+			if strings.EqualFold(l.Items[i].Username, username) {
+				user, done = &l.Items[i], true
+				break
+			}
+		}
+		links, done = l.Links, done || len(l.Items) == 0
+		return
+	})
+
+	return
+}
+
+// newDomain returns a domain with the settings used by the Baruwa
+// web interface for new domains
+func newDomain() *api.Domain {
+	return &api.Domain{
+		VirusChecksAtSMTP: true,
+		SpamActions:       2,
+		HighspamActions:   2,
+		VirusActions:      2,
+		MessageSize:       "0",
+		DeliveryMode:      1,
+		Language:          "en",
+		ReportEvery:       3,
+	}
+}
+
+func (s *DomainSpec) apply(d *api.Domain, orgs []int) {
+	d.Name = s.Name
+	d.SiteURL = s.SiteURL
+	d.Enabled = s.Enabled
+	d.AcceptInbound = s.AcceptInbound
+	d.SpamChecks = s.SpamChecks
+	d.VirusChecks = s.VirusChecks
+	d.Organizations = orgs
+	if s.Language != "" {
+		d.Language = s.Language
+	}
+	if s.Timezone != "" {
+		d.Timezone = s.Timezone
+	}
+}
+
+// changed returns true if the managed fields of the domain differ from
+// the spec, the organizations are only compared when the server
+// returns them
+func (s *DomainSpec) changed(d *api.Domain, orgs []int) bool {
+	want := *d
+	s.apply(&want, orgs)
+
+	if want.Name != d.Name || want.SiteURL != d.SiteURL ||
+		want.Enabled != d.Enabled || want.AcceptInbound != d.AcceptInbound ||
+		want.SpamChecks != d.SpamChecks || want.VirusChecks != d.VirusChecks ||
+		want.Language != d.Language || want.Timezone != d.Timezone {
+		return true
+	}
+
+	return d.Organizations != nil && !reflect.DeepEqual(sortedInts(d.Organizations), sortedInts(orgs))
+}
+
+func (s *UserSpec) accountType() int {
+	if s.AccountType == 0 {
+		return defaultAccountType
+	}
+
+	return s.AccountType
+}
+
+func (s *UserSpec) form(domains []int) *api.UserForm {
+	username, email := s.Username, s.Email
+	firstname, lastname := s.Firstname, s.Lastname
+	accountType, enabled := s.accountType(), s.Enabled
+
+	form := &api.UserForm{
+		Username:    &username,
+		Email:       &email,
+		Firstname:   &firstname,
+		Lastname:    &lastname,
+		AccountType: &accountType,
+		Enabled:     &enabled,
+		Domains:     domains,
+	}
+
+	if s.Timezone != "" {
+		tz := s.Timezone
+		form.Timezone = &tz
+	}
+
+	return form
+}
+
+// changes returns a form holding the fields of u that differ from the
+// spec
+func (s *UserSpec) changes(u *api.User, domains []int) (form *api.UserForm, changed bool) {
+	var current []int
+
+	form = &api.UserForm{ID: &u.ID}
+
+	if !strings.EqualFold(s.Email, u.Email) {
+		v := s.Email
+		form.Email = &v
+		changed = true
+	}
+
+	if s.Firstname != u.Firstname {
+		v := s.Firstname
+		form.Firstname = &v
+		changed = true
+	}
+
+	if s.Lastname != u.Lastname {
+		v := s.Lastname
+		form.Lastname = &v
+		changed = true
+	}
+
+	if s.accountType() != u.AccountType {
+		v := s.accountType()
+		form.AccountType = &v
+		changed = true
+	}
+
+	if s.Enabled != u.Enabled {
+		v := s.Enabled
+		form.Enabled = &v
+		changed = true
+	}
+
+	if s.Timezone != "" && s.Timezone != u.Timezone {
+		v := s.Timezone
+		form.Timezone = &v
+		changed = true
+	}
+
+	for _, d := range u.Domains {
+		current = append(current, d.ID)
+	}
+	if !reflect.DeepEqual(sortedInts(current), domains) {
+		form.Domains = domains
+		changed = true
+	}
+
+	return
+}
+
+func setStatus(obj *Object, id int, err error) {
+	var notReady *notReadyError
+	var invalid *specError
+
+	if id > 0 {
+		obj.Status.ID = id
+	}
+
+	cond := Condition{Type: ReadyCondition, Status: ConditionFalse}
+	switch {
+	case err == nil:
+		cond.Status = ConditionTrue
+		cond.Reason = ReasonReconciled
+		obj.Status.ObservedGeneration = obj.Generation
+	case errors.As(err, &notReady):
+		cond.Reason = ReasonDependencyNotReady
+	case errors.As(err, &invalid):
+		cond.Reason = ReasonInvalidSpec
+	default:
+		cond.Reason = ReasonFailed
+	}
+	if err != nil {
+		cond.Message = err.Error()
+	}
+
+	obj.Status.SetCondition(cond)
+}
+
+// sortedInts returns a sorted copy of ids, nil when empty
+func sortedInts(ids []int) []int {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	s := append([]int(nil), ids...)
+	sort.Ints(s)
+
+	return s
+}
@@ -0,0 +1,646 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeClient struct {
+	mu      sync.Mutex
+	nextID  int
+	orgs    map[int]*api.Organization
+	domains map[int]*api.Domain
+	users   map[int]*api.User
+	calls   []string
+	fail    string
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		nextID:  100,
+		orgs:    make(map[int]*api.Organization),
+		domains: make(map[int]*api.Domain),
+		users:   make(map[int]*api.User),
+	}
+}
+
+func notFound() error {
+	return &api.ErrorResponse{
+		Code:     http.StatusNotFound,
+		Message:  "Not Found",
+		Response: &http.Response{Request: &http.Request{Method: "GET", URL: &url.URL{Path: "/"}}},
+	}
+}
+
+func (c *fakeClient) call(name string) (err error) {
+	c.calls = append(c.calls, name)
+	if c.fail != "" && strings.HasPrefix(name, c.fail) {
+		err = fmt.Errorf("500 Internal Server Error")
+	}
+	return
+}
+
+func (c *fakeClient) count(prefix string) (n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range c.calls {
+		if strings.HasPrefix(v, prefix) {
+			n++
+		}
+	}
+	return
+}
+
+func (c *fakeClient) GetOrganizations(opts *api.ListOptions) (l *api.OrganizationList, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l = &api.OrganizationList{}
+	for _, o := range c.orgs {
+		l.Items = append(l.Items, *o)
+	}
+	return
+}
+
+func (c *fakeClient) GetOrganization(id int) (org *api.Organization, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o, ok := c.orgs[id]
+	if !ok {
+		err = notFound()
+		return
+	}
+	v := *o
+	org = &v
+	return
+}
+
+func (c *fakeClient) CreateOrganization(form *api.OrganizationForm) (org *api.Organization, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call("create-org " + form.Name); err != nil {
+		return
+	}
+	c.nextID++
+	c.orgs[c.nextID] = &api.Organization{ID: c.nextID, Name: form.Name}
+	org = &api.Organization{ID: c.nextID, Name: form.Name}
+	return
+}
+
+func (c *fakeClient) UpdateOrganization(form *api.OrganizationForm, org *api.Organization) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call("update-org " + form.Name); err != nil {
+		return
+	}
+	c.orgs[form.ID].Name = form.Name
+	return
+}
+
+func (c *fakeClient) DeleteOrganization(id int) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call(fmt.Sprintf("delete-org %d", id)); err != nil {
+		return
+	}
+	if _, ok := c.orgs[id]; !ok {
+		err = notFound()
+		return
+	}
+	delete(c.orgs, id)
+	return
+}
+
+func (c *fakeClient) GetDomain(id int) (domain *api.Domain, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d, ok := c.domains[id]
+	if !ok {
+		err = notFound()
+		return
+	}
+	v := *d
+	domain = &v
+	return
+}
+
+func (c *fakeClient) GetDomainByName(name string) (domain *api.Domain, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range c.domains {
+		if d.Name == name {
+			v := *d
+			domain = &v
+			return
+		}
+	}
+	err = notFound()
+	return
+}
+
+func (c *fakeClient) CreateDomain(domain *api.Domain) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call("create-domain " + domain.Name); err != nil {
+		return
+	}
+	c.nextID++
+	domain.ID = c.nextID
+	v := *domain
+	c.domains[domain.ID] = &v
+	return
+}
+
+func (c *fakeClient) UpdateDomain(domain *api.Domain) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call("update-domain " + domain.Name); err != nil {
+		return
+	}
+	v := *domain
+	c.domains[domain.ID] = &v
+	return
+}
+
+func (c *fakeClient) DeleteDomain(id int) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call(fmt.Sprintf("delete-domain %d", id)); err != nil {
+		return
+	}
+	delete(c.domains, id)
+	return
+}
+
+func (c *fakeClient) GetUsers(opts *api.ListOptions) (l *api.UserList, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l = &api.UserList{}
+	for _, u := range c.users {
+		l.Items = append(l.Items, *u)
+	}
+	return
+}
+
+func (c *fakeClient) GetUser(id int) (user *api.User, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.users[id]
+	if !ok {
+		err = notFound()
+		return
+	}
+	v := *u
+	user = &v
+	return
+}
+
+func (c *fakeClient) CreateUser(form *api.UserForm) (user *api.User, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call("create-user " + *form.Username); err != nil {
+		return
+	}
+	c.nextID++
+	u := &api.User{ID: c.nextID}
+	applyForm(u, form)
+	c.users[u.ID] = u
+	v := *u
+	user = &v
+	return
+}
+
+func (c *fakeClient) UpdateUser(form *api.UserForm) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call(fmt.Sprintf("update-user %d", *form.ID)); err != nil {
+		return
+	}
+	applyForm(c.users[*form.ID], form)
+	return
+}
+
+func (c *fakeClient) DeleteUser(id int) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = c.call(fmt.Sprintf("delete-user %d", id)); err != nil {
+		return
+	}
+	delete(c.users, id)
+	return
+}
+
+func applyForm(u *api.User, form *api.UserForm) {
+	if form.Username != nil {
+		u.Username = *form.Username
+	}
+	if form.Email != nil {
+		u.Email = *form.Email
+	}
+	if form.Firstname != nil {
+		u.Firstname = *form.Firstname
+	}
+	if form.Lastname != nil {
+		u.Lastname = *form.Lastname
+	}
+	if form.AccountType != nil {
+		u.AccountType = *form.AccountType
+	}
+	if form.Enabled != nil {
+		u.Enabled = *form.Enabled
+	}
+	if form.Domains != nil {
+		u.Domains = nil
+		for _, d := range form.Domains {
+			u.Domains = append(u.Domains, api.UserDomain{ID: d})
+		}
+	}
+}
+
+var (
+	orgKey    = Key{Kind: OrganizationKind, Name: "example"}
+	domainKey = Key{Kind: DomainKind, Name: "example-com"}
+	userKey   = Key{Kind: UserKind, Name: "jdoe"}
+)
+
+func applyExample(s *MemoryStore) {
+	s.Apply(&Object{Key: userKey, Spec: &UserSpec{
+		Username: "jdoe",
+		Email:    "jdoe@example.com",
+		Enabled:  true,
+		Domains:  []string{domainKey.Name},
+	}})
+	s.Apply(&Object{Key: domainKey, Spec: &DomainSpec{
+		Name:          "example.com",
+		SiteURL:       "https://mail.example.com",
+		Organizations: []string{orgKey.Name},
+		Enabled:       true,
+		AcceptInbound: true,
+		SpamChecks:    true,
+		VirusChecks:   true,
+	}})
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example Inc"}})
+}
+
+func getObject(t *testing.T, s Store, key Key) *Object {
+	o, err := s.Get(key)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if o == nil {
+		t.Fatalf("Expected the object %s to exist", key)
+	}
+	return o
+}
+
+func checkReady(t *testing.T, s Store, key Key) *Object {
+	o := getObject(t, s, key)
+	c := o.Status.Condition(ReadyCondition)
+	if c == nil || c.Status != ConditionTrue {
+		t.Fatalf("Expected %s to be ready got %v", key, c)
+	}
+	if o.Status.ID == 0 {
+		t.Errorf("Expected the ID of %s to be set", key)
+	}
+	if o.Status.ObservedGeneration != o.Generation {
+		t.Errorf("Expected %d got %d", o.Generation, o.Status.ObservedGeneration)
+	}
+	if !o.HasFinalizer(Finalizer) {
+		t.Errorf("Expected %s to hold the finalizer", key)
+	}
+	return o
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil, NewMemoryStore(), nil); err == nil || err.Error() != clientParamError {
+		t.Errorf("Expected '%s' got '%v'", clientParamError, err)
+	}
+	if _, err := New(newFakeClient(), nil, nil); err == nil || err.Error() != storeParamError {
+		t.Errorf("Expected '%s' got '%v'", storeParamError, err)
+	}
+	c, err := New(newFakeClient(), NewMemoryStore(), nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if c.opts.Workers != DefaultWorkers || c.opts.Resync != DefaultResync {
+		t.Errorf("Expected the default options got %v", c.opts)
+	}
+}
+
+func TestReconcileAll(t *testing.T) {
+	fc := newFakeClient()
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	org := checkReady(t, s, orgKey)
+	domain := checkReady(t, s, domainKey)
+	user := checkReady(t, s, userKey)
+
+	d := fc.domains[domain.Status.ID]
+	if len(d.Organizations) != 1 || d.Organizations[0] != org.Status.ID {
+		t.Errorf("Expected %v got %v", []int{org.Status.ID}, d.Organizations)
+	}
+	if d.DeliveryMode != 1 || d.Language != "en" || !d.SpamChecks {
+		t.Errorf("Expected the domain defaults got %v", d)
+	}
+	u := fc.users[user.Status.ID]
+	if len(u.Domains) != 1 || u.Domains[0].ID != domain.Status.ID {
+		t.Errorf("Expected %v got %v", []int{domain.Status.ID}, u.Domains)
+	}
+	if u.AccountType != defaultAccountType {
+		t.Errorf("Expected %d got %d", defaultAccountType, u.AccountType)
+	}
+
+	calls := len(fc.calls)
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(fc.calls) != calls {
+		t.Errorf("Expected no changes got %v", fc.calls[calls:])
+	}
+
+	// the domains endpoint does not return the organizations
+	d.Organizations = nil
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(fc.calls) != calls {
+		t.Errorf("Expected no changes got %v", fc.calls[calls:])
+	}
+}
+
+func TestReconcileDrift(t *testing.T) {
+	fc := newFakeClient()
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	c.ReconcileAll()
+	org := getObject(t, s, orgKey)
+	domain := getObject(t, s, domainKey)
+	user := getObject(t, s, userKey)
+
+	fc.orgs[org.Status.ID].Name = "Renamed"
+	fc.domains[domain.Status.ID].SpamChecks = false
+	fc.users[user.Status.ID].Enabled = false
+
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if fc.orgs[org.Status.ID].Name != "Example Inc" {
+		t.Errorf("Expected %s got %s", "Example Inc", fc.orgs[org.Status.ID].Name)
+	}
+	if !fc.domains[domain.Status.ID].SpamChecks {
+		t.Errorf("Expected spam checks to be enabled")
+	}
+	if !fc.users[user.Status.ID].Enabled {
+		t.Errorf("Expected the user to be enabled")
+	}
+
+	delete(fc.users, user.Status.ID)
+	if err := c.Reconcile(userKey); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if n := fc.count("create-user"); n != 2 {
+		t.Errorf("Expected %d got %d", 2, n)
+	}
+
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example Ltd"}})
+	if err := c.Reconcile(orgKey); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	org = checkReady(t, s, orgKey)
+	if org.Generation != 2 {
+		t.Errorf("Expected %d got %d", 2, org.Generation)
+	}
+	if fc.orgs[org.Status.ID].Name != "Example Ltd" {
+		t.Errorf("Expected %s got %s", "Example Ltd", fc.orgs[org.Status.ID].Name)
+	}
+}
+
+func TestReconcileAdopt(t *testing.T) {
+	fc := newFakeClient()
+	fc.orgs[1] = &api.Organization{ID: 1, Name: "Example Inc"}
+	fc.domains[2] = &api.Domain{ID: 2, Name: "example.com", Organizations: []int{1}}
+	fc.users[3] = &api.User{ID: 3, Username: "JDoe", Email: "jdoe@example.com", AccountType: 3}
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	for key, id := range map[Key]int{orgKey: 1, domainKey: 2, userKey: 3} {
+		if o := checkReady(t, s, key); o.Status.ID != id {
+			t.Errorf("Expected %d got %d", id, o.Status.ID)
+		}
+	}
+	if n := fc.count("create"); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+	if n := fc.count("update-domain"); n != 1 {
+		t.Errorf("Expected %d got %d", 1, n)
+	}
+}
+
+func TestReconcileDependencyNotReady(t *testing.T) {
+	fc := newFakeClient()
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	err := c.Reconcile(domainKey)
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	expected := fmt.Sprintf(dependencyError, OrganizationKind, orgKey.Name)
+	if err.Error() != expected {
+		t.Errorf("Expected '%s' got '%s'", expected, err)
+	}
+	o := getObject(t, s, domainKey)
+	cond := o.Status.Condition(ReadyCondition)
+	if cond == nil || cond.Status != ConditionFalse || cond.Reason != ReasonDependencyNotReady || cond.Message != expected {
+		t.Errorf("Expected a %s condition got %v", ReasonDependencyNotReady, cond)
+	}
+	if n := fc.count("create-domain"); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+
+	c.Reconcile(orgKey)
+	if err = c.Reconcile(domainKey); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	o = checkReady(t, s, domainKey)
+	if c := o.Status.Condition(ReadyCondition); c.Message != "" {
+		t.Errorf("Expected an empty message got %s", c.Message)
+	}
+}
+
+func TestReconcileFailed(t *testing.T) {
+	fc := newFakeClient()
+	fc.fail = "create-org"
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	if err := c.Reconcile(orgKey); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	o := getObject(t, s, orgKey)
+	cond := o.Status.Condition(ReadyCondition)
+	if cond == nil || cond.Reason != ReasonFailed {
+		t.Errorf("Expected a %s condition got %v", ReasonFailed, cond)
+	}
+	if o.Status.ObservedGeneration != 0 {
+		t.Errorf("Expected %d got %d", 0, o.Status.ObservedGeneration)
+	}
+	first := cond.LastTransitionTime
+
+	c.Reconcile(orgKey)
+	o = getObject(t, s, orgKey)
+	if cond = o.Status.Condition(ReadyCondition); !cond.LastTransitionTime.Equal(first) {
+		t.Errorf("Expected the transition time to be kept")
+	}
+
+	s.Apply(&Object{Key: Key{Kind: UserKind, Name: "invalid"}, Spec: &DomainSpec{Name: "example.org"}})
+	if err := c.Reconcile(Key{Kind: UserKind, Name: "invalid"}); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	o = getObject(t, s, Key{Kind: UserKind, Name: "invalid"})
+	if cond = o.Status.Condition(ReadyCondition); cond == nil || cond.Reason != ReasonInvalidSpec {
+		t.Errorf("Expected a %s condition got %v", ReasonInvalidSpec, cond)
+	}
+
+	if err := c.Reconcile(Key{Kind: UserKind, Name: "missing"}); err != nil {
+		t.Errorf("An error should not be returned: %s", err)
+	}
+}
+
+func TestReconcileDelete(t *testing.T) {
+	fc := newFakeClient()
+	s := NewMemoryStore()
+	c, _ := New(fc, s, nil)
+
+	applyExample(s)
+	c.ReconcileAll()
+	org := getObject(t, s, orgKey)
+	domain := getObject(t, s, domainKey)
+	user := getObject(t, s, userKey)
+
+	fc.fail = "delete-user"
+	s.Delete(userKey)
+	if err := c.Reconcile(userKey); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	o := getObject(t, s, userKey)
+	if !o.Deleting || !o.HasFinalizer(Finalizer) {
+		t.Errorf("Expected the user to be kept until it is deleted")
+	}
+	if cond := o.Status.Condition(ReadyCondition); cond == nil || cond.Reason != ReasonFailed {
+		t.Errorf("Expected a %s condition got %v", ReasonFailed, cond)
+	}
+
+	fc.fail = ""
+	s.Delete(orgKey)
+	s.Delete(domainKey)
+	if err := c.ReconcileAll(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	objs, _ := s.List()
+	if len(objs) != 0 {
+		t.Errorf("Expected %d got %d", 0, len(objs))
+	}
+
+	var deletes []string
+	for _, v := range fc.calls {
+		if strings.HasPrefix(v, "delete") {
+			deletes = append(deletes, v)
+		}
+	}
+	expected := []string{
+		fmt.Sprintf("delete-user %d", user.Status.ID),
+		fmt.Sprintf("delete-user %d", user.Status.ID),
+		fmt.Sprintf("delete-domain %d", domain.Status.ID),
+		fmt.Sprintf("delete-org %d", org.Status.ID),
+	}
+	if fmt.Sprint(deletes) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v got %v", expected, deletes)
+	}
+
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example Inc"}})
+	c.Reconcile(orgKey)
+	o = getObject(t, s, orgKey)
+	delete(fc.orgs, o.Status.ID)
+	s.Delete(orgKey)
+	if err := c.Reconcile(orgKey); err != nil {
+		t.Errorf("An error should not be returned: %s", err)
+	}
+	if o, _ = s.Get(orgKey); o != nil {
+		t.Errorf("Expected the organization to be removed")
+	}
+}
+
+func TestControllerStart(t *testing.T) {
+	fc := newFakeClient()
+	s := NewMemoryStore()
+	c, _ := New(fc, s, &Options{Resync: time.Hour, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond})
+
+	c.Start()
+	defer c.Stop()
+
+	applyExample(s)
+	waitFor(t, func() bool {
+		for _, key := range []Key{orgKey, domainKey, userKey} {
+			if o, _ := s.Get(key); o == nil || !o.Status.Ready() {
+				return false
+			}
+		}
+		return true
+	})
+
+	s.Delete(userKey)
+	waitFor(t, func() bool {
+		o, _ := s.Get(userKey)
+		return o == nil
+	})
+	if len(fc.users) != 0 {
+		t.Errorf("Expected %d got %d", 0, len(fc.users))
+	}
+
+	c.Stop()
+	c.Stop()
+	s.Apply(&Object{Key: Key{Kind: OrganizationKind, Name: "stopped"}, Spec: &OrganizationSpec{Name: "Stopped"}})
+	time.Sleep(20 * time.Millisecond)
+	if n := fc.count("create-org Stopped"); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+}
+
+func waitFor(t *testing.T, fn func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !fn() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the controller")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,158 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"sync"
+	"time"
+)
+
+// Queue is a work queue of object keys. A key is only queued once, a
+// key added while it is being processed is queued again when Done is
+// called so that a key is never processed by two workers at once.
+type Queue struct {
+	base       time.Duration
+	max        time.Duration
+	mu         sync.Mutex
+	cond       *sync.Cond
+	items      []Key
+	dirty      map[Key]bool
+	processing map[Key]bool
+	failures   map[Key]int
+	shutdown   bool
+}
+
+// NewQueue returns a Queue, failed keys are retried after base doubled
+// for every consecutive failure up to max
+func NewQueue(base, max time.Duration) *Queue {
+	q := &Queue{
+		base:       base,
+		max:        max,
+		dirty:      make(map[Key]bool),
+		processing: make(map[Key]bool),
+		failures:   make(map[Key]int),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+// Add queues a key
+func (q *Queue) Add(key Key) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.shutdown || q.dirty[key] {
+		return
+	}
+
+	q.dirty[key] = true
+	if q.processing[key] {
+		return
+	}
+
+	q.items = append(q.items, key)
+	q.cond.Signal()
+}
+
+// AddAfter queues a key once d has passed
+func (q *Queue) AddAfter(key Key, d time.Duration) {
+	if d <= 0 {
+		q.Add(key)
+		return
+	}
+
+	time.AfterFunc(d, func() {
+		q.Add(key)
+	})
+}
+
+// AddRateLimited queues a key that failed after a backoff delay
+func (q *Queue) AddRateLimited(key Key) {
+	q.mu.Lock()
+	n := q.failures[key]
+	q.failures[key] = n + 1
+	q.mu.Unlock()
+
+	d := q.base
+	for i := 0; i < n && d < q.max; i++ {
+		d *= 2
+	}
+	if d > q.max {
+		d = q.max
+	}
+
+	q.AddAfter(key, d)
+}
+
+// Forget clears the failures of a key
+func (q *Queue) Forget(key Key) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.failures, key)
+}
+
+// Failures returns the number of consecutive failures of a key
+func (q *Queue) Failures(key Key) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.failures[key]
+}
+
+// Get blocks until a key is queued, ok is false once the queue is
+// shut down
+func (q *Queue) Get() (key Key, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.shutdown {
+		q.cond.Wait()
+	}
+
+	if len(q.items) == 0 {
+		return
+	}
+
+	key, q.items = q.items[0], q.items[1:]
+	delete(q.dirty, key)
+	q.processing[key] = true
+	ok = true
+
+	return
+}
+
+// Done marks a key returned by Get as processed
+func (q *Queue) Done(key Key) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.processing, key)
+	if q.dirty[key] {
+		q.items = append(q.items, key)
+		q.cond.Signal()
+	}
+}
+
+// Len returns the number of queued keys
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+}
+
+// ShutDown stops the queue and wakes the workers waiting in Get
+func (q *Queue) ShutDown() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.shutdown = true
+	q.cond.Broadcast()
+}
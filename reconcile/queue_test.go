@@ -0,0 +1,103 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewQueue(time.Millisecond, 10*time.Millisecond)
+	a, b := Key{Kind: DomainKind, Name: "a"}, Key{Kind: DomainKind, Name: "b"}
+
+	q.Add(a)
+	q.Add(b)
+	q.Add(a)
+	if q.Len() != 2 {
+		t.Errorf("Expected %d got %d", 2, q.Len())
+	}
+
+	key, ok := q.Get()
+	if !ok || key != a {
+		t.Fatalf("Expected %s got %s", a, key)
+	}
+
+	// a key added while it is processed is queued once it is done
+	q.Add(a)
+	if q.Len() != 1 {
+		t.Errorf("Expected %d got %d", 1, q.Len())
+	}
+	q.Done(a)
+	if q.Len() != 2 {
+		t.Errorf("Expected %d got %d", 2, q.Len())
+	}
+
+	if key, _ = q.Get(); key != b {
+		t.Errorf("Expected %s got %s", b, key)
+	}
+	q.Done(b)
+	if key, _ = q.Get(); key != a {
+		t.Errorf("Expected %s got %s", a, key)
+	}
+	q.Done(a)
+}
+
+func TestQueueRateLimited(t *testing.T) {
+	q := NewQueue(20*time.Millisecond, 40*time.Millisecond)
+	a := Key{Kind: UserKind, Name: "a"}
+
+	for i := 0; i < 4; i++ {
+		q.AddRateLimited(a)
+	}
+	if q.Failures(a) != 4 {
+		t.Errorf("Expected %d got %d", 4, q.Failures(a))
+	}
+	if q.Len() != 0 {
+		t.Errorf("Expected %d got %d", 0, q.Len())
+	}
+
+	start := time.Now()
+	if key, ok := q.Get(); !ok || key != a {
+		t.Fatalf("Expected %s got %s", a, key)
+	}
+	if d := time.Since(start); d < 15*time.Millisecond {
+		t.Errorf("Expected the key to be delayed got %s", d)
+	}
+	q.Done(a)
+
+	q.Forget(a)
+	if q.Failures(a) != 0 {
+		t.Errorf("Expected %d got %d", 0, q.Failures(a))
+	}
+}
+
+func TestQueueShutDown(t *testing.T) {
+	q := NewQueue(time.Millisecond, time.Millisecond)
+
+	done := make(chan bool)
+	go func() {
+		_, ok := q.Get()
+		done <- ok
+	}()
+
+	q.ShutDown()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Errorf("Expected Get to fail after a shutdown")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Get to return after a shutdown")
+	}
+
+	q.Add(Key{Kind: UserKind, Name: "a"})
+	if q.Len() != 0 {
+		t.Errorf("Expected %d got %d", 0, q.Len())
+	}
+}
@@ -0,0 +1,264 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package reconcile Declarative reconciliation of Baruwa resources
+
+Objects of kind BaruwaOrganization, BaruwaDomain and BaruwaUser are
+declared in a Store. A Controller takes the keys of changed objects from
+a work queue, compares each object with Baruwa and creates or updates
+the Baruwa resource so that it matches the spec, recording the outcome
+in the status conditions of the object. All the objects are queued
+again every resync period so that changes made directly in Baruwa are
+reverted.
+
+The controller adds a finalizer to every object it manages. When an
+object is deleted from the store the Baruwa resource is deleted before
+the finalizer is removed, the store then drops the object.
+
+	store := reconcile.NewMemoryStore()
+	c, err := reconcile.New(client, store, nil)
+	c.Start()
+	defer c.Stop()
+	store.Apply(&reconcile.Object{
+		Key:  reconcile.Key{Kind: reconcile.OrganizationKind, Name: "example"},
+		Spec: &reconcile.OrganizationSpec{Name: "Example Inc"},
+	})
+*/
+package reconcile
+
+import (
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Kind is the type of a declared object
+type Kind string
+
+const (
+	// OrganizationKind objects hold an OrganizationSpec
+	OrganizationKind Kind = "BaruwaOrganization"
+	// DomainKind objects hold a DomainSpec
+	DomainKind Kind = "BaruwaDomain"
+	// UserKind objects hold a UserSpec
+	UserKind Kind = "BaruwaUser"
+)
+
+// Finalizer is added to the objects managed by the controller
+const Finalizer = "baruwa.com/finalizer"
+
+// ReadyCondition reports whether Baruwa matches the spec of an object
+const ReadyCondition = "Ready"
+
+const (
+	// ReasonReconciled Baruwa matches the spec
+	ReasonReconciled = "Reconciled"
+	// ReasonFailed a Baruwa API call failed
+	ReasonFailed = "Failed"
+	// ReasonDependencyNotReady a referenced object is not ready
+	ReasonDependencyNotReady = "DependencyNotReady"
+	// ReasonInvalidSpec the spec does not match the kind
+	ReasonInvalidSpec = "InvalidSpec"
+)
+
+const (
+	clientParamError   = "The client param is required"
+	storeParamError    = "The store param is required"
+	specTypeError      = "The spec of %s is a %T"
+	unknownKindError   = "Unknown kind %q"
+	dependencyError    = "The %s %q is not ready"
+	objectMissingError = "The object %s does not exist"
+)
+
+// Key identifies an object
+type Key struct {
+	Kind Kind
+	Name string
+}
+
+func (k Key) String() string {
+	return string(k.Kind) + "/" + k.Name
+}
+
+// OrganizationSpec is the desired state of an organization
+type OrganizationSpec struct {
+	Name string
+}
+
+// DomainSpec is the desired state of a domain, settings not in the
+// spec are left as they are in Baruwa
+type DomainSpec struct {
+	Name    string
+	SiteURL string
+	// Organizations are the names of BaruwaOrganization objects
+	Organizations []string
+	Enabled       bool
+	AcceptInbound bool
+	SpamChecks    bool
+	VirusChecks   bool
+	// Language and Timezone are left unchanged when empty
+	Language string
+	Timezone string
+}
+
+// UserSpec is the desired state of a user account, the password is
+// not managed
+type UserSpec struct {
+	Username  string
+	Email     string
+	Firstname string
+	Lastname  string
+	// AccountType defaults to a normal user
+	AccountType int
+	Enabled     bool
+	// Timezone is left unchanged when empty
+	Timezone string
+	// Domains are the names of BaruwaDomain objects
+	Domains []string
+}
+
+// ConditionStatus is the status of a condition
+type ConditionStatus string
+
+const (
+	// ConditionTrue the condition holds
+	ConditionTrue ConditionStatus = "True"
+	// ConditionFalse the condition does not hold
+	ConditionFalse ConditionStatus = "False"
+	// ConditionUnknown the condition has not been checked
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// Condition is an observation of an object
+type Condition struct {
+	Type               string
+	Status             ConditionStatus
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// Status is the observed state of an object
+type Status struct {
+	// ID of the Baruwa resource
+	ID                 int
+	ObservedGeneration int64
+	Conditions         []Condition
+}
+
+// Condition returns the condition of type t or nil
+func (s *Status) Condition(t string) *Condition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// SetCondition adds or replaces a condition, the transition time is
+// only changed when the status changes
+func (s *Status) SetCondition(c Condition) {
+	if c.LastTransitionTime.IsZero() {
+		c.LastTransitionTime = time.Now()
+	}
+
+	existing := s.Condition(c.Type)
+	if existing == nil {
+		s.Conditions = append(s.Conditions, c)
+		return
+	}
+
+	if existing.Status == c.Status {
+		c.LastTransitionTime = existing.LastTransitionTime
+	}
+	*existing = c
+}
+
+// Ready reports whether the Ready condition is true
+func (s *Status) Ready() bool {
+	c := s.Condition(ReadyCondition)
+
+	return c != nil && c.Status == ConditionTrue
+}
+
+// Object is a declared Baruwa resource
+type Object struct {
+	Key
+	// Generation is increased by the store when the spec changes
+	Generation int64
+	// Deleting is set by the store when the object is deleted
+	Deleting   bool
+	Finalizers []string
+	// Spec is a *OrganizationSpec, *DomainSpec or *UserSpec matching
+	// the kind, the controller does not modify it
+	Spec   interface{}
+	Status Status
+}
+
+// HasFinalizer reports whether the object holds the finalizer f
+func (o *Object) HasFinalizer(f string) bool {
+	for _, v := range o.Finalizers {
+		if v == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RemoveFinalizer removes the finalizer f
+func (o *Object) RemoveFinalizer(f string) {
+	finalizers := o.Finalizers[:0]
+	for _, v := range o.Finalizers {
+		if v != f {
+			finalizers = append(finalizers, v)
+		}
+	}
+	o.Finalizers = finalizers
+}
+
+// Store holds the declared objects
+type Store interface {
+	// Get returns a copy of an object, or nil when it does not exist
+	Get(key Key) (*Object, error)
+	// List returns copies of all the objects
+	List() ([]*Object, error)
+	// Update saves the finalizers of an object, an object that is
+	// being deleted is removed once it has no finalizers
+	Update(obj *Object) error
+	// UpdateStatus saves the status of an object
+	UpdateStatus(obj *Object) error
+}
+
+// Watcher is implemented by stores that report changed objects
+type Watcher interface {
+	Watch(fn func(key Key))
+}
+
+// Client is the subset of api.Client used by the controller
+type Client interface {
+	GetOrganizations(opts *api.ListOptions) (*api.OrganizationList, error)
+	GetOrganization(organizationID int) (*api.Organization, error)
+	CreateOrganization(form *api.OrganizationForm) (*api.Organization, error)
+	UpdateOrganization(form *api.OrganizationForm, org *api.Organization) error
+	DeleteOrganization(organizationID int) error
+	GetDomain(domainID int) (*api.Domain, error)
+	GetDomainByName(domainName string) (*api.Domain, error)
+	CreateDomain(domain *api.Domain) error
+	UpdateDomain(domain *api.Domain) error
+	DeleteDomain(domainID int) error
+	GetUsers(opts *api.ListOptions) (*api.UserList, error)
+	GetUser(userID int) (*api.User, error)
+	CreateUser(user *api.UserForm) (*api.User, error)
+	UpdateUser(user *api.UserForm) error
+	DeleteUser(userID int) error
+}
+
+var _ Client = (*api.Client)(nil)
@@ -0,0 +1,166 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu       sync.Mutex
+	objects  map[Key]*Object
+	watchers []func(key Key)
+}
+
+var (
+	_ Store   = (*MemoryStore)(nil)
+	_ Watcher = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{objects: make(map[Key]*Object)}
+}
+
+// Apply declares an object or replaces the spec of an existing one,
+// the status and finalizers of an existing object are kept
+func (s *MemoryStore) Apply(obj *Object) {
+	s.mu.Lock()
+	existing, ok := s.objects[obj.Key]
+	switch {
+	case !ok:
+		o := copyObject(obj)
+		o.Generation = 1
+		o.Deleting = false
+		s.objects[o.Key] = o
+	case !reflect.DeepEqual(existing.Spec, obj.Spec):
+		existing.Spec = obj.Spec
+		existing.Generation++
+	}
+	s.mu.Unlock()
+
+	s.notify(obj.Key)
+}
+
+// Delete requests the deletion of an object, it is removed once it has
+// no finalizers
+func (s *MemoryStore) Delete(key Key) {
+	s.mu.Lock()
+	o, ok := s.objects[key]
+	if ok {
+		o.Deleting = true
+		if len(o.Finalizers) == 0 {
+			delete(s.objects, key)
+		}
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notify(key)
+	}
+}
+
+// Get returns a copy of an object
+func (s *MemoryStore) Get(key Key) (*Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyObject(o), nil
+}
+
+// List returns copies of the objects sorted by key
+func (s *MemoryStore) List() ([]*Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := make([]*Object, 0, len(s.objects))
+	for _, o := range s.objects {
+		l = append(l, copyObject(o))
+	}
+
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Key.String() < l[j].Key.String()
+	})
+
+	return l, nil
+}
+
+// Update saves the finalizers of an object
+func (s *MemoryStore) Update(obj *Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[obj.Key]
+	if !ok {
+		return fmt.Errorf(objectMissingError, obj.Key)
+	}
+
+	o.Finalizers = append([]string(nil), obj.Finalizers...)
+	if o.Deleting && len(o.Finalizers) == 0 {
+		delete(s.objects, obj.Key)
+	}
+
+	return nil
+}
+
+// UpdateStatus saves the status of an object
+func (s *MemoryStore) UpdateStatus(obj *Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[obj.Key]
+	if !ok {
+		return fmt.Errorf(objectMissingError, obj.Key)
+	}
+
+	o.Status = copyStatus(obj.Status)
+
+	return nil
+}
+
+// Watch calls fn with the key of every applied or deleted object
+func (s *MemoryStore) Watch(fn func(key Key)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watchers = append(s.watchers, fn)
+}
+
+func (s *MemoryStore) notify(key Key) {
+	s.mu.Lock()
+	watchers := make([]func(key Key), len(s.watchers))
+	copy(watchers, s.watchers)
+	s.mu.Unlock()
+
+	for _, fn := range watchers {
+		fn(key)
+	}
+}
+
+func copyObject(o *Object) *Object {
+	c := *o
+	c.Finalizers = append([]string(nil), o.Finalizers...)
+	c.Status = copyStatus(o.Status)
+
+	return &c
+}
+
+func copyStatus(s Status) Status {
+	s.Conditions = append([]Condition(nil), s.Conditions...)
+
+	return s
+}
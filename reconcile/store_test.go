@@ -0,0 +1,80 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package reconcile
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	var notified []Key
+
+	s := NewMemoryStore()
+	s.Watch(func(key Key) {
+		notified = append(notified, key)
+	})
+
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example"}, Generation: 9, Deleting: true})
+	o := getObject(t, s, orgKey)
+	if o.Generation != 1 || o.Deleting {
+		t.Errorf("Expected a new object got %v", o)
+	}
+
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example"}})
+	if o = getObject(t, s, orgKey); o.Generation != 1 {
+		t.Errorf("Expected %d got %d", 1, o.Generation)
+	}
+	s.Apply(&Object{Key: orgKey, Spec: &OrganizationSpec{Name: "Example Inc"}})
+	if o = getObject(t, s, orgKey); o.Generation != 2 {
+		t.Errorf("Expected %d got %d", 2, o.Generation)
+	}
+
+	// changes to a returned object are only saved by Update and UpdateStatus
+	o.Finalizers = append(o.Finalizers, Finalizer)
+	o.Status.ID = 10
+	if o = getObject(t, s, orgKey); len(o.Finalizers) != 0 || o.Status.ID != 0 {
+		t.Errorf("Expected a copy of the object got %v", o)
+	}
+	o.Finalizers = append(o.Finalizers, Finalizer)
+	o.Status.ID = 10
+	s.Update(o)
+	s.UpdateStatus(o)
+	if o = getObject(t, s, orgKey); !o.HasFinalizer(Finalizer) || o.Status.ID != 10 {
+		t.Errorf("Expected the object to be saved got %v", o)
+	}
+
+	s.Delete(orgKey)
+	if o = getObject(t, s, orgKey); !o.Deleting {
+		t.Errorf("Expected the object to be deleting")
+	}
+	o.RemoveFinalizer(Finalizer)
+	s.Update(o)
+	if o, _ = s.Get(orgKey); o != nil {
+		t.Errorf("Expected the object to be removed")
+	}
+
+	s.Apply(&Object{Key: domainKey, Spec: &DomainSpec{Name: "example.com"}})
+	s.Delete(domainKey)
+	if o, _ = s.Get(domainKey); o != nil {
+		t.Errorf("Expected the object to be removed")
+	}
+
+	expected := fmt.Sprint([]Key{orgKey, orgKey, orgKey, orgKey, domainKey, domainKey})
+	if fmt.Sprint(notified) != expected {
+		t.Errorf("Expected %s got %s", expected, fmt.Sprint(notified))
+	}
+
+	missing := &Object{Key: userKey}
+	if err := s.Update(missing); err == nil || err.Error() != fmt.Sprintf(objectMissingError, userKey) {
+		t.Errorf("Expected '%s' got '%v'", fmt.Sprintf(objectMissingError, userKey), err)
+	}
+	if err := s.UpdateStatus(missing); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
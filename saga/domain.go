@@ -0,0 +1,208 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package saga
+
+import (
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	specParamError      = "The spec param is required"
+	domainNameError     = "The spec.Domain.Name param is required"
+	ldapAuthServerError = "The spec.LDAPSettings param requires an LDAP spec.AuthServer"
+)
+
+// Client is the subset of api.Client used by ProvisionDomain
+type Client interface {
+	GetOrganization(organizationID int) (*api.Organization, error)
+	UpdateOrganization(form *api.OrganizationForm, org *api.Organization) error
+	CreateDomain(domain *api.Domain) error
+	DeleteDomain(domainID int) error
+	CreateDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) (*api.DomainDeliveryServer, error)
+	DeleteDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) error
+	CreateAuthServer(domainID int, server *api.AuthServer) error
+	DeleteAuthServer(domainID int, server *api.AuthServer) error
+	CreateLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) error
+	DeleteLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) error
+	CreateDomainAlias(domainID int, form *api.DomainAliasForm) (*api.DomainAlias, error)
+	DeleteDomainAlias(domainID int, form *api.DomainAliasForm) error
+}
+
+var _ Client = (*api.Client)(nil)
+
+// DomainSpec describes a domain to provision, the optional parts are
+// skipped when empty
+type DomainSpec struct {
+	Domain          api.Domain
+	DeliveryServers []api.DomainDeliveryServerForm
+	AuthServer      *api.AuthServer
+	// LDAPSettings requires an AuthServer using the LDAP protocol
+	LDAPSettings *api.LDAPSettings
+	Aliases      []api.DomainAliasForm
+	// OrganizationID attaches the domain to the organization when > 0
+	OrganizationID int
+}
+
+// Provisioned holds the resources created by ProvisionDomain
+type Provisioned struct {
+	Domain          *api.Domain
+	DeliveryServers []*api.DomainDeliveryServer
+	AuthServer      *api.AuthServer
+	LDAPSettings    *api.LDAPSettings
+	Aliases         []*api.DomainAlias
+}
+
+// ProvisionDomain creates a domain as described by spec, when a step
+// fails the resources already created are deleted and the organization
+// is restored. The Provisioned lists what was created before the failure
+func ProvisionDomain(c Client, spec *DomainSpec) (p *Provisioned, err error) {
+	var s *Saga
+
+	if s, p, err = DomainSaga(c, spec); err != nil {
+		return
+	}
+
+	err = s.Run()
+
+	return
+}
+
+// DomainSaga returns the saga run by ProvisionDomain, more steps can be
+// added before it is run. The Provisioned is filled in as the steps
+// complete
+func DomainSaga(c Client, spec *DomainSpec) (s *Saga, p *Provisioned, err error) {
+	if spec == nil {
+		err = fmt.Errorf(specParamError)
+		return
+	}
+
+	if spec.Domain.Name == "" {
+		err = fmt.Errorf(domainNameError)
+		return
+	}
+
+	if spec.LDAPSettings != nil && (spec.AuthServer == nil || spec.AuthServer.Protocol != api.AuthProtocolLDAP) {
+		err = fmt.Errorf(ldapAuthServerError)
+		return
+	}
+
+	s = New()
+	p = &Provisioned{}
+	d := spec.Domain
+	d.ID = 0
+
+	s.Add(fmt.Sprintf("create domain %s", d.Name), func() error {
+		if err := c.CreateDomain(&d); err != nil {
+			return err
+		}
+		p.Domain = &d
+		return nil
+	}, func() error {
+		return c.DeleteDomain(d.ID)
+	})
+
+	for i := range spec.DeliveryServers {
+		form := spec.DeliveryServers[i]
+		form.ID = 0
+		s.Add(fmt.Sprintf("create delivery server %s", form.Address), func() error {
+			form.Domain = d.ID
+			server, err := c.CreateDomainDeliveryServer(d.ID, &form)
+			if err != nil {
+				return err
+			}
+			form.ID = server.ID
+			p.DeliveryServers = append(p.DeliveryServers, server)
+			return nil
+		}, func() error {
+			return c.DeleteDomainDeliveryServer(d.ID, &form)
+		})
+	}
+
+	if spec.AuthServer != nil {
+		server := *spec.AuthServer
+		server.ID = 0
+		s.Add(fmt.Sprintf("create authentication server %s", server.Address), func() error {
+			if err := c.CreateAuthServer(d.ID, &server); err != nil {
+				return err
+			}
+			p.AuthServer = &server
+			return nil
+		}, func() error {
+			return c.DeleteAuthServer(d.ID, &server)
+		})
+
+		if spec.LDAPSettings != nil {
+			settings := *spec.LDAPSettings
+			settings.ID = 0
+			s.Add(fmt.Sprintf("create LDAP settings %s", server.Address), func() error {
+				settings.AuthServer.ID = server.ID
+				if err := c.CreateLDAPSettings(d.ID, server.ID, &settings); err != nil {
+					return err
+				}
+				p.LDAPSettings = &settings
+				return nil
+			}, func() error {
+				return c.DeleteLDAPSettings(d.ID, server.ID, &settings)
+			})
+		}
+	}
+
+	for i := range spec.Aliases {
+		form := spec.Aliases[i]
+		form.ID = 0
+		s.Add(fmt.Sprintf("create domain alias %s", form.Name), func() error {
+			form.Domain = d.ID
+			alias, err := c.CreateDomainAlias(d.ID, &form)
+			if err != nil {
+				return err
+			}
+			form.ID = alias.ID
+			p.Aliases = append(p.Aliases, alias)
+			return nil
+		}, func() error {
+			return c.DeleteDomainAlias(d.ID, &form)
+		})
+	}
+
+	if spec.OrganizationID > 0 {
+		var prev *api.Organization
+		s.Add(fmt.Sprintf("attach to organization %d", spec.OrganizationID), func() (err error) {
+			if prev, err = c.GetOrganization(spec.OrganizationID); err != nil {
+				return
+			}
+			form := organizationForm(prev)
+			form.Domains = append(form.Domains, d.ID)
+			err = c.UpdateOrganization(form, &api.Organization{ID: prev.ID})
+			return
+		}, func() error {
+			return c.UpdateOrganization(organizationForm(prev), &api.Organization{ID: prev.ID})
+		})
+	}
+
+	return
+}
+
+// organizationForm converts an organization to the form that updates it
+func organizationForm(org *api.Organization) (form *api.OrganizationForm) {
+	form = &api.OrganizationForm{
+		ID:   org.ID,
+		Name: org.Name,
+	}
+
+	for _, d := range org.Domains {
+		form.Domains = append(form.Domains, d.ID)
+	}
+
+	for _, a := range org.Admins {
+		form.Admins = append(form.Admins, a.ID)
+	}
+
+	return
+}
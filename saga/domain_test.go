@@ -0,0 +1,243 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type fakeClient struct {
+	nextID int
+	calls  []string
+	fail   string
+	org    api.Organization
+}
+
+func (c *fakeClient) call(format string, a ...interface{}) (err error) {
+	name := fmt.Sprintf(format, a...)
+	c.calls = append(c.calls, name)
+	if c.fail != "" && strings.HasPrefix(name, c.fail) {
+		err = fmt.Errorf("500 Internal Server Error")
+	}
+	return
+}
+
+func (c *fakeClient) id() int {
+	c.nextID++
+	return c.nextID
+}
+
+func (c *fakeClient) GetOrganization(organizationID int) (*api.Organization, error) {
+	if err := c.call("GetOrganization %d", organizationID); err != nil {
+		return nil, err
+	}
+	o := c.org
+	return &o, nil
+}
+
+func (c *fakeClient) UpdateOrganization(form *api.OrganizationForm, org *api.Organization) error {
+	return c.call("UpdateOrganization %d %v", form.ID, form.Domains)
+}
+
+func (c *fakeClient) CreateDomain(domain *api.Domain) error {
+	if err := c.call("CreateDomain %s", domain.Name); err != nil {
+		return err
+	}
+	domain.ID = c.id()
+	return nil
+}
+
+func (c *fakeClient) DeleteDomain(domainID int) error {
+	return c.call("DeleteDomain %d", domainID)
+}
+
+func (c *fakeClient) CreateDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) (*api.DomainDeliveryServer, error) {
+	if err := c.call("CreateDomainDeliveryServer %d %s", domainID, form.Address); err != nil {
+		return nil, err
+	}
+	return &api.DomainDeliveryServer{ID: c.id(), Address: form.Address, Port: form.Port}, nil
+}
+
+func (c *fakeClient) DeleteDomainDeliveryServer(domainID int, form *api.DomainDeliveryServerForm) error {
+	return c.call("DeleteDomainDeliveryServer %d %d", domainID, form.ID)
+}
+
+func (c *fakeClient) CreateAuthServer(domainID int, server *api.AuthServer) error {
+	if err := c.call("CreateAuthServer %d %s", domainID, server.Address); err != nil {
+		return err
+	}
+	server.ID = c.id()
+	return nil
+}
+
+func (c *fakeClient) DeleteAuthServer(domainID int, server *api.AuthServer) error {
+	return c.call("DeleteAuthServer %d %d", domainID, server.ID)
+}
+
+func (c *fakeClient) CreateLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) error {
+	if err := c.call("CreateLDAPSettings %d %d %d", domainID, serverID, settings.AuthServer.ID); err != nil {
+		return err
+	}
+	settings.ID = c.id()
+	return nil
+}
+
+func (c *fakeClient) DeleteLDAPSettings(domainID, serverID int, settings *api.LDAPSettings) error {
+	return c.call("DeleteLDAPSettings %d %d %d", domainID, serverID, settings.ID)
+}
+
+func (c *fakeClient) CreateDomainAlias(domainID int, form *api.DomainAliasForm) (*api.DomainAlias, error) {
+	if err := c.call("CreateDomainAlias %d %s", domainID, form.Name); err != nil {
+		return nil, err
+	}
+	return &api.DomainAlias{ID: c.id(), Name: form.Name}, nil
+}
+
+func (c *fakeClient) DeleteDomainAlias(domainID int, form *api.DomainAliasForm) error {
+	return c.call("DeleteDomainAlias %d %d", domainID, form.ID)
+}
+
+func newSpec() *DomainSpec {
+	return &DomainSpec{
+		Domain: api.Domain{ID: 99, Name: "example.com", Enabled: true},
+		DeliveryServers: []api.DomainDeliveryServerForm{
+			{Address: "192.168.1.10", Port: 25, Enabled: true},
+			{Address: "192.168.1.11", Port: 25, Enabled: true},
+		},
+		AuthServer:     &api.AuthServer{Address: "ldap.example.com", Port: 389, Protocol: api.AuthProtocolLDAP, Enabled: true},
+		LDAPSettings:   &api.LDAPSettings{Basedn: "dc=example,dc=com", NameAttribute: "uid", EmailAttribute: "mail"},
+		Aliases:        []api.DomainAliasForm{{Name: "example.net", Enabled: true}},
+		OrganizationID: 10,
+	}
+}
+
+func TestProvisionDomain(t *testing.T) {
+	c := &fakeClient{
+		org: api.Organization{ID: 10, Name: "Example Inc", Domains: []api.OrgDomain{{ID: 5}}},
+	}
+
+	spec := newSpec()
+	p, err := ProvisionDomain(c, spec)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	expected := []string{
+		"CreateDomain example.com",
+		"CreateDomainDeliveryServer 1 192.168.1.10",
+		"CreateDomainDeliveryServer 1 192.168.1.11",
+		"CreateAuthServer 1 ldap.example.com",
+		"CreateLDAPSettings 1 4 4",
+		"CreateDomainAlias 1 example.net",
+		"GetOrganization 10",
+		"UpdateOrganization 10 [5 1]",
+	}
+	if !reflect.DeepEqual(c.calls, expected) {
+		t.Errorf("Expected %v got %v", expected, c.calls)
+	}
+	if p.Domain.ID != 1 || len(p.DeliveryServers) != 2 || p.AuthServer.ID != 4 || p.LDAPSettings.ID != 5 || p.Aliases[0].ID != 6 {
+		t.Errorf("Expected the created resources got %v", p)
+	}
+	if spec.Domain.ID != 99 || spec.AuthServer.ID != 0 {
+		t.Errorf("Expected the spec not to be modified")
+	}
+
+	if _, err = ProvisionDomain(c, &DomainSpec{Domain: api.Domain{Name: "example.org"}}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if c.calls[len(c.calls)-1] != "CreateDomain example.org" {
+		t.Errorf("Expected only the domain to be created got %v", c.calls[len(expected):])
+	}
+}
+
+func TestProvisionDomainRollback(t *testing.T) {
+	c := &fakeClient{
+		fail: "CreateDomainAlias",
+		org:  api.Organization{ID: 10},
+	}
+
+	p, err := ProvisionDomain(c, newSpec())
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+
+	expected := []string{
+		"CreateDomain example.com",
+		"CreateDomainDeliveryServer 1 192.168.1.10",
+		"CreateDomainDeliveryServer 1 192.168.1.11",
+		"CreateAuthServer 1 ldap.example.com",
+		"CreateLDAPSettings 1 4 4",
+		"CreateDomainAlias 1 example.net",
+		"DeleteLDAPSettings 1 4 5",
+		"DeleteAuthServer 1 4",
+		"DeleteDomainDeliveryServer 1 3",
+		"DeleteDomainDeliveryServer 1 2",
+		"DeleteDomain 1",
+	}
+	if !reflect.DeepEqual(c.calls, expected) {
+		t.Errorf("Expected %v got %v", expected, c.calls)
+	}
+	var serr *Error
+	if !errors.As(err, &serr) || serr.Step != "create domain alias example.net" || !serr.Recovered() {
+		t.Errorf("Expected the alias step to fail and be recovered got %s", err)
+	}
+	if len(serr.Compensated) != 5 {
+		t.Errorf("Expected %d got %d", 5, len(serr.Compensated))
+	}
+	if p.Domain == nil || len(p.Aliases) != 0 {
+		t.Errorf("Expected the resources created before the failure got %v", p)
+	}
+
+	c = &fakeClient{
+		fail: "UpdateOrganization 10 [1]",
+		org:  api.Organization{ID: 10, Name: "Example Inc", Admins: []api.OrgAdmin{{ID: 3}}},
+	}
+	spec := newSpec()
+	spec.AuthServer, spec.LDAPSettings, spec.Aliases = nil, nil, nil
+	if _, err = ProvisionDomain(c, spec); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if last := c.calls[len(c.calls)-1]; last != "DeleteDomain 1" {
+		t.Errorf("Expected %s got %s", "DeleteDomain 1", last)
+	}
+}
+
+func TestDomainSaga(t *testing.T) {
+	c := &fakeClient{}
+
+	if _, _, err := DomainSaga(c, nil); err == nil || err.Error() != specParamError {
+		t.Errorf("Expected '%s' got '%v'", specParamError, err)
+	}
+
+	if _, _, err := DomainSaga(c, &DomainSpec{}); err == nil || err.Error() != domainNameError {
+		t.Errorf("Expected '%s' got '%v'", domainNameError, err)
+	}
+
+	spec := newSpec()
+	spec.AuthServer.Protocol = 4
+	if _, _, err := DomainSaga(c, spec); err == nil || err.Error() != ldapAuthServerError {
+		t.Errorf("Expected '%s' got '%v'", ldapAuthServerError, err)
+	}
+
+	s, _, err := DomainSaga(c, newSpec())
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(s.Steps()) != 7 {
+		t.Errorf("Expected %d got %d", 7, len(s.Steps()))
+	}
+	if len(c.calls) != 0 {
+		t.Errorf("Expected no calls before the saga runs got %v", c.calls)
+	}
+}
@@ -0,0 +1,146 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package saga Run multi-step Baruwa provisioning with rollback
+
+A Saga runs its steps in order, when a step fails the steps that
+completed before it are compensated in reverse order, leaving the
+server as it was before the saga started. Later steps use the IDs
+returned by earlier ones through the variables their closures share.
+
+ProvisionDomain is a ready-made saga that creates a domain with its
+delivery servers, authentication server, LDAP settings and aliases and
+attaches it to an organization.
+
+	p, err := saga.ProvisionDomain(c, &saga.DomainSpec{
+		Domain:         api.Domain{Name: "example.com", Enabled: true},
+		OrganizationID: 10,
+	})
+	if err != nil {
+		var serr *saga.Error
+		if errors.As(err, &serr) && !serr.Recovered() {
+			log.Printf("manual cleanup required: %s", serr)
+		}
+	}
+*/
+package saga
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Step is a single action with the compensation that reverts it,
+// Compensate is nil for actions that do not need reverting
+type Step struct {
+	Name       string
+	Action     func() error
+	Compensate func() error
+}
+
+// StepError is a failed compensation
+type StepError struct {
+	Step string
+	Err  error
+}
+
+// Error is returned when a step fails
+type Error struct {
+	// Step is the name of the step that failed
+	Step string
+	// Err is the error returned by the step
+	Err error
+	// Compensated lists the steps reverted, in the order they were reverted
+	Compensated []string
+	// Failed lists the compensations that failed
+	Failed []StepError
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	s := fmt.Sprintf("%s failed: %s", e.Step, e.Err)
+	if len(e.Failed) > 0 {
+		l := make([]string, len(e.Failed))
+		for i, f := range e.Failed {
+			l[i] = fmt.Sprintf("%s: %s", f.Step, f.Err)
+		}
+		s = fmt.Sprintf("%s, rollback failed: %s", s, strings.Join(l, ", "))
+	}
+	return s
+}
+
+// Unwrap returns the error returned by the step
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Recovered returns true if all the completed steps were reverted
+func (e *Error) Recovered() bool {
+	return len(e.Failed) == 0
+}
+
+// Saga is an ordered list of steps
+type Saga struct {
+	steps []Step
+}
+
+// New returns an empty Saga
+func New() *Saga {
+	return &Saga{}
+}
+
+// Add appends a step and returns the Saga
+func (s *Saga) Add(name string, action, compensate func() error) *Saga {
+	s.steps = append(s.steps, Step{
+		Name:       name,
+		Action:     action,
+		Compensate: compensate,
+	})
+	return s
+}
+
+// Steps returns the names of the steps
+func (s *Saga) Steps() (l []string) {
+	for _, v := range s.steps {
+		l = append(l, v.Name)
+	}
+	return
+}
+
+// Run runs the steps, the error returned when a step fails is an *Error
+func (s *Saga) Run() (err error) {
+	for i, step := range s.steps {
+		if serr := step.Action(); serr != nil {
+			err = s.compensate(i, step.Name, serr)
+			return
+		}
+	}
+
+	return
+}
+
+func (s *Saga) compensate(n int, name string, cause error) *Error {
+	e := &Error{
+		Step: name,
+		Err:  cause,
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		step := s.steps[i]
+		if step.Compensate == nil {
+			continue
+		}
+		if err := step.Compensate(); err != nil {
+			e.Failed = append(e.Failed, StepError{Step: step.Name, Err: err})
+			continue
+		}
+		e.Compensated = append(e.Compensated, step.Name)
+	}
+
+	return e
+}
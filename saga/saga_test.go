@@ -0,0 +1,92 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package saga
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var calls []string
+
+	step := func(name string, fail bool) (func() error, func() error) {
+		return func() error {
+				calls = append(calls, "do "+name)
+				if fail {
+					return fmt.Errorf("%s failed", name)
+				}
+				return nil
+			}, func() error {
+				calls = append(calls, "undo "+name)
+				if name == "b" {
+					return fmt.Errorf("undo %s failed", name)
+				}
+				return nil
+			}
+	}
+
+	s := New()
+	for _, n := range []string{"a", "b", "c"} {
+		do, undo := step(n, false)
+		s.Add(n, do, undo)
+	}
+	if err := s.Run(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if strings.Join(calls, ",") != "do a,do b,do c" {
+		t.Errorf("Expected %s got %s", "do a,do b,do c", strings.Join(calls, ","))
+	}
+	if strings.Join(s.Steps(), ",") != "a,b,c" {
+		t.Errorf("Expected %s got %v", "a,b,c", s.Steps())
+	}
+
+	calls = nil
+	s = New()
+	do, undo := step("a", false)
+	s.Add("a", do, undo)
+	do, undo = step("b", false)
+	s.Add("b", do, undo)
+	do, _ = step("n", false)
+	s.Add("n", do, nil)
+	do, undo = step("d", true)
+	s.Add("d", do, undo)
+	do, undo = step("e", false)
+	s.Add("e", do, undo)
+
+	err := s.Run()
+	if err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	expected := "do a,do b,do n,do d,undo b,undo a"
+	if strings.Join(calls, ",") != expected {
+		t.Errorf("Expected %s got %s", expected, strings.Join(calls, ","))
+	}
+	var serr *Error
+	if !errors.As(err, &serr) {
+		t.Fatalf("Expected a *Error got %T", err)
+	}
+	if serr.Step != "d" || serr.Recovered() {
+		t.Errorf("Expected step d to fail without recovering got %s %t", serr.Step, serr.Recovered())
+	}
+	if len(serr.Compensated) != 1 || serr.Compensated[0] != "a" {
+		t.Errorf("Expected [a] got %v", serr.Compensated)
+	}
+	if len(serr.Failed) != 1 || serr.Failed[0].Step != "b" {
+		t.Errorf("Expected [b] got %v", serr.Failed)
+	}
+	if errors.Unwrap(err).Error() != "d failed" {
+		t.Errorf("Expected %s got %s", "d failed", errors.Unwrap(err))
+	}
+	expected = "d failed: d failed, rollback failed: b: undo b failed"
+	if err.Error() != expected {
+		t.Errorf("Expected %s got %s", expected, err)
+	}
+}
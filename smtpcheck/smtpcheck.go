@@ -0,0 +1,464 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package smtpcheck Reachability checks for delivery servers and smarthosts
+
+A Checker connects to each configured server, performs EHLO (LHLO for
+LMTP servers), STARTTLS when offered or required and AUTH when
+credentials are available, reporting the latency, certificate and the
+stage at which a check failed. AUTH is only attempted once the server
+certificate verifies, unless InsecureAuth is set, and targets that
+require TLS fail when the certificate does not verify.
+*/
+package smtpcheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the type of server being checked
+type Kind string
+
+const (
+	// DomainDeliveryServer a domain delivery server
+	DomainDeliveryServer Kind = "domain-delivery-server"
+	// UserDeliveryServer a user delivery server
+	UserDeliveryServer Kind = "user-delivery-server"
+	// FallBackServer an organization fallback server
+	FallBackServer Kind = "fallback-server"
+	// DomainSmartHost a domain smarthost
+	DomainSmartHost Kind = "domain-smarthost"
+	// OrgSmartHost an organization smarthost
+	OrgSmartHost Kind = "org-smarthost"
+)
+
+// OwnerKind is the type of the owner of a target
+type OwnerKind string
+
+const (
+	// OwnerDomain the target belongs to a domain
+	OwnerDomain OwnerKind = "domain"
+	// OwnerOrganization the target belongs to an organization
+	OwnerOrganization OwnerKind = "organization"
+)
+
+// OwnerKind returns the type of the owner of a server
+func (k Kind) OwnerKind() OwnerKind {
+	switch k {
+	case FallBackServer, OrgSmartHost:
+		return OwnerOrganization
+	}
+
+	return OwnerDomain
+}
+
+// Stage is the step of a check
+type Stage string
+
+const (
+	// StageConnect TCP connect and greeting
+	StageConnect Stage = "connect"
+	// StageHello EHLO or LHLO
+	StageHello Stage = "hello"
+	// StageTLS STARTTLS
+	StageTLS Stage = "starttls"
+	// StageAuth AUTH
+	StageAuth Stage = "auth"
+)
+
+const (
+	// ProtocolLMTP is the delivery server protocol value for LMTP
+	ProtocolLMTP        = 2
+	defaultPort         = 25
+	defaultTimeout      = 10 * time.Second
+	defaultWorkers      = 4
+	noTLSError          = "STARTTLS is required but not offered"
+	untrustedError      = "STARTTLS is required but the certificate is not trusted: %s"
+	plainAuthError      = "refusing to authenticate over an unencrypted connection"
+	unverifiedAuthError = "refusing to authenticate over an unverified TLS connection"
+	noAuthError         = "AUTH is not offered"
+	noMechError         = "no supported AUTH mechanism offered"
+)
+
+// Target holds a server to check
+type Target struct {
+	Kind       Kind   `json:"kind"`
+	Owner      string `json:"owner"`
+	OwnerID    int    `json:"owner_id"`
+	ServerID   int    `json:"server_id"`
+	Address    string `json:"address"`
+	Port       int    `json:"port"`
+	Protocol   int    `json:"protocol"`
+	RequireTLS bool   `json:"require_tls"`
+	Username   string `json:"username,omitempty"`
+}
+
+// Certificate holds the details of the server certificate
+type Certificate struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	DNSNames    []string  `json:"dns_names"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Verified    bool      `json:"verified"`
+	VerifyError string    `json:"verify_error,omitempty"`
+}
+
+func (c *Certificate) trusted() bool {
+	return c != nil && c.Verified
+}
+
+func (c *Certificate) reason() string {
+	if c == nil {
+		return "no certificate presented"
+	}
+
+	return c.VerifyError
+}
+
+// Result holds the outcome of checking a target
+type Result struct {
+	Target      Target        `json:"target"`
+	Latency     time.Duration `json:"latency"`
+	Banner      string        `json:"banner"`
+	Extensions  []string      `json:"extensions"`
+	TLS         bool          `json:"tls"`
+	Certificate *Certificate  `json:"certificate,omitempty"`
+	Auth        bool          `json:"auth"`
+	Stage       Stage         `json:"stage,omitempty"`
+	Err         error         `json:"-"`
+	Error       string        `json:"error,omitempty"`
+	Warnings    []string      `json:"warnings,omitempty"`
+}
+
+// OK returns true if the check succeeded
+func (r *Result) OK() bool {
+	return r.Err == nil
+}
+
+// Checker performs SMTP reachability checks
+type Checker struct {
+	// Timeout for each check, defaults to 10 seconds
+	Timeout time.Duration
+	// HeloName sent in EHLO, defaults to localhost
+	HeloName string
+	// Workers is the number of concurrent checks in CheckAll
+	Workers int
+	// Password returns the smarthost password for a target, the
+	// API does not return passwords. Auth is skipped when nil or
+	// an empty password is returned.
+	Password func(t *Target) string
+	// RootCAs used to verify certificates, nil uses the system roots
+	RootCAs *x509.CertPool
+	// InsecureAuth allows AUTH over a TLS connection whose certificate
+	// does not verify, the password is exposed to anyone able to
+	// intercept the connection
+	InsecureAuth bool
+}
+
+// CheckAll checks the targets concurrently, results are returned in
+// the same order as the targets
+func (c *Checker) CheckAll(targets []Target) (results []Result) {
+	var wg sync.WaitGroup
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+
+	results = make([]Result, len(targets))
+	jobs := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = c.Check(targets[i])
+			}
+		}()
+	}
+
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+
+	wg.Wait()
+
+	return
+}
+
+// Check checks a single target
+func (c *Checker) Check(t Target) (r Result) {
+	var err error
+	var conn net.Conn
+	var tp *textproto.Conn
+	var ext map[string]string
+
+	r.Target = t
+	start := time.Now()
+	defer func() {
+		r.Latency = time.Since(start)
+		if r.Err != nil {
+			r.Error = r.Err.Error()
+		}
+	}()
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	port := t.Port
+	if port == 0 {
+		port = defaultPort
+	}
+
+	r.Stage = StageConnect
+	if conn, err = net.DialTimeout("tcp", net.JoinHostPort(t.Address, strconv.Itoa(port)), timeout); err != nil {
+		r.Err = err
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	tp = textproto.NewConn(conn)
+	if _, r.Banner, err = tp.ReadResponse(220); err != nil {
+		r.Err = err
+		return
+	}
+
+	r.Stage = StageHello
+	if ext, err = c.hello(tp, t.Protocol); err != nil {
+		r.Err = err
+		return
+	}
+
+	r.Stage = StageTLS
+	if _, ok := ext["STARTTLS"]; ok {
+		var tlsConn *tls.Conn
+		if tlsConn, err = c.startTLS(tp, conn, t.Address); err != nil {
+			if t.RequireTLS {
+				r.Err = err
+				return
+			}
+			r.Warnings = append(r.Warnings, fmt.Sprintf("STARTTLS failed: %s", err))
+			r.Extensions = extensions(ext)
+			r.Stage = ""
+			tp.Close()
+			return
+		}
+		r.TLS = true
+		r.Certificate = c.certificate(tlsConn, t.Address)
+		if t.RequireTLS && !r.Certificate.trusted() {
+			r.Err = fmt.Errorf(untrustedError, r.Certificate.reason())
+			return
+		}
+		conn = tlsConn
+		tp = textproto.NewConn(conn)
+		if ext, err = c.hello(tp, t.Protocol); err != nil {
+			r.Stage = StageHello
+			r.Err = err
+			return
+		}
+	} else if t.RequireTLS {
+		r.Err = fmt.Errorf(noTLSError)
+		return
+	}
+
+	r.Extensions = extensions(ext)
+
+	if t.Username != "" && c.Password != nil {
+		if pw := c.Password(&t); pw != "" {
+			r.Stage = StageAuth
+			if !r.TLS {
+				r.Err = fmt.Errorf(plainAuthError)
+				return
+			}
+			if !r.Certificate.trusted() && !c.InsecureAuth {
+				r.Err = fmt.Errorf(unverifiedAuthError)
+				return
+			}
+			if err = auth(tp, ext, t.Username, pw); err != nil {
+				r.Err = err
+				return
+			}
+			r.Auth = true
+		}
+	}
+
+	r.Stage = ""
+	tp.Cmd("QUIT")
+
+	return
+}
+
+func (c *Checker) hello(tp *textproto.Conn, protocol int) (ext map[string]string, err error) {
+	var id uint
+	var msg string
+
+	cmd := "EHLO"
+	if protocol == ProtocolLMTP {
+		cmd = "LHLO"
+	}
+
+	name := c.HeloName
+	if name == "" {
+		name = "localhost"
+	}
+
+	if id, err = tp.Cmd("%s %s", cmd, name); err != nil {
+		return
+	}
+
+	tp.StartResponse(id)
+	defer tp.EndResponse(id)
+
+	if _, msg, err = tp.ReadResponse(250); err != nil {
+		return
+	}
+
+	ext = make(map[string]string)
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines[1:] {
+		args := strings.SplitN(line, " ", 2)
+		if len(args) > 1 {
+			ext[strings.ToUpper(args[0])] = args[1]
+		} else {
+			ext[strings.ToUpper(args[0])] = ""
+		}
+	}
+
+	return
+}
+
+func (c *Checker) startTLS(tp *textproto.Conn, conn net.Conn, host string) (tlsConn *tls.Conn, err error) {
+	var id uint
+
+	if id, err = tp.Cmd("STARTTLS"); err != nil {
+		return
+	}
+
+	tp.StartResponse(id)
+	_, _, err = tp.ReadResponse(220)
+	tp.EndResponse(id)
+	if err != nil {
+		return
+	}
+
+	// Verification is done separately so that the certificate
+	// details are reported even when they are not trusted
+	tlsConn = tls.Client(conn, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	})
+
+	err = tlsConn.Handshake()
+
+	return
+}
+
+func (c *Checker) certificate(conn *tls.Conn, host string) (cert *Certificate) {
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+
+	leaf := state.PeerCertificates[0]
+	cert = &Certificate{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		DNSNames:  leaf.DNSNames,
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, ic := range state.PeerCertificates[1:] {
+		intermediates.AddCert(ic)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         c.RootCAs,
+		Intermediates: intermediates,
+	}); err != nil {
+		cert.VerifyError = err.Error()
+	} else {
+		cert.Verified = true
+	}
+
+	return
+}
+
+func auth(tp *textproto.Conn, ext map[string]string, username, password string) (err error) {
+	var id uint
+
+	mechs, ok := ext["AUTH"]
+	if !ok {
+		err = fmt.Errorf(noAuthError)
+		return
+	}
+
+	mechs = " " + strings.ToUpper(mechs) + " "
+	enc := base64.StdEncoding.EncodeToString
+
+	switch {
+	case strings.Contains(mechs, " PLAIN "):
+		if id, err = tp.Cmd("AUTH PLAIN %s", enc([]byte("\x00"+username+"\x00"+password))); err != nil {
+			return
+		}
+		tp.StartResponse(id)
+		_, _, err = tp.ReadResponse(235)
+		tp.EndResponse(id)
+	case strings.Contains(mechs, " LOGIN "):
+		for _, step := range []struct {
+			cmd  string
+			code int
+		}{
+			{"AUTH LOGIN", 334},
+			{enc([]byte(username)), 334},
+			{enc([]byte(password)), 235},
+		} {
+			if id, err = tp.Cmd("%s", step.cmd); err != nil {
+				return
+			}
+			tp.StartResponse(id)
+			_, _, err = tp.ReadResponse(step.code)
+			tp.EndResponse(id)
+			if err != nil {
+				return
+			}
+		}
+	default:
+		err = fmt.Errorf(noMechError)
+	}
+
+	return
+}
+
+func extensions(ext map[string]string) (names []string) {
+	for k := range ext {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	return
+}
@@ -0,0 +1,447 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package smtpcheck
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type stubServer struct {
+	ln       net.Listener
+	tls      *tls.Config
+	roots    *x509.CertPool
+	lmtp     bool
+	username string
+	password string
+}
+
+func newStubServer(t *testing.T, startTLS, lmtp bool) (s *stubServer) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	s = &stubServer{
+		ln:       ln,
+		lmtp:     lmtp,
+		username: "relay",
+		password: "secret",
+	}
+
+	if startTLS {
+		cert := testCertificate(t)
+		s.tls = &tls.Config{Certificates: []tls.Certificate{cert}}
+		s.roots = x509.NewCertPool()
+		leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+		s.roots.AddCert(leaf)
+	}
+
+	go s.serve()
+
+	return
+}
+
+func (s *stubServer) target() Target {
+	host, port, _ := net.SplitHostPort(s.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return Target{
+		Kind:    DomainDeliveryServer,
+		Owner:   "example.com",
+		OwnerID: 1,
+		Address: host,
+		Port:    p,
+	}
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	var secure bool
+
+	defer func() {
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	reply := func(lines ...string) {
+		for _, l := range lines {
+			w.WriteString(l + "\r\n")
+		}
+		w.Flush()
+	}
+
+	reply("220 stub.example.com ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case (cmd == "EHLO" && !s.lmtp) || (cmd == "LHLO" && s.lmtp):
+			lines := []string{"250-stub.example.com", "250-PIPELINING"}
+			if s.tls != nil && !secure {
+				lines = append(lines, "250-STARTTLS")
+			}
+			if secure {
+				lines = append(lines, "250-AUTH PLAIN LOGIN")
+			}
+			lines = append(lines, "250 8BITMIME")
+			reply(lines...)
+		case cmd == "EHLO" || cmd == "LHLO":
+			reply("500 5.5.1 command unrecognized")
+		case cmd == "STARTTLS" && s.tls != nil:
+			reply("220 2.0.0 ready to start TLS")
+			tc := tls.Server(conn, s.tls)
+			if err = tc.Handshake(); err != nil {
+				return
+			}
+			conn = tc
+			r = bufio.NewReader(conn)
+			w = bufio.NewWriter(conn)
+			secure = true
+		case cmd == "AUTH":
+			want := base64.StdEncoding.EncodeToString([]byte("\x00" + s.username + "\x00" + s.password))
+			if line == "AUTH PLAIN "+want {
+				reply("235 2.7.0 authentication successful")
+			} else {
+				reply("535 5.7.8 authentication failed")
+			}
+		case cmd == "QUIT":
+			reply("221 2.0.0 bye")
+			return
+		default:
+			reply("502 5.5.2 command not implemented")
+		}
+	}
+}
+
+func testCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stub.example.com"},
+		DNSNames:              []string{"stub.example.com"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestCheckConnectError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	c := &Checker{Timeout: time.Second}
+	r := c.Check(Target{Address: "127.0.0.1", Port: addr.Port})
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageConnect {
+		t.Errorf("Expected %s got %s", StageConnect, r.Stage)
+	}
+}
+
+func TestCheckOK(t *testing.T) {
+	s := newStubServer(t, false, false)
+	defer s.ln.Close()
+
+	c := &Checker{Timeout: time.Second}
+	r := c.Check(s.target())
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+	if r.Banner != "stub.example.com ESMTP" {
+		t.Errorf("Expected %s got %s", "stub.example.com ESMTP", r.Banner)
+	}
+	if r.TLS {
+		t.Errorf("Expected TLS to be false")
+	}
+	if len(r.Extensions) != 2 {
+		t.Errorf("Expected %d got %d", 2, len(r.Extensions))
+	}
+	if r.Latency <= 0 {
+		t.Errorf("Expected latency to be recorded")
+	}
+}
+
+func TestCheckRequireTLSError(t *testing.T) {
+	s := newStubServer(t, false, false)
+	defer s.ln.Close()
+
+	target := s.target()
+	target.RequireTLS = true
+
+	c := &Checker{Timeout: time.Second}
+	r := c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageTLS {
+		t.Errorf("Expected %s got %s", StageTLS, r.Stage)
+	}
+	if r.Err.Error() != noTLSError {
+		t.Errorf("Expected '%s' got '%s'", noTLSError, r.Err)
+	}
+	if r.Error != noTLSError {
+		t.Errorf("Expected '%s' got '%s'", noTLSError, r.Error)
+	}
+}
+
+func TestCheckTLSAuthOK(t *testing.T) {
+	s := newStubServer(t, true, false)
+	defer s.ln.Close()
+
+	target := s.target()
+	target.Kind = DomainSmartHost
+	target.RequireTLS = true
+	target.Username = s.username
+
+	c := &Checker{
+		Timeout:  time.Second,
+		Password: func(t *Target) string { return s.password },
+		RootCAs:  s.roots,
+	}
+	r := c.Check(target)
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+	if !r.TLS {
+		t.Errorf("Expected TLS to be true")
+	}
+	if !r.Auth {
+		t.Errorf("Expected Auth to be true")
+	}
+	if r.Certificate == nil {
+		t.Fatalf("Expected certificate details")
+	}
+	if r.Certificate.Subject != "CN=stub.example.com" {
+		t.Errorf("Expected %s got %s", "CN=stub.example.com", r.Certificate.Subject)
+	}
+	if !r.Certificate.Verified {
+		t.Errorf("Expected the certificate to be verified got %s", r.Certificate.VerifyError)
+	}
+}
+
+func TestCheckUnverifiedTLS(t *testing.T) {
+	s := newStubServer(t, true, false)
+	defer s.ln.Close()
+
+	target := s.target()
+	target.Username = s.username
+
+	c := &Checker{
+		Timeout:  time.Second,
+		Password: func(t *Target) string { return s.password },
+	}
+	r := c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageAuth || r.Auth {
+		t.Errorf("Expected %s got %s", StageAuth, r.Stage)
+	}
+	if r.Err.Error() != unverifiedAuthError {
+		t.Errorf("Expected '%s' got '%s'", unverifiedAuthError, r.Err)
+	}
+	if r.Certificate == nil || r.Certificate.Verified || r.Certificate.VerifyError == "" {
+		t.Errorf("Expected self signed certificate not to be verified")
+	}
+
+	c.InsecureAuth = true
+	if r = c.Check(target); !r.OK() || !r.Auth {
+		t.Errorf("Expected nil got %s", r.Err)
+	}
+
+	target.RequireTLS = true
+	r = c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageTLS {
+		t.Errorf("Expected %s got %s", StageTLS, r.Stage)
+	}
+	if !strings.HasPrefix(r.Error, "STARTTLS is required but the certificate is not trusted") {
+		t.Errorf("Expected the certificate error got '%s'", r.Error)
+	}
+}
+
+func TestCheckAuthError(t *testing.T) {
+	s := newStubServer(t, true, false)
+	defer s.ln.Close()
+
+	target := s.target()
+	target.Username = s.username
+
+	c := &Checker{
+		Timeout:  time.Second,
+		Password: func(t *Target) string { return "wrong" },
+		RootCAs:  s.roots,
+	}
+	r := c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageAuth {
+		t.Errorf("Expected %s got %s", StageAuth, r.Stage)
+	}
+}
+
+func TestCheckPlainAuthError(t *testing.T) {
+	s := newStubServer(t, false, false)
+	defer s.ln.Close()
+
+	target := s.target()
+	target.Username = s.username
+
+	c := &Checker{
+		Timeout:  time.Second,
+		Password: func(t *Target) string { return s.password },
+	}
+	r := c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Err.Error() != plainAuthError {
+		t.Errorf("Expected '%s' got '%s'", plainAuthError, r.Err)
+	}
+}
+
+func TestCheckLMTP(t *testing.T) {
+	s := newStubServer(t, false, true)
+	defer s.ln.Close()
+
+	target := s.target()
+
+	c := &Checker{Timeout: time.Second}
+	r := c.Check(target)
+	if r.OK() {
+		t.Fatalf("An error should be returned")
+	}
+	if r.Stage != StageHello {
+		t.Errorf("Expected %s got %s", StageHello, r.Stage)
+	}
+
+	target.Protocol = ProtocolLMTP
+	r = c.Check(target)
+	if !r.OK() {
+		t.Fatalf("Expected nil got %s", r.Err)
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	s := newStubServer(t, true, false)
+	defer s.ln.Close()
+
+	var targets []Target
+	for i := 0; i < 5; i++ {
+		target := s.target()
+		target.ServerID = i + 1
+		targets = append(targets, target)
+	}
+
+	c := &Checker{Timeout: time.Second, Workers: 2}
+	results := c.CheckAll(targets)
+	if len(results) != len(targets) {
+		t.Fatalf("Expected %d got %d", len(targets), len(results))
+	}
+	for i, r := range results {
+		if r.Target.ServerID != i+1 {
+			t.Errorf("Expected %d got %d", i+1, r.Target.ServerID)
+		}
+		if !r.OK() {
+			t.Errorf("Expected nil got %s", r.Err)
+		}
+		if !r.TLS {
+			t.Errorf("Expected TLS to be true")
+		}
+	}
+}
+
+func TestGroupByOwner(t *testing.T) {
+	results := []Result{
+		{Target: Target{Kind: DomainDeliveryServer, Owner: "b.example.com", OwnerID: 2}},
+		{Target: Target{Kind: DomainDeliveryServer, Owner: "a.example.com", OwnerID: 1}, Err: fmt.Errorf("failed")},
+		{Target: Target{Kind: DomainSmartHost, Owner: "b.example.com", OwnerID: 2}, Err: fmt.Errorf("failed")},
+		{Target: Target{Kind: UserDeliveryServer, Owner: "a.example.com", OwnerID: 1}},
+		{Target: Target{Kind: FallBackServer, Owner: "a.example.com", OwnerID: 1}, Err: fmt.Errorf("refused")},
+		{Target: Target{Kind: OrgSmartHost, Owner: "a.example.com", OwnerID: 1}},
+	}
+
+	reports := GroupByOwner(results)
+	if len(reports) != 3 {
+		t.Fatalf("Expected %d got %d", 3, len(reports))
+	}
+	expected := []OwnerKind{OwnerDomain, OwnerOrganization, OwnerDomain}
+	for i, r := range reports {
+		if r.Kind != expected[i] {
+			t.Errorf("Expected %s got %s", expected[i], r.Kind)
+		}
+		if len(r.Results) != 2 {
+			t.Errorf("Expected %d got %d", 2, len(r.Results))
+		}
+		if r.Failed != 1 {
+			t.Errorf("Expected %d got %d", 1, r.Failed)
+		}
+	}
+	if reports[0].Owner != "a.example.com" || reports[2].Owner != "b.example.com" {
+		t.Errorf("Expected the reports to be sorted by owner got %v", reports)
+	}
+
+	b, err := json.Marshal(reports[1])
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if !strings.Contains(string(b), `"error":"refused"`) || !strings.Contains(string(b), `"kind":"organization"`) {
+		t.Errorf("Expected the failure reason to be encoded got %s", b)
+	}
+}
@@ -0,0 +1,210 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package smtpcheck
+
+import (
+	"sort"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+// Client is the subset of api.Client used to find targets
+type Client interface {
+	GetDomainDeliveryServers(domainID int, opts *api.ListOptions) (*api.DomainDeliveryServerList, error)
+	GetUserDeliveryServers(domainID int, opts *api.ListOptions) (*api.UserDeliveryServerList, error)
+	GetDomainSmartHosts(domainID int, opts *api.ListOptions) (*api.DomainSmartHostList, error)
+	GetFallBackServers(organizationID int, opts *api.ListOptions) (*api.FallBackServerList, error)
+	GetOrgSmartHosts(organizationID int, opts *api.ListOptions) (*api.OrgSmartHostList, error)
+}
+
+var _ Client = (*api.Client)(nil)
+
+// Report holds the results for a single domain or organization
+type Report struct {
+	Kind    OwnerKind `json:"kind"`
+	OwnerID int       `json:"owner_id"`
+	Owner   string    `json:"owner"`
+	Results []Result  `json:"results"`
+	Failed  int       `json:"failed"`
+}
+
+// DomainTargets returns the enabled delivery servers and smarthosts
+// of a domain
+func DomainTargets(c Client, domain *api.Domain) (targets []Target, err error) {
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainDeliveryServerList
+		if l, err = c.GetDomainDeliveryServers(domain.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if s.Enabled {
+				targets = append(targets, Target{
+					Kind:       DomainDeliveryServer,
+					Owner:      domain.Name,
+					OwnerID:    domain.ID,
+					ServerID:   s.ID,
+					Address:    s.Address,
+					Port:       s.Port,
+					Protocol:   s.Protocol,
+					RequireTLS: s.RequireTLS,
+				})
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.UserDeliveryServerList
+		if l, err = c.GetUserDeliveryServers(domain.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if s.Enabled {
+				targets = append(targets, Target{
+					Kind:       UserDeliveryServer,
+					Owner:      domain.Name,
+					OwnerID:    domain.ID,
+					ServerID:   s.ID,
+					Address:    s.Address,
+					Port:       s.Port,
+					Protocol:   s.Protocol,
+					RequireTLS: s.RequireTLS,
+				})
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainSmartHostList
+		if l, err = c.GetDomainSmartHosts(domain.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if s.Enabled {
+				targets = append(targets, Target{
+					Kind:       DomainSmartHost,
+					Owner:      domain.Name,
+					OwnerID:    domain.ID,
+					ServerID:   s.ID,
+					Address:    s.Address,
+					Port:       s.Port,
+					RequireTLS: s.RequireTLS,
+					Username:   s.Username,
+				})
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	return
+}
+
+// OrganizationTargets returns the enabled fallback servers and
+// smarthosts of an organization
+func OrganizationTargets(c Client, org *api.Organization) (targets []Target, err error) {
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.FallBackServerList
+		if l, err = c.GetFallBackServers(org.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if s.Enabled {
+				targets = append(targets, Target{
+					Kind:       FallBackServer,
+					Owner:      org.Name,
+					OwnerID:    org.ID,
+					ServerID:   s.ID,
+					Address:    s.Address,
+					Port:       s.Port,
+					Protocol:   s.Protocol,
+					RequireTLS: s.RequireTLS,
+				})
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrgSmartHostList
+		if l, err = c.GetOrgSmartHosts(org.ID, opts); err != nil {
+			return
+		}
+		for _, s := range l.Items {
+			if s.Enabled {
+				targets = append(targets, Target{
+					Kind:       OrgSmartHost,
+					Owner:      org.Name,
+					OwnerID:    org.ID,
+					ServerID:   s.ID,
+					Address:    s.Address,
+					Port:       s.Port,
+					RequireTLS: s.RequireTLS,
+					Username:   s.Username,
+				})
+			}
+		}
+		links, done = l.Links, len(l.Items) == 0
+		return
+	}); err != nil {
+		return
+	}
+
+	return
+}
+
+// GroupByOwner groups results by the domain or organization they
+// belong to, sorted by owner
+func GroupByOwner(results []Result) (reports []Report) {
+	type owner struct {
+		kind OwnerKind
+		id   int
+	}
+	idx := make(map[owner]int)
+
+	for _, r := range results {
+		k := owner{r.Target.Kind.OwnerKind(), r.Target.OwnerID}
+		i, ok := idx[k]
+		if !ok {
+			i = len(reports)
+			idx[k] = i
+			reports = append(reports, Report{Kind: k.kind, OwnerID: k.id, Owner: r.Target.Owner})
+		}
+		if r.Err != nil && r.Error == "" {
+			r.Error = r.Err.Error()
+		}
+		reports[i].Results = append(reports[i].Results, r)
+		if !r.OK() {
+			reports[i].Failed++
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Owner != reports[j].Owner {
+			return reports[i].Owner < reports[j].Owner
+		}
+		if reports[i].Kind != reports[j].Kind {
+			return reports[i].Kind < reports[j].Kind
+		}
+		return reports[i].OwnerID < reports[j].OwnerID
+	})
+
+	return
+}
@@ -0,0 +1,116 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package smtpcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+func getTestTargetsClient(t *testing.T, mux *http.ServeMux) (*httptest.Server, *api.Client) {
+	server := httptest.NewServer(mux)
+	c, err := api.New(server.URL, "test-token", nil)
+	if err != nil {
+		server.Close()
+		t.Fatalf("Expected nil got %s", err)
+	}
+
+	return server, c
+}
+
+func emptyList(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, `{"items":[],"links":{},"meta":{"total":0}}`)
+}
+
+func TestDomainTargetsError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/deliveryservers/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"code":500,"message":"Internal Server Error"}`)
+	})
+	server, c := getTestTargetsClient(t, mux)
+	defer server.Close()
+
+	if _, err := DomainTargets(c, &api.Domain{ID: 1, Name: "example.com"}); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+}
+
+func TestDomainTargetsOK(t *testing.T) {
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/deliveryservers/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"items":[{"id":2,"address":"192.168.1.151","protocol":2,"port":24,"require_tls":false,"enabled":true}],"links":{},"meta":{"total":2}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items":[{"id":1,"address":"192.168.1.150","protocol":1,"port":25,"require_tls":true,"enabled":true},{"id":3,"address":"192.168.1.152","protocol":1,"port":25,"enabled":false}],"links":{"pages":{"next":"%s/api/v1/deliveryservers/1?page=2"}},"meta":{"total":2}}`, server.URL)
+	})
+	mux.HandleFunc("/api/v1/userdeliveryservers/1", emptyList)
+	mux.HandleFunc("/api/v1/domains/smarthosts/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"id":4,"address":"smtp.example.net","username":"relay","port":587,"require_tls":true,"enabled":true}],"links":{},"meta":{"total":1}}`)
+	})
+	server, c := getTestTargetsClient(t, mux)
+	defer server.Close()
+
+	targets, err := DomainTargets(c, &api.Domain{ID: 1, Name: "example.com"})
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("Expected %d got %d", 3, len(targets))
+	}
+	if targets[0].ServerID != 1 || !targets[0].RequireTLS {
+		t.Errorf("Expected server 1 requiring TLS got %d", targets[0].ServerID)
+	}
+	if targets[1].Protocol != ProtocolLMTP {
+		t.Errorf("Expected %d got %d", ProtocolLMTP, targets[1].Protocol)
+	}
+	if targets[2].Kind != DomainSmartHost {
+		t.Errorf("Expected %s got %s", DomainSmartHost, targets[2].Kind)
+	}
+	if targets[2].Username != "relay" {
+		t.Errorf("Expected %s got %s", "relay", targets[2].Username)
+	}
+	for _, target := range targets {
+		if target.Owner != "example.com" || target.OwnerID != 1 {
+			t.Errorf("Expected owner example.com got %s", target.Owner)
+		}
+	}
+}
+
+func TestOrganizationTargetsOK(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/fallbackservers/list/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"id":1,"address":"192.168.1.160","protocol":1,"port":25,"require_tls":false,"enabled":true}],"links":{},"meta":{"total":1}}`)
+	})
+	mux.HandleFunc("/api/v1/organizations/smarthosts/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[{"id":2,"address":"smtp.example.org","username":"org","port":25,"require_tls":true,"enabled":true}],"links":{},"meta":{"total":1}}`)
+	})
+	server, c := getTestTargetsClient(t, mux)
+	defer server.Close()
+
+	targets, err := OrganizationTargets(c, &api.Organization{ID: 1, Name: "Example"})
+	if err != nil {
+		t.Fatalf("Expected nil got %s", err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("Expected %d got %d", 2, len(targets))
+	}
+	if targets[0].Kind != FallBackServer {
+		t.Errorf("Expected %s got %s", FallBackServer, targets[0].Kind)
+	}
+	if targets[1].Kind != OrgSmartHost {
+		t.Errorf("Expected %s got %s", OrgSmartHost, targets[1].Kind)
+	}
+}
@@ -0,0 +1,190 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*aliasAddressResource)(nil)
+	_ resource.ResourceWithImportState = (*aliasAddressResource)(nil)
+)
+
+type aliasAddressResource struct {
+	baseResource
+}
+
+type aliasAddressModel struct {
+	ID      types.Int64  `tfsdk:"id"`
+	UserID  types.Int64  `tfsdk:"user_id"`
+	Address types.String `tfsdk:"address"`
+	Enabled types.Bool   `tfsdk:"enabled"`
+}
+
+func newAliasAddressResource() resource.Resource {
+	return &aliasAddressResource{}
+}
+
+func (r *aliasAddressResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_alias_address"
+}
+
+func (r *aliasAddressResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "An additional address that delivers to a user. Import using <user>/<alias> where the user is an ID or a username and the alias an ID or an address.",
+		Attributes: map[string]schema.Attribute{
+			"id":      idAttribute(),
+			"user_id": parentAttribute("The ID of the user."),
+			"address": schema.StringAttribute{
+				Description: "The email address.",
+				Required:    true,
+			},
+			"enabled": defaultBool("Whether the address is enabled.", true),
+		},
+	}
+}
+
+func (r *aliasAddressResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan aliasAddressModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alias := plan.toAPI()
+	if err := r.client.CreateAliasAddress(intValue(plan.UserID), alias); err != nil {
+		addError(&resp.Diagnostics, "create", "alias address", err)
+		return
+	}
+
+	plan.fromAPI(alias)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *aliasAddressResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state aliasAddressModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alias, err := r.client.GetAliasAddress(intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "alias address", err)
+		return
+	}
+
+	state.fromAPI(alias)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *aliasAddressResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan aliasAddressModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateAliasAddress(plan.toAPI()); err != nil {
+		addError(&resp.Diagnostics, "update", "alias address", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *aliasAddressResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state aliasAddressModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteAliasAddress(state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "alias address", err)
+	}
+}
+
+func (r *aliasAddressResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	userID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "alias address", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user_id"), int64(userID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *aliasAddressResource) lookup(s string) (userID, id int, err error) {
+	var ok bool
+	var user, address string
+
+	if user, address, err = splitImportID(s, "<user>/<alias>"); err != nil {
+		return
+	}
+
+	if userID, err = lookupUser(r.client, user); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.AliasAddressList
+		if l, err = r.client.GetUserAliasAddresses(userID, opts); err != nil {
+			return
+		}
+		for _, a := range l.Items {
+			if a.Address == address {
+				id = a.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "alias address", address)
+
+	return
+}
+
+func (m *aliasAddressModel) toAPI() *api.AliasAddress {
+	return &api.AliasAddress{
+		ID:      intValue(m.ID),
+		Address: m.Address.ValueString(),
+		Enabled: m.Enabled.ValueBool(),
+	}
+}
+
+func (m *aliasAddressModel) fromAPI(a *api.AliasAddress) {
+	m.ID = int64Value(a.ID)
+	m.Address = types.StringValue(a.Address)
+	m.Enabled = types.BoolValue(a.Enabled)
+}
@@ -0,0 +1,105 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAliasAddressResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	userID := s.user(t, "jdoe")
+	h := newHarness(t, s, newAliasAddressResource())
+	state := h.create(&aliasAddressModel{
+		ID:      types.Int64Unknown(),
+		UserID:  int64Value(userID),
+		Address: types.StringValue("john@example.com"),
+		Enabled: types.BoolValue(true),
+	})
+
+	var m aliasAddressModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+
+	plan := m
+	plan.Enabled = types.BoolValue(false)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the alias address to exist")
+	}
+	h.get(state, &m)
+	if m.Enabled.ValueBool() {
+		t.Errorf("Expected the alias address to be disabled")
+	}
+
+	for _, id := range []string{fmt.Sprintf("%d/%d", userID, m.ID.ValueInt64()), "jdoe/john@example.com"} {
+		var i aliasAddressModel
+		h.get(h.importState(id), &i)
+		if i != m {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	if e := h.importError("jdoe/jd@example.com"); e != fmt.Sprintf(notFoundError, "alias address", "jd@example.com") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(notFoundError, "alias address", "jd@example.com"), e)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the alias address to be removed from the state")
+	}
+}
+
+func TestAccAliasAddressResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := `
+resource "baruwa_user" "test" {
+  username = "jdoe"
+  email    = "jdoe@example.com"
+}
+
+resource "baruwa_alias_address" "test" {
+  user_id = baruwa_user.test.id
+  address = "john@example.com"
+  enabled = %t
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "aliasaddresses"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, true)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_alias_address.test", "address", "john@example.com"),
+					resource.TestCheckResourceAttrPair("baruwa_alias_address.test", "user_id", "baruwa_user.test", "id"),
+				),
+			},
+			{
+				ResourceName:      "baruwa_alias_address.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccImportID("baruwa_alias_address.test", "jdoe/%s", "address"),
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, false)),
+				Check:  resource.TestCheckResourceAttr("baruwa_alias_address.test", "enabled", "false"),
+			},
+		},
+	})
+}
@@ -0,0 +1,463 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const settingsProtocolError = "The %s settings are only used by servers with protocol %d, got %d"
+
+var (
+	_ resource.ResourceWithConfigure      = (*authServerResource)(nil)
+	_ resource.ResourceWithImportState    = (*authServerResource)(nil)
+	_ resource.ResourceWithValidateConfig = (*authServerResource)(nil)
+)
+
+type authServerResource struct {
+	baseResource
+}
+
+type authServerModel struct {
+	ID              types.Int64  `tfsdk:"id"`
+	DomainID        types.Int64  `tfsdk:"domain_id"`
+	Address         types.String `tfsdk:"address"`
+	Port            types.Int64  `tfsdk:"port"`
+	Protocol        types.Int64  `tfsdk:"protocol"`
+	Enabled         types.Bool   `tfsdk:"enabled"`
+	SplitAddress    types.Bool   `tfsdk:"split_address"`
+	UserMapTemplate types.String `tfsdk:"user_map_template"`
+	LDAP            *ldapModel   `tfsdk:"ldap"`
+	Radius          *radiusModel `tfsdk:"radius"`
+}
+
+type ldapModel struct {
+	ID                types.Int64  `tfsdk:"id"`
+	BaseDN            types.String `tfsdk:"basedn"`
+	NameAttribute     types.String `tfsdk:"name_attribute"`
+	EmailAttribute    types.String `tfsdk:"email_attribute"`
+	BindDN            types.String `tfsdk:"bind_dn"`
+	BindPassword      types.String `tfsdk:"bind_password"`
+	UseTLS            types.Bool   `tfsdk:"use_tls"`
+	UseSearch         types.Bool   `tfsdk:"use_search"`
+	SearchFilter      types.String `tfsdk:"search_filter"`
+	SearchScope       types.String `tfsdk:"search_scope"`
+	EmailSearchFilter types.String `tfsdk:"email_search_filter"`
+	EmailSearchScope  types.String `tfsdk:"email_search_scope"`
+}
+
+type radiusModel struct {
+	ID      types.Int64  `tfsdk:"id"`
+	Secret  types.String `tfsdk:"secret"`
+	Timeout types.Int64  `tfsdk:"timeout"`
+}
+
+func newAuthServerResource() resource.Resource {
+	return &authServerResource{}
+}
+
+func (r *authServerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_auth_server"
+}
+
+func (r *authServerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A server used to authenticate the users of a domain. Import using <domain>/<server>[/<settings ID>] where the domain is an ID or a name, the server an ID or an address and the settings ID that of the LDAP or RADIUS settings.",
+		Attributes: map[string]schema.Attribute{
+			"id":        idAttribute(),
+			"domain_id": parentAttribute("The ID of the domain."),
+			"address": schema.StringAttribute{
+				Description: "The hostname or IP address of the server.",
+				Required:    true,
+			},
+			"port": schema.Int64Attribute{
+				Description: "The port of the server.",
+				Required:    true,
+			},
+			"protocol": schema.Int64Attribute{
+				Description: "The protocol, 1 is POP3, 2 IMAP, 3 SMTP, 4 RADIUS and 5 LDAP.",
+				Required:    true,
+			},
+			"enabled":           defaultBool("Whether the server is enabled.", true),
+			"split_address":     defaultBool("Whether the domain is stripped from the username.", false),
+			"user_map_template": defaultString("The template used to map usernames.", ""),
+			"ldap": schema.SingleNestedAttribute{
+				Description: "The settings of an LDAP server.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"id": idAttribute(),
+					"basedn": schema.StringAttribute{
+						Description: "The base DN to search from.",
+						Required:    true,
+					},
+					"name_attribute":      defaultString("The attribute holding the username.", "uid"),
+					"email_attribute":     defaultString("The attribute holding the email address.", "mail"),
+					"bind_dn":             defaultString("The DN to bind as.", ""),
+					"bind_password":       secretString("The password of the bind DN."),
+					"use_tls":             defaultBool("Whether TLS is used.", false),
+					"use_search":          defaultBool("Whether the user is searched for before binding.", false),
+					"search_filter":       defaultString("The filter used to find users.", ""),
+					"search_scope":        defaultString("The scope used to find users.", "subtree"),
+					"email_search_filter": defaultString("The filter used to find email addresses.", ""),
+					"email_search_scope":  defaultString("The scope used to find email addresses.", "subtree"),
+				},
+			},
+			"radius": schema.SingleNestedAttribute{
+				Description: "The settings of a RADIUS server.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"id": idAttribute(),
+					"secret": schema.StringAttribute{
+						Description: "The shared secret.",
+						Required:    true,
+						Sensitive:   true,
+					},
+					"timeout": defaultInt64("The timeout in seconds.", 30),
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that the settings match the protocol
+func (r *authServerResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config authServerModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Protocol.IsNull() || config.Protocol.IsUnknown() {
+		return
+	}
+
+	protocol := config.Protocol.ValueInt64()
+	if config.LDAP != nil && protocol != api.AuthProtocolLDAP {
+		resp.Diagnostics.AddAttributeError(path.Root("ldap"), "Invalid LDAP settings", fmt.Sprintf(settingsProtocolError, "LDAP", api.AuthProtocolLDAP, protocol))
+	}
+	if config.Radius != nil && protocol != api.AuthProtocolRADIUS {
+		resp.Diagnostics.AddAttributeError(path.Root("radius"), "Invalid RADIUS settings", fmt.Sprintf(settingsProtocolError, "RADIUS", api.AuthProtocolRADIUS, protocol))
+	}
+}
+
+func (r *authServerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan authServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.CreateAuthServer(intValue(plan.DomainID), server); err != nil {
+		addError(&resp.Diagnostics, "create", "auth server", err)
+		return
+	}
+
+	plan.fromAPI(server)
+
+	// The server exists even when its settings fail, so the state
+	// is saved to have it tainted rather than lost
+	r.saveSettings(&plan, nil, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *authServerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state authServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domainID, serverID := intValue(state.DomainID), intValue(state.ID)
+	server, err := r.client.GetAuthServer(domainID, serverID)
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "auth server", err)
+		return
+	}
+
+	state.fromAPI(server)
+
+	if state.LDAP != nil {
+		settings, err := r.client.GetLDAPSettings(domainID, serverID, intValue(state.LDAP.ID))
+		switch {
+		case api.IsNotFound(err):
+			state.LDAP = nil
+		case err != nil:
+			addError(&resp.Diagnostics, "read", "LDAP settings", err)
+			return
+		default:
+			state.LDAP.fromAPI(settings)
+		}
+	}
+
+	if state.Radius != nil {
+		settings, err := r.client.GetRadiusSettings(domainID, serverID, intValue(state.Radius.ID))
+		switch {
+		case api.IsNotFound(err):
+			state.Radius = nil
+		case err != nil:
+			addError(&resp.Diagnostics, "read", "RADIUS settings", err)
+			return
+		default:
+			state.Radius.fromAPI(settings)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *authServerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, prior authServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateAuthServer(intValue(plan.DomainID), plan.toAPI()); err != nil {
+		addError(&resp.Diagnostics, "update", "auth server", err)
+		return
+	}
+
+	r.saveSettings(&plan, &prior, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *authServerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state authServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.saveSettings(&authServerModel{ID: state.ID, DomainID: state.DomainID}, &state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteAuthServer(intValue(state.DomainID), state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "auth server", err)
+	}
+}
+
+// ImportState imports a server, the settings are found using the
+// protocol of the server
+func (r *authServerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var settingsID int
+
+	format := "<domain>/<server>[/<settings ID>]"
+	id := req.ID
+	if p := strings.Split(req.ID, "/"); len(p) == 3 {
+		var ok bool
+		if settingsID, ok = parseID(p[2]); !ok {
+			addError(&resp.Diagnostics, "import", "auth server", fmt.Errorf(importIDError, format, req.ID))
+			return
+		}
+		id = p[0] + "/" + p[1]
+	}
+
+	domainID, serverID, err := r.lookup(id, format)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "auth server", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain_id"), int64(domainID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(serverID))...)
+	if settingsID == 0 {
+		return
+	}
+
+	server, err := r.client.GetAuthServer(domainID, serverID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "auth server", err)
+		return
+	}
+
+	switch server.Protocol {
+	case api.AuthProtocolLDAP:
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ldap").AtName("id"), int64(settingsID))...)
+	case api.AuthProtocolRADIUS:
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("radius").AtName("id"), int64(settingsID))...)
+	default:
+		addError(&resp.Diagnostics, "import", "auth server", fmt.Errorf("Protocol %d servers do not have settings", server.Protocol))
+	}
+}
+
+func (r *authServerResource) lookup(s, format string) (domainID, id int, err error) {
+	var ok bool
+	var domain, address string
+
+	if domain, address, err = splitImportID(s, format); err != nil {
+		return
+	}
+
+	if domainID, err = lookupDomain(r.client, domain); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.AuthServerList
+		if l, err = r.client.GetAuthServers(domainID, opts); err != nil {
+			return
+		}
+		for _, as := range l.Items {
+			if as.Address == address {
+				id = as.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "auth server", address)
+
+	return
+}
+
+// saveSettings creates, updates or deletes the LDAP and RADIUS settings
+// of plan, prior is nil when the server was just created
+func (r *authServerResource) saveSettings(plan, prior *authServerModel, diags *diag.Diagnostics) {
+	var err error
+
+	domainID, serverID := intValue(plan.DomainID), intValue(plan.ID)
+	if prior == nil {
+		prior = &authServerModel{}
+	}
+
+	switch {
+	case plan.LDAP != nil && prior.LDAP == nil:
+		settings := plan.LDAP.toAPI()
+		if err = r.client.CreateLDAPSettings(domainID, serverID, settings); err != nil {
+			addError(diags, "create", "LDAP settings", err)
+			return
+		}
+		plan.LDAP.ID = int64Value(settings.ID)
+	case plan.LDAP != nil:
+		plan.LDAP.ID = prior.LDAP.ID
+		if err = r.client.UpdateLDAPSettings(domainID, serverID, plan.LDAP.toAPI()); err != nil {
+			addError(diags, "update", "LDAP settings", err)
+			return
+		}
+	case prior.LDAP != nil:
+		if err = r.client.DeleteLDAPSettings(domainID, serverID, prior.LDAP.toAPI()); err != nil && !api.IsNotFound(err) {
+			addError(diags, "delete", "LDAP settings", err)
+			return
+		}
+	}
+
+	switch {
+	case plan.Radius != nil && prior.Radius == nil:
+		settings := plan.Radius.toAPI()
+		if err = r.client.CreateRadiusSettings(domainID, serverID, settings); err != nil {
+			addError(diags, "create", "RADIUS settings", err)
+			return
+		}
+		plan.Radius.ID = int64Value(settings.ID)
+	case plan.Radius != nil:
+		plan.Radius.ID = prior.Radius.ID
+		if err = r.client.UpdateRadiusSettings(domainID, serverID, plan.Radius.toAPI()); err != nil {
+			addError(diags, "update", "RADIUS settings", err)
+		}
+	case prior.Radius != nil:
+		if err = r.client.DeleteRadiusSettings(domainID, serverID, prior.Radius.toAPI()); err != nil && !api.IsNotFound(err) {
+			addError(diags, "delete", "RADIUS settings", err)
+		}
+	}
+}
+
+func (m *authServerModel) toAPI() *api.AuthServer {
+	return &api.AuthServer{
+		ID:              intValue(m.ID),
+		Address:         m.Address.ValueString(),
+		Port:            intValue(m.Port),
+		Protocol:        intValue(m.Protocol),
+		Enabled:         m.Enabled.ValueBool(),
+		SplitAddress:    m.SplitAddress.ValueBool(),
+		UserMapTemplate: m.UserMapTemplate.ValueString(),
+	}
+}
+
+func (m *authServerModel) fromAPI(s *api.AuthServer) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Port = int64Value(s.Port)
+	m.Protocol = int64Value(s.Protocol)
+	m.Enabled = types.BoolValue(s.Enabled)
+	m.SplitAddress = types.BoolValue(s.SplitAddress)
+	m.UserMapTemplate = types.StringValue(s.UserMapTemplate)
+}
+
+func (m *ldapModel) toAPI() *api.LDAPSettings {
+	return &api.LDAPSettings{
+		ID:                intValue(m.ID),
+		Basedn:            m.BaseDN.ValueString(),
+		NameAttribute:     m.NameAttribute.ValueString(),
+		EmailAttribute:    m.EmailAttribute.ValueString(),
+		BindDN:            m.BindDN.ValueString(),
+		BindPw:            m.BindPassword.ValueString(),
+		UseTLS:            m.UseTLS.ValueBool(),
+		UseSearch:         m.UseSearch.ValueBool(),
+		SearchFilter:      m.SearchFilter.ValueString(),
+		SearchScope:       m.SearchScope.ValueString(),
+		EmailSearchFilter: m.EmailSearchFilter.ValueString(),
+		EmailSearchScope:  m.EmailSearchScope.ValueString(),
+	}
+}
+
+// fromAPI sets the model from LDAP settings, the bind password is kept
+// as the server does not return it
+func (m *ldapModel) fromAPI(s *api.LDAPSettings) {
+	m.ID = int64Value(s.ID)
+	m.BaseDN = types.StringValue(s.Basedn)
+	m.NameAttribute = types.StringValue(s.NameAttribute)
+	m.EmailAttribute = types.StringValue(s.EmailAttribute)
+	m.BindDN = types.StringValue(s.BindDN)
+	m.UseTLS = types.BoolValue(s.UseTLS)
+	m.UseSearch = types.BoolValue(s.UseSearch)
+	m.SearchFilter = types.StringValue(s.SearchFilter)
+	m.SearchScope = types.StringValue(s.SearchScope)
+	m.EmailSearchFilter = types.StringValue(s.EmailSearchFilter)
+	m.EmailSearchScope = types.StringValue(s.EmailSearchScope)
+}
+
+func (m *radiusModel) toAPI() *api.RadiusSettings {
+	return &api.RadiusSettings{
+		ID:      intValue(m.ID),
+		Secret:  m.Secret.ValueString(),
+		Timeout: intValue(m.Timeout),
+	}
+}
+
+// fromAPI sets the model from RADIUS settings, the secret is kept as
+// the server does not return it
+func (m *radiusModel) fromAPI(s *api.RadiusSettings) {
+	m.ID = int64Value(s.ID)
+	m.Timeout = int64Value(s.Timeout)
+}
@@ -0,0 +1,220 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	tfresource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func testLDAPModel() *ldapModel {
+	return &ldapModel{
+		ID:                types.Int64Unknown(),
+		BaseDN:            types.StringValue("ou=users,dc=example,dc=com"),
+		NameAttribute:     types.StringValue("uid"),
+		EmailAttribute:    types.StringValue("mail"),
+		BindDN:            types.StringValue("cn=admin,dc=example,dc=com"),
+		BindPassword:      types.StringValue("secret"),
+		UseTLS:            types.BoolValue(true),
+		UseSearch:         types.BoolValue(false),
+		SearchFilter:      types.StringValue(""),
+		SearchScope:       types.StringValue("subtree"),
+		EmailSearchFilter: types.StringValue(""),
+		EmailSearchScope:  types.StringValue("subtree"),
+	}
+}
+
+func TestAuthServerResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	domainID := s.domain(t, "example.com")
+	h := newHarness(t, s, newAuthServerResource())
+	state := h.create(&authServerModel{
+		ID:              types.Int64Unknown(),
+		DomainID:        int64Value(domainID),
+		Address:         types.StringValue("ldap.example.com"),
+		Port:            types.Int64Value(389),
+		Protocol:        types.Int64Value(api.AuthProtocolLDAP),
+		Enabled:         types.BoolValue(true),
+		SplitAddress:    types.BoolValue(false),
+		UserMapTemplate: types.StringValue(""),
+		LDAP:            testLDAPModel(),
+	})
+
+	var m authServerModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+	if m.LDAP == nil || m.LDAP.ID.IsUnknown() || m.LDAP.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the LDAP settings to be created")
+	}
+	if n := s.count("ldapsettings"); n != 1 {
+		t.Errorf("Expected %d got %d", 1, n)
+	}
+
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the auth server to exist")
+	}
+	h.get(state, &m)
+	if m.LDAP.BaseDN.ValueString() != "ou=users,dc=example,dc=com" {
+		t.Errorf("Expected %s got %s", "ou=users,dc=example,dc=com", m.LDAP.BaseDN)
+	}
+	if m.LDAP.BindPassword.ValueString() != "secret" {
+		t.Errorf("Expected the bind password to be kept got %s", m.LDAP.BindPassword)
+	}
+
+	ldapID := m.LDAP.ID.ValueInt64()
+	for _, id := range []string{
+		fmt.Sprintf("%d/%d/%d", domainID, m.ID.ValueInt64(), ldapID),
+		fmt.Sprintf("example.com/ldap.example.com/%d", ldapID),
+	} {
+		var i authServerModel
+		h.get(h.importState(id), &i)
+		if i.LDAP == nil || i.LDAP.ID != m.LDAP.ID || i.LDAP.BaseDN != m.LDAP.BaseDN || i.Address != m.Address {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	var i authServerModel
+	h.get(h.importState("example.com/ldap.example.com"), &i)
+	if i.LDAP != nil {
+		t.Errorf("Expected the LDAP settings to not be imported")
+	}
+	if e := h.importError("example.com/ldap.example.com/settings"); e != fmt.Sprintf(importIDError, "<domain>/<server>[/<settings ID>]", "example.com/ldap.example.com/settings") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(importIDError, "<domain>/<server>[/<settings ID>]", "example.com/ldap.example.com/settings"), e)
+	}
+
+	plan := m
+	plan.Address = types.StringValue("radius.example.com")
+	plan.Port = types.Int64Value(1812)
+	plan.Protocol = types.Int64Value(api.AuthProtocolRADIUS)
+	plan.LDAP = nil
+	plan.Radius = &radiusModel{ID: types.Int64Unknown(), Secret: types.StringValue("shared"), Timeout: types.Int64Value(30)}
+	state = h.update(state, &plan)
+	h.get(state, &m)
+	if m.Radius == nil || m.Radius.ID.IsUnknown() || m.Radius.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the RADIUS settings to be created")
+	}
+	if n := s.count("ldapsettings"); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+
+	plan = m
+	plan.Radius = &radiusModel{ID: m.Radius.ID, Secret: m.Radius.Secret, Timeout: types.Int64Value(10)}
+	state = h.update(state, &plan)
+	state, ok = h.read(state)
+	if !ok {
+		t.Fatalf("Expected the auth server to exist")
+	}
+	h.get(state, &m)
+	if m.Radius.Timeout.ValueInt64() != 10 {
+		t.Errorf("Expected %d got %d", 10, m.Radius.Timeout.ValueInt64())
+	}
+	if m.Radius.Secret.ValueString() != "shared" {
+		t.Errorf("Expected the secret to be kept got %s", m.Radius.Secret)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the auth server to be removed from the state")
+	}
+	if n := s.count("radiussettings"); n != 0 {
+		t.Errorf("Expected %d got %d", 0, n)
+	}
+}
+
+func TestAuthServerResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := newAuthServerResource().(*authServerResource)
+
+	sresp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, sresp)
+
+	for _, tc := range []struct {
+		protocol int64
+		model    *authServerModel
+		errors   int
+	}{
+		{api.AuthProtocolLDAP, &authServerModel{LDAP: testLDAPModel()}, 0},
+		{api.AuthProtocolRADIUS, &authServerModel{LDAP: testLDAPModel()}, 1},
+		{api.AuthProtocolRADIUS, &authServerModel{Radius: &radiusModel{Secret: types.StringValue("shared")}}, 0},
+		{1, &authServerModel{Radius: &radiusModel{Secret: types.StringValue("shared")}}, 1},
+	} {
+		m := tc.model
+		m.ID, m.DomainID, m.Address, m.Port = types.Int64Null(), types.Int64Value(1), types.StringValue("auth.example.com"), types.Int64Value(389)
+		m.Protocol = types.Int64Value(tc.protocol)
+		// A config has no setter so the value is built as a state
+		st := tfsdk.State{Schema: sresp.Schema, Raw: tftypes.NewValue(sresp.Schema.Type().TerraformType(ctx), nil)}
+		if diags := st.Set(ctx, m); diags.HasError() {
+			t.Fatalf("An error should not be returned: %v", diags)
+		}
+		config := tfsdk.Config{Schema: sresp.Schema, Raw: st.Raw}
+
+		resp := &resource.ValidateConfigResponse{}
+		r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: config}, resp)
+		if resp.Diagnostics.ErrorsCount() != tc.errors {
+			t.Errorf("Expected %d got %d", tc.errors, resp.Diagnostics.ErrorsCount())
+		}
+	}
+}
+
+func TestAccAuthServerResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := fmt.Sprintf(testAccDomainConfig, "") + `
+resource "baruwa_auth_server" "test" {
+  domain_id = baruwa_domain.test.id
+  address   = "ldap.example.com"
+  port      = 389
+  protocol  = 5
+
+  ldap = {
+    basedn        = "ou=users,dc=example,dc=com"
+    bind_dn       = "cn=admin,dc=example,dc=com"
+    bind_password = "secret"
+    use_tls       = %t
+  }
+}
+`
+	tfresource.Test(t, tfresource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "authservers"),
+		Steps: []tfresource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, false)),
+				Check: tfresource.ComposeAggregateTestCheckFunc(
+					tfresource.TestCheckResourceAttr("baruwa_auth_server.test", "address", "ldap.example.com"),
+					tfresource.TestCheckResourceAttr("baruwa_auth_server.test", "ldap.name_attribute", "uid"),
+					tfresource.TestCheckResourceAttrSet("baruwa_auth_server.test", "ldap.id"),
+				),
+			},
+			{
+				ResourceName:            "baruwa_auth_server.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccImportID("baruwa_auth_server.test", "example.com/%s/%s", "address", "ldap.id"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"ldap.bind_password"},
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, true)),
+				Check:  tfresource.TestCheckResourceAttr("baruwa_auth_server.test", "ldap.use_tls", "true"),
+			},
+		},
+	})
+}
@@ -0,0 +1,215 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*deliveryServerResource)(nil)
+	_ resource.ResourceWithImportState = (*deliveryServerResource)(nil)
+)
+
+type deliveryServerResource struct {
+	baseResource
+}
+
+type deliveryServerModel struct {
+	ID               types.Int64  `tfsdk:"id"`
+	DomainID         types.Int64  `tfsdk:"domain_id"`
+	Address          types.String `tfsdk:"address"`
+	Protocol         types.Int64  `tfsdk:"protocol"`
+	Port             types.Int64  `tfsdk:"port"`
+	RequireTLS       types.Bool   `tfsdk:"require_tls"`
+	VerificationOnly types.Bool   `tfsdk:"verification_only"`
+	Enabled          types.Bool   `tfsdk:"enabled"`
+}
+
+func newDeliveryServerResource() resource.Resource {
+	return &deliveryServerResource{}
+}
+
+func (r *deliveryServerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_delivery_server"
+}
+
+func (r *deliveryServerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A server that the mail of a domain is delivered to. Import using <domain>/<server> where the domain is an ID or a name and the server an ID or an address.",
+		Attributes:  serverAttributes("domain_id", "The ID of the domain."),
+	}
+}
+
+func (r *deliveryServerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan deliveryServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.CreateDomainDeliveryServer(intValue(plan.DomainID), plan.toAPI())
+	if err != nil {
+		addError(&resp.Diagnostics, "create", "delivery server", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *deliveryServerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state deliveryServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.GetDomainDeliveryServer(intValue(state.DomainID), intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "delivery server", err)
+		return
+	}
+
+	state.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *deliveryServerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan deliveryServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateDomainDeliveryServer(intValue(plan.DomainID), plan.toAPI()); err != nil {
+		addError(&resp.Diagnostics, "update", "delivery server", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *deliveryServerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state deliveryServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteDomainDeliveryServer(intValue(state.DomainID), state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "delivery server", err)
+	}
+}
+
+func (r *deliveryServerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domainID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "delivery server", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain_id"), int64(domainID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *deliveryServerResource) lookup(s string) (domainID, id int, err error) {
+	var ok bool
+	var domain, address string
+
+	if domain, address, err = splitImportID(s, "<domain>/<server>"); err != nil {
+		return
+	}
+
+	if domainID, err = lookupDomain(r.client, domain); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainDeliveryServerList
+		if l, err = r.client.GetDomainDeliveryServers(domainID, opts); err != nil {
+			return
+		}
+		for _, ds := range l.Items {
+			if ds.Address == address {
+				id = ds.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "delivery server", address)
+
+	return
+}
+
+func (m *deliveryServerModel) toAPI() *api.DomainDeliveryServerForm {
+	return &api.DomainDeliveryServerForm{
+		ID:               intValue(m.ID),
+		Address:          m.Address.ValueString(),
+		Protocol:         intValue(m.Protocol),
+		Port:             intValue(m.Port),
+		RequireTLS:       m.RequireTLS.ValueBool(),
+		VerificationOnly: m.VerificationOnly.ValueBool(),
+		Enabled:          m.Enabled.ValueBool(),
+		Domain:           intValue(m.DomainID),
+	}
+}
+
+func (m *deliveryServerModel) fromAPI(s *api.DomainDeliveryServer) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Protocol = int64Value(s.Protocol)
+	m.Port = int64Value(s.Port)
+	m.RequireTLS = types.BoolValue(s.RequireTLS)
+	m.VerificationOnly = types.BoolValue(s.VerificationOnly)
+	m.Enabled = types.BoolValue(s.Enabled)
+	if s.Domain != nil && s.Domain.ID > 0 {
+		m.DomainID = int64Value(s.Domain.ID)
+	}
+}
+
+// serverAttributes are shared by the delivery and fallback servers
+func serverAttributes(parent, description string) map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id":   idAttribute(),
+		parent: parentAttribute(description),
+		"address": schema.StringAttribute{
+			Description: "The hostname or IP address of the server.",
+			Required:    true,
+		},
+		"protocol":          defaultInt64("The delivery protocol, 1 is SMTP and 2 is LMTP.", 1),
+		"port":              defaultInt64("The port of the server.", 25),
+		"require_tls":       defaultBool("Whether TLS is required.", false),
+		"verification_only": defaultBool("Whether the server is only used to verify recipients.", false),
+		"enabled":           defaultBool("Whether the server is enabled.", true),
+	}
+}
@@ -0,0 +1,198 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*domainAliasResource)(nil)
+	_ resource.ResourceWithImportState = (*domainAliasResource)(nil)
+)
+
+type domainAliasResource struct {
+	baseResource
+}
+
+type domainAliasModel struct {
+	ID            types.Int64  `tfsdk:"id"`
+	DomainID      types.Int64  `tfsdk:"domain_id"`
+	Name          types.String `tfsdk:"name"`
+	Enabled       types.Bool   `tfsdk:"enabled"`
+	AcceptInbound types.Bool   `tfsdk:"accept_inbound"`
+}
+
+func newDomainAliasResource() resource.Resource {
+	return &domainAliasResource{}
+}
+
+func (r *domainAliasResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_alias"
+}
+
+func (r *domainAliasResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A Baruwa domain alias. Import using <domain>/<alias> where each part is an ID or a name.",
+		Attributes: map[string]schema.Attribute{
+			"id":        idAttribute(),
+			"domain_id": parentAttribute("The ID of the domain."),
+			"name": schema.StringAttribute{
+				Description: "The alias domain name.",
+				Required:    true,
+			},
+			"enabled":        defaultBool("Whether the alias is enabled.", true),
+			"accept_inbound": defaultBool("Whether inbound mail is accepted.", true),
+		},
+	}
+}
+
+func (r *domainAliasResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan domainAliasModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alias, err := r.client.CreateDomainAlias(intValue(plan.DomainID), plan.toAPI())
+	if err != nil {
+		addError(&resp.Diagnostics, "create", "domain alias", err)
+		return
+	}
+
+	plan.fromAPI(alias)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainAliasResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state domainAliasModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alias, err := r.client.GetDomainAlias(intValue(state.DomainID), intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "domain alias", err)
+		return
+	}
+
+	state.fromAPI(alias)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *domainAliasResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan domainAliasModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateDomainAlias(intValue(plan.DomainID), plan.toAPI()); err != nil {
+		addError(&resp.Diagnostics, "update", "domain alias", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainAliasResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state domainAliasModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteDomainAlias(intValue(state.DomainID), state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "domain alias", err)
+	}
+}
+
+func (r *domainAliasResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domainID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "domain alias", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain_id"), int64(domainID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *domainAliasResource) lookup(s string) (domainID, id int, err error) {
+	var ok bool
+	var domain, name string
+
+	if domain, name, err = splitImportID(s, "<domain>/<alias>"); err != nil {
+		return
+	}
+
+	if domainID, err = lookupDomain(r.client, domain); err != nil {
+		return
+	}
+
+	if id, ok = parseID(name); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainAliasList
+		if l, err = r.client.GetDomainAliases(domainID, opts); err != nil {
+			return
+		}
+		for _, a := range l.Items {
+			if a.Name == name {
+				id = a.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "domain alias", name)
+
+	return
+}
+
+func (m *domainAliasModel) toAPI() *api.DomainAliasForm {
+	return &api.DomainAliasForm{
+		ID:            intValue(m.ID),
+		Name:          m.Name.ValueString(),
+		Enabled:       m.Enabled.ValueBool(),
+		AcceptInbound: m.AcceptInbound.ValueBool(),
+		Domain:        intValue(m.DomainID),
+	}
+}
+
+func (m *domainAliasModel) fromAPI(a *api.DomainAlias) {
+	m.ID = int64Value(a.ID)
+	m.Name = types.StringValue(a.Name)
+	m.Enabled = types.BoolValue(a.Enabled)
+	m.AcceptInbound = types.BoolValue(a.AcceptInbound)
+	if a.Domain != nil && a.Domain.ID > 0 {
+		m.DomainID = int64Value(a.Domain.ID)
+	}
+}
@@ -0,0 +1,100 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestDomainAliasResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	domainID := s.domain(t, "example.com")
+	h := newHarness(t, s, newDomainAliasResource())
+	state := h.create(&domainAliasModel{
+		ID:            types.Int64Unknown(),
+		DomainID:      int64Value(domainID),
+		Name:          types.StringValue("example.net"),
+		Enabled:       types.BoolValue(true),
+		AcceptInbound: types.BoolValue(true),
+	})
+
+	var m domainAliasModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+
+	plan := m
+	plan.Enabled = types.BoolValue(false)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the domain alias to exist")
+	}
+	h.get(state, &m)
+	if m.Enabled.ValueBool() {
+		t.Errorf("Expected the domain alias to be disabled")
+	}
+
+	for _, id := range []string{fmt.Sprintf("%d/%d", domainID, m.ID.ValueInt64()), "example.com/example.net"} {
+		var i domainAliasModel
+		h.get(h.importState(id), &i)
+		if i != m {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	if e := h.importError("example.net"); e != fmt.Sprintf(importIDError, "<domain>/<alias>", "example.net") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(importIDError, "<domain>/<alias>", "example.net"), e)
+	}
+	if e := h.importError("example.com/example.org"); e != fmt.Sprintf(notFoundError, "domain alias", "example.org") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(notFoundError, "domain alias", "example.org"), e)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the domain alias to be removed from the state")
+	}
+}
+
+func TestAccDomainAliasResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := fmt.Sprintf(testAccDomainConfig, "") + `
+resource "baruwa_domain_alias" "test" {
+  domain_id = baruwa_domain.test.id
+  name      = "example.net"
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "domainaliases"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, config),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_domain_alias.test", "name", "example.net"),
+					resource.TestCheckResourceAttr("baruwa_domain_alias.test", "enabled", "true"),
+					resource.TestCheckResourceAttrPair("baruwa_domain_alias.test", "domain_id", "baruwa_domain.test", "id"),
+				),
+			},
+			{
+				ResourceName:      "baruwa_domain_alias.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccImportID("baruwa_domain_alias.test", "example.com/%s", "name"),
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
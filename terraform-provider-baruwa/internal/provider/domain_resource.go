@@ -0,0 +1,268 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*domainResource)(nil)
+	_ resource.ResourceWithImportState = (*domainResource)(nil)
+)
+
+type domainResource struct {
+	baseResource
+}
+
+type domainModel struct {
+	ID                types.Int64   `tfsdk:"id"`
+	Name              types.String  `tfsdk:"name"`
+	SiteURL           types.String  `tfsdk:"site_url"`
+	Organizations     types.List    `tfsdk:"organizations"`
+	Enabled           types.Bool    `tfsdk:"enabled"`
+	AcceptInbound     types.Bool    `tfsdk:"accept_inbound"`
+	DiscardMail       types.Bool    `tfsdk:"discard_mail"`
+	SMTPCallout       types.Bool    `tfsdk:"smtp_callout"`
+	LdapCallout       types.Bool    `tfsdk:"ldap_callout"`
+	VirusChecks       types.Bool    `tfsdk:"virus_checks"`
+	VirusChecksAtSMTP types.Bool    `tfsdk:"virus_checks_at_smtp"`
+	BlockMacros       types.Bool    `tfsdk:"block_macros"`
+	SpamChecks        types.Bool    `tfsdk:"spam_checks"`
+	SpamActions       types.Int64   `tfsdk:"spam_actions"`
+	HighspamActions   types.Int64   `tfsdk:"highspam_actions"`
+	VirusActions      types.Int64   `tfsdk:"virus_actions"`
+	LowScore          types.Float64 `tfsdk:"low_score"`
+	HighScore         types.Float64 `tfsdk:"high_score"`
+	MessageSize       types.String  `tfsdk:"message_size"`
+	DeliveryMode      types.Int64   `tfsdk:"delivery_mode"`
+	Language          types.String  `tfsdk:"language"`
+	Timezone          types.String  `tfsdk:"timezone"`
+	ReportEvery       types.Int64   `tfsdk:"report_every"`
+}
+
+func newDomainResource() resource.Resource {
+	return &domainResource{}
+}
+
+func (r *domainResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain"
+}
+
+func (r *domainResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A Baruwa domain. Import using the ID or the domain name.",
+		Attributes: map[string]schema.Attribute{
+			"id": idAttribute(),
+			"name": schema.StringAttribute{
+				Description: "The domain name.",
+				Required:    true,
+			},
+			"site_url": schema.StringAttribute{
+				Description: "The URL of the web interface used in reports.",
+				Required:    true,
+			},
+			"organizations": schema.ListAttribute{
+				Description: "The IDs of the organizations the domain belongs to.",
+				ElementType: types.Int64Type,
+				Required:    true,
+			},
+			"enabled":              defaultBool("Whether the domain is enabled.", true),
+			"accept_inbound":       defaultBool("Whether inbound mail is accepted.", true),
+			"discard_mail":         defaultBool("Whether mail is discarded.", false),
+			"smtp_callout":         defaultBool("Whether recipients are verified using SMTP callouts.", false),
+			"ldap_callout":         defaultBool("Whether recipients are verified using LDAP.", false),
+			"virus_checks":         defaultBool("Whether mail is scanned for viruses.", true),
+			"virus_checks_at_smtp": defaultBool("Whether viruses are rejected at SMTP time.", true),
+			"block_macros":         defaultBool("Whether documents with macros are blocked.", false),
+			"spam_checks":          defaultBool("Whether mail is checked for spam.", true),
+			"spam_actions":         defaultInt64("The action taken on spam.", 2),
+			"highspam_actions":     defaultInt64("The action taken on high scoring spam.", 2),
+			"virus_actions":        defaultInt64("The action taken on viruses.", 2),
+			"low_score":            defaultFloat64("The spam score, 0 uses the system default.", 0),
+			"high_score":           defaultFloat64("The high spam score, 0 uses the system default.", 0),
+			"message_size":         defaultString("The maximum message size, 0 is unlimited.", "0"),
+			"delivery_mode":        defaultInt64("The delivery mode, 1 load balances and 2 fails over.", 1),
+			"language":             defaultString("The language of reports.", "en"),
+			"timezone":             computedString("The timezone of reports."),
+			"report_every":         defaultInt64("How often reports are sent.", 3),
+		},
+	}
+}
+
+func (r *domainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan domainModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domain := plan.toAPI(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.CreateDomain(domain); err != nil {
+		addError(&resp.Diagnostics, "create", "domain", err)
+		return
+	}
+
+	plan.fromAPI(domain)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state domainModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domain, err := r.client.GetDomain(intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "domain", err)
+		return
+	}
+
+	state.fromAPI(domain)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *domainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan domainModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	domain := plan.toAPI(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateDomain(domain); err != nil {
+		addError(&resp.Diagnostics, "update", "domain", err)
+		return
+	}
+
+	plan.fromAPI(domain)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state domainModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteDomain(intValue(state.ID)); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "domain", err)
+	}
+}
+
+func (r *domainResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, err := lookupDomain(r.client, req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "domain", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+// lookupDomain returns the ID of a domain given its ID or name
+func lookupDomain(client *api.Client, s string) (id int, err error) {
+	var ok bool
+	var domain *api.Domain
+
+	if id, ok = parseID(s); ok {
+		return
+	}
+
+	if domain, err = client.GetDomainByName(s); err != nil {
+		return
+	}
+
+	id = domain.ID
+
+	return
+}
+
+func (m *domainModel) toAPI(ctx context.Context, diags *diag.Diagnostics) *api.Domain {
+	return &api.Domain{
+		ID:                intValue(m.ID),
+		Name:              m.Name.ValueString(),
+		SiteURL:           m.SiteURL.ValueString(),
+		Organizations:     intList(ctx, m.Organizations, diags),
+		Enabled:           m.Enabled.ValueBool(),
+		AcceptInbound:     m.AcceptInbound.ValueBool(),
+		DiscardMail:       m.DiscardMail.ValueBool(),
+		SMTPCallout:       m.SMTPCallout.ValueBool(),
+		LdapCallout:       m.LdapCallout.ValueBool(),
+		VirusChecks:       m.VirusChecks.ValueBool(),
+		VirusChecksAtSMTP: m.VirusChecksAtSMTP.ValueBool(),
+		BlockMacros:       m.BlockMacros.ValueBool(),
+		SpamChecks:        m.SpamChecks.ValueBool(),
+		SpamActions:       intValue(m.SpamActions),
+		HighspamActions:   intValue(m.HighspamActions),
+		VirusActions:      intValue(m.VirusActions),
+		LowScore:          floatValue(m.LowScore),
+		HighScore:         floatValue(m.HighScore),
+		MessageSize:       m.MessageSize.ValueString(),
+		DeliveryMode:      intValue(m.DeliveryMode),
+		Language:          m.Language.ValueString(),
+		Timezone:          m.Timezone.ValueString(),
+		ReportEvery:       intValue(m.ReportEvery),
+	}
+}
+
+// fromAPI sets the model from a domain, the organizations are kept as
+// the server does not always return them
+func (m *domainModel) fromAPI(d *api.Domain) {
+	m.ID = int64Value(d.ID)
+	m.Name = types.StringValue(d.Name)
+	m.SiteURL = types.StringValue(d.SiteURL)
+	if len(d.Organizations) > 0 || m.Organizations.IsNull() || m.Organizations.IsUnknown() {
+		m.Organizations = int64List(d.Organizations)
+	}
+	m.Enabled = types.BoolValue(d.Enabled)
+	m.AcceptInbound = types.BoolValue(d.AcceptInbound)
+	m.DiscardMail = types.BoolValue(d.DiscardMail)
+	m.SMTPCallout = types.BoolValue(d.SMTPCallout)
+	m.LdapCallout = types.BoolValue(d.LdapCallout)
+	m.VirusChecks = types.BoolValue(d.VirusChecks)
+	m.VirusChecksAtSMTP = types.BoolValue(d.VirusChecksAtSMTP)
+	m.BlockMacros = types.BoolValue(d.BlockMacros)
+	m.SpamChecks = types.BoolValue(d.SpamChecks)
+	m.SpamActions = int64Value(d.SpamActions)
+	m.HighspamActions = int64Value(d.HighspamActions)
+	m.VirusActions = int64Value(d.VirusActions)
+	m.LowScore = float64Value(d.LowScore)
+	m.HighScore = float64Value(d.HighScore)
+	m.MessageSize = types.StringValue(d.MessageSize)
+	m.DeliveryMode = int64Value(d.DeliveryMode)
+	m.Language = types.StringValue(d.Language)
+	m.Timezone = types.StringValue(d.Timezone)
+	m.ReportEvery = int64Value(d.ReportEvery)
+}
@@ -0,0 +1,134 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+const testAccDomainConfig = `
+resource "baruwa_organization" "test" {
+  name = "Example"
+}
+
+resource "baruwa_domain" "test" {
+  name          = "example.com"
+  site_url      = "https://mail.example.com"
+  organizations = [baruwa_organization.test.id]
+  %s
+}
+`
+
+func testDomainModel(orgID int) *domainModel {
+	return &domainModel{
+		ID:                types.Int64Unknown(),
+		Name:              types.StringValue("example.com"),
+		SiteURL:           types.StringValue("https://mail.example.com"),
+		Organizations:     int64List([]int{orgID}),
+		Enabled:           types.BoolValue(true),
+		AcceptInbound:     types.BoolValue(true),
+		DiscardMail:       types.BoolValue(false),
+		SMTPCallout:       types.BoolValue(false),
+		LdapCallout:       types.BoolValue(false),
+		VirusChecks:       types.BoolValue(true),
+		VirusChecksAtSMTP: types.BoolValue(true),
+		BlockMacros:       types.BoolValue(false),
+		SpamChecks:        types.BoolValue(true),
+		SpamActions:       types.Int64Value(2),
+		HighspamActions:   types.Int64Value(2),
+		VirusActions:      types.Int64Value(2),
+		LowScore:          types.Float64Value(0),
+		HighScore:         types.Float64Value(0),
+		MessageSize:       types.StringValue("0"),
+		DeliveryMode:      types.Int64Value(1),
+		Language:          types.StringValue("en"),
+		Timezone:          types.StringUnknown(),
+		ReportEvery:       types.Int64Value(3),
+	}
+}
+
+func TestDomainResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	orgID := s.organization(t, "Example")
+	h := newHarness(t, s, newDomainResource())
+	state := h.create(testDomainModel(orgID))
+
+	var m domainModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+	if m.Timezone.IsUnknown() {
+		t.Errorf("Expected the timezone to be known")
+	}
+
+	plan := m
+	plan.SpamChecks = types.BoolValue(false)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the domain to exist")
+	}
+	h.get(state, &m)
+	if m.SpamChecks.ValueBool() {
+		t.Errorf("Expected spam checks to be disabled")
+	}
+	if !m.Organizations.Equal(int64List([]int{orgID})) {
+		t.Errorf("Expected %s got %s", int64List([]int{orgID}), m.Organizations)
+	}
+
+	for _, id := range []string{fmt.Sprint(m.ID.ValueInt64()), "example.com"} {
+		var i domainModel
+		h.get(h.importState(id), &i)
+		if i.ID != m.ID || i.Name != m.Name || i.SpamChecks != m.SpamChecks {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the domain to be removed from the state")
+	}
+}
+
+func TestAccDomainResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "domains"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(testAccDomainConfig, "")),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_domain.test", "name", "example.com"),
+					resource.TestCheckResourceAttr("baruwa_domain.test", "spam_checks", "true"),
+					resource.TestCheckResourceAttrPair("baruwa_domain.test", "organizations.0", "baruwa_organization.test", "id"),
+				),
+			},
+			{
+				ResourceName:            "baruwa_domain.test",
+				ImportState:             true,
+				ImportStateId:           "example.com",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"organizations"},
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(testAccDomainConfig, "spam_checks = false")),
+				Check:  resource.TestCheckResourceAttr("baruwa_domain.test", "spam_checks", "false"),
+			},
+		},
+	})
+}
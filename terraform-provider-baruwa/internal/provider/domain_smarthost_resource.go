@@ -0,0 +1,220 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*domainSmartHostResource)(nil)
+	_ resource.ResourceWithImportState = (*domainSmartHostResource)(nil)
+)
+
+type domainSmartHostResource struct {
+	baseResource
+}
+
+type domainSmartHostModel struct {
+	ID          types.Int64  `tfsdk:"id"`
+	DomainID    types.Int64  `tfsdk:"domain_id"`
+	Address     types.String `tfsdk:"address"`
+	Port        types.Int64  `tfsdk:"port"`
+	Username    types.String `tfsdk:"username"`
+	Password    types.String `tfsdk:"password"`
+	RequireTLS  types.Bool   `tfsdk:"require_tls"`
+	Enabled     types.Bool   `tfsdk:"enabled"`
+	Description types.String `tfsdk:"description"`
+}
+
+func newDomainSmartHostResource() resource.Resource {
+	return &domainSmartHostResource{}
+}
+
+func (r *domainSmartHostResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_domain_smarthost"
+}
+
+func (r *domainSmartHostResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attrs := smartHostAttributes()
+	attrs["domain_id"] = parentAttribute("The ID of the domain.")
+
+	resp.Schema = schema.Schema{
+		Description: "A smarthost used to relay the mail of a domain. Import using <domain>/<smarthost> where the domain is an ID or a name and the smarthost an ID or an address.",
+		Attributes:  attrs,
+	}
+}
+
+func (r *domainSmartHostResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan domainSmartHostModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.CreateDomainSmartHost(intValue(plan.DomainID), server); err != nil {
+		addError(&resp.Diagnostics, "create", "domain smarthost", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainSmartHostResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state domainSmartHostModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.GetDomainSmartHost(intValue(state.DomainID), intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "domain smarthost", err)
+		return
+	}
+
+	state.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *domainSmartHostResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan domainSmartHostModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.UpdateDomainSmartHost(intValue(plan.DomainID), server); err != nil {
+		addError(&resp.Diagnostics, "update", "domain smarthost", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *domainSmartHostResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state domainSmartHostModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteDomainSmartHost(intValue(state.DomainID), state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "domain smarthost", err)
+	}
+}
+
+func (r *domainSmartHostResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	domainID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "domain smarthost", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("domain_id"), int64(domainID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *domainSmartHostResource) lookup(s string) (domainID, id int, err error) {
+	var ok bool
+	var domain, address string
+
+	if domain, address, err = splitImportID(s, "<domain>/<smarthost>"); err != nil {
+		return
+	}
+
+	if domainID, err = lookupDomain(r.client, domain); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.DomainSmartHostList
+		if l, err = r.client.GetDomainSmartHosts(domainID, opts); err != nil {
+			return
+		}
+		for _, h := range l.Items {
+			if h.Address == address {
+				id = h.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "domain smarthost", address)
+
+	return
+}
+
+func (m *domainSmartHostModel) toAPI() *api.DomainSmartHost {
+	return &api.DomainSmartHost{
+		ID:          intValue(m.ID),
+		Address:     m.Address.ValueString(),
+		Port:        intValue(m.Port),
+		Username:    m.Username.ValueString(),
+		Password:    m.Password.ValueString(),
+		RequireTLS:  m.RequireTLS.ValueBool(),
+		Enabled:     m.Enabled.ValueBool(),
+		Description: m.Description.ValueString(),
+	}
+}
+
+// fromAPI sets the model from a smarthost, the password is kept as
+// the server does not return it
+func (m *domainSmartHostModel) fromAPI(s *api.DomainSmartHost) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Port = int64Value(s.Port)
+	m.Username = types.StringValue(s.Username)
+	m.RequireTLS = types.BoolValue(s.RequireTLS)
+	m.Enabled = types.BoolValue(s.Enabled)
+	m.Description = types.StringValue(s.Description)
+}
+
+// smartHostAttributes are shared by the domain and organization smarthosts
+func smartHostAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id": idAttribute(),
+		"address": schema.StringAttribute{
+			Description: "The hostname or IP address of the smarthost.",
+			Required:    true,
+		},
+		"port":        defaultInt64("The SMTP port.", 25),
+		"username":    defaultString("The SMTP AUTH username.", ""),
+		"password":    secretString("The SMTP AUTH password."),
+		"require_tls": defaultBool("Whether TLS is required.", false),
+		"enabled":     defaultBool("Whether the smarthost is enabled.", true),
+		"description": defaultString("A description of the smarthost.", ""),
+	}
+}
@@ -0,0 +1,199 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*fallbackServerResource)(nil)
+	_ resource.ResourceWithImportState = (*fallbackServerResource)(nil)
+)
+
+type fallbackServerResource struct {
+	baseResource
+}
+
+type fallbackServerModel struct {
+	ID               types.Int64  `tfsdk:"id"`
+	OrganizationID   types.Int64  `tfsdk:"organization_id"`
+	Address          types.String `tfsdk:"address"`
+	Protocol         types.Int64  `tfsdk:"protocol"`
+	Port             types.Int64  `tfsdk:"port"`
+	RequireTLS       types.Bool   `tfsdk:"require_tls"`
+	VerificationOnly types.Bool   `tfsdk:"verification_only"`
+	Enabled          types.Bool   `tfsdk:"enabled"`
+}
+
+func newFallbackServerResource() resource.Resource {
+	return &fallbackServerResource{}
+}
+
+func (r *fallbackServerResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_fallback_server"
+}
+
+func (r *fallbackServerResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A server that the mail of an organization is delivered to when the delivery servers fail. Import using <organization>/<server> where the organization is an ID or a name and the server an ID or an address.",
+		Attributes:  serverAttributes("organization_id", "The ID of the organization."),
+	}
+}
+
+func (r *fallbackServerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan fallbackServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.CreateFallBackServer(intValue(plan.OrganizationID), server); err != nil {
+		addError(&resp.Diagnostics, "create", "fallback server", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *fallbackServerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state fallbackServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.GetFallBackServer(intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "fallback server", err)
+		return
+	}
+
+	state.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *fallbackServerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan fallbackServerModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.UpdateFallBackServer(server); err != nil {
+		addError(&resp.Diagnostics, "update", "fallback server", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *fallbackServerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state fallbackServerModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteFallBackServer(state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "fallback server", err)
+	}
+}
+
+func (r *fallbackServerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	orgID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "fallback server", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("organization_id"), int64(orgID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *fallbackServerResource) lookup(s string) (orgID, id int, err error) {
+	var ok bool
+	var org, address string
+
+	if org, address, err = splitImportID(s, "<organization>/<server>"); err != nil {
+		return
+	}
+
+	if orgID, err = lookupOrganization(r.client, org); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.FallBackServerList
+		if l, err = r.client.GetFallBackServers(orgID, opts); err != nil {
+			return
+		}
+		for _, fs := range l.Items {
+			if fs.Address == address {
+				id = fs.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "fallback server", address)
+
+	return
+}
+
+func (m *fallbackServerModel) toAPI() *api.FallBackServer {
+	return &api.FallBackServer{
+		ID:               intValue(m.ID),
+		Address:          m.Address.ValueString(),
+		Protocol:         intValue(m.Protocol),
+		Port:             intValue(m.Port),
+		RequireTLS:       m.RequireTLS.ValueBool(),
+		VerificationOnly: m.VerificationOnly.ValueBool(),
+		Enabled:          m.Enabled.ValueBool(),
+	}
+}
+
+func (m *fallbackServerModel) fromAPI(s *api.FallBackServer) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Protocol = int64Value(s.Protocol)
+	m.Port = int64Value(s.Port)
+	m.RequireTLS = types.BoolValue(s.RequireTLS)
+	m.VerificationOnly = types.BoolValue(s.VerificationOnly)
+	m.Enabled = types.BoolValue(s.Enabled)
+	if s.Organization != nil && s.Organization.ID > 0 {
+		m.OrganizationID = int64Value(s.Organization.ID)
+	}
+}
@@ -0,0 +1,113 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestFallbackServerResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	orgID := s.organization(t, "Example")
+	h := newHarness(t, s, newFallbackServerResource())
+	state := h.create(&fallbackServerModel{
+		ID:               types.Int64Unknown(),
+		OrganizationID:   int64Value(orgID),
+		Address:          types.StringValue("192.168.1.150"),
+		Protocol:         types.Int64Value(1),
+		Port:             types.Int64Value(25),
+		RequireTLS:       types.BoolValue(false),
+		VerificationOnly: types.BoolValue(false),
+		Enabled:          types.BoolValue(true),
+	})
+
+	var m fallbackServerModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+	if m.OrganizationID.ValueInt64() != int64(orgID) {
+		t.Errorf("Expected %d got %d", orgID, m.OrganizationID.ValueInt64())
+	}
+
+	plan := m
+	plan.Protocol = types.Int64Value(2)
+	plan.Port = types.Int64Value(24)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the fallback server to exist")
+	}
+	h.get(state, &m)
+	if m.Protocol.ValueInt64() != 2 {
+		t.Errorf("Expected %d got %d", 2, m.Protocol.ValueInt64())
+	}
+
+	for _, id := range []string{fmt.Sprintf("%d/%d", orgID, m.ID.ValueInt64()), "Example/192.168.1.150"} {
+		var i fallbackServerModel
+		h.get(h.importState(id), &i)
+		if i != m {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	if e := h.importError("Example/192.168.1.151"); e != fmt.Sprintf(notFoundError, "fallback server", "192.168.1.151") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(notFoundError, "fallback server", "192.168.1.151"), e)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the fallback server to be removed from the state")
+	}
+}
+
+func TestAccFallbackServerResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := `
+resource "baruwa_organization" "test" {
+  name = "Example"
+}
+
+resource "baruwa_fallback_server" "test" {
+  organization_id = baruwa_organization.test.id
+  address         = "192.168.1.150"
+  port            = %d
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "fallbackservers"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 25)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_fallback_server.test", "address", "192.168.1.150"),
+					resource.TestCheckResourceAttr("baruwa_fallback_server.test", "protocol", "1"),
+					resource.TestCheckResourceAttr("baruwa_fallback_server.test", "enabled", "true"),
+				),
+			},
+			{
+				ResourceName:      "baruwa_fallback_server.test",
+				ImportState:       true,
+				ImportStateIdFunc: testAccImportID("baruwa_fallback_server.test", "%s/%s", "organization_id", "id"),
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 2525)),
+				Check:  resource.TestCheckResourceAttr("baruwa_fallback_server.test", "port", "2525"),
+			},
+		},
+	})
+}
@@ -0,0 +1,201 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/float64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	importIDError  = "Expected an import ID of the form %s, got %q"
+	notFoundError  = "No %s named %q was found"
+	clientTypeNote = "Expected *api.Client, got %T. Please report this issue to the provider developers."
+)
+
+// baseResource holds the client shared by all the resources
+type baseResource struct {
+	client *api.Client
+}
+
+// Configure sets the client created by the provider
+func (r *baseResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*api.Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected resource configure type", fmt.Sprintf(clientTypeNote, req.ProviderData))
+		return
+	}
+
+	r.client = client
+}
+
+// splitImportID splits a parent/child import ID
+func splitImportID(id, format string) (parent, child string, err error) {
+	p := strings.SplitN(id, "/", 2)
+	if len(p) != 2 || p[0] == "" || p[1] == "" {
+		err = fmt.Errorf(importIDError, format, id)
+		return
+	}
+
+	parent, child = p[0], p[1]
+
+	return
+}
+
+// parseID returns the numeric value of s and whether s is numeric
+func parseID(s string) (int, bool) {
+	id, err := strconv.Atoi(s)
+
+	return id, err == nil && id > 0
+}
+
+func addError(diags *diag.Diagnostics, action, kind string, err error) {
+	diags.AddError(fmt.Sprintf("Unable to %s %s", action, kind), err.Error())
+}
+
+func intValue(v types.Int64) int {
+	return int(v.ValueInt64())
+}
+
+func int64Value(v int) types.Int64 {
+	return types.Int64Value(int64(v))
+}
+
+func floatValue(v types.Float64) api.LocalFloat64 {
+	return api.LocalFloat64(v.ValueFloat64())
+}
+
+func float64Value(v api.LocalFloat64) types.Float64 {
+	return types.Float64Value(float64(v))
+}
+
+// intList converts a list of numbers
+func intList(ctx context.Context, l types.List, diags *diag.Diagnostics) (ids []int) {
+	var values []int64
+
+	if l.IsNull() || l.IsUnknown() {
+		return
+	}
+
+	diags.Append(l.ElementsAs(ctx, &values, false)...)
+	for _, v := range values {
+		ids = append(ids, int(v))
+	}
+
+	return
+}
+
+func int64List(ids []int) types.List {
+	values := make([]types.Int64, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, int64Value(id))
+	}
+
+	l, _ := types.ListValueFrom(context.Background(), types.Int64Type, values)
+
+	return l
+}
+
+// idAttribute is the computed ID of a resource
+func idAttribute() schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Description: "The Baruwa ID.",
+		Computed:    true,
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+// parentAttribute is the ID of the object a resource belongs to,
+// changing it replaces the resource
+func parentAttribute(description string) schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Description: description,
+		Required:    true,
+		PlanModifiers: []planmodifier.Int64{
+			int64planmodifier.RequiresReplace(),
+		},
+	}
+}
+
+// computedString is an optional string the server fills in when unset
+func computedString(description string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: description,
+		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+func defaultString(description, def string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: description,
+		Optional:    true,
+		Computed:    true,
+		Default:     stringdefault.StaticString(def),
+	}
+}
+
+// secretString is write only, the server does not return it
+func secretString(description string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: description,
+		Optional:    true,
+		Sensitive:   true,
+	}
+}
+
+func defaultBool(description string, def bool) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Description: description,
+		Optional:    true,
+		Computed:    true,
+		Default:     booldefault.StaticBool(def),
+	}
+}
+
+func defaultInt64(description string, def int64) schema.Int64Attribute {
+	return schema.Int64Attribute{
+		Description: description,
+		Optional:    true,
+		Computed:    true,
+		Default:     int64default.StaticInt64(def),
+	}
+}
+
+func defaultFloat64(description string, def float64) schema.Float64Attribute {
+	return schema.Float64Attribute{
+		Description: description,
+		Optional:    true,
+		Computed:    true,
+		Default:     float64default.StaticFloat64(def),
+	}
+}
@@ -0,0 +1,203 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*orgSmartHostResource)(nil)
+	_ resource.ResourceWithImportState = (*orgSmartHostResource)(nil)
+)
+
+type orgSmartHostResource struct {
+	baseResource
+}
+
+type orgSmartHostModel struct {
+	ID             types.Int64  `tfsdk:"id"`
+	OrganizationID types.Int64  `tfsdk:"organization_id"`
+	Address        types.String `tfsdk:"address"`
+	Port           types.Int64  `tfsdk:"port"`
+	Username       types.String `tfsdk:"username"`
+	Password       types.String `tfsdk:"password"`
+	RequireTLS     types.Bool   `tfsdk:"require_tls"`
+	Enabled        types.Bool   `tfsdk:"enabled"`
+	Description    types.String `tfsdk:"description"`
+}
+
+func newOrgSmartHostResource() resource.Resource {
+	return &orgSmartHostResource{}
+}
+
+func (r *orgSmartHostResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_organization_smarthost"
+}
+
+func (r *orgSmartHostResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attrs := smartHostAttributes()
+	attrs["organization_id"] = parentAttribute("The ID of the organization.")
+
+	resp.Schema = schema.Schema{
+		Description: "A smarthost used to relay the mail of an organization. Import using <organization>/<smarthost> where the organization is an ID or a name and the smarthost an ID or an address.",
+		Attributes:  attrs,
+	}
+}
+
+func (r *orgSmartHostResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan orgSmartHostModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.CreateOrgSmartHost(intValue(plan.OrganizationID), server); err != nil {
+		addError(&resp.Diagnostics, "create", "organization smarthost", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *orgSmartHostResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state orgSmartHostModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server, err := r.client.GetOrgSmartHost(intValue(state.OrganizationID), intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "organization smarthost", err)
+		return
+	}
+
+	state.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *orgSmartHostResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan orgSmartHostModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	server := plan.toAPI()
+	if err := r.client.UpdateOrgSmartHost(intValue(plan.OrganizationID), server); err != nil {
+		addError(&resp.Diagnostics, "update", "organization smarthost", err)
+		return
+	}
+
+	plan.fromAPI(server)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *orgSmartHostResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state orgSmartHostModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteOrgSmartHost(intValue(state.OrganizationID), state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "organization smarthost", err)
+	}
+}
+
+func (r *orgSmartHostResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	orgID, id, err := r.lookup(req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "organization smarthost", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("organization_id"), int64(orgID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (r *orgSmartHostResource) lookup(s string) (orgID, id int, err error) {
+	var ok bool
+	var org, address string
+
+	if org, address, err = splitImportID(s, "<organization>/<smarthost>"); err != nil {
+		return
+	}
+
+	if orgID, err = lookupOrganization(r.client, org); err != nil {
+		return
+	}
+
+	if id, ok = parseID(address); ok {
+		return
+	}
+
+	if err = api.EachPage(func(opts *api.ListOptions) (links api.Links, done bool, err error) {
+		var l *api.OrgSmartHostList
+		if l, err = r.client.GetOrgSmartHosts(orgID, opts); err != nil {
+			return
+		}
+		for _, h := range l.Items {
+			if h.Address == address {
+				id = h.ID
+				break
+			}
+		}
+		links, done = l.Links, id > 0 || len(l.Items) == 0
+		return
+	}); err != nil || id > 0 {
+		return
+	}
+
+	err = fmt.Errorf(notFoundError, "organization smarthost", address)
+
+	return
+}
+
+func (m *orgSmartHostModel) toAPI() *api.OrgSmartHost {
+	return &api.OrgSmartHost{
+		ID:          intValue(m.ID),
+		Address:     m.Address.ValueString(),
+		Port:        intValue(m.Port),
+		Username:    m.Username.ValueString(),
+		Password:    m.Password.ValueString(),
+		RequireTLS:  m.RequireTLS.ValueBool(),
+		Enabled:     m.Enabled.ValueBool(),
+		Description: m.Description.ValueString(),
+	}
+}
+
+// fromAPI sets the model from a smarthost, the password is kept as
+// the server does not return it
+func (m *orgSmartHostModel) fromAPI(s *api.OrgSmartHost) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Port = int64Value(s.Port)
+	m.Username = types.StringValue(s.Username)
+	m.RequireTLS = types.BoolValue(s.RequireTLS)
+	m.Enabled = types.BoolValue(s.Enabled)
+	m.Description = types.StringValue(s.Description)
+}
@@ -0,0 +1,115 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestOrgSmartHostResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	orgID := s.organization(t, "Example")
+	h := newHarness(t, s, newOrgSmartHostResource())
+	state := h.create(&orgSmartHostModel{
+		ID:             types.Int64Unknown(),
+		OrganizationID: int64Value(orgID),
+		Address:        types.StringValue("smtp.example.com"),
+		Port:           types.Int64Value(25),
+		Username:       types.StringValue("relay"),
+		Password:       types.StringValue("secret"),
+		RequireTLS:     types.BoolValue(false),
+		Enabled:        types.BoolValue(true),
+		Description:    types.StringValue(""),
+	})
+
+	var m orgSmartHostModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+
+	plan := m
+	plan.Port = types.Int64Value(587)
+	plan.RequireTLS = types.BoolValue(true)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the smarthost to exist")
+	}
+	h.get(state, &m)
+	if m.Port.ValueInt64() != 587 {
+		t.Errorf("Expected %d got %d", 587, m.Port.ValueInt64())
+	}
+	if m.Password.ValueString() != "secret" {
+		t.Errorf("Expected the password to be kept got %s", m.Password)
+	}
+
+	for _, id := range []string{fmt.Sprintf("%d/%d", orgID, m.ID.ValueInt64()), "Example/smtp.example.com"} {
+		var i orgSmartHostModel
+		h.get(h.importState(id), &i)
+		i.Password = m.Password
+		if i != m {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the smarthost to be removed from the state")
+	}
+}
+
+func TestAccOrgSmartHostResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := `
+resource "baruwa_organization" "test" {
+  name = "Example"
+}
+
+resource "baruwa_organization_smarthost" "test" {
+  organization_id = baruwa_organization.test.id
+  address         = "smtp.example.com"
+  username        = "relay"
+  password        = "secret"
+  port            = %d
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "orgsmarthosts"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 25)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_organization_smarthost.test", "address", "smtp.example.com"),
+					resource.TestCheckResourceAttr("baruwa_organization_smarthost.test", "port", "25"),
+					resource.TestCheckResourceAttr("baruwa_organization_smarthost.test", "password", "secret"),
+				),
+			},
+			{
+				ResourceName:            "baruwa_organization_smarthost.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccImportID("baruwa_organization_smarthost.test", "Example/%s", "address"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 587)),
+				Check:  resource.TestCheckResourceAttr("baruwa_organization_smarthost.test", "port", "587"),
+			},
+		},
+	})
+}
@@ -0,0 +1,116 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestOrganizationResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	h := newHarness(t, s, newOrganizationResource())
+	state := h.create(&organizationModel{ID: types.Int64Unknown(), Name: types.StringValue("Example")})
+
+	var m organizationModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+
+	state = h.update(state, &organizationModel{ID: m.ID, Name: types.StringValue("Example Inc")})
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the organization to exist")
+	}
+	h.get(state, &m)
+	if m.Name.ValueString() != "Example Inc" {
+		t.Errorf("Expected %s got %s", "Example Inc", m.Name)
+	}
+
+	for _, id := range []string{fmt.Sprint(m.ID.ValueInt64()), "Example Inc"} {
+		var i organizationModel
+		h.get(h.importState(id), &i)
+		if i != m {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	if e := h.importError("Missing"); e != fmt.Sprintf(notFoundError, "organization", "Missing") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(notFoundError, "organization", "Missing"), e)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the organization to be removed from the state")
+	}
+}
+
+func TestAccOrganizationResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "organizations"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, `resource "baruwa_organization" "test" { name = "Example" }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_organization.test", "name", "Example"),
+					resource.TestCheckResourceAttrSet("baruwa_organization.test", "id"),
+				),
+			},
+			{
+				ResourceName:      "baruwa_organization.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "baruwa_organization.test",
+				ImportState:       true,
+				ImportStateId:     "Example",
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccConfig(s, `resource "baruwa_organization" "test" { name = "Example Inc" }`),
+				Check:  resource.TestCheckResourceAttr("baruwa_organization.test", "name", "Example Inc"),
+			},
+		},
+	})
+}
+
+// testAccCheckDestroyed checks that the stand-in holds no objects of kind
+func testAccCheckDestroyed(s *standIn, kind string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		if n := s.count(kind); n != 0 {
+			return fmt.Errorf("Expected %d %s got %d", 0, kind, n)
+		}
+		return nil
+	}
+}
+
+// testAccImportID returns an import ID built from attributes of name
+func testAccImportID(name, format string, attrs ...string) resource.ImportStateIdFunc {
+	return func(st *terraform.State) (string, error) {
+		rs, ok := st.RootModule().Resources[name]
+		if !ok {
+			return "", fmt.Errorf("Resource %s not found", name)
+		}
+		var values []interface{}
+		for _, a := range attrs {
+			values = append(values, rs.Primary.Attributes[a])
+		}
+		return fmt.Sprintf(format, values...), nil
+	}
+}
@@ -0,0 +1,145 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package provider Terraform provider for Baruwa
+
+The provider manages Baruwa organizations, domains and users along with
+their mail routing and authentication settings using api.Client.
+
+	provider "baruwa" {
+	  endpoint = "https://baruwa.example.com"
+	  token    = var.baruwa_token
+	}
+*/
+package provider
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	// EndpointEnv is used when the endpoint is not configured
+	EndpointEnv = "BARUWA_ENDPOINT"
+	// TokenEnv is used when the token is not configured
+	TokenEnv = "BARUWA_TOKEN"
+)
+
+var _ provider.Provider = (*baruwaProvider)(nil)
+
+type baruwaProvider struct {
+	version string
+}
+
+type providerModel struct {
+	Endpoint types.String `tfsdk:"endpoint"`
+	Token    types.String `tfsdk:"token"`
+}
+
+// New returns a function that creates the provider
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &baruwaProvider{version: version}
+	}
+}
+
+func (p *baruwaProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "baruwa"
+	resp.Version = p.version
+}
+
+func (p *baruwaProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages Baruwa organizations, domains and users.",
+		Attributes: map[string]schema.Attribute{
+			"endpoint": schema.StringAttribute{
+				Description: "The Baruwa server URL, defaults to the " + EndpointEnv + " environment variable.",
+				Optional:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "The OAuth2 access token, defaults to the " + TokenEnv + " environment variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (p *baruwaProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var config providerModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	endpoint := os.Getenv(EndpointEnv)
+	if !config.Endpoint.IsNull() && !config.Endpoint.IsUnknown() {
+		endpoint = config.Endpoint.ValueString()
+	}
+
+	token := os.Getenv(TokenEnv)
+	if !config.Token.IsNull() && !config.Token.IsUnknown() {
+		token = config.Token.ValueString()
+	}
+
+	if endpoint == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("endpoint"), "Missing Baruwa endpoint",
+			"Set the endpoint in the provider configuration or the "+EndpointEnv+" environment variable.")
+	}
+
+	if token == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("token"), "Missing Baruwa token",
+			"Set the token in the provider configuration or the "+TokenEnv+" environment variable.")
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, err := api.New(endpoint, token, &api.Options{
+		HTTPClient: &http.Client{},
+		UserAgent:  "terraform-provider-baruwa/" + p.version,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to create the Baruwa client", err.Error())
+		return
+	}
+
+	resp.ResourceData = client
+	resp.DataSourceData = client
+}
+
+func (p *baruwaProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		newOrganizationResource,
+		newDomainResource,
+		newDomainAliasResource,
+		newDomainSmartHostResource,
+		newOrgSmartHostResource,
+		newDeliveryServerResource,
+		newFallbackServerResource,
+		newRelayResource,
+		newAuthServerResource,
+		newUserResource,
+		newAliasAddressResource,
+	}
+}
+
+func (p *baruwaProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
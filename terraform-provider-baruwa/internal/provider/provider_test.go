@@ -0,0 +1,221 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// the acceptance tests run when TF_ACC is set and a terraform binary
+// is available, they use the stand-in server so no Baruwa server is
+// required
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"baruwa": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+func testAccConfig(s *standIn, config string) string {
+	return fmt.Sprintf(`
+provider "baruwa" {
+  endpoint = %q
+  token    = %q
+}
+%s`, s.URL, standInToken, config)
+}
+
+// harness drives the CRUD methods of a resource directly so that the
+// resources are tested without a terraform binary
+type harness struct {
+	t   *testing.T
+	ctx context.Context
+	r   resource.Resource
+	typ tftypes.Type
+	res resource.SchemaResponse
+}
+
+func newHarness(t *testing.T, s *standIn, r resource.Resource) *harness {
+	h := &harness{t: t, ctx: context.Background(), r: r}
+
+	r.Schema(h.ctx, resource.SchemaRequest{}, &h.res)
+	if h.res.Diagnostics.HasError() {
+		t.Fatalf("An error should not be returned: %v", h.res.Diagnostics)
+	}
+	h.typ = h.res.Schema.Type().TerraformType(h.ctx)
+
+	cresp := &resource.ConfigureResponse{}
+	r.(resource.ResourceWithConfigure).Configure(h.ctx, resource.ConfigureRequest{ProviderData: s.client()}, cresp)
+	if cresp.Diagnostics.HasError() {
+		t.Fatalf("An error should not be returned: %v", cresp.Diagnostics)
+	}
+
+	return h
+}
+
+func (h *harness) state() tfsdk.State {
+	return tfsdk.State{Schema: h.res.Schema, Raw: tftypes.NewValue(h.typ, nil)}
+}
+
+func (h *harness) plan(model interface{}) tfsdk.Plan {
+	p := tfsdk.Plan{Schema: h.res.Schema, Raw: tftypes.NewValue(h.typ, nil)}
+	if diags := p.Set(h.ctx, model); diags.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", diags)
+	}
+
+	return p
+}
+
+func (h *harness) create(model interface{}) tfsdk.State {
+	resp := &resource.CreateResponse{State: h.state()}
+	h.r.Create(h.ctx, resource.CreateRequest{Plan: h.plan(model)}, resp)
+	if resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+
+	return resp.State
+}
+
+// read returns the refreshed state and whether the resource exists
+func (h *harness) read(state tfsdk.State) (tfsdk.State, bool) {
+	resp := &resource.ReadResponse{State: state}
+	h.r.Read(h.ctx, resource.ReadRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+
+	return resp.State, !resp.State.Raw.IsNull()
+}
+
+func (h *harness) update(state tfsdk.State, model interface{}) tfsdk.State {
+	plan := h.plan(model)
+	resp := &resource.UpdateResponse{State: state}
+	h.r.Update(h.ctx, resource.UpdateRequest{Plan: plan, State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+
+	return resp.State
+}
+
+func (h *harness) delete(state tfsdk.State) {
+	resp := &resource.DeleteResponse{State: state}
+	h.r.Delete(h.ctx, resource.DeleteRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+}
+
+// importState imports and refreshes a resource
+func (h *harness) importState(id string) tfsdk.State {
+	resp := &resource.ImportStateResponse{State: h.state()}
+	h.r.(resource.ResourceWithImportState).ImportState(h.ctx, resource.ImportStateRequest{ID: id}, resp)
+	if resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+
+	state, ok := h.read(resp.State)
+	if !ok {
+		h.t.Fatalf("Expected the imported resource %s to exist", id)
+	}
+
+	return state
+}
+
+// importError returns the error summary of a failed import
+func (h *harness) importError(id string) string {
+	resp := &resource.ImportStateResponse{State: h.state()}
+	h.r.(resource.ResourceWithImportState).ImportState(h.ctx, resource.ImportStateRequest{ID: id}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.t.Fatalf("An error should be returned importing %s", id)
+	}
+
+	return resp.Diagnostics.Errors()[0].Detail()
+}
+
+func (h *harness) get(state tfsdk.State, model interface{}) {
+	if diags := state.Get(h.ctx, model); diags.HasError() {
+		h.t.Fatalf("An error should not be returned: %v", diags)
+	}
+}
+
+func TestProviderSchemas(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")()
+
+	presp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, presp)
+	if diags := presp.Schema.ValidateImplementation(ctx); diags.HasError() {
+		t.Fatalf("An error should not be returned: %v", diags)
+	}
+
+	names := make(map[string]bool)
+	for _, fn := range p.Resources(ctx) {
+		r := fn()
+		mresp := &resource.MetadataResponse{}
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "baruwa"}, mresp)
+		if names[mresp.TypeName] {
+			t.Errorf("Duplicate resource %s", mresp.TypeName)
+		}
+		names[mresp.TypeName] = true
+		sresp := &resource.SchemaResponse{}
+		r.Schema(ctx, resource.SchemaRequest{}, sresp)
+		if diags := sresp.Schema.ValidateImplementation(ctx); diags.HasError() {
+			t.Errorf("%s: an error should not be returned: %v", mresp.TypeName, diags)
+		}
+		if _, ok := r.(resource.ResourceWithImportState); !ok {
+			t.Errorf("%s: Expected import support", mresp.TypeName)
+		}
+	}
+	if len(names) != 11 {
+		t.Errorf("Expected %d got %d", 11, len(names))
+	}
+}
+
+func TestProviderConfigure(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")()
+
+	sresp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, sresp)
+	typ := sresp.Schema.Type().TerraformType(ctx)
+
+	config := func(endpoint, token interface{}) tfsdk.Config {
+		return tfsdk.Config{
+			Schema: sresp.Schema,
+			Raw: tftypes.NewValue(typ, map[string]tftypes.Value{
+				"endpoint": tftypes.NewValue(tftypes.String, endpoint),
+				"token":    tftypes.NewValue(tftypes.String, token),
+			}),
+		}
+	}
+
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(TokenEnv, "")
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: config(nil, nil)}, resp)
+	if resp.Diagnostics.ErrorsCount() != 2 {
+		t.Errorf("Expected %d got %d", 2, resp.Diagnostics.ErrorsCount())
+	}
+
+	t.Setenv(TokenEnv, standInToken)
+	resp = &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: config("http://baruwa.example.com", nil)}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("An error should not be returned: %v", resp.Diagnostics)
+	}
+	if resp.ResourceData == nil {
+		t.Errorf("Expected the client to be passed to the resources")
+	}
+}
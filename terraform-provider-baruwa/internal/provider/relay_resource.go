@@ -0,0 +1,209 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*relayResource)(nil)
+	_ resource.ResourceWithImportState = (*relayResource)(nil)
+)
+
+type relayResource struct {
+	baseResource
+}
+
+type relayModel struct {
+	ID              types.Int64   `tfsdk:"id"`
+	OrganizationID  types.Int64   `tfsdk:"organization_id"`
+	Address         types.String  `tfsdk:"address"`
+	Username        types.String  `tfsdk:"username"`
+	Password        types.String  `tfsdk:"password"`
+	Enabled         types.Bool    `tfsdk:"enabled"`
+	RequireTLS      types.Bool    `tfsdk:"require_tls"`
+	Description     types.String  `tfsdk:"description"`
+	LowScore        types.Float64 `tfsdk:"low_score"`
+	HighScore       types.Float64 `tfsdk:"high_score"`
+	SpamActions     types.Int64   `tfsdk:"spam_actions"`
+	HighSpamActions types.Int64   `tfsdk:"highspam_actions"`
+	BlockMacros     types.Bool    `tfsdk:"block_macros"`
+	RateLimit       types.Int64   `tfsdk:"rate_limit"`
+	AllowAllSenders types.Bool    `tfsdk:"allow_all_senders"`
+}
+
+func newRelayResource() resource.Resource {
+	return &relayResource{}
+}
+
+func (r *relayResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_relay"
+}
+
+func (r *relayResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A host or account allowed to relay outbound mail through an organization. Import using <organization>/<relay ID> where the organization is an ID or a name.",
+		Attributes: map[string]schema.Attribute{
+			"id":                idAttribute(),
+			"organization_id":   parentAttribute("The ID of the organization."),
+			"address":           defaultString("The IP address or network allowed to relay, empty when SMTP AUTH is used.", ""),
+			"username":          defaultString("The SMTP AUTH username.", ""),
+			"password":          secretString("The SMTP AUTH password."),
+			"enabled":           defaultBool("Whether relaying is enabled.", true),
+			"require_tls":       defaultBool("Whether TLS is required.", false),
+			"description":       defaultString("A description of the relay.", ""),
+			"low_score":         defaultFloat64("The spam score, 0 uses the system default.", 0),
+			"high_score":        defaultFloat64("The high spam score, 0 uses the system default.", 0),
+			"spam_actions":      defaultInt64("The action taken on spam.", 2),
+			"highspam_actions":  defaultInt64("The action taken on high scoring spam.", 2),
+			"block_macros":      defaultBool("Whether documents with macros are blocked.", false),
+			"rate_limit":        defaultInt64("The number of messages allowed per hour.", 250),
+			"allow_all_senders": defaultBool("Whether any sender address is allowed.", false),
+		},
+	}
+}
+
+func (r *relayResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan relayModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	relay := plan.toAPI()
+	if err := r.client.CreateRelaySetting(intValue(plan.OrganizationID), relay); err != nil {
+		addError(&resp.Diagnostics, "create", "relay", err)
+		return
+	}
+
+	plan.fromAPI(relay)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *relayResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state relayModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	relay, err := r.client.GetRelaySetting(intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "relay", err)
+		return
+	}
+
+	state.fromAPI(relay)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *relayResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan relayModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateRelaySetting(plan.toAPI()); err != nil {
+		addError(&resp.Diagnostics, "update", "relay", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *relayResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state relayModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteRelaySetting(state.toAPI()); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "relay", err)
+	}
+}
+
+// ImportState imports a relay, the API has no relay listing so the
+// relay is identified by its ID
+func (r *relayResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	org, relay, err := splitImportID(req.ID, "<organization>/<relay ID>")
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "relay", err)
+		return
+	}
+
+	orgID, err := lookupOrganization(r.client, org)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "relay", err)
+		return
+	}
+
+	id, ok := parseID(relay)
+	if !ok {
+		addError(&resp.Diagnostics, "import", "relay", fmt.Errorf(importIDError, "<organization>/<relay ID>", req.ID))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("organization_id"), int64(orgID))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+func (m *relayModel) toAPI() *api.RelaySetting {
+	return &api.RelaySetting{
+		ID:              intValue(m.ID),
+		Address:         m.Address.ValueString(),
+		Username:        m.Username.ValueString(),
+		Password1:       m.Password.ValueString(),
+		Password2:       m.Password.ValueString(),
+		Enabled:         m.Enabled.ValueBool(),
+		RequireTLS:      m.RequireTLS.ValueBool(),
+		Description:     m.Description.ValueString(),
+		LowScore:        floatValue(m.LowScore),
+		HighScore:       floatValue(m.HighScore),
+		SpamActions:     intValue(m.SpamActions),
+		HighSpamActions: intValue(m.HighSpamActions),
+		BlockMacros:     m.BlockMacros.ValueBool(),
+		RateLimit:       intValue(m.RateLimit),
+		AllowAllSenders: m.AllowAllSenders.ValueBool(),
+	}
+}
+
+// fromAPI sets the model from a relay, the password is kept as the
+// server does not return it
+func (m *relayModel) fromAPI(s *api.RelaySetting) {
+	m.ID = int64Value(s.ID)
+	m.Address = types.StringValue(s.Address)
+	m.Username = types.StringValue(s.Username)
+	m.Enabled = types.BoolValue(s.Enabled)
+	m.RequireTLS = types.BoolValue(s.RequireTLS)
+	m.Description = types.StringValue(s.Description)
+	m.LowScore = float64Value(s.LowScore)
+	m.HighScore = float64Value(s.HighScore)
+	m.SpamActions = int64Value(s.SpamActions)
+	m.HighSpamActions = int64Value(s.HighSpamActions)
+	m.BlockMacros = types.BoolValue(s.BlockMacros)
+	m.RateLimit = int64Value(s.RateLimit)
+	m.AllowAllSenders = types.BoolValue(s.AllowAllSenders)
+}
@@ -0,0 +1,122 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestRelayResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	orgID := s.organization(t, "Example")
+	h := newHarness(t, s, newRelayResource())
+	state := h.create(&relayModel{
+		ID:              types.Int64Unknown(),
+		OrganizationID:  int64Value(orgID),
+		Address:         types.StringValue("192.168.1.0/24"),
+		Username:        types.StringValue(""),
+		Password:        types.StringNull(),
+		Enabled:         types.BoolValue(true),
+		RequireTLS:      types.BoolValue(false),
+		Description:     types.StringValue("Office"),
+		LowScore:        types.Float64Value(0),
+		HighScore:       types.Float64Value(0),
+		SpamActions:     types.Int64Value(2),
+		HighSpamActions: types.Int64Value(2),
+		BlockMacros:     types.BoolValue(false),
+		RateLimit:       types.Int64Value(250),
+		AllowAllSenders: types.BoolValue(false),
+	})
+
+	var m relayModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+
+	plan := m
+	plan.RateLimit = types.Int64Value(500)
+	plan.LowScore = types.Float64Value(5.5)
+	state = h.update(state, &plan)
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the relay to exist")
+	}
+	h.get(state, &m)
+	if m.RateLimit.ValueInt64() != 500 {
+		t.Errorf("Expected %d got %d", 500, m.RateLimit.ValueInt64())
+	}
+	if m.LowScore.ValueFloat64() != 5.5 {
+		t.Errorf("Expected %f got %f", 5.5, m.LowScore.ValueFloat64())
+	}
+
+	for _, id := range []string{fmt.Sprintf("%d/%d", orgID, m.ID.ValueInt64()), fmt.Sprintf("Example/%d", m.ID.ValueInt64())} {
+		var i relayModel
+		h.get(h.importState(id), &i)
+		if fmt.Sprint(i) != fmt.Sprint(m) {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+	if e := h.importError("Example/192.168.1.0"); e != fmt.Sprintf(importIDError, "<organization>/<relay ID>", "Example/192.168.1.0") {
+		t.Errorf("Expected '%s' got '%s'", fmt.Sprintf(importIDError, "<organization>/<relay ID>", "Example/192.168.1.0"), e)
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the relay to be removed from the state")
+	}
+}
+
+func TestAccRelayResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := `
+resource "baruwa_organization" "test" {
+  name = "Example"
+}
+
+resource "baruwa_relay" "test" {
+  organization_id = baruwa_organization.test.id
+  username        = "outbound"
+  password        = "secret"
+  rate_limit      = %d
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "relays"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 250)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_relay.test", "username", "outbound"),
+					resource.TestCheckResourceAttr("baruwa_relay.test", "address", ""),
+					resource.TestCheckResourceAttr("baruwa_relay.test", "spam_actions", "2"),
+				),
+			},
+			{
+				ResourceName:            "baruwa_relay.test",
+				ImportState:             true,
+				ImportStateIdFunc:       testAccImportID("baruwa_relay.test", "Example/%s", "id"),
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, 500)),
+				Check:  resource.TestCheckResourceAttr("baruwa_relay.test", "rate_limit", "500"),
+			},
+		},
+	})
+}
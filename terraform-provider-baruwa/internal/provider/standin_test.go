@@ -0,0 +1,389 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const standInToken = "test-token"
+
+// kinds maps the stand-in collections to the api types they store
+var kinds = map[string]reflect.Type{
+	"organizations":    reflect.TypeOf(api.Organization{}),
+	"domains":          reflect.TypeOf(api.Domain{}),
+	"domainaliases":    reflect.TypeOf(api.DomainAlias{}),
+	"domainsmarthosts": reflect.TypeOf(api.DomainSmartHost{}),
+	"orgsmarthosts":    reflect.TypeOf(api.OrgSmartHost{}),
+	"deliveryservers":  reflect.TypeOf(api.DomainDeliveryServer{}),
+	"fallbackservers":  reflect.TypeOf(api.FallBackServer{}),
+	"relays":           reflect.TypeOf(api.RelaySetting{}),
+	"authservers":      reflect.TypeOf(api.AuthServer{}),
+	"ldapsettings":     reflect.TypeOf(api.LDAPSettings{}),
+	"radiussettings":   reflect.TypeOf(api.RadiusSettings{}),
+	"users":            reflect.TypeOf(api.User{}),
+	"aliasaddresses":   reflect.TypeOf(api.AliasAddress{}),
+}
+
+// write only fields that a Baruwa server does not return
+var secretFields = []string{"Password", "Password1", "Password2", "BindPw", "Secret"}
+
+type route struct {
+	re     *regexp.Regexp
+	method string
+	kind   string
+	op     string
+	parent int
+	id     int
+}
+
+// routes follow the paths used by api.Client, the parent and id are
+// regexp group numbers
+var routes = []route{
+	{re: regexp.MustCompile(`^organizations$`), method: "GET", kind: "organizations", op: "list"},
+	{re: regexp.MustCompile(`^organizations$`), method: "POST", kind: "organizations", op: "create"},
+	{re: regexp.MustCompile(`^organizations/(\d+)$`), kind: "organizations", op: "item", id: 1},
+	{re: regexp.MustCompile(`^organizations/smarthosts/(\d+)$`), method: "GET", kind: "orgsmarthosts", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^organizations/smarthosts/(\d+)$`), method: "POST", kind: "orgsmarthosts", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^organizations/smarthosts/(\d+)/(\d+)$`), kind: "orgsmarthosts", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^domains$`), method: "GET", kind: "domains", op: "list"},
+	{re: regexp.MustCompile(`^domains$`), method: "POST", kind: "domains", op: "create"},
+	{re: regexp.MustCompile(`^domains/byname/(.+)$`), method: "GET", kind: "domains", op: "byname", id: 1},
+	{re: regexp.MustCompile(`^domains/(\d+)$`), kind: "domains", op: "item", id: 1},
+	{re: regexp.MustCompile(`^domains/smarthosts/(\d+)$`), method: "GET", kind: "domainsmarthosts", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^domains/smarthosts/(\d+)$`), method: "POST", kind: "domainsmarthosts", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^domains/smarthosts/(\d+)/(\d+)$`), kind: "domainsmarthosts", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^domainaliases/(\d+)$`), method: "GET", kind: "domainaliases", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^domainaliases/(\d+)$`), method: "POST", kind: "domainaliases", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^domainaliases/(\d+)/(\d+)$`), kind: "domainaliases", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^deliveryservers/(\d+)$`), method: "GET", kind: "deliveryservers", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^deliveryservers/(\d+)$`), method: "POST", kind: "deliveryservers", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^deliveryservers/(\d+)/(\d+)$`), kind: "deliveryservers", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^fallbackservers/list/(\d+)$`), method: "GET", kind: "fallbackservers", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^fallbackservers/(\d+)$`), method: "POST", kind: "fallbackservers", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^fallbackservers/(\d+)$`), kind: "fallbackservers", op: "item", id: 1},
+	{re: regexp.MustCompile(`^relays/(\d+)$`), method: "POST", kind: "relays", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^relays/(\d+)$`), kind: "relays", op: "item", id: 1},
+	{re: regexp.MustCompile(`^authservers/(\d+)$`), method: "GET", kind: "authservers", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^authservers/(\d+)$`), method: "POST", kind: "authservers", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^authservers/(\d+)/(\d+)$`), kind: "authservers", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^ldapsettings/\d+/(\d+)$`), method: "POST", kind: "ldapsettings", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^ldapsettings/\d+/(\d+)/(\d+)$`), kind: "ldapsettings", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^radiussettings/\d+/(\d+)$`), method: "POST", kind: "radiussettings", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^radiussettings/\d+/(\d+)/(\d+)$`), kind: "radiussettings", op: "item", parent: 1, id: 2},
+	{re: regexp.MustCompile(`^users$`), method: "GET", kind: "users", op: "list"},
+	{re: regexp.MustCompile(`^users$`), method: "POST", kind: "users", op: "create"},
+	{re: regexp.MustCompile(`^users/chpw/(\d+)$`), method: "POST", kind: "users", op: "chpw", id: 1},
+	{re: regexp.MustCompile(`^users/byname/(.+)$`), method: "GET", kind: "users", op: "byname", id: 1},
+	{re: regexp.MustCompile(`^users/(\d+)$`), kind: "users", op: "item", id: 1},
+	{re: regexp.MustCompile(`^aliasaddresses/list/(\d+)$`), method: "GET", kind: "aliasaddresses", op: "list", parent: 1},
+	{re: regexp.MustCompile(`^aliasaddresses/(\d+)$`), method: "POST", kind: "aliasaddresses", op: "create", parent: 1},
+	{re: regexp.MustCompile(`^aliasaddresses/(\d+)$`), kind: "aliasaddresses", op: "item", id: 1},
+}
+
+type object struct {
+	kind   string
+	parent int
+	value  reflect.Value
+}
+
+// standIn is an in-memory Baruwa server
+type standIn struct {
+	*httptest.Server
+	mu              sync.Mutex
+	lastID          int
+	objects         map[int]*object
+	passwordChanges int
+}
+
+func newStandIn() *standIn {
+	s := &standIn{objects: make(map[int]*object)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *standIn) client() *api.Client {
+	c, _ := api.New(s.URL, standInToken, &api.Options{HTTPClient: s.Client()})
+
+	return c
+}
+
+// count returns the number of objects of a kind
+func (s *standIn) count(kind string) (n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range s.objects {
+		if o.kind == kind {
+			n++
+		}
+	}
+
+	return
+}
+
+// organization adds an organization for resources to belong to
+func (s *standIn) organization(t *testing.T, name string) int {
+	org, err := s.client().CreateOrganization(&api.OrganizationForm{Name: name})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	return org.ID
+}
+
+// domain adds a domain for resources to belong to
+func (s *standIn) domain(t *testing.T, name string) int {
+	domain := &api.Domain{Name: name, SiteURL: "https://mail." + name}
+	if err := s.client().CreateDomain(domain); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	return domain.ID
+}
+
+// user adds a user for resources to belong to
+func (s *standIn) user(t *testing.T, username string) int {
+	email := username + "@example.com"
+	user, err := s.client().CreateUser(&api.UserForm{Username: &username, Email: &email})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	return user.ID
+}
+
+func (s *standIn) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("Authorization") != "Bearer "+standInToken {
+		writeError(w, http.StatusUnauthorized)
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/api/"+api.APIVersion+"/")
+	for _, rt := range routes {
+		m := rt.re.FindStringSubmatch(p)
+		if m == nil || (rt.method != "" && rt.method != r.Method) {
+			continue
+		}
+		parent, id := 0, 0
+		if rt.parent > 0 {
+			parent, _ = strconv.Atoi(m[rt.parent])
+		}
+		if rt.id > 0 && rt.op != "byname" {
+			id, _ = strconv.Atoi(m[rt.id])
+		}
+		switch rt.op {
+		case "list":
+			s.list(w, rt.kind, parent)
+		case "create":
+			s.create(w, r, rt.kind, parent)
+		case "chpw":
+			s.changePassword(w, id)
+		case "byname":
+			name, _ := url.PathUnescape(m[rt.id])
+			s.byName(w, rt.kind, name)
+		default:
+			s.item(w, r, rt.kind, parent, id)
+		}
+		return
+	}
+
+	writeError(w, http.StatusNotFound)
+}
+
+func (s *standIn) list(w http.ResponseWriter, kind string, parent int) {
+	var ids []int
+
+	items := []interface{}{}
+	for id, o := range s.objects {
+		if o.kind == kind && o.parent == parent {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+	for _, id := range ids {
+		items = append(items, response(s.objects[id].value))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items": items,
+		"links": api.Links{},
+		"meta":  api.Meta{Total: len(items)},
+	})
+}
+
+func (s *standIn) create(w http.ResponseWriter, r *http.Request, kind string, parent int) {
+	r.ParseForm()
+
+	s.lastID++
+	v := reflect.New(kinds[kind])
+	decodeForm(r.PostForm, v.Elem())
+	v.Elem().FieldByName("ID").SetInt(int64(s.lastID))
+	for i := 0; i < v.Elem().NumField(); i++ {
+		if f := v.Elem().Field(i); f.Type() == reflect.TypeOf(api.MyTime{}) {
+			f.Set(reflect.ValueOf(api.MyTime{Time: time.Now()}))
+		}
+	}
+	for _, name := range []string{"Domain", "Organization"} {
+		if f := v.Elem().FieldByName(name); parent > 0 && f.IsValid() && f.Kind() == reflect.Ptr {
+			f.Set(reflect.New(f.Type().Elem()))
+			setRef(f.Elem(), strconv.Itoa(parent))
+		}
+	}
+	s.objects[s.lastID] = &object{kind: kind, parent: parent, value: v}
+
+	writeJSON(w, http.StatusCreated, response(v))
+}
+
+func (s *standIn) byName(w http.ResponseWriter, kind, name string) {
+	field := "Name"
+	if kind == "users" {
+		field = "Username"
+	}
+
+	for _, o := range s.objects {
+		if o.kind == kind && o.value.Elem().FieldByName(field).String() == name {
+			writeJSON(w, http.StatusOK, response(o.value))
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound)
+}
+
+func (s *standIn) changePassword(w http.ResponseWriter, id int) {
+	if o, ok := s.objects[id]; !ok || o.kind != "users" {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	s.passwordChanges++
+	writeJSON(w, http.StatusOK, map[string]string{"code": "200"})
+}
+
+func (s *standIn) item(w http.ResponseWriter, r *http.Request, kind string, parent, id int) {
+	o, ok := s.objects[id]
+	if !ok || o.kind != kind || (parent > 0 && o.parent != parent) {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, response(o.value))
+	case http.MethodPut:
+		r.ParseForm()
+		decodeForm(r.PostForm, o.value.Elem())
+		o.value.Elem().FieldByName("ID").SetInt(int64(id))
+		writeJSON(w, http.StatusOK, response(o.value))
+	case http.MethodDelete:
+		delete(s.objects, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed)
+	}
+}
+
+// response returns a copy of the stored value without its secrets
+func response(v reflect.Value) interface{} {
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	for _, name := range secretFields {
+		if f := c.Elem().FieldByName(name); f.IsValid() {
+			f.SetString("")
+		}
+	}
+
+	return c.Interface()
+}
+
+// decodeForm sets the fields of dst from the form values using the url
+// struct tags, fields missing from the form are left unchanged
+func decodeForm(form url.Values, dst reflect.Value) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("url"), ",")[0]
+		values, ok := form[name]
+		if name == "" || name == "id" || !ok {
+			continue
+		}
+		setField(dst.Field(i), values)
+	}
+}
+
+func setField(f reflect.Value, values []string) {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(values[0])
+	case reflect.Int:
+		n, _ := strconv.Atoi(values[0])
+		f.SetInt(int64(n))
+	case reflect.Bool:
+		b, _ := strconv.ParseBool(values[0])
+		f.SetBool(b)
+	case reflect.Float64:
+		n, _ := strconv.ParseFloat(values[0], 64)
+		f.SetFloat(n)
+	case reflect.Ptr:
+		// references such as the domain of a domain alias
+		if id := f.Type().Elem(); id.Kind() == reflect.Struct {
+			v := reflect.New(id)
+			setRef(v.Elem(), values[0])
+			f.Set(v)
+		}
+	case reflect.Slice:
+		s := reflect.MakeSlice(f.Type(), len(values), len(values))
+		for i, val := range values {
+			if s.Index(i).Kind() == reflect.Struct {
+				setRef(s.Index(i), val)
+				continue
+			}
+			n, _ := strconv.Atoi(val)
+			s.Index(i).SetInt(int64(n))
+		}
+		f.Set(s)
+	}
+}
+
+func setRef(v reflect.Value, val string) {
+	n, _ := strconv.Atoi(val)
+	if f := v.FieldByName("ID"); f.IsValid() {
+		f.SetInt(int64(n))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int) {
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `{"code": %d, "message": "%s"}`, code, http.StatusText(code))
+}
@@ -0,0 +1,299 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.ResourceWithConfigure   = (*userResource)(nil)
+	_ resource.ResourceWithImportState = (*userResource)(nil)
+)
+
+type userResource struct {
+	baseResource
+}
+
+type userModel struct {
+	ID            types.Int64   `tfsdk:"id"`
+	Username      types.String  `tfsdk:"username"`
+	Email         types.String  `tfsdk:"email"`
+	Firstname     types.String  `tfsdk:"firstname"`
+	Lastname      types.String  `tfsdk:"lastname"`
+	Password      types.String  `tfsdk:"password"`
+	Timezone      types.String  `tfsdk:"timezone"`
+	AccountType   types.Int64   `tfsdk:"account_type"`
+	Enabled       types.Bool    `tfsdk:"enabled"`
+	SendReport    types.Bool    `tfsdk:"send_report"`
+	SpamChecks    types.Bool    `tfsdk:"spam_checks"`
+	LowScore      types.Float64 `tfsdk:"low_score"`
+	HighScore     types.Float64 `tfsdk:"high_score"`
+	BlockMacros   types.Bool    `tfsdk:"block_macros"`
+	Domains       types.List    `tfsdk:"domains"`
+	Organizations types.List    `tfsdk:"organizations"`
+}
+
+func newUserResource() resource.Resource {
+	return &userResource{}
+}
+
+func (r *userResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+func (r *userResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "A Baruwa user account. Import using the ID or the username.",
+		Attributes: map[string]schema.Attribute{
+			"id": idAttribute(),
+			"username": schema.StringAttribute{
+				Description: "The username.",
+				Required:    true,
+			},
+			"email": schema.StringAttribute{
+				Description: "The email address.",
+				Required:    true,
+			},
+			"firstname":    defaultString("The first name.", ""),
+			"lastname":     defaultString("The last name.", ""),
+			"password":     secretString("The password, changing it sets a new password."),
+			"timezone":     computedString("The timezone, the server default is used when unset."),
+			"account_type": defaultInt64("The account type, 1 is a superadmin, 2 a domain admin and 3 a user.", 3),
+			"enabled":      defaultBool("Whether the account is active.", true),
+			"send_report":  defaultBool("Whether quarantine reports are sent.", true),
+			"spam_checks":  defaultBool("Whether spam checks are run.", true),
+			"low_score":    defaultFloat64("The spam score, 0 uses the domain default.", 0),
+			"high_score":   defaultFloat64("The high spam score, 0 uses the domain default.", 0),
+			"block_macros": defaultBool("Whether documents with macros are blocked.", false),
+			"domains": schema.ListAttribute{
+				Description: "The IDs of the domains the user belongs to.",
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"organizations": schema.ListAttribute{
+				Description: "The IDs of the organizations a domain admin manages.",
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan userModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	form := plan.toAPI(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Password.IsNull() {
+		password := plan.Password.ValueString()
+		form.Password1, form.Password2 = &password, &password
+	}
+
+	user, err := r.client.CreateUser(form)
+	if err != nil {
+		addError(&resp.Diagnostics, "create", "user", err)
+		return
+	}
+
+	plan.fromAPI(user)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state userModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	user, err := r.client.GetUser(intValue(state.ID))
+	if api.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addError(&resp.Diagnostics, "read", "user", err)
+		return
+	}
+
+	state.fromAPI(user)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// Update updates a user, the password is set using the password change
+// endpoint as the user form ignores it on updates
+func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, prior userModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	form := plan.toAPI(ctx, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.UpdateUser(form); err != nil {
+		addError(&resp.Diagnostics, "update", "user", err)
+		return
+	}
+
+	if !plan.Password.IsNull() && !plan.Password.Equal(prior.Password) {
+		pw := &api.PasswordForm{Password1: plan.Password.ValueString(), Password2: plan.Password.ValueString()}
+		if err := r.client.ChangeUserPassword(intValue(plan.ID), pw); err != nil {
+			addError(&resp.Diagnostics, "change the password of", "user", err)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state userModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.client.DeleteUser(intValue(state.ID)); err != nil && !api.IsNotFound(err) {
+		addError(&resp.Diagnostics, "delete", "user", err)
+	}
+}
+
+func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, err := lookupUser(r.client, req.ID)
+	if err != nil {
+		addError(&resp.Diagnostics, "import", "user", err)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), int64(id))...)
+}
+
+// lookupUser returns the ID of a user given an ID or a username
+func lookupUser(client *api.Client, s string) (id int, err error) {
+	var ok bool
+	var user *api.User
+
+	if id, ok = parseID(s); ok {
+		return
+	}
+
+	if user, err = client.GetUserByUsername(s); err != nil {
+		return
+	}
+
+	id = user.ID
+
+	return
+}
+
+func (m *userModel) toAPI(ctx context.Context, diags *diag.Diagnostics) *api.UserForm {
+	id := intValue(m.ID)
+	username := m.Username.ValueString()
+	email := m.Email.ValueString()
+	firstname := m.Firstname.ValueString()
+	lastname := m.Lastname.ValueString()
+	accountType := intValue(m.AccountType)
+	enabled := m.Enabled.ValueBool()
+	sendReport := m.SendReport.ValueBool()
+	spamChecks := m.SpamChecks.ValueBool()
+	lowScore := floatValue(m.LowScore)
+	highScore := floatValue(m.HighScore)
+	blockMacros := m.BlockMacros.ValueBool()
+
+	form := &api.UserForm{
+		Username:      &username,
+		Email:         &email,
+		Firstname:     &firstname,
+		Lastname:      &lastname,
+		AccountType:   &accountType,
+		Enabled:       &enabled,
+		SendReport:    &sendReport,
+		SpamChecks:    &spamChecks,
+		LowScore:      &lowScore,
+		HighScore:     &highScore,
+		BlockMacros:   &blockMacros,
+		Domains:       intList(ctx, m.Domains, diags),
+		Organizations: intList(ctx, m.Organizations, diags),
+	}
+	if id > 0 {
+		form.ID = &id
+	}
+	if !m.Timezone.IsNull() && !m.Timezone.IsUnknown() {
+		timezone := m.Timezone.ValueString()
+		form.Timezone = &timezone
+	}
+
+	return form
+}
+
+// fromAPI sets the model from a user, the domains and organizations are
+// kept when the server does not return them
+func (m *userModel) fromAPI(u *api.User) {
+	m.ID = int64Value(u.ID)
+	m.Username = types.StringValue(u.Username)
+	m.Email = types.StringValue(u.Email)
+	m.Firstname = types.StringValue(u.Firstname)
+	m.Lastname = types.StringValue(u.Lastname)
+	m.Timezone = types.StringValue(u.Timezone)
+	m.AccountType = int64Value(u.AccountType)
+	m.Enabled = types.BoolValue(u.Enabled)
+	m.SendReport = types.BoolValue(u.SendReport)
+	m.SpamChecks = types.BoolValue(u.SpamChecks)
+	m.LowScore = float64Value(u.LowScore)
+	m.HighScore = float64Value(u.HighScore)
+	m.BlockMacros = types.BoolValue(u.BlockMacros)
+
+	if len(u.Domains) > 0 || m.Domains.IsNull() || m.Domains.IsUnknown() {
+		ids := make([]int, 0, len(u.Domains))
+		for _, d := range u.Domains {
+			ids = append(ids, d.ID)
+		}
+		m.Domains = int64List(ids)
+	}
+	if len(u.Organizations) > 0 || m.Organizations.IsNull() || m.Organizations.IsUnknown() {
+		ids := make([]int, 0, len(u.Organizations))
+		for _, o := range u.Organizations {
+			ids = append(ids, o.ID)
+		}
+		m.Organizations = int64List(ids)
+	}
+}
@@ -0,0 +1,129 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestUserResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	domainID := s.domain(t, "example.com")
+	h := newHarness(t, s, newUserResource())
+	state := h.create(&userModel{
+		ID:            types.Int64Unknown(),
+		Username:      types.StringValue("jdoe"),
+		Email:         types.StringValue("jdoe@example.com"),
+		Firstname:     types.StringValue("John"),
+		Lastname:      types.StringValue("Doe"),
+		Password:      types.StringValue("Passw0rd"),
+		Timezone:      types.StringUnknown(),
+		AccountType:   types.Int64Value(3),
+		Enabled:       types.BoolValue(true),
+		SendReport:    types.BoolValue(true),
+		SpamChecks:    types.BoolValue(true),
+		LowScore:      types.Float64Value(0),
+		HighScore:     types.Float64Value(0),
+		BlockMacros:   types.BoolValue(false),
+		Domains:       int64List([]int{domainID}),
+		Organizations: types.ListUnknown(types.Int64Type),
+	})
+
+	var m userModel
+	h.get(state, &m)
+	if m.ID.IsUnknown() || m.ID.ValueInt64() == 0 {
+		t.Fatalf("Expected the ID to be set got %s", m.ID)
+	}
+	if m.Timezone.IsUnknown() || m.Organizations.IsUnknown() {
+		t.Errorf("Expected the computed attributes to be known")
+	}
+	if !m.Domains.Equal(int64List([]int{domainID})) {
+		t.Errorf("Expected %s got %s", int64List([]int{domainID}), m.Domains)
+	}
+
+	plan := m
+	plan.Lastname = types.StringValue("Smith")
+	state = h.update(state, &plan)
+	if s.passwordChanges != 0 {
+		t.Errorf("Expected %d got %d", 0, s.passwordChanges)
+	}
+
+	plan.Password = types.StringValue("n3wPassw0rd")
+	state = h.update(state, &plan)
+	if s.passwordChanges != 1 {
+		t.Errorf("Expected %d got %d", 1, s.passwordChanges)
+	}
+
+	state, ok := h.read(state)
+	if !ok {
+		t.Fatalf("Expected the user to exist")
+	}
+	h.get(state, &m)
+	if m.Lastname.ValueString() != "Smith" {
+		t.Errorf("Expected %s got %s", "Smith", m.Lastname)
+	}
+
+	for _, id := range []string{fmt.Sprint(m.ID.ValueInt64()), "jdoe"} {
+		var i userModel
+		h.get(h.importState(id), &i)
+		if i.ID != m.ID || i.Username != m.Username || i.Lastname != m.Lastname || !i.Domains.Equal(m.Domains) {
+			t.Errorf("Expected %v got %v", m, i)
+		}
+	}
+
+	h.delete(state)
+	if _, ok = h.read(state); ok {
+		t.Errorf("Expected the user to be removed from the state")
+	}
+}
+
+func TestAccUserResource(t *testing.T) {
+	s := newStandIn()
+	defer s.Close()
+
+	config := fmt.Sprintf(testAccDomainConfig, "") + `
+resource "baruwa_user" "test" {
+  username = "jdoe"
+  email    = "jdoe@example.com"
+  password = "Passw0rd"
+  lastname = "%s"
+  domains  = [baruwa_domain.test.id]
+}
+`
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckDestroyed(s, "users"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, "Doe")),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("baruwa_user.test", "username", "jdoe"),
+					resource.TestCheckResourceAttr("baruwa_user.test", "account_type", "3"),
+					resource.TestCheckResourceAttrPair("baruwa_user.test", "domains.0", "baruwa_domain.test", "id"),
+				),
+			},
+			{
+				ResourceName:            "baruwa_user.test",
+				ImportState:             true,
+				ImportStateId:           "jdoe",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"password"},
+			},
+			{
+				Config: testAccConfig(s, fmt.Sprintf(config, "Smith")),
+				Check:  resource.TestCheckResourceAttr("baruwa_user.test", "lastname", "Smith"),
+			},
+		},
+	})
+}
@@ -0,0 +1,36 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Terraform provider for Baruwa
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/baruwa-enterprise/baruwa-go/terraform-provider-baruwa/internal/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+)
+
+// set by the release build
+var version = "dev"
+
+func main() {
+	var debug bool
+
+	flag.BoolVar(&debug, "debug", false, "run the provider with support for debuggers")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
+		Address: "registry.terraform.io/baruwa-enterprise/baruwa",
+		Debug:   debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,430 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+/*
+Package webhook Deliver Baruwa API change events to HTTP endpoints
+
+A Sink subscribes to the api.EventBus of one or more clients and POSTs
+each event as JSON to an endpoint. The body is signed with HMAC-SHA256
+using a shared secret, the signature is sent in the X-Baruwa-Signature
+header as sha256=<hex> and can be checked by the receiver with Verify.
+
+Events are written to an outbox file before they are sent and removed
+once the endpoint accepts them, failed deliveries are retried with an
+exponential backoff and the events still pending when the process
+exits are sent when a Sink is created with the same outbox.
+
+	bus := api.NewEventBus()
+	c, err := api.New(endpoint, token, &api.Options{Events: bus})
+	s, err := webhook.New("https://hooks.example.com/baruwa", secret, &webhook.Options{
+		Outbox: "/var/lib/baruwa/webhook.json",
+		Filter: func(e *api.Event) bool {
+			return e.Resource == "domains"
+		},
+	})
+	bus.Subscribe(s.Handle)
+	s.Start()
+	defer s.Stop()
+*/
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+const (
+	// DefaultRetries is the number of attempts made to deliver an event
+	DefaultRetries = 5
+	// DefaultBackoff is the delay before the first retry, it doubles
+	// with each attempt
+	DefaultBackoff = 5 * time.Second
+	// DefaultInterval is how often Start checks for deliveries due
+	DefaultInterval = time.Second
+	// DefaultTimeout is the timeout of the default HTTP client
+	DefaultTimeout = 10 * time.Second
+	// SignatureHeader holds the HMAC-SHA256 signature of the body
+	SignatureHeader = "X-Baruwa-Signature"
+	// EventHeader holds the event type
+	EventHeader = "X-Baruwa-Event"
+	// DeliveryHeader holds the delivery ID, it is the same for retries
+	DeliveryHeader = "X-Baruwa-Delivery"
+)
+
+const (
+	endpointError = "The endpoint param is required"
+	secretError   = "The secret param is required"
+	statusError   = "The endpoint returned %s"
+)
+
+// Delivery is an event waiting to be sent
+type Delivery struct {
+	ID          string          `json:"id"`
+	Type        api.EventType   `json:"type"`
+	Event       json.RawMessage `json:"event"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// Options represents optional settings that can be passed to New
+type Options struct {
+	// HTTPClient defaults to a client with a DefaultTimeout timeout
+	HTTPClient *http.Client
+	// Retries defaults to DefaultRetries
+	Retries int
+	// Backoff defaults to DefaultBackoff
+	Backoff time.Duration
+	// Interval defaults to DefaultInterval
+	Interval time.Duration
+	// Outbox is the file holding the pending deliveries, they are only
+	// kept in memory when it is not set
+	Outbox string
+	// Filter selects the events sent, all events are sent when it is nil
+	Filter func(e *api.Event) bool
+	// OnError is called when an attempt fails or the outbox can not be
+	// written, the delivery is dropped once d.Attempts reaches Retries
+	OnError func(d *Delivery, err error)
+}
+
+// Sink sends events to a webhook endpoint
+type Sink struct {
+	endpoint string
+	secret   string
+	client   *http.Client
+	retries  int
+	backoff  time.Duration
+	interval time.Duration
+	outbox   string
+	filter   func(e *api.Event) bool
+	onError  func(d *Delivery, err error)
+	pending  []*Delivery
+	notify   chan struct{}
+	stop     chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	run      sync.Mutex
+}
+
+// New returns a Sink, the deliveries pending in the outbox are loaded
+func New(endpoint, secret string, opts *Options) (s *Sink, err error) {
+	var b []byte
+
+	if endpoint == "" {
+		err = fmt.Errorf(endpointError)
+		return
+	}
+
+	if secret == "" {
+		err = fmt.Errorf(secretError)
+		return
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	s = &Sink{
+		endpoint: endpoint,
+		secret:   secret,
+		client:   opts.HTTPClient,
+		retries:  opts.Retries,
+		backoff:  opts.Backoff,
+		interval: opts.Interval,
+		outbox:   opts.Outbox,
+		filter:   opts.Filter,
+		onError:  opts.OnError,
+		notify:   make(chan struct{}, 1),
+	}
+
+	if s.client == nil {
+		s.client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	if s.retries <= 0 {
+		s.retries = DefaultRetries
+	}
+
+	if s.backoff <= 0 {
+		s.backoff = DefaultBackoff
+	}
+
+	if s.interval <= 0 {
+		s.interval = DefaultInterval
+	}
+
+	if s.outbox != "" {
+		if b, err = ioutil.ReadFile(s.outbox); err != nil {
+			if !os.IsNotExist(err) {
+				s = nil
+				return
+			}
+			err = nil
+		} else if err = json.Unmarshal(b, &s.pending); err != nil {
+			s = nil
+			return
+		}
+	}
+
+	return
+}
+
+// Handle queues an event, it is an api.EventHandler
+func (s *Sink) Handle(e *api.Event) {
+	var err error
+	var b []byte
+
+	if s.filter != nil && !s.filter(e) {
+		return
+	}
+
+	d := &Delivery{
+		ID:          newID(),
+		Type:        e.Type,
+		NextAttempt: time.Now(),
+	}
+
+	if b, err = json.Marshal(e); err != nil {
+		s.failed(d, err)
+		return
+	}
+	d.Event = b
+
+	s.mu.Lock()
+	s.pending = append(s.pending, d)
+	err = s.save()
+	s.mu.Unlock()
+
+	if err != nil {
+		s.failed(d, err)
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the deliveries waiting to be sent
+func (s *Sink) Pending() (l []Delivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.pending {
+		l = append(l, *d)
+	}
+
+	return
+}
+
+// Deliver sends the deliveries that are due and returns the first error
+func (s *Sink) Deliver() (err error) {
+	var due []*Delivery
+
+	s.run.Lock()
+	defer s.run.Unlock()
+
+	now := time.Now()
+
+	s.mu.Lock()
+	for _, d := range s.pending {
+		if !d.NextAttempt.After(now) {
+			due = append(due, d)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, d := range due {
+		serr := s.send(d)
+
+		s.mu.Lock()
+		if serr == nil {
+			s.remove(d)
+		} else {
+			d.Attempts++
+			d.LastError = serr.Error()
+			d.NextAttempt = time.Now().Add(s.backoff << uint(d.Attempts-1))
+			if d.Attempts >= s.retries {
+				s.remove(d)
+			}
+		}
+		werr := s.save()
+		s.mu.Unlock()
+
+		if serr != nil {
+			s.failed(d, serr)
+			if err == nil {
+				err = serr
+			}
+		}
+		if werr != nil {
+			s.failed(d, werr)
+			if err == nil {
+				err = werr
+			}
+		}
+	}
+
+	return
+}
+
+// Start delivers the events in the background
+func (s *Sink) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return
+	}
+
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func(stop chan struct{}) {
+		defer s.wg.Done()
+		t := time.NewTicker(s.interval)
+		defer t.Stop()
+		s.Deliver()
+		for {
+			select {
+			case <-t.C:
+				s.Deliver()
+			case <-s.notify:
+				s.Deliver()
+			case <-stop:
+				return
+			}
+		}
+	}(s.stop)
+}
+
+// Stop stops the background deliveries, the pending deliveries are
+// kept in the outbox
+func (s *Sink) Stop() {
+	s.mu.Lock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Sign returns the signature of a body as sent in SignatureHeader
+func Sign(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify returns true if signature is the signature of the body
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func (s *Sink) send(d *Delivery) (err error) {
+	var req *http.Request
+	var resp *http.Response
+
+	if req, err = http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(d.Event)); err != nil {
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("baruwa-go/%s", api.Version))
+	req.Header.Set(EventHeader, string(d.Type))
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SignatureHeader, Sign(s.secret, d.Event))
+
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf(statusError, resp.Status)
+	}
+
+	return
+}
+
+func (s *Sink) failed(d *Delivery, err error) {
+	if s.onError != nil {
+		s.onError(d, err)
+	}
+}
+
+func (s *Sink) remove(d *Delivery) {
+	for i, v := range s.pending {
+		if v == d {
+			s.pending = append(s.pending[:i:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// save writes the pending deliveries to the outbox, s.mu must be held
+func (s *Sink) save() (err error) {
+	var b []byte
+
+	if s.outbox == "" {
+		return
+	}
+
+	if b, err = json.Marshal(s.pending); err != nil {
+		return
+	}
+
+	err = writeFile(s.outbox, b)
+
+	return
+}
+
+func writeFile(path string, b []byte) (err error) {
+	var f *os.File
+
+	if f, err = ioutil.TempFile(filepath.Dir(path), ".webhook-"); err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return
+	}
+
+	if err = f.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(f.Name(), path)
+
+	return
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,296 @@
+// BaruwaAPI Golang bindings for Baruwa REST API
+// Copyright (C) 2019 Andrew Colin Kissa <andrew@topdog.za.net>
+
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/baruwa-enterprise/baruwa-go/api"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	fail     int
+	events   []api.Event
+	bad      int
+	requests int
+	ids      []string
+	received chan struct{}
+}
+
+func newReceiver(secret string, fail int) (*receiver, *httptest.Server) {
+	r := &receiver{fail: fail, received: make(chan struct{}, 10)}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests++
+		r.ids = append(r.ids, req.Header.Get(DeliveryHeader))
+		if !Verify(secret, body, req.Header.Get(SignatureHeader)) {
+			r.bad++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.fail > 0 {
+			r.fail--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var e api.Event
+		json.Unmarshal(body, &e)
+		if string(e.Type) != req.Header.Get(EventHeader) {
+			r.bad++
+		}
+		r.events = append(r.events, e)
+		r.received <- struct{}{}
+	}))
+
+	return r, server
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("", "secret", nil); err == nil || err.Error() != endpointError {
+		t.Errorf("Expected '%s' got '%v'", endpointError, err)
+	}
+
+	if _, err := New("http://localhost", "", nil); err == nil || err.Error() != secretError {
+		t.Errorf("Expected '%s' got '%v'", secretError, err)
+	}
+
+	s, err := New("http://localhost", "secret", nil)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if s.retries != DefaultRetries || s.backoff != DefaultBackoff || s.interval != DefaultInterval {
+		t.Errorf("Expected the defaults to be set")
+	}
+
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outbox := filepath.Join(dir, "outbox.json")
+	ioutil.WriteFile(outbox, []byte("{"), 0600)
+	if _, err = New("http://localhost", "secret", &Options{Outbox: outbox}); err == nil {
+		t.Errorf("An error should be returned")
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"type":"created"}`)
+
+	sig := Sign("secret", body)
+	if len(sig) != 71 || sig[:7] != "sha256=" {
+		t.Errorf("Expected a sha256 signature got %s", sig)
+	}
+	if !Verify("secret", body, sig) {
+		t.Errorf("Expected the signature to verify")
+	}
+	if Verify("other", body, sig) || Verify("secret", []byte("{}"), sig) {
+		t.Errorf("Expected the signature not to verify")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	r, server := newReceiver("secret", 1)
+	defer server.Close()
+
+	var errs []error
+	s, err := New(server.URL, "secret", &Options{
+		Backoff: time.Millisecond,
+		Filter: func(e *api.Event) bool {
+			return e.Resource == "domains"
+		},
+		OnError: func(d *Delivery, err error) {
+			errs = append(errs, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	s.Handle(&api.Event{Type: api.EventUpdated, Resource: "domains", ID: 3})
+	s.Handle(&api.Event{Type: api.EventCreated, Resource: "users", ID: 4})
+	if n := len(s.Pending()); n != 1 {
+		t.Fatalf("Expected %d got %d", 1, n)
+	}
+
+	if err = s.Deliver(); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	p := s.Pending()
+	if len(p) != 1 || p[0].Attempts != 1 || p[0].LastError != "The endpoint returned 503 Service Unavailable" {
+		t.Fatalf("Expected the delivery to be retried got %v", p)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected %d got %d", 1, len(errs))
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if err = s.Deliver(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if len(s.Pending()) != 0 {
+		t.Errorf("Expected the delivery to be removed")
+	}
+	if len(r.events) != 1 || r.events[0].ID != 3 || r.events[0].Type != api.EventUpdated || r.bad != 0 {
+		t.Errorf("Expected the event to be received got %v", r.events)
+	}
+	if r.ids[0] != r.ids[1] {
+		t.Errorf("Expected the retry to keep the delivery ID")
+	}
+}
+
+func TestDeliverDropped(t *testing.T) {
+	r, server := newReceiver("other", 0)
+	defer server.Close()
+
+	var dropped *Delivery
+	s, err := New(server.URL, "secret", &Options{
+		Retries: 2,
+		Backoff: time.Hour,
+		OnError: func(d *Delivery, err error) {
+			if d.Attempts >= 2 {
+				dropped = d
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	s.Handle(&api.Event{Type: api.EventDeleted, Resource: "domains", ID: 3})
+	s.Deliver()
+	if err = s.Deliver(); err != nil {
+		t.Fatalf("Expected the delivery to wait for the backoff got %s", err)
+	}
+	if r.requests != 1 {
+		t.Errorf("Expected %d got %d", 1, r.requests)
+	}
+
+	s.mu.Lock()
+	s.pending[0].NextAttempt = time.Now()
+	s.mu.Unlock()
+	if err = s.Deliver(); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+	if len(s.Pending()) != 0 || dropped == nil || dropped.Attempts != 2 {
+		t.Errorf("Expected the delivery to be dropped got %v", s.Pending())
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	outbox := filepath.Join(dir, "outbox.json")
+	s, err := New("http://127.0.0.1:1", "secret", &Options{Outbox: outbox})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		s.Handle(&api.Event{Type: api.EventCreated, Resource: "domains", ID: i})
+	}
+	if err = s.Deliver(); err == nil {
+		t.Fatalf("An error should be returned")
+	}
+
+	r, server := newReceiver("secret", 0)
+	defer server.Close()
+
+	if s, err = New(server.URL, "secret", &Options{Outbox: outbox}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	p := s.Pending()
+	if len(p) != 3 || p[0].Attempts != 1 {
+		t.Fatalf("Expected the pending deliveries to be loaded got %v", p)
+	}
+
+	for i := range p {
+		s.pending[i].NextAttempt = time.Now()
+	}
+	if err = s.Deliver(); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	for i, e := range r.events {
+		if e.ID != i+1 {
+			t.Errorf("Expected %d got %d", i+1, e.ID)
+		}
+	}
+
+	b, err := ioutil.ReadFile(outbox)
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	if string(b) != "[]" {
+		t.Errorf("Expected an empty outbox got %s", b)
+	}
+}
+
+func TestStart(t *testing.T) {
+	r, server := newReceiver("secret", 0)
+	defer server.Close()
+
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 3, "name": "example.com", "status": false}`)
+	}))
+	defer apiServer.Close()
+
+	bus := api.NewEventBus()
+	c, err := api.New(apiServer.URL, "test-token", &api.Options{Events: bus})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	s, err := New(server.URL, "secret", &Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+	bus.Subscribe(s.Handle)
+	s.Start()
+	s.Start()
+	defer s.Stop()
+
+	if err = c.UpdateDomain(&api.Domain{ID: 3, Name: "example.com"}); err != nil {
+		t.Fatalf("An error should not be returned: %s", err)
+	}
+
+	select {
+	case <-r.received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the event to be delivered")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.events[0]
+	if e.Type != api.EventUpdated || e.Resource != "domains" || e.ID != 3 {
+		t.Errorf("Expected the domain update got %v", e)
+	}
+	if after, ok := e.After.(map[string]interface{}); !ok || after["status"] != false {
+		t.Errorf("Expected the domain to be sent got %v", e.After)
+	}
+}